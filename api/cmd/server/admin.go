@@ -0,0 +1,230 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Admin API
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// adminMiddleware restricts a handler to requests carrying ADMIN_KEY in the X-Admin-Key header
+func adminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminKey := os.Getenv("ADMIN_KEY")
+		if adminKey == "" {
+			http.Error(w, "admin API disabled (ADMIN_KEY not set)", http.StatusForbidden)
+			return
+		}
+
+		provided := r.Header.Get("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(adminKey)) != 1 {
+			http.Error(w, "invalid admin key", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              CUSTOM CHAINS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// CustomChain is an EVM-compatible chain registered at runtime
+type CustomChain struct {
+	ID              string `json:"id"`
+	RPCURL          string `json:"rpcUrl"`
+	ChainNumericID  int    `json:"chainNumericId"`
+	EtherscanURL    string `json:"etherscanUrl,omitempty"`
+	EtherscanAPIKey string `json:"etherscanApiKey,omitempty"`
+}
+
+// customChains holds runtime-registered chains by ID (guarded by chainsMu)
+var customChains = map[string]CustomChain{}
+
+var chainIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,31}$`)
+
+// validate checks a custom chain definition before registration
+func (cc CustomChain) validate() error {
+	if !chainIDPattern.MatchString(cc.ID) {
+		return fmt.Errorf("id must be 2-32 lowercase letters, digits or dashes")
+	}
+	if err := validateHTTPURL(cc.RPCURL); err != nil {
+		return fmt.Errorf("rpcUrl: %w", err)
+	}
+	if cc.ChainNumericID <= 0 {
+		return fmt.Errorf("chainNumericId must be a positive integer")
+	}
+	if cc.EtherscanURL != "" {
+		if err := validateHTTPURL(cc.EtherscanURL); err != nil {
+			return fmt.Errorf("etherscanUrl: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateHTTPURL ensures raw is an absolute http(s) URL
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("must be an http(s) URL")
+	}
+	return nil
+}
+
+// registerChain adds a custom chain to the config, Etherscan settings and chain clients
+func (s *Server) registerChain(cc CustomChain) error {
+	if err := cc.validate(); err != nil {
+		return err
+	}
+
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+
+	if _, exists := config.RPC[cc.ID]; exists {
+		return fmt.Errorf("chain %q is already registered", cc.ID)
+	}
+
+	chain := ChainID(cc.ID)
+	config.RPC[cc.ID] = []string{cc.RPCURL}
+	etherscanConfig.ChainIDs[cc.ID] = cc.ChainNumericID
+	if cc.EtherscanURL != "" {
+		etherscanConfig.Explorers[cc.ID] = ExplorerConfig{URL: cc.EtherscanURL, APIKey: cc.EtherscanAPIKey}
+	}
+	AllChains = append(AllChains, chain)
+
+	// The server, scanner and contract analyzer normally share one client map
+	client := NewChainClient(chain, cc.RPCURL, s.logger())
+	s.chainClients[chain] = client
+	if scanner, ok := s.scanner.(*Scanner); ok {
+		scanner.clients[chain] = client
+	}
+
+	customChains[cc.ID] = cc
+	s.logger().Info("Registered custom chain", Fields{"chain": cc.ID, "chain_id": cc.ChainNumericID})
+	return nil
+}
+
+// loadCustomChains registers chains persisted by a previous run
+func (s *Server) loadCustomChains(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var chains []CustomChain
+	if err := json.Unmarshal(data, &chains); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, cc := range chains {
+		if err := s.registerChain(cc); err != nil {
+			s.logger().Warn("Skipping custom chain", Fields{"chain": cc.ID, "error": errorText(err)})
+		}
+	}
+	return nil
+}
+
+// saveCustomChains persists runtime-registered chains, writing atomically via rename
+func saveCustomChains(path string) error {
+	chainsMu.RLock()
+	chains := make([]CustomChain, 0, len(customChains))
+	for _, cc := range customChains {
+		chains = append(chains, cc)
+	}
+	chainsMu.RUnlock()
+
+	if len(chains) == 0 {
+		return nil
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].ID < chains[j].ID })
+
+	data, err := json.MarshalIndent(chains, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".custom-chains-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Admin chain registry endpoint - GET lists chains, POST registers a new one
+func (s *Server) handleAdminChains(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		type chainInfo struct {
+			ID             ChainID `json:"id"`
+			ChainNumericID int     `json:"chainNumericId,omitempty"`
+			Custom         bool    `json:"custom"`
+			RPCURL         string  `json:"rpcUrl,omitempty"`
+		}
+
+		chainsMu.RLock()
+		chains := make([]chainInfo, 0, len(AllChains))
+		for _, chain := range AllChains {
+			info := chainInfo{ID: chain, ChainNumericID: etherscanConfig.ChainIDs[string(chain)]}
+			// Only expose RPC URLs of custom chains - built-in ones embed API keys
+			if cc, ok := customChains[string(chain)]; ok {
+				info.Custom = true
+				info.RPCURL = cc.RPCURL
+			}
+			chains = append(chains, info)
+		}
+		chainsMu.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"chains": chains,
+		})
+
+	case http.MethodPost:
+		var cc CustomChain
+		if err := json.NewDecoder(r.Body).Decode(&cc); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.registerChain(cc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":             cc.ID,
+			"chainNumericId": cc.ChainNumericID,
+			"registered":     true,
+		})
+
+	default:
+		http.Error(w, "GET or POST method required", http.StatusMethodNotAllowed)
+	}
+}
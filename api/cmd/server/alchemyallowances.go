@@ -0,0 +1,221 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Alchemy Token Allowances
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// maxTokenAllowancePages caps the pages read from alchemy_getTokenAllowances per scan
+const maxTokenAllowancePages = 20
+
+// maxAllowanceChecks caps the concurrent alchemy_getTokenAllowance calls per scan
+const maxAllowanceChecks = 8
+
+// tokenAllowancesUnsupported records the endpoints that answered
+// alchemy_getTokenAllowances with method-not-found, so later scans go straight to
+// eth_getLogs
+var tokenAllowancesUnsupported sync.Map
+
+// tokenAllowanceUnsupported records the endpoints that answered
+// alchemy_getTokenAllowance with method-not-found, so later scans keep the allowances
+// replayed from events
+var tokenAllowanceUnsupported sync.Map
+
+// isMethodNotFound reports whether a JSON-RPC call failed because the endpoint doesn't
+// implement the method
+func isMethodNotFound(err error) bool {
+	var rpcErr *jsonRPCError
+	return errors.As(err, &rpcErr) && rpcErr.Code == -32601
+}
+
+// tokenAllowance is one entry of an alchemy_getTokenAllowances response
+type tokenAllowance struct {
+	TokenAddress   string `json:"tokenAddress"`
+	SpenderAddress string `json:"spenderAddress"`
+	Allowance      string `json:"allowance"` // hex quantity or decimal
+}
+
+// parseAllowance parses a 0x-prefixed hex or decimal allowance
+func parseAllowance(s string) (*big.Int, bool) {
+	if hex, ok := strings.CutPrefix(s, "0x"); ok {
+		if hex == "" {
+			return new(big.Int), true
+		}
+		return new(big.Int).SetString(hex, 16)
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+// getApprovalsAlchemyV2 reads a wallet's current ERC20 allowances with
+// alchemy_getTokenAllowances, which returns the allowance state directly instead of
+// replaying Approval events, so missing or truncated logs can't leave stale approvals.
+// NFT ApprovalForAll events still come from eth_getLogs.
+func (c *ChainClient) getApprovalsAlchemyV2(ctx context.Context, walletAddress string, endpoint string) (*ChainApprovals, error) {
+	var allowances []tokenAllowance
+	truncated := false
+	pageKey := ""
+	for page := 0; ; page++ {
+		if page == maxTokenAllowancePages {
+			truncated = true
+			break
+		}
+		params := map[string]interface{}{"account": walletAddress, "contractAddresses": []string{}}
+		if pageKey != "" {
+			params["pageKey"] = pageKey
+		}
+		var result struct {
+			TokenAllowances []tokenAllowance `json:"tokenAllowances"`
+			PageKey         string           `json:"pageKey"`
+		}
+		// Not retried: a failure falls back to eth_getLogs, which is
+		if err := c.rpcCallURL(ctx, endpoint, "alchemy_getTokenAllowances", []interface{}{params}, &result); err != nil {
+			return nil, err
+		}
+		allowances = append(allowances, result.TokenAllowances...)
+		if pageKey = result.PageKey; pageKey == "" {
+			break
+		}
+	}
+
+	// NFT ApprovalForAll events are fetched while the allowances are processed
+	applyApprovalForAll := c.fetchApprovalForAll(ctx, walletAddress, endpoint, blockRange{})
+
+	totalEvents := len(allowances)
+	if limit := config.MaxApprovalsPerChain; limit > 0 && len(allowances) > limit {
+		allowances = allowances[:limit]
+		truncated = true
+	}
+	if truncated {
+		requestLogger(ctx, c.logger()).Warn("Truncated token allowances", Fields{"wallet": walletAddress, "allowances": len(allowances), "total_allowances": totalEvents})
+	}
+
+	tokens := make([]string, 0, len(allowances))
+	for _, allowance := range allowances {
+		tokens = append(tokens, allowance.TokenAddress)
+	}
+	c.prefetchTokenMetadata(ctx, tokens)
+
+	latestApprovals := make(map[string]Approval)
+	for _, entry := range allowances {
+		allowance, ok := parseAllowance(entry.Allowance)
+		if !ok || allowance.Sign() == 0 || !isValidEthereumAddress(entry.TokenAddress) || !isValidEthereumAddress(entry.SpenderAddress) {
+			continue
+		}
+		if c.isNFTContract(entry.TokenAddress) {
+			continue
+		}
+		tokenAddress := strings.ToLower(entry.TokenAddress)
+		spenderAddress := strings.ToLower(entry.SpenderAddress)
+		latestApprovals[approvalKey(tokenAddress, spenderAddress)] = c.erc20Approval(ctx, tokenAddress, spenderAddress, allowance)
+	}
+
+	nftEvents, nftTruncated, nftOK := applyApprovalForAll(latestApprovals, map[string]bool{})
+
+	approvals := make([]Approval, 0, len(latestApprovals))
+	for _, approval := range latestApprovals {
+		approvals = append(approvals, approval)
+	}
+
+	requestLogger(ctx, c.logger()).Info("Found active approvals", Fields{"wallet": walletAddress, "provider": "alchemy_getTokenAllowances", "approvals_found": len(approvals)})
+	return &ChainApprovals{
+		Approvals:   approvals,
+		TotalEvents: totalEvents + nftEvents,
+		Truncated:   truncated || nftTruncated,
+		Source:      "alchemy",
+		Incomplete:  !nftOK,
+		Revoked:     []string{},
+	}, nil
+}
+
+// getTokenAllowance returns the current allowance of owner to spender on token with
+// alchemy_getTokenAllowance
+func (c *ChainClient) getTokenAllowance(ctx context.Context, endpoint, token, owner, spender string) (*big.Int, error) {
+	var allowance string
+	params := []interface{}{map[string]string{"contract": token, "owner": owner, "spender": spender}}
+	if err := c.rpcCallURL(ctx, endpoint, "alchemy_getTokenAllowance", params, &allowance); err != nil {
+		return nil, err
+	}
+	value, ok := parseAllowance(allowance)
+	if !ok {
+		return nil, errors.New("invalid allowance " + allowance)
+	}
+	return value, nil
+}
+
+// confirmAllowances replaces the ERC20 allowances replayed from Approval events with
+// the tokens' current allowances, so allowances spent by transferFrom (which many
+// tokens don't log) or revoked by missing events aren't reported. Approvals keep
+// their event's transaction and block; pairs whose lookup fails keep the replayed value.
+func (c *ChainClient) confirmAllowances(ctx context.Context, endpoint, walletAddress string, result *ChainApprovals) {
+	if _, unsupported := tokenAllowanceUnsupported.Load(endpoint); unsupported {
+		return
+	}
+
+	current := make([]*big.Int, len(result.Approvals))
+	sem := make(chan struct{}, maxAllowanceChecks)
+	var wg sync.WaitGroup
+	for i, approval := range result.Approvals {
+		if approval.IsNFT || approval.ViaPermit2 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, approval Approval) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if _, unsupported := tokenAllowanceUnsupported.Load(endpoint); unsupported {
+				return
+			}
+			allowance, err := c.getTokenAllowance(ctx, endpoint, approval.TokenAddress, walletAddress, approval.SpenderAddress)
+			if err != nil {
+				if isMethodNotFound(err) {
+					tokenAllowanceUnsupported.Store(endpoint, true)
+					requestLogger(ctx, c.logger()).Info("alchemy_getTokenAllowance not supported, keeping event allowances", Fields{"wallet": walletAddress})
+					return
+				}
+				requestLogger(ctx, c.logger()).Debug("Allowance lookup failed", Fields{"wallet": walletAddress, "token": approval.TokenAddress, "error": errorText(err)})
+				return
+			}
+			current[i] = allowance
+		}(i, approval)
+	}
+	wg.Wait()
+
+	confirmed := result.Approvals[:0]
+	for i, approval := range result.Approvals {
+		allowance := current[i]
+		switch {
+		case allowance == nil || allowance.String() == approval.AllowanceRaw:
+		case allowance.Sign() == 0:
+			continue // spent or revoked since the last event
+		default:
+			updated := c.erc20Approval(ctx, approval.TokenAddress, approval.SpenderAddress, allowance)
+			updated.TxHash, updated.LogIndex = approval.TxHash, approval.LogIndex
+			updated.BlockNumber, updated.LastUpdated = approval.BlockNumber, approval.LastUpdated
+			approval = updated
+		}
+		confirmed = append(confirmed, approval)
+	}
+	result.Approvals = confirmed
+}
+
+// confirmStoredAllowances confirms approvals merged from an earlier scan's state, whose
+// allowances may have been spent since, against the chain's first Alchemy endpoint.
+// Chains without one keep the replayed values.
+func (c *ChainClient) confirmStoredAllowances(ctx context.Context, walletAddress string, result *ChainApprovals) {
+	endpoints := c.alchemyEndpoints()
+	if len(endpoints) == 0 {
+		return
+	}
+	c.confirmAllowances(ctx, endpoints[0], walletAddress, result)
+}
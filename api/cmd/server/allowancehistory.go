@@ -0,0 +1,198 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Allowance History
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// approvalEventTopic is keccak256("Approval(address,address,uint256)")
+const approvalEventTopic = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+
+// maxHistoryTimestampLookups caps eth_getBlockByNumber calls per history request
+const maxHistoryTimestampLookups = 50
+
+// HistoricalApproval is a single Approval event of a token+spender pair
+type HistoricalApproval struct {
+	BlockNumber    uint64 `json:"blockNumber"`
+	TxHash         string `json:"txHash"`
+	LogIndex       int    `json:"logIndex"`
+	AllowanceRaw   string `json:"allowanceRaw"`
+	AllowanceHuman string `json:"allowanceHuman"`
+	IsUnlimited    bool   `json:"isUnlimited"`
+	Timestamp      int64  `json:"timestamp"` // unix seconds, 0 = unknown
+}
+
+// AllowanceHistoryResponse is returned by /api/v1/allowance/history
+type AllowanceHistoryResponse struct {
+	Chain       ChainID              `json:"chain"`
+	Wallet      string               `json:"wallet"`
+	Token       string               `json:"token"`
+	TokenSymbol string               `json:"tokenSymbol"`
+	Spender     string               `json:"spender"`
+	SpenderName string               `json:"spenderName"`
+	Source      string               `json:"source"` // "alchemy" or "etherscan"
+	Events      []HistoricalApproval `json:"events"` // oldest first
+}
+
+// padTopicAddress left-pads an address to a 32-byte log topic
+func padTopicAddress(address string) string {
+	return "0x000000000000000000000000" + strings.TrimPrefix(strings.ToLower(address), "0x")
+}
+
+// GetAllowanceHistory returns every Approval event from wallet to spender on token,
+// ordered by block. Unlike GetApprovals, earlier events are kept.
+func (c *ChainClient) GetAllowanceHistory(ctx context.Context, walletAddress, tokenAddress, spenderAddress string) ([]HistoricalApproval, string, error) {
+	logs, source, err := c.getContractLogs(ctx, tokenAddress,
+		[]string{approvalEventTopic, padTopicAddress(walletAddress), padTopicAddress(spenderAddress)})
+	if err != nil {
+		return nil, source, err
+	}
+
+	decimals := c.tokenDecimalsOrDefault(ctx, tokenAddress)
+	events := make([]HistoricalApproval, 0, len(logs))
+	for _, logEntry := range logs {
+		allowance, ok := new(big.Int).SetString(strings.TrimPrefix(logEntry.Data, "0x"), 16)
+		if !ok {
+			allowance = big.NewInt(0)
+		}
+		events = append(events, HistoricalApproval{
+			BlockNumber:    parseHexUint64(logEntry.BlockNumber),
+			TxHash:         logEntry.TxHash,
+			LogIndex:       int(parseHexUint64(logEntry.LogIndex)),
+			AllowanceRaw:   allowance.String(),
+			AllowanceHuman: formatAllowanceWithDecimals(allowance, decimals),
+			IsUnlimited:    isUnlimitedWithDecimals(allowance, decimals),
+			Timestamp:      int64(parseHexUint64(logEntry.TimeStamp)),
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].LogIndex < events[j].LogIndex
+	})
+
+	c.fillBlockTimestamps(ctx, events)
+	return events, source, nil
+}
+
+// getContractLogs fetches all logs of contractAddress matching topics (topic0, topic1, ...),
+// via Alchemy when configured for the chain and the chain's explorer otherwise.
+// An empty topic matches any value.
+func (c *ChainClient) getContractLogs(ctx context.Context, contractAddress string, topics []string) ([]approvalLog, string, error) {
+	if endpoint, ok := alchemyConfig.Endpoints[string(c.ChainID)]; ok {
+		filterTopics := make([]interface{}, len(topics))
+		for i, topic := range topics {
+			if topic != "" {
+				filterTopics[i] = topic
+			}
+		}
+		logs, err := c.getLogsAlchemy(ctx, endpoint, map[string]interface{}{
+			"fromBlock": "0x0",
+			"toBlock":   "latest",
+			"address":   contractAddress,
+			"topics":    filterTopics,
+		})
+		if err == nil {
+			return logs, "alchemy", nil
+		}
+		requestLogger(ctx, c.logger()).Warn("Alchemy log lookup failed, trying Etherscan", Fields{"error": errorText(err)})
+	}
+
+	query := "module=logs&action=getLogs&fromBlock=0&toBlock=latest&address=" + contractAddress
+	previous := -1
+	for i, topic := range topics {
+		if topic == "" {
+			continue
+		}
+		if previous >= 0 {
+			query += fmt.Sprintf("&topic%d_%d_opr=and", previous, i)
+		}
+		query += fmt.Sprintf("&topic%d=%s", i, topic)
+		previous = i
+	}
+
+	var logs []approvalLog
+	if err := c.etherscanQuery(ctx, query, &logs); err != nil {
+		if strings.Contains(err.Error(), "No records found") {
+			return []approvalLog{}, "etherscan", nil
+		}
+		return nil, "etherscan", err
+	}
+	return logs, "etherscan", nil
+}
+
+// fillBlockTimestamps looks up block timestamps for events that don't carry one
+// (eth_getLogs has no timestamps; Etherscan logs do)
+func (c *ChainClient) fillBlockTimestamps(ctx context.Context, events []HistoricalApproval) {
+	timestamps := make(map[uint64]int64)
+	for i := range events {
+		if events[i].Timestamp != 0 {
+			continue
+		}
+		events[i].Timestamp = c.blockTime(ctx, events[i].BlockNumber, timestamps, maxHistoryTimestampLookups)
+	}
+}
+
+// Allowance history endpoint
+func (s *Server) handleAllowanceHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	chain := ChainID(strings.ToLower(query.Get("chain")))
+	if chain == "" {
+		chain = Ethereum
+	}
+	wallet, token, spender := query.Get("wallet"), query.Get("token"), query.Get("spender")
+
+	for name, addr := range map[string]string{"wallet": wallet, "token": token, "spender": spender} {
+		if !isValidEthereumAddress(addr) {
+			http.Error(w, fmt.Sprintf("%s parameter must be a 0x address", name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	chainsMu.RLock()
+	client, ok := s.chainClients[chain]
+	chainsMu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported chain: %s", chain), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	events, source, err := client.GetAllowanceHistory(ctx, wallet, token, spender)
+	if err != nil {
+		http.Error(w, "failed to fetch allowance history: "+redactURLs(err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	spenderName, _ := getSpenderInfo(spender)
+	response := AllowanceHistoryResponse{
+		Chain:       chain,
+		Wallet:      wallet,
+		Token:       token,
+		TokenSymbol: getTokenSymbol(token, client),
+		Spender:     spender,
+		SpenderName: spenderName,
+		Source:      source,
+		Events:      events,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,156 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - API v2 Envelope
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// apiVersion is reported by /health and in every v2 envelope
+const apiVersion = "1.0.0"
+
+// ResponseEnvelope wraps every /api/v2 response
+type ResponseEnvelope struct {
+	Version   string          `json:"version"`
+	Timestamp int64           `json:"timestamp"`
+	RequestID string          `json:"requestId"`
+	Data      json.RawMessage `json:"data"`            // null on errors
+	Error     string          `json:"error,omitempty"` // plain-text error of the v1 handler
+	Warnings  []string        `json:"warnings"`
+}
+
+// responseBuffer captures a v1 handler's response so it can be re-sent in an envelope
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *responseBuffer) Header() http.Header         { return b.header }
+func (b *responseBuffer) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *responseBuffer) WriteHeader(status int)      { b.status = status }
+
+// writeEnvelope sends a buffered v1 response wrapped in a ResponseEnvelope. JSON bodies
+// become data; anything else (http.Error text) becomes error.
+func writeEnvelope(w http.ResponseWriter, r *http.Request, buf *responseBuffer, warnings []string) {
+	envelope := ResponseEnvelope{
+		Version:   apiVersion,
+		Timestamp: time.Now().Unix(),
+		RequestID: requestIDOrNew(r.Context()),
+		Warnings:  warnings,
+	}
+	if envelope.Warnings == nil {
+		envelope.Warnings = []string{}
+	}
+	if body := bytes.TrimSpace(buf.body.Bytes()); json.Valid(body) {
+		envelope.Data = body
+	} else {
+		envelope.Error = string(body)
+	}
+
+	for key, values := range buf.header {
+		if key == "Content-Length" {
+			continue
+		}
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-ID", envelope.RequestID)
+	w.WriteHeader(buf.status)
+	_ = json.NewEncoder(w).Encode(envelope)
+}
+
+// envelopeMiddleware serves a v1 handler under /api/v2 with the response enveloped
+func envelopeMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf := newResponseBuffer()
+		next(buf, r)
+		writeEnvelope(w, r, buf, nil)
+	}
+}
+
+// Scan wallet endpoint (v2) - handleScan in an envelope, with chain errors and
+// truncated chains surfaced as warnings
+func (s *Server) handleScanV2(w http.ResponseWriter, r *http.Request) {
+	// CSV exports can't be enveloped; v2 serves the same file
+	if wantsCSV(r) {
+		s.handleScan(w, r)
+		return
+	}
+
+	// Envelopes carry the request ID and a fresh timestamp, so the v1 ETag doesn't apply
+	r = r.Clone(r.Context())
+	r.Header.Del("If-None-Match")
+	buf := newResponseBuffer()
+	s.handleScan(buf, r)
+	buf.header.Del("ETag")
+
+	var warnings []string
+	if buf.status == http.StatusOK {
+		var result WalletScanResult
+		if err := json.Unmarshal(buf.body.Bytes(), &result); err == nil {
+			warnings = scanWarnings(&result)
+		}
+	}
+	writeEnvelope(w, r, buf, warnings)
+}
+
+// scanWarnings lists the parts of a scan that may be incomplete, in chain order
+func scanWarnings(result *WalletScanResult) []string {
+	var warnings []string
+	for _, chain := range result.ChainsScanned {
+		if stats, ok := result.ChainScanStats[chain]; ok && stats.Error != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: scan failed: %s", chain, stats.Error))
+		} else if ok && stats.Skipped {
+			warnings = append(warnings, fmt.Sprintf("%s: skipped: provider circuit breaker open", chain))
+		}
+	}
+	for _, chain := range result.TruncatedChains {
+		warnings = append(warnings, fmt.Sprintf("%s: approval history truncated", chain))
+	}
+	return warnings
+}
+
+// v1SunsetHeader formats API_V1_SUNSET_DATE (YYYY-MM-DD or an HTTP date) for the
+// Sunset header, returning "" when it is unset or invalid
+func v1SunsetHeader() string {
+	raw := strings.TrimSpace(os.Getenv("API_V1_SUNSET_DATE"))
+	if raw == "" {
+		return ""
+	}
+	for _, layout := range []string{"2006-01-02", http.TimeFormat, time.RFC3339} {
+		if sunset, err := time.Parse(layout, raw); err == nil {
+			return sunset.UTC().Format(http.TimeFormat)
+		}
+	}
+	defaultLogger.Warn("Ignoring invalid API_V1_SUNSET_DATE", Fields{"value": raw})
+	return ""
+}
+
+// deprecatedV1 marks a v1 route as deprecated in favor of its /api/v2 successor
+func deprecatedV1(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecated", "true")
+		if sunset := v1SunsetHeader(); sunset != "" {
+			w.Header().Set("Sunset", sunset)
+		}
+		successor := "/api/v2/" + strings.TrimPrefix(r.URL.Path, "/api/v1/")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next(w, r)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Approval Timestamps
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxAssetTransferPages caps the alchemy_getAssetTransfers pages read per scan
+const maxAssetTransferPages = 5
+
+// maxApprovalTimestampLookups caps the eth_getBlockByNumber calls per chain scan
+const maxApprovalTimestampLookups = 50
+
+// assetTransfer is one entry of an alchemy_getAssetTransfers response
+type assetTransfer struct {
+	Hash        string `json:"hash"`
+	RawContract struct {
+		Address string `json:"address"` // token contract
+	} `json:"rawContract"`
+	Metadata struct {
+		BlockTimestamp string `json:"blockTimestamp"` // RFC 3339
+	} `json:"metadata"`
+}
+
+// timestamp returns the transfer's block time in unix seconds, 0 if unknown
+func (t assetTransfer) timestamp() int64 {
+	ts, err := time.Parse(time.RFC3339, t.Metadata.BlockTimestamp)
+	if err != nil {
+		return 0
+	}
+	return ts.Unix()
+}
+
+// getOutgoingAssetTransfers returns the wallet's outgoing ERC20, ERC721 and ERC1155
+// transfers, newest first, read from alchemy_getAssetTransfers on the chain's first
+// Alchemy endpoint. complete is false when older transfers were left unread.
+func (c *ChainClient) getOutgoingAssetTransfers(ctx context.Context, walletAddress string) (transfers []assetTransfer, complete bool, err error) {
+	endpoints := c.alchemyEndpoints()
+	if len(endpoints) == 0 {
+		return nil, false, fmt.Errorf("no Alchemy endpoint for %s", c.ChainID)
+	}
+
+	pageKey := ""
+	for page := 0; page < maxAssetTransferPages; page++ {
+		params := map[string]interface{}{
+			"fromBlock":    "0x0",
+			"toBlock":      "latest",
+			"fromAddress":  walletAddress,
+			"category":     []string{"erc20", "erc721", "erc1155"},
+			"withMetadata": true,
+			"order":        "desc",
+		}
+		if pageKey != "" {
+			params["pageKey"] = pageKey
+		}
+		var result struct {
+			Transfers []assetTransfer `json:"transfers"`
+			PageKey   string          `json:"pageKey"`
+		}
+		if err := c.rpcCallURL(ctx, endpoints[0], "alchemy_getAssetTransfers", []interface{}{params}, &result); err != nil {
+			return transfers, false, err
+		}
+		transfers = append(transfers, result.Transfers...)
+		if pageKey = result.PageKey; pageKey == "" {
+			return transfers, true, nil
+		}
+	}
+	return transfers, false, nil
+}
+
+// getAssetTransferTimestamps returns the block times of the wallet's outgoing ERC20,
+// ERC721 and ERC1155 transfers by lowercase transaction hash
+func (c *ChainClient) getAssetTransferTimestamps(ctx context.Context, walletAddress string) (map[string]int64, error) {
+	transfers, _, err := c.getOutgoingAssetTransfers(ctx, walletAddress)
+	timestamps := make(map[string]int64)
+	for _, transfer := range transfers {
+		if ts := transfer.timestamp(); ts != 0 {
+			timestamps[strings.ToLower(transfer.Hash)] = ts
+		}
+	}
+	return timestamps, err
+}
+
+// annotateBlockTimestamps sets LastUpdated of approvals without one to the block time
+// of their transaction: from the wallet's asset transfers when the transaction also
+// moved tokens (e.g. approve-and-swap), otherwise from the block header
+func (c *ChainClient) annotateBlockTimestamps(ctx context.Context, walletAddress string, approvals []Approval) {
+	undated := 0
+	for _, approval := range approvals {
+		if approval.LastUpdated == 0 && approval.BlockNumber > 0 {
+			undated++
+		}
+	}
+	if undated == 0 {
+		return
+	}
+
+	var transfers map[string]int64
+	if len(c.alchemyEndpoints()) > 0 {
+		var err error
+		if transfers, err = c.getAssetTransferTimestamps(ctx, walletAddress); err != nil {
+			requestLogger(ctx, c.logger()).Debug("Asset transfer lookup failed", Fields{"wallet": walletAddress, "error": errorText(err)})
+		}
+	}
+
+	blocks := make(map[uint64]int64)
+	for i := range approvals {
+		approval := &approvals[i]
+		if approval.LastUpdated != 0 || approval.BlockNumber == 0 {
+			continue
+		}
+		if ts, ok := transfers[strings.ToLower(approval.TxHash)]; ok && approval.TxHash != "" {
+			approval.LastUpdated = ts
+			continue
+		}
+		approval.LastUpdated = c.blockTime(ctx, approval.BlockNumber, blocks, maxApprovalTimestampLookups)
+	}
+}
+
+// blockTime returns the timestamp of a block from its header, 0 if unknown. Lookups
+// are cached in times, failed ones included, and capped at limit blocks per map.
+func (c *ChainClient) blockTime(ctx context.Context, block uint64, times map[uint64]int64, limit int) int64 {
+	if ts, ok := times[block]; ok || block == 0 || len(times) >= limit {
+		return ts
+	}
+	var header struct {
+		Timestamp string `json:"timestamp"`
+	}
+	var ts int64
+	if err := c.rpcCall(ctx, "eth_getBlockByNumber", []interface{}{fmt.Sprintf("0x%x", block), false}, &header); err == nil {
+		ts = int64(parseHexUint64(header.Timestamp))
+	}
+	times[block] = ts
+	return ts
+}
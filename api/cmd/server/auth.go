@@ -0,0 +1,256 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - API Key Authentication
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// apiKeyHashPattern matches the hex SHA-256 hash keys are configured by
+var apiKeyHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// APIKey is a configured API key, identified by the SHA-256 hash of the key itself
+type APIKey struct {
+	Hash string `json:"hash"`
+	// Wallets scopes the key to these wallets (empty = any wallet)
+	Wallets []string `json:"wallets,omitempty"`
+}
+
+// APIKeyStore holds the accepted API keys. While it is empty authentication is disabled.
+type APIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*APIKey // by hash
+}
+
+// NewAPIKeyStore creates an empty store
+func NewAPIKeyStore() *APIKeyStore {
+	return &APIKeyStore{keys: make(map[string]*APIKey)}
+}
+
+// apiKeys is checked by AuthMiddleware; loaded from API_KEYS, extended via the admin API
+var apiKeys = loadAPIKeys(os.Getenv("API_KEYS"))
+
+// loadAPIKeys parses API_KEYS: comma-separated SHA-256 hex hashes of the keys, each
+// optionally scoped to a wallet as "<hash>:wallet:0x..." (repeat the hash for more wallets)
+func loadAPIKeys(spec string) *APIKeyStore {
+	store := NewAPIKeyStore()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, err := parseAPIKeyEntry(entry)
+		if err != nil {
+			defaultLogger.Warn("Skipping invalid API_KEYS entry", Fields{"error": err.Error()})
+			continue
+		}
+		store.Add(key)
+	}
+	return store
+}
+
+// parseAPIKeyEntry parses one API_KEYS entry
+func parseAPIKeyEntry(entry string) (APIKey, error) {
+	hash, scope, scoped := strings.Cut(entry, ":")
+	key := APIKey{Hash: strings.ToLower(hash)}
+	if !apiKeyHashPattern.MatchString(key.Hash) {
+		return APIKey{}, fmt.Errorf("key must be a hex SHA-256 hash")
+	}
+	if scoped {
+		wallet, ok := strings.CutPrefix(scope, "wallet:")
+		if !ok || !isValidEthereumAddress(wallet) && !isValidSolanaAddress(wallet) {
+			return APIKey{}, fmt.Errorf("scope of %s... must be wallet:<address>", key.Hash[:8])
+		}
+		key.Wallets = []string{historyKey(wallet)}
+	}
+	return key, nil
+}
+
+// Add registers a key, merging wallet scopes when the hash is already known. A key
+// added without wallets becomes unscoped.
+func (st *APIKeyStore) Add(key APIKey) {
+	key.Hash = strings.ToLower(key.Hash)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	existing, ok := st.keys[key.Hash]
+	if !ok {
+		st.keys[key.Hash] = &APIKey{Hash: key.Hash, Wallets: key.Wallets}
+		return
+	}
+	if len(existing.Wallets) == 0 {
+		return
+	}
+	if len(key.Wallets) == 0 {
+		existing.Wallets = nil
+		return
+	}
+	for _, wallet := range key.Wallets {
+		if !containsString(existing.Wallets, wallet) {
+			existing.Wallets = append(existing.Wallets, wallet)
+		}
+	}
+	sort.Strings(existing.Wallets)
+}
+
+// Len returns the number of configured keys
+func (st *APIKeyStore) Len() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return len(st.keys)
+}
+
+// Lookup returns the key whose hash matches the raw key presented by a client
+func (st *APIKeyStore) Lookup(rawKey string) (APIKey, bool) {
+	sum := sha256.Sum256([]byte(rawKey))
+	hash := hex.EncodeToString(sum[:])
+
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	// Compare every hash in constant time rather than indexing the map by a client value
+	for stored, key := range st.keys {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			return APIKey{Hash: key.Hash, Wallets: append([]string(nil), key.Wallets...)}, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// allows reports whether the key may access wallet ("" = the request names no wallet)
+func (k APIKey) allows(wallet string) bool {
+	return len(k.Wallets) == 0 || wallet == "" || containsString(k.Wallets, historyKey(wallet))
+}
+
+// apiKeyContextKey carries the authenticated APIKey in the request context
+type apiKeyContextKey struct{}
+
+// walletAllowed reports whether the request's API key may access wallet, for handlers
+// taking wallets from the body. Unauthenticated requests (auth off) may access any.
+func walletAllowed(r *http.Request, wallet string) bool {
+	return walletAllowedContext(r.Context(), wallet)
+}
+
+// walletAllowedContext is walletAllowed for the context of a request
+func walletAllowedContext(ctx context.Context, wallet string) bool {
+	key, ok := ctx.Value(apiKeyContextKey{}).(APIKey)
+	return !ok || key.allows(wallet)
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// authExempt reports whether a path is reachable without an API key: the health checks,
+// the API docs, metrics (own token) and the admin API (ADMIN_KEY)
+func authExempt(path string) bool {
+	return path == "/health" || path == "/health/live" || path == "/health/ready" || path == "/metrics" ||
+		path == "/api/v1/openapi.json" || path == "/api/v1/docs" || strings.HasPrefix(path, "/api/v1/admin/")
+}
+
+// requestWallet returns the wallet a request targets: the wallet query parameter or
+// the address of /history/{wallet}
+func requestWallet(r *http.Request) string {
+	if wallet := r.URL.Query().Get("wallet"); wallet != "" {
+		return wallet
+	}
+	if i := strings.Index(r.URL.Path, "/history/"); i >= 0 {
+		return strings.Trim(r.URL.Path[i+len("/history/"):], "/")
+	}
+	return ""
+}
+
+// writeAuthError writes a JSON error body with status
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// AuthMiddleware requires an "Authorization: Bearer <key>" header whose SHA-256 hash
+// is in apiKeys; wallet-scoped keys may only query their wallets. Authentication is
+// off while no keys are configured. CORS preflights and authExempt paths pass through.
+func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || authExempt(r.URL.Path) || apiKeys.Len() == 0 {
+			next(w, r)
+			return
+		}
+
+		rawKey, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || rawKey == "" {
+			writeAuthError(w, http.StatusUnauthorized, "missing API key (Authorization: Bearer <key>)")
+			return
+		}
+		key, ok := apiKeys.Lookup(rawKey)
+		if !ok {
+			writeAuthError(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+		if !key.allows(requestWallet(r)) {
+			writeAuthError(w, http.StatusForbidden, "API key is not authorized for this wallet")
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	}
+}
+
+// Admin API key endpoint - POST adds a key (by hash) without a restart. Added keys
+// live in memory only; add them to API_KEYS to keep them.
+func (s *Server) handleAdminKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var key APIKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	key.Hash = strings.ToLower(key.Hash)
+	if !apiKeyHashPattern.MatchString(key.Hash) {
+		http.Error(w, "hash must be the hex SHA-256 hash of the key", http.StatusBadRequest)
+		return
+	}
+	for i, wallet := range key.Wallets {
+		if !isValidEthereumAddress(wallet) && !isValidSolanaAddress(wallet) {
+			http.Error(w, "wallets must be Ethereum or Solana addresses", http.StatusBadRequest)
+			return
+		}
+		key.Wallets[i] = historyKey(wallet)
+	}
+
+	apiKeys.Add(key)
+	auditLog(r, "API key added: %s... (wallets %v)", key.Hash[:8], key.Wallets)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"hash":    key.Hash,
+		"wallets": key.Wallets,
+		"keys":    apiKeys.Len(),
+	})
+}
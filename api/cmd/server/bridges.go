@@ -0,0 +1,242 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Canonical Bridge Registry
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bridgeRefreshInterval is how often canonical bridge addresses are re-fetched
+const bridgeRefreshInterval = 24 * time.Hour
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              DYNAMIC SPENDERS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// DynamicSpenderStore holds spender names loaded at runtime, grouped by source
+// so a refresh replaces exactly the addresses that source provided
+type DynamicSpenderStore struct {
+	mu       sync.RWMutex
+	bySource map[string]map[string]string // source -> address -> name
+}
+
+// NewDynamicSpenderStore creates an empty store
+func NewDynamicSpenderStore() *DynamicSpenderStore {
+	return &DynamicSpenderStore{bySource: make(map[string]map[string]string)}
+}
+
+// Replace sets the spenders provided by source, dropping its previous addresses
+func (d *DynamicSpenderStore) Replace(source string, spenders map[string]string) {
+	normalized := make(map[string]string, len(spenders))
+	for addr, name := range spenders {
+		normalized[strings.ToLower(addr)] = name
+	}
+
+	d.mu.Lock()
+	d.bySource[source] = normalized
+	d.mu.Unlock()
+}
+
+// Get returns the name of a dynamically loaded spender
+func (d *DynamicSpenderStore) Get(address string) (string, bool) {
+	address = strings.ToLower(address)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, spenders := range d.bySource {
+		if name, ok := spenders[address]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Count returns the number of dynamically loaded spenders
+func (d *DynamicSpenderStore) Count() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	total := 0
+	for _, spenders := range d.bySource {
+		total += len(spenders)
+	}
+	return total
+}
+
+// dynamicSpenders complements knownSpenders with addresses fetched at runtime
+var dynamicSpenders = NewDynamicSpenderStore()
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              BRIDGE SOURCES
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// bridgeSource is a published list of canonical bridge contracts
+type bridgeSource struct {
+	Name  string
+	URL   string
+	Parse func(body []byte) (map[string]string, error) // address -> spender name
+}
+
+// superchainRegistryURL hosts the OP Stack chain configs (Base publishes its
+// L1 contracts through the same registry)
+var superchainRegistryURL = "https://raw.githubusercontent.com/ethereum-optimism/superchain-registry/main/superchain/configs/mainnet"
+
+// arbitrumNetworksURL is the Arbitrum SDK network file listing the token bridge deployment
+var arbitrumNetworksURL = "https://raw.githubusercontent.com/OffchainLabs/arbitrum-sdk/main/src/lib/dataEntities/networks.ts"
+
+// defaultBridgeSources returns the sources fetched by the updater
+func defaultBridgeSources() []bridgeSource {
+	return []bridgeSource{
+		{Name: "superchain-registry/op", URL: superchainRegistryURL + "/op.toml", Parse: superchainConfigParser("Optimism")},
+		{Name: "superchain-registry/base", URL: superchainRegistryURL + "/base.toml", Parse: superchainConfigParser("Base")},
+		{Name: "arbitrum-sdk", URL: arbitrumNetworksURL, Parse: parseArbitrumTokenBridge},
+	}
+}
+
+// superchainBridgeContracts are the L1 contracts users approve or deposit into
+var superchainBridgeContracts = map[string]string{
+	"L1StandardBridgeProxy":       "L1 Standard Bridge",
+	"L1ERC721BridgeProxy":         "L1 ERC721 Bridge",
+	"L1CrossDomainMessengerProxy": "L1 Cross-Domain Messenger",
+	"OptimismPortalProxy":         "Optimism Portal",
+}
+
+// tomlAddressPattern matches `Name = "0x..."` lines in registry TOML files
+var tomlAddressPattern = regexp.MustCompile(`(?m)^\s*(\w+)\s*=\s*"(0x[0-9a-fA-F]{40})"`)
+
+// superchainConfigParser extracts bridge contracts from a superchain-registry chain config
+func superchainConfigParser(chainName string) func([]byte) (map[string]string, error) {
+	return func(body []byte) (map[string]string, error) {
+		spenders := make(map[string]string)
+		for _, match := range tomlAddressPattern.FindAllStringSubmatch(string(body), -1) {
+			if contract, ok := superchainBridgeContracts[match[1]]; ok {
+				spenders[match[2]] = "✅ " + chainName + ": " + contract
+			}
+		}
+		if len(spenders) == 0 {
+			return nil, fmt.Errorf("no bridge contracts found in %s config", chainName)
+		}
+		return spenders, nil
+	}
+}
+
+// arbitrumBridgeContracts are the L1 token bridge contracts of Arbitrum One
+var arbitrumBridgeContracts = map[string]string{
+	"l1GatewayRouter": "L1 Gateway Router",
+	"l1ERC20Gateway":  "L1 ERC20 Gateway",
+	"l1CustomGateway": "L1 Custom Gateway",
+	"l1WethGateway":   "L1 WETH Gateway",
+}
+
+// tsAddressPattern matches `name: '0x...'` properties in the SDK network file
+var tsAddressPattern = regexp.MustCompile(`(\w+)\s*:\s*['"](0x[0-9a-fA-F]{40})['"]`)
+
+// parseArbitrumTokenBridge extracts the Arbitrum One L1 gateways from the SDK network file
+func parseArbitrumTokenBridge(body []byte) (map[string]string, error) {
+	text := string(body)
+	start := strings.Index(text, "mainnetTokenBridge")
+	if start < 0 {
+		return nil, fmt.Errorf("mainnetTokenBridge not found")
+	}
+	block := text[start:]
+	if end := strings.Index(block, "}"); end >= 0 {
+		block = block[:end]
+	}
+
+	spenders := make(map[string]string)
+	for _, match := range tsAddressPattern.FindAllStringSubmatch(block, -1) {
+		if contract, ok := arbitrumBridgeContracts[match[1]]; ok {
+			spenders[match[2]] = "✅ Arbitrum: " + contract
+		}
+	}
+	if len(spenders) == 0 {
+		return nil, fmt.Errorf("no gateways found in mainnetTokenBridge")
+	}
+	return spenders, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              UPDATER
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// BridgeRegistryUpdater keeps dynamicSpenders in sync with the canonical bridge lists,
+// so upgraded bridge contracts are recognized without a deployment
+type BridgeRegistryUpdater struct {
+	sources []bridgeSource
+	store   *DynamicSpenderStore
+	client  *http.Client
+}
+
+// NewBridgeRegistryUpdater creates an updater over the default sources
+func NewBridgeRegistryUpdater(store *DynamicSpenderStore) *BridgeRegistryUpdater {
+	return &BridgeRegistryUpdater{
+		sources: defaultBridgeSources(),
+		store:   store,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Refresh fetches every source. A failing source keeps its previous addresses.
+func (u *BridgeRegistryUpdater) Refresh(ctx context.Context) {
+	for _, source := range u.sources {
+		spenders, err := u.fetch(ctx, source)
+		if err != nil {
+			defaultLogger.Warn("Failed to load bridge addresses", Fields{"source": source.Name, "error": errorText(err)})
+			continue
+		}
+		u.store.Replace(source.Name, spenders)
+		defaultLogger.Info("Loaded bridge addresses", Fields{"source": source.Name, "addresses": len(spenders)})
+	}
+}
+
+func (u *BridgeRegistryUpdater) fetch(ctx context.Context, source bridgeSource) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", source.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return source.Parse(body)
+}
+
+// Start refreshes now and then every 24 hours until ctx is cancelled
+func (u *BridgeRegistryUpdater) Start(ctx context.Context) {
+	go func() {
+		u.Refresh(ctx)
+
+		ticker := time.NewTicker(bridgeRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				u.Refresh(ctx)
+			}
+		}
+	}()
+}
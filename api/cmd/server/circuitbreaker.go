@@ -0,0 +1,142 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Circuit Breaker
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults of the per-chain provider circuit breaker
+const (
+	defaultBreakerThreshold    = 5
+	defaultBreakerResetTimeout = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned instead of calling a provider whose breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open: provider failing, chain skipped")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects calls until the reset timeout has passed
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe call through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops calling a provider after threshold consecutive failures. After
+// resetTimeout one probe is let through: success closes the breaker, failure reopens it.
+type CircuitBreaker struct {
+	mu           sync.Mutex
+	threshold    int
+	resetTimeout time.Duration
+	clock        Clock
+
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// probing is set while the half-open probe is in flight
+	probing bool
+}
+
+// NewCircuitBreaker creates a closed breaker (threshold <= 0 and resetTimeout <= 0 use the defaults)
+func NewCircuitBreaker(threshold int, resetTimeout time.Duration, clock Clock) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if resetTimeout <= 0 {
+		resetTimeout = defaultBreakerResetTimeout
+	}
+	return &CircuitBreaker{threshold: threshold, resetTimeout: resetTimeout, clock: clock}
+}
+
+// State returns the breaker's state; an open breaker past its reset timeout reports half-open
+func (b *CircuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.clock.Now().Sub(b.openedAt) >= b.resetTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Allow returns ErrCircuitOpen when a call must not be made. A nil breaker allows everything.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.clock.Now().Sub(b.openedAt) < b.resetTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call. Cancelled calls say nothing about the
+// provider and are ignored.
+func (b *CircuitBreaker) Record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) {
+		b.probing = false
+		return
+	}
+
+	if err == nil {
+		b.state = CircuitClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.clock.Now()
+		b.probing = false
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Spender Classification
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Spender categories assigned by ContractClassifier
+const (
+	categoryDEXRouter   = "DEX Router"
+	categoryLendingPool = "Lending Pool"
+	categoryVault       = "Vault"
+)
+
+// classificationTTL is how long a spender classification is cached
+const classificationTTL = time.Hour
+
+// View function selectors probed by the classifier
+const (
+	supportsInterfaceSelector = "0x01ffc9a7" // supportsInterface(bytes4)
+	factorySelector           = "0xc45a0155" // factory()
+	getReservesListSelector   = "0xd1946dbc" // getReservesList() (Aave-style pools)
+	assetSelector             = "0x38d52e0f" // asset() (ERC-4626)
+)
+
+// erc165Interfaces are the ERC-165 interface IDs reported in SpenderClassification
+var erc165Interfaces = map[string]string{
+	"36372b07": "ERC-20",
+	"80ac58cd": "ERC-721",
+	"d9b67a26": "ERC-1155",
+	"87dfe5a0": "ERC-4626",
+}
+
+// knownDEXFactories are AMM factories whose routers expose factory()
+var knownDEXFactories = map[string]string{
+	"0x5c69bee701ef814a2b6a3edd4b1652cb9cc5aa6f": "Uniswap V2",
+	"0x1f98431c8ad98523631ae4a59f267346ea31f984": "Uniswap V3",
+	"0x33128a8fc17869897dce68ed026d694621f6fdfd": "Uniswap V3 (Base)",
+	"0x8909dc15e40173ff4699343b6eb8132c65e18ec6": "Uniswap V2 (Base)",
+	"0xc0aee478e3658e2610c5f7a4a2e1777ce9e4f2ac": "SushiSwap",
+	"0xca143ce32fe78f1f7019d7d551a6402fc5350c73": "PancakeSwap V2",
+	"0x0bfbcf9fa4f9c56b0f40a671ad40e0805a091865": "PancakeSwap V3",
+	"0x5757371414417b8c6caad45baef941abc7d3ab32": "QuickSwap",
+}
+
+// SpenderClassification is what on-chain probing revealed about a spender
+type SpenderClassification struct {
+	Category   string   // empty when no pattern matched
+	Interfaces []string // ERC-165 interfaces the contract reports
+}
+
+// ContractClassifier categorizes unknown spenders from on-chain signals: ERC-165
+// interfaces, a factory() pointing at a known DEX factory, and lending pool views
+type ContractClassifier struct {
+	cache CacheStore
+}
+
+// NewContractClassifier creates a classifier caching results in cache for an hour
+func NewContractClassifier(cache CacheStore) *ContractClassifier {
+	return &ContractClassifier{cache: cache}
+}
+
+// ClassifyApprovals sets SpenderCategory on approvals to unknown spenders
+func (cc *ContractClassifier) ClassifyApprovals(ctx context.Context, client *ChainClient, approvals []Approval) {
+	for i := range approvals {
+		if !isUnknownSpender(approvals[i]) {
+			continue
+		}
+		classification := cc.Classify(ctx, client, approvals[i].SpenderAddress)
+		if classification.Category == "" {
+			continue
+		}
+		approvals[i].SpenderCategory = classification.Category
+		approvals[i].RiskReasons = append(approvals[i].RiskReasons,
+			"🔍 Unverified spender looks like a "+classification.Category+" (on-chain signals)")
+	}
+}
+
+// Classify probes a contract on the client's chain
+func (cc *ContractClassifier) Classify(ctx context.Context, client *ChainClient, address string) SpenderClassification {
+	address = strings.ToLower(address)
+	cacheKey := "classify:" + string(client.ChainID) + ":" + address
+	if cached, ok := cc.cache.Get(cacheKey); ok {
+		return cached.(SpenderClassification)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var classification SpenderClassification
+	if client.callBool(ctx, address, supportsInterfaceSelector+padSelector(strings.TrimPrefix(supportsInterfaceSelector, "0x"))) {
+		for id, name := range erc165Interfaces {
+			if client.callBool(ctx, address, supportsInterfaceSelector+padSelector(id)) {
+				classification.Interfaces = append(classification.Interfaces, name)
+			}
+		}
+	}
+
+	switch {
+	case slices.Contains(classification.Interfaces, "ERC-4626"):
+		classification.Category = categoryVault
+	case knownDEXFactories[client.callAddress(ctx, address, factorySelector)] != "":
+		classification.Category = categoryDEXRouter
+	case client.callArrayLength(ctx, address, getReservesListSelector) > 0:
+		classification.Category = categoryLendingPool
+	case client.callAddress(ctx, address, assetSelector) != "":
+		classification.Category = categoryVault
+	}
+
+	cc.cache.SetWithTTL(cacheKey, classification, classificationTTL)
+	return classification
+}
+
+// padSelector right-pads a bytes4 argument to a 32-byte ABI word
+func padSelector(id string) string {
+	return id + strings.Repeat("0", 56)
+}
+
+// abiWords splits an eth_call result into 32-byte hex words
+func abiWords(result string) []string {
+	data := strings.TrimPrefix(result, "0x")
+	words := make([]string, 0, len(data)/64)
+	for len(data) >= 64 {
+		words = append(words, data[:64])
+		data = data[64:]
+	}
+	return words
+}
+
+// callBool calls a view returning bool; failures read as false
+func (c *ChainClient) callBool(ctx context.Context, to, data string) bool {
+	result, err := c.ethCall(ctx, to, data)
+	if err != nil {
+		return false
+	}
+	words := abiWords(result)
+	return len(words) > 0 && strings.TrimLeft(words[0], "0") == "1"
+}
+
+// callAddress calls a view returning an address; failures and the zero address read as ""
+func (c *ChainClient) callAddress(ctx context.Context, to, data string) string {
+	result, err := c.ethCall(ctx, to, data)
+	if err != nil {
+		return ""
+	}
+	words := abiWords(result)
+	if len(words) == 0 || strings.Trim(words[0], "0") == "" {
+		return ""
+	}
+	return "0x" + words[0][24:]
+}
+
+// callArrayLength calls a view returning a dynamic array; failures read as 0
+func (c *ChainClient) callArrayLength(ctx context.Context, to, data string) int {
+	result, err := c.ethCall(ctx, to, data)
+	if err != nil {
+		return 0
+	}
+	words := abiWords(result)
+	if len(words) < 2 {
+		return 0
+	}
+	return int(parseHexUint64(words[1]))
+}
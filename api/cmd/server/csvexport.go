@@ -0,0 +1,91 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - CSV Export
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// approvalCSVHeader is the first row of a CSV export
+var approvalCSVHeader = []string{
+	"chain", "tokenAddress", "tokenSymbol", "spenderAddress", "spenderName",
+	"allowanceHuman", "isUnlimited", "riskLevel", "riskReasons", "lastUpdated",
+}
+
+// csvFlushRows is how many rows are written between flushes to the client
+const csvFlushRows = 100
+
+// wantsCSV reports whether the request's Accept header asks for text/csv
+func wantsCSV(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == "text/csv" {
+			return true
+		}
+	}
+	return false
+}
+
+// csvCell neutralizes values a spreadsheet would run as a formula. Token symbols and
+// ENS names are chosen by whoever deployed the contract, so "=HYPERLINK(...)" is a
+// real payload.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// writeApprovalsCSV streams a scan's approvals as RFC 4180 CSV, flushing to the client
+// every csvFlushRows rows
+func writeApprovalsCSV(w http.ResponseWriter, result *WalletScanResult) {
+	filename := fmt.Sprintf("approvals-%s-%d.csv", strings.ToLower(result.WalletAddress), result.ScanTimestamp)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	flusher, _ := w.(http.Flusher)
+	writer := csv.NewWriter(w)
+	_ = writer.Write(approvalCSVHeader)
+	for i, approval := range result.Approvals {
+		_ = writer.Write([]string{
+			string(approval.Chain),
+			approval.TokenAddress,
+			csvCell(approval.TokenSymbol),
+			approval.SpenderAddress,
+			csvCell(approval.SpenderName),
+			csvCell(approval.AllowanceHuman),
+			strconv.FormatBool(approval.IsUnlimited),
+			approval.RiskLevel,
+			csvCell(strings.Join(approval.RiskReasons, "|")),
+			csvTime(approval.LastUpdated),
+		})
+		if (i+1)%csvFlushRows == 0 {
+			writer.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		defaultLogger.Warn("CSV export interrupted", Fields{"wallet": result.WalletAddress, "error": errorText(err)})
+	}
+}
+
+// csvTime formats unix seconds as RFC 3339, leaving unknown times (0) empty
+func csvTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,60 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - DAO Treasury Governance
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// isDAOTreasury reports whether the address book marks a wallet as a DAO treasury
+func isDAOTreasury(walletAddress string) bool {
+	return walletLabels.Type(walletAddress) == labelTypeDAOTreasury
+}
+
+// governanceProposalTemplate drafts a Snapshot/Tally post asking the DAO to revoke the
+// treasury's critical approvals. Returns "" when there is nothing to revoke.
+func governanceProposalTemplate(result *WalletScanResult) string {
+	var critical []Approval
+	for _, approval := range result.Approvals {
+		if approval.RiskLevel == "critical" {
+			critical = append(critical, approval)
+		}
+	}
+	if len(critical) == 0 {
+		return ""
+	}
+
+	treasury := result.WalletAddress
+	if result.WalletLabel != "" {
+		treasury = fmt.Sprintf("%s (%s)", result.WalletLabel, result.WalletAddress)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Revoke %d high-risk token approvals from the treasury\n\n", len(critical))
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "A SENTINEL scan of %s flagged %d critical token approvals. "+
+		"Each lets its spender move treasury funds without a further vote.\n\n", treasury, len(critical))
+	b.WriteString("## Approvals to revoke\n\n")
+	for _, approval := range critical {
+		fmt.Fprintf(&b, "- %s (`%s`) on %s: spender %s (`%s`), allowance %s\n",
+			approval.TokenSymbol, approval.TokenAddress, chainDisplayName(approval.Chain),
+			approval.SpenderName, approval.SpenderAddress, approval.AllowanceHuman)
+		for _, reason := range approval.RiskReasons {
+			fmt.Fprintf(&b, "  - %s\n", reason)
+		}
+	}
+	b.WriteString("\n## Proposed action\n\n")
+	b.WriteString("For each approval above, the treasury calls `approve(spender, 0)` on the token contract")
+	if slices.ContainsFunc(critical, func(approval Approval) bool { return approval.IsNFT }) {
+		b.WriteString(", or `setApprovalForAll(operator, false)` on NFT collections")
+	}
+	b.WriteString(".\n")
+	return b.String()
+}
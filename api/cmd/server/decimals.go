@@ -0,0 +1,91 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Token Decimals
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenDecimalsCaches maps ChainID to a *sync.Map of lowercase token address -> int
+// decimals, seeded with tokenDecimals and shared by every ChainClient of the chain
+var tokenDecimalsCaches sync.Map
+
+// tokenDecimalsCache returns the decimals cache of a chain
+func tokenDecimalsCache(chain ChainID) *sync.Map {
+	if cache, ok := tokenDecimalsCaches.Load(chain); ok {
+		return cache.(*sync.Map)
+	}
+	seeded := &sync.Map{}
+	for address, decimals := range tokenDecimals {
+		seeded.Store(address, decimals)
+	}
+	cache, _ := tokenDecimalsCaches.LoadOrStore(chain, seeded)
+	return cache.(*sync.Map)
+}
+
+// cachedTokenDecimals returns the known decimals of a token without calling the chain
+// (default 18)
+func cachedTokenDecimals(chain ChainID, tokenAddress string) int {
+	if decimals, ok := tokenDecimalsCache(chain).Load(strings.ToLower(tokenAddress)); ok {
+		return decimals.(int)
+	}
+	return getTokenDecimals(tokenAddress)
+}
+
+// GetTokenDecimals returns a token's decimals, calling decimals() on first use. Results
+// of Multicall3 metadata batches are reused.
+func (c *ChainClient) GetTokenDecimals(ctx context.Context, tokenAddress string) (int, error) {
+	token := strings.ToLower(tokenAddress)
+	cache := tokenDecimalsCache(c.ChainID)
+	if decimals, ok := cache.Load(token); ok {
+		return decimals.(int), nil
+	}
+	if meta, ok := cachedTokenMetadata(c.ChainID, token); ok {
+		if meta.Decimals < 0 {
+			return 0, fmt.Errorf("decimals() failed in Multicall3 batch for %s", token)
+		}
+		cache.Store(token, meta.Decimals)
+		return meta.Decimals, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	result, err := c.ethCall(ctx, token, decimalsSelector)
+	if err != nil {
+		return 0, err
+	}
+
+	// decimals() returns a uint8 in a 32-byte word
+	words := abiWords(result)
+	if len(words) == 0 {
+		return 0, fmt.Errorf("empty decimals() result for %s", token)
+	}
+	n, ok := new(big.Int).SetString(words[0], 16)
+	if !ok || !n.IsUint64() || n.Uint64() > 255 {
+		return 0, fmt.Errorf("invalid decimals() result %q for %s", result, token)
+	}
+
+	decimals := int(n.Uint64())
+	cache.Store(token, decimals)
+	return decimals, nil
+}
+
+// tokenDecimalsOrDefault returns a token's decimals, or 18 when they can't be read
+func (c *ChainClient) tokenDecimalsOrDefault(ctx context.Context, tokenAddress string) int {
+	decimals, err := c.GetTokenDecimals(ctx, tokenAddress)
+	if err != nil {
+		requestLogger(ctx, c.logger()).Debug("Failed to read token decimals, assuming 18", Fields{"token": tokenAddress, "error": errorText(err)})
+		return 18
+	}
+	return decimals
+}
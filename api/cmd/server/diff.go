@@ -0,0 +1,174 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Scan Diff
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxBaselineDistance is how far the closest recorded scan may be from the
+// requested baseline timestamp
+const maxBaselineDistance = 24 * time.Hour
+
+// RiskLevelChange is an approval present in both scans whose risk level changed
+type RiskLevelChange struct {
+	Approval Approval `json:"approval"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+}
+
+// ScanDiff is the response of POST /api/v1/diff
+type ScanDiff struct {
+	Wallet            string            `json:"wallet"`
+	BaselineTimestamp int64             `json:"baselineTimestamp"`
+	CurrentTimestamp  int64             `json:"currentTimestamp"`
+	NewApprovals      []Approval        `json:"newApprovals"`
+	RevokedApprovals  []Approval        `json:"revokedApprovals"`
+	RiskLevelChanges  []RiskLevelChange `json:"riskLevelChanges"`
+	RiskScoreChange   int               `json:"riskScoreChange"`
+}
+
+// ScanBaselineService is implemented by scanners that can return a past scan to diff against
+type ScanBaselineService interface {
+	Baseline(walletAddress string, timestamp int64) (HistoryEntry, bool)
+}
+
+// Baseline returns the wallet's recorded scan closest to timestamp
+func (s *Scanner) Baseline(walletAddress string, timestamp int64) (HistoryEntry, bool) {
+	return s.history.Closest(walletAddress, timestamp)
+}
+
+// diffKey matches approvals across scans by chain, token and spender; only EVM
+// addresses are case-insensitive
+func diffKey(approval Approval) string {
+	key := approval.TokenAddress + ":" + approval.SpenderAddress
+	if isEVMChain(approval.Chain) {
+		key = strings.ToLower(key)
+	}
+	return string(approval.Chain) + ":" + key
+}
+
+// diffApprovals compares the approvals of two scans on chains
+func diffApprovals(baseline, current []Approval, chains []ChainID) ScanDiff {
+	onChains := make(map[ChainID]bool, len(chains))
+	for _, chain := range chains {
+		onChains[chain] = true
+	}
+
+	before := make(map[string]Approval)
+	for _, approval := range baseline {
+		if onChains[approval.Chain] {
+			before[diffKey(approval)] = approval
+		}
+	}
+
+	diff := ScanDiff{
+		NewApprovals:     []Approval{},
+		RevokedApprovals: []Approval{},
+		RiskLevelChanges: []RiskLevelChange{},
+	}
+	seen := make(map[string]bool)
+	for _, approval := range current {
+		if !onChains[approval.Chain] {
+			continue
+		}
+		key := diffKey(approval)
+		seen[key] = true
+		previous, ok := before[key]
+		switch {
+		case !ok:
+			diff.NewApprovals = append(diff.NewApprovals, approval)
+		case previous.RiskLevel != approval.RiskLevel:
+			diff.RiskLevelChanges = append(diff.RiskLevelChanges, RiskLevelChange{Approval: approval, From: previous.RiskLevel, To: approval.RiskLevel})
+		}
+	}
+	for _, approval := range baseline {
+		if onChains[approval.Chain] && !seen[diffKey(approval)] {
+			diff.RevokedApprovals = append(diff.RevokedApprovals, approval)
+		}
+	}
+	return diff
+}
+
+// Scan diff endpoint - compares a fresh (or cached) scan with the recorded scan
+// closest to baselineTimestamp
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Wallet            string   `json:"wallet"`
+		Chains            []string `json:"chains"`
+		BaselineTimestamp int64    `json:"baselineTimestamp"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	wallet, ok := normalizeWallet(w, req.Wallet)
+	if !ok {
+		return
+	}
+	req.Wallet = wallet
+	if !walletAllowed(r, req.Wallet) {
+		writeAuthError(w, http.StatusForbidden, "API key is not authorized for this wallet")
+		return
+	}
+	if req.BaselineTimestamp <= 0 {
+		http.Error(w, "baselineTimestamp must be a unix timestamp", http.StatusBadRequest)
+		return
+	}
+
+	chains := walletChains(req.Wallet, supportedChains())
+	if len(req.Chains) > 0 {
+		selected, err := resolveChains(req.Chains)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		chains = selected
+	}
+
+	history, ok := s.scanner.(ScanBaselineService)
+	if !ok {
+		http.Error(w, "scan history not available", http.StatusNotImplemented)
+		return
+	}
+	// Look the baseline up first so the scan below can't become its own baseline
+	baseline, found := history.Baseline(req.Wallet, req.BaselineTimestamp)
+	if !found || absInt64(baseline.Timestamp-req.BaselineTimestamp) > int64(maxBaselineDistance/time.Second) {
+		http.Error(w, fmt.Sprintf("no scan of %s recorded within %s of %d", req.Wallet, maxBaselineDistance, req.BaselineTimestamp), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := s.scanner.ScanWallet(ctx, req.Wallet, chains, false)
+	if err != nil {
+		s.writeScanFailed(w, r, "Diff scan failed", req.Wallet, err)
+		return
+	}
+
+	diff := diffApprovals(baseline.approvals, result.Approvals, chains)
+	diff.Wallet = historyKey(req.Wallet)
+	diff.BaselineTimestamp = baseline.Timestamp
+	diff.CurrentTimestamp = result.ScanTimestamp
+	diff.RiskScoreChange = result.OverallRiskScore - baseline.OverallRiskScore
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(diff)
+}
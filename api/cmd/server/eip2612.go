@@ -0,0 +1,75 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - EIP-2612 Permit Tokens
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// noncesSelector is EIP-2612 nonces(address)
+const noncesSelector = "0x7ecebe00"
+
+// EIP-2612 defines no event of its own: permit() sets the allowance and emits the
+// token's regular Approval event, so approvals granted by signature are already among
+// the scanned ones, and once used a permit's deadline no longer limits them. What the
+// scan can add is which tokens accept such signatures and how many the wallet has had
+// executed (its nonce).
+
+// annotatePermitSupport sets SupportsPermit and PermitNonce on ERC20 approvals, reading
+// nonces(wallet) of every token in one Multicall3 request. If the batch fails the
+// approvals are left alone.
+func (c *ChainClient) annotatePermitSupport(ctx context.Context, walletAddress string, approvals []Approval) {
+	tokens := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, approval := range approvals {
+		token := strings.ToLower(approval.TokenAddress)
+		if approval.IsNFT || seen[token] {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	data := noncesSelector + strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(walletAddress), "0x")
+	nonces := make(map[string]uint64) // tokens implementing nonces(address)
+	for start := 0; start < len(tokens); start += maxMulticallCalls {
+		batch := tokens[start:min(start+maxMulticallCalls, len(tokens))]
+		calls := make([]multicallCall, len(batch))
+		for i, token := range batch {
+			calls[i] = multicallCall{Target: token, Data: data}
+		}
+
+		results, err := c.aggregate3(ctx, calls)
+		if err != nil {
+			requestLogger(ctx, c.logger()).Debug("EIP-2612 nonces batch failed", Fields{"tokens": len(batch), "error": errorText(err)})
+			continue
+		}
+		for i, token := range batch {
+			// Tokens without nonces() revert, or answer something that isn't a word
+			if words := abiWords(results[i].ReturnData); results[i].Success && len(words) == 1 {
+				nonces[token] = parseHexUint64(words[0])
+			}
+		}
+	}
+
+	for i := range approvals {
+		nonce, ok := nonces[strings.ToLower(approvals[i].TokenAddress)]
+		if ok && !approvals[i].IsNFT {
+			approvals[i].SupportsPermit = true
+			approvals[i].PermitNonce = nonce
+		}
+	}
+}
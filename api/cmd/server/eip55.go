@@ -0,0 +1,51 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - EIP-55 Address Checksums
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChecksumAddress returns the EIP-55 mixed-case form of an Ethereum address: each
+// hex letter is uppercased when the matching nibble of keccak256(lowercase address)
+// is 8 or more
+func ChecksumAddress(addr string) (string, error) {
+	if !isValidEthereumAddress(addr) {
+		return "", fmt.Errorf("invalid Ethereum address %q", addr)
+	}
+	lower := strings.ToLower(addr[2:])
+	hash := keccak256([]byte(lower))
+
+	checksummed := []byte(lower)
+	for i, ch := range checksummed {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if ch >= 'a' && nibble >= 8 {
+			checksummed[i] = ch - 'a' + 'A'
+		}
+	}
+	return "0x" + string(checksummed), nil
+}
+
+// NormalizeAddress validates an Ethereum address and returns its lowercase form.
+// All-lowercase and all-uppercase addresses carry no checksum and are accepted;
+// mixed-case ones must match their EIP-55 checksum.
+func NormalizeAddress(addr string) (string, error) {
+	checksummed, err := ChecksumAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	digits := addr[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && addr != checksummed {
+		return "", fmt.Errorf("invalid EIP-55 checksum for %s (expected %s)", addr, checksummed)
+	}
+	return strings.ToLower(addr), nil
+}
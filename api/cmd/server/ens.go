@@ -0,0 +1,351 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - ENS Resolution
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ENS contracts on Ethereum mainnet
+const (
+	ensRegistryAddress    = "0x00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
+	ensNameWrapperAddress = "0xd4416b13d2b3a9abae7acd5d6c2bbdbe25686401"
+)
+
+// ENS function selectors
+const (
+	selectorENSResolver = "0x0178b8bf" // resolver(bytes32)
+	selectorENSOwner    = "0x02571be3" // owner(bytes32)
+	selectorENSName     = "0x691f3431" // name(bytes32)
+	selectorENSAddr     = "0x3b3b57de" // addr(bytes32)
+	selectorENSResolve  = "0x9061b923" // resolve(bytes,bytes) - ENSIP-10 wildcard
+	selectorOwnerOf     = "0x6352211e" // ownerOf(uint256) - NameWrapper
+)
+
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+// trustedENSParents are protocol names whose subdomains are operated by the protocol.
+// A spender whose verified primary name is a subdomain of one of these is treated as safe.
+var trustedENSParents = map[string]string{
+	"uniswap.eth":  "Uniswap",
+	"curve.eth":    "Curve",
+	"aave.eth":     "Aave",
+	"balancer.eth": "Balancer",
+	"1inch.eth":    "1inch",
+	"lido.eth":     "Lido",
+	"compound.eth": "Compound",
+	"cow.eth":      "CoW Protocol",
+	"ens.eth":      "ENS",
+}
+
+// keccak256 returns the legacy Keccak-256 hash used by Ethereum
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// namehash computes the EIP-137 node for an ENS name
+func namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		copy(node[:], keccak256(node[:], keccak256([]byte(labels[i]))))
+	}
+	return node
+}
+
+// trustedENSParent returns the trusted protocol name is a subdomain of, if any
+func trustedENSParent(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for parent, protocol := range trustedENSParents {
+		if strings.HasSuffix(name, "."+parent) {
+			return protocol, true
+		}
+	}
+	return "", false
+}
+
+// dnsEncode encodes an ENS name in DNS wire format, as required by ENSIP-10
+func dnsEncode(name string) []byte {
+	var out []byte
+	for _, label := range strings.Split(name, ".") {
+		out = append(out, byte(len(label)))
+		out = append(out, label...)
+	}
+	return append(out, 0)
+}
+
+// abiEncodeBytesPair ABI-encodes two dynamic bytes arguments
+func abiEncodeBytesPair(a, b []byte) string {
+	word := func(n int) string { return fmt.Sprintf("%064x", n) }
+	padded := func(d []byte) string {
+		h := hex.EncodeToString(d)
+		if rem := len(h) % 64; rem != 0 {
+			h += strings.Repeat("0", 64-rem)
+		}
+		return h
+	}
+
+	aEnc := word(len(a)) + padded(a)
+	bEnc := word(len(b)) + padded(b)
+	return word(64) + word(64+len(aEnc)/2) + aEnc + bEnc
+}
+
+// wordToAddress extracts an address from a 32-byte ABI word
+func wordToAddress(result string) string {
+	data := strings.TrimPrefix(result, "0x")
+	if len(data) < 64 {
+		return zeroAddress
+	}
+	return "0x" + strings.ToLower(data[24:64])
+}
+
+// ENSResolver resolves ENS names on Ethereum mainnet
+type ENSResolver struct {
+	client *ChainClient
+	cache  CacheStore
+}
+
+// NewENSResolver creates a resolver backed by an Ethereum mainnet client
+func NewENSResolver(client *ChainClient) *ENSResolver {
+	return &ENSResolver{
+		client: client,
+		cache:  NewCache(time.Hour),
+	}
+}
+
+func nodeHex(node [32]byte) string {
+	return hex.EncodeToString(node[:])
+}
+
+// registryCall calls a bytes32-argument function on the ENS registry and returns the address result
+func (r *ENSResolver) registryCall(ctx context.Context, selector string, node [32]byte) (string, error) {
+	result, err := r.client.ethCall(ctx, ensRegistryAddress, selector+nodeHex(node))
+	if err != nil {
+		return "", err
+	}
+	return wordToAddress(result), nil
+}
+
+// Owner returns the effective owner of a name, looking through the NameWrapper for wrapped names
+func (r *ENSResolver) Owner(ctx context.Context, name string) (string, error) {
+	node := namehash(name)
+	owner, err := r.registryCall(ctx, selectorENSOwner, node)
+	if err != nil {
+		return "", err
+	}
+
+	if owner == ensNameWrapperAddress {
+		result, err := r.client.ethCall(ctx, ensNameWrapperAddress, selectorOwnerOf+nodeHex(node))
+		if err != nil {
+			return "", err
+		}
+		owner = wordToAddress(result)
+	}
+	return owner, nil
+}
+
+// ReverseName returns the primary name set in the reverse record of address ("" if none)
+func (r *ENSResolver) ReverseName(ctx context.Context, address string) (string, error) {
+	node := namehash(strings.TrimPrefix(strings.ToLower(address), "0x") + ".addr.reverse")
+
+	resolver, err := r.registryCall(ctx, selectorENSResolver, node)
+	if err != nil {
+		return "", err
+	}
+	if resolver == zeroAddress {
+		return "", nil
+	}
+
+	result, err := r.client.ethCall(ctx, resolver, selectorENSName+nodeHex(node))
+	if err != nil {
+		return "", err
+	}
+	return decodeString(result), nil
+}
+
+// Reverse returns the primary name of address, or "" if none is registered. Reverse
+// records are self-asserted, so a name that doesn't resolve back to address counts as
+// none. Results are cached for an hour; RPC failures are not cached.
+func (r *ENSResolver) Reverse(ctx context.Context, address string) (string, error) {
+	address = strings.ToLower(address)
+	cacheKey := "ens:reverse:" + address
+	if cached, ok := r.cache.Get(cacheKey); ok {
+		return cached.(string), nil
+	}
+
+	name, err := r.ReverseName(ctx, address)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		resolved, err := r.ResolveAddress(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if resolved != address {
+			name = ""
+		}
+	}
+
+	r.cache.Set(cacheKey, name)
+	return name, nil
+}
+
+// ResolveAddress forward-resolves name to an address. Subdomains without their own
+// resolver fall back to the closest parent resolver via ENSIP-10 wildcard resolution.
+func (r *ENSResolver) ResolveAddress(ctx context.Context, name string) (string, error) {
+	node := namehash(name)
+	labels := strings.Split(name, ".")
+
+	for i := 0; i < len(labels)-1; i++ {
+		candidate := strings.Join(labels[i:], ".")
+		resolver, err := r.registryCall(ctx, selectorENSResolver, namehash(candidate))
+		if err != nil {
+			return "", err
+		}
+		if resolver == zeroAddress {
+			continue
+		}
+
+		if i == 0 {
+			result, err := r.client.ethCall(ctx, resolver, selectorENSAddr+nodeHex(node))
+			if err != nil {
+				return "", err
+			}
+			return wordToAddress(result), nil
+		}
+
+		// Wildcard: resolve(dnsEncode(name), addr(node)) returns ABI-encoded bytes
+		addrCall, _ := hex.DecodeString(strings.TrimPrefix(selectorENSAddr, "0x") + nodeHex(node))
+		result, err := r.client.ethCall(ctx, resolver, selectorENSResolve+abiEncodeBytesPair(dnsEncode(name), addrCall))
+		if err != nil {
+			return "", err
+		}
+		data := strings.TrimPrefix(result, "0x")
+		if len(data) < 192 { // offset + length + one word
+			return zeroAddress, nil
+		}
+		return wordToAddress(data[128:192]), nil
+	}
+
+	return zeroAddress, nil
+}
+
+// ResolveSpender reverse-resolves a spender and reports whether its name is a
+// subdomain of a trusted protocol (e.g. v3.uniswap.eth). Reverse records are
+// self-asserted, so the name must forward-resolve back to the spender and exist
+// in the registry (directly or through the NameWrapper) before it is trusted.
+func (r *ENSResolver) ResolveSpender(ctx context.Context, address string) (string, bool) {
+	address = strings.ToLower(address)
+	cacheKey := "ens:spender:" + address
+	if cached, ok := r.cache.Get(cacheKey); ok {
+		entry := cached.(ensSpenderEntry)
+		return entry.Name, entry.Trusted
+	}
+
+	name, err := r.ReverseName(ctx, address)
+	if err != nil {
+		return "", false // don't cache transient RPC failures
+	}
+
+	entry := ensSpenderEntry{Name: name}
+	defer func() { r.cache.Set(cacheKey, entry) }()
+	if name == "" {
+		return "", false
+	}
+
+	if _, ok := trustedENSParent(name); !ok {
+		return name, false
+	}
+
+	resolved, err := r.ResolveAddress(ctx, name)
+	if err != nil || resolved != address {
+		r.client.logger().Warn("ENS name does not resolve back to its claimant", Fields{"name": name, "address": address})
+		return name, false
+	}
+
+	// Wildcard subnames have no registry entry; explicit ones must have an owner
+	owner, err := r.Owner(ctx, name)
+	if err != nil {
+		return name, false
+	}
+	if owner == zeroAddress {
+		parentOwner, err := r.Owner(ctx, name[strings.Index(name, ".")+1:])
+		if err != nil || parentOwner == zeroAddress {
+			return name, false
+		}
+	}
+
+	entry.Trusted = true
+	return name, true
+}
+
+type ensSpenderEntry struct {
+	Name    string
+	Trusted bool
+}
+
+// resolveSpenderENS upgrades an unknown Ethereum spender to "safe" when it is a
+// verified subdomain of a trusted protocol name
+func (c *ChainClient) resolveSpenderENS(ctx context.Context, spenderAddress, spenderName, spenderRisk string) (string, string) {
+	if c.ens == nil || !strings.HasPrefix(spenderName, "0x") {
+		return spenderName, spenderRisk
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if name, trusted := c.ens.ResolveSpender(ctx, spenderAddress); trusted {
+		return "✅ ENS: " + name, "safe"
+	}
+	return spenderName, spenderRisk
+}
+
+// annotateSpenderENS prefixes spender names with the spender's primary ENS name, e.g.
+// "uniswap.eth (✅ Uniswap V3: Router 2)". Only Ethereum clients resolve ENS.
+func (c *ChainClient) annotateSpenderENS(ctx context.Context, approvals []Approval) {
+	if c.ens == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	names := make(map[string]string)
+	for i := range approvals {
+		spender := strings.ToLower(approvals[i].SpenderAddress)
+		name, ok := names[spender]
+		if !ok {
+			var err error
+			if name, err = c.ens.Reverse(ctx, spender); err != nil {
+				requestLogger(ctx, c.logger()).Debug("ENS reverse lookup failed", Fields{"spender": spender, "error": errorText(err)})
+			}
+			names[spender] = name
+		}
+		// Trusted ENS spenders are already named after it
+		if name == "" || strings.Contains(approvals[i].SpenderName, name) {
+			continue
+		}
+		approvals[i].SpenderENS = name
+		approvals[i].SpenderName = name + " (" + approvals[i].SpenderName + ")"
+	}
+}
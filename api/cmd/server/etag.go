@@ -0,0 +1,103 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Scan Response ETags
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// scanResponse is an encoded scan response and its ETag
+type scanResponse struct {
+	ETag string
+	Body []byte
+}
+
+// scanResponseQuery are the query parameters besides wallet and chains that change
+// the body of a scan response; others are left out of the cache key
+var scanResponseQuery = []string{"risk", "tokenType", "isUnlimited", "spender", "groupBy", "includeRevocationCost", "skipTCCheck", "cursor", "limit"}
+
+// scanResponseKey identifies an encoded response: the wallet, its sorted chains, the
+// scan's timestamp, whether it was served from the scan cache (a hit keeps the
+// timestamp but reports cacheHit) and the query parameters that change the body
+func scanResponseKey(walletAddress string, chains []ChainID, scanTimestamp int64, cacheHit bool, query url.Values) string {
+	sorted := make([]string, len(chains))
+	for i, chain := range chains {
+		sorted[i] = string(chain)
+	}
+	slices.Sort(sorted)
+
+	params := url.Values{}
+	for _, name := range scanResponseQuery {
+		if values, ok := query[name]; ok {
+			params[name] = values
+		}
+	}
+	return fmt.Sprintf("scan-response:%s:%s:%d:%t:%s", historyKey(walletAddress), strings.Join(sorted, ","), scanTimestamp, cacheHit, params.Encode())
+}
+
+// encodeScanResponse encodes page like json.Encoder and tags it with the SHA-256 of the body
+func encodeScanResponse(page *WalletScanResult) (scanResponse, error) {
+	body, err := json.Marshal(page)
+	if err != nil {
+		return scanResponse{}, err
+	}
+	body = append(body, '\n')
+	sum := sha256.Sum256(body)
+	return scanResponse{ETag: `"` + hex.EncodeToString(sum[:]) + `"`, Body: body}, nil
+}
+
+// etagMatches reports whether an If-None-Match header lists etag (or is "*")
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeScanResponse sends page with its ETag, or 304 Not Modified when the client
+// already has it. Encoded responses of complete scans are cached so popular wallets
+// are encoded once per scan; forceRefresh re-encodes.
+func (s *Server) writeScanResponse(w http.ResponseWriter, r *http.Request, walletAddress string, chains []ChainID, page *WalletScanResult, forceRefresh bool) {
+	key := scanResponseKey(walletAddress, chains, page.ScanTimestamp, page.CacheHit, r.URL.Query())
+	complete := scanComplete(page)
+
+	var response scanResponse
+	cached, ok := s.responses.Get(key)
+	if ok && complete && !forceRefresh {
+		response = cached.(scanResponse)
+	} else {
+		encoded, err := encodeScanResponse(page)
+		if err != nil {
+			http.Error(w, "failed to encode scan result", http.StatusInternalServerError)
+			return
+		}
+		response = encoded
+		// Like the scan cache, keep scans with chain errors out for the rescan after them
+		if complete {
+			s.responses.Set(key, response)
+		}
+	}
+
+	w.Header().Set("ETag", response.ETag)
+	if etagMatches(r.Header.Get("If-None-Match"), response.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(response.Body)
+}
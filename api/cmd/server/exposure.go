@@ -0,0 +1,148 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Protocol Exposure
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// highExposureUSD is the per-protocol exposure that triggers a recommendation
+const highExposureUSD = 10_000
+
+// usdStablecoins are valued at $1 per token without a price lookup
+var usdStablecoins = map[string]bool{
+	"0xdac17f958d2ee523a2206206994597c13d831ec7": true, // USDT
+	"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": true, // USDC
+	"0x833589fcd6edb6e08f4c7c32d4f71b54bda02913": true, // USDC (Base)
+	"0x6b175474e89094c44da98b954eedeac495271d0f": true, // DAI
+}
+
+// allowanceUSDValue returns the USD value of an allowance, or -1 when unknown.
+// Unlimited approvals have no bounded value and are reported as unknown.
+func allowanceUSDValue(amount *big.Int, tokenAddress string, isUnlimited bool) float64 {
+	if isUnlimited || !usdStablecoins[strings.ToLower(tokenAddress)] {
+		return -1
+	}
+
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(getTokenDecimals(tokenAddress))), nil))
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), divisor).Float64()
+	return value
+}
+
+// ProtocolExposure aggregates a wallet's approvals to a single spender
+type ProtocolExposure struct {
+	Protocol         string     `json:"protocol"`
+	TotalExposureUSD string     `json:"totalExposureUsd"` // sum of approvals with a known USD value
+	TokenCount       int        `json:"tokenCount"`
+	Approvals        []Approval `json:"approvals"`
+	WorstRiskLevel   string     `json:"worstRiskLevel"`
+
+	totalUSD float64
+}
+
+var riskLevelRank = map[string]int{"safe": 0, "warning": 1, "critical": 2}
+
+// buildProtocolExposures groups approvals by spender, sorted by exposure descending
+func buildProtocolExposures(approvals []Approval) []ProtocolExposure {
+	byProtocol := make(map[string]*ProtocolExposure)
+	tokens := make(map[string]map[string]bool)
+
+	for _, approval := range approvals {
+		exposure, ok := byProtocol[approval.SpenderName]
+		if !ok {
+			exposure = &ProtocolExposure{Protocol: approval.SpenderName, WorstRiskLevel: approval.RiskLevel}
+			byProtocol[approval.SpenderName] = exposure
+			tokens[approval.SpenderName] = make(map[string]bool)
+		}
+
+		exposure.Approvals = append(exposure.Approvals, approval)
+		if approval.AllowanceUSD > 0 {
+			exposure.totalUSD += approval.AllowanceUSD
+		}
+		if riskLevelRank[approval.RiskLevel] > riskLevelRank[exposure.WorstRiskLevel] {
+			exposure.WorstRiskLevel = approval.RiskLevel
+		}
+		tokens[approval.SpenderName][string(approval.Chain)+":"+strings.ToLower(approval.TokenAddress)] = true
+	}
+
+	exposures := make([]ProtocolExposure, 0, len(byProtocol))
+	for protocol, exposure := range byProtocol {
+		exposure.TokenCount = len(tokens[protocol])
+		exposure.TotalExposureUSD = fmt.Sprintf("%.2f", exposure.totalUSD)
+		exposures = append(exposures, *exposure)
+	}
+
+	sort.Slice(exposures, func(i, j int) bool {
+		if exposures[i].totalUSD != exposures[j].totalUSD {
+			return exposures[i].totalUSD > exposures[j].totalUSD
+		}
+		return exposures[i].Protocol < exposures[j].Protocol
+	})
+	return exposures
+}
+
+// GroupedApproval is a wallet's approvals to one protocol across chains
+// (?groupBy=spender)
+type GroupedApproval struct {
+	SpenderName string `json:"spenderName"`
+	// SpenderAddresses lists the lowercase spender addresses per chain; names such as
+	// labels or mevBotSpenderName can cover several spenders
+	SpenderAddresses map[ChainID][]string `json:"spenderAddresses"`
+	TotalExposureUSD float64              `json:"totalExposureUsd"` // sum of approvals with a known USD value
+	Chains           []ChainID            `json:"chains"`
+	HighestRiskLevel string               `json:"highestRiskLevel"`
+	Approvals        []Approval           `json:"approvals"`
+}
+
+// groupApprovalsBySpender is buildProtocolExposures with the spender addresses and
+// chains of each group, chains in order of first appearance
+func groupApprovalsBySpender(approvals []Approval) []GroupedApproval {
+	exposures := buildProtocolExposures(approvals)
+	grouped := make([]GroupedApproval, len(exposures))
+	for i, exposure := range exposures {
+		group := GroupedApproval{
+			SpenderName:      exposure.Protocol,
+			SpenderAddresses: make(map[ChainID][]string),
+			TotalExposureUSD: exposure.totalUSD,
+			HighestRiskLevel: exposure.WorstRiskLevel,
+			Approvals:        exposure.Approvals,
+		}
+		for _, approval := range exposure.Approvals {
+			addresses, seen := group.SpenderAddresses[approval.Chain]
+			if !seen {
+				group.Chains = append(group.Chains, approval.Chain)
+			}
+			if address := strings.ToLower(approval.SpenderAddress); !containsString(addresses, address) {
+				group.SpenderAddresses[approval.Chain] = append(addresses, address)
+			}
+		}
+		grouped[i] = group
+	}
+	return grouped
+}
+
+// formatUSDCompact formats a dollar amount as e.g. "$500K" or "$1.2M"
+func formatUSDCompact(value float64) string {
+	format := func(v float64, suffix string) string {
+		s := strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0")
+		return "$" + s + suffix
+	}
+
+	switch {
+	case value >= 1e9:
+		return format(value/1e9, "B")
+	case value >= 1e6:
+		return format(value/1e6, "M")
+	case value >= 1e3:
+		return format(value/1e3, "K")
+	}
+	return fmt.Sprintf("$%.0f", value)
+}
@@ -0,0 +1,378 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Fee Market & Revocation Cost
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRevokeGasLimit is used when eth_estimateGas fails (typical ERC-20 approve(spender, 0))
+const defaultRevokeGasLimit = 45_000
+
+// permit2RevokeGasLimit is a typical Permit2 approve(token, spender, 0, 0)
+const permit2RevokeGasLimit = 29_000
+
+// feeHistoryPercentiles are the priority fee percentiles for slow, standard and fast
+var feeHistoryPercentiles = []int{1, 50, 90}
+
+// FeeData is the current EIP-1559 fee market on a chain, in gwei
+type FeeData struct {
+	BaseFeeGwei          float64 `json:"baseFeeGwei"`
+	MaxPriorityFeeGwei   float64 `json:"maxPriorityFeeGwei"` // 50th percentile
+	EstimatedNextBaseFee float64 `json:"estimatedNextBaseFee"`
+	SlowPriorityFeeGwei  float64 `json:"slowPriorityFeeGwei"` // 1st percentile
+	FastPriorityFeeGwei  float64 `json:"fastPriorityFeeGwei"` // 90th percentile
+}
+
+// FeeMarketClient reads fee market data from chain RPCs
+type FeeMarketClient struct {
+	clients map[ChainID]*ChainClient
+	cache   CacheStore
+}
+
+// NewFeeMarketClient creates a fee client over the shared chain clients
+func NewFeeMarketClient(clients map[ChainID]*ChainClient) *FeeMarketClient {
+	return &FeeMarketClient{
+		clients: clients,
+		cache:   NewCache(12 * time.Second), // ~one Ethereum block
+	}
+}
+
+// GetCurrentFees returns the base fee and priority fee percentiles of the latest block
+func (f *FeeMarketClient) GetCurrentFees(ctx context.Context, chain ChainID) (*FeeData, error) {
+	cacheKey := "fees:" + string(chain)
+	if cached, ok := f.cache.Get(cacheKey); ok {
+		return cached.(*FeeData), nil
+	}
+
+	chainsMu.RLock()
+	client, ok := f.clients[chain]
+	chainsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
+	}
+
+	var history struct {
+		BaseFeePerGas []string   `json:"baseFeePerGas"`
+		Reward        [][]string `json:"reward"`
+	}
+	if err := client.rpcCall(ctx, "eth_feeHistory", []interface{}{1, "latest", feeHistoryPercentiles}, &history); err != nil {
+		return nil, err
+	}
+
+	// baseFeePerGas has blockCount+1 entries: the last one is the next block's base fee
+	if len(history.BaseFeePerGas) < 2 || len(history.Reward) == 0 || len(history.Reward[0]) != len(feeHistoryPercentiles) {
+		return nil, errors.New("eth_feeHistory returned incomplete data")
+	}
+
+	reward := history.Reward[0]
+	fees := &FeeData{
+		BaseFeeGwei:          hexWeiToGwei(history.BaseFeePerGas[0]),
+		EstimatedNextBaseFee: hexWeiToGwei(history.BaseFeePerGas[len(history.BaseFeePerGas)-1]),
+		SlowPriorityFeeGwei:  hexWeiToGwei(reward[0]),
+		MaxPriorityFeeGwei:   hexWeiToGwei(reward[1]),
+		FastPriorityFeeGwei:  hexWeiToGwei(reward[2]),
+	}
+
+	f.cache.Set(cacheKey, fees)
+	return fees, nil
+}
+
+// hexWeiToGwei converts a hex wei quantity to gwei
+func hexWeiToGwei(hexWei string) float64 {
+	wei, ok := new(big.Int).SetString(strings.TrimPrefix(hexWei, "0x"), 16)
+	if !ok {
+		return 0
+	}
+	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+	return gwei
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              NATIVE TOKEN PRICES
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// coinGeckoBaseURL is the CoinGecko public API (overridable in tests)
+var coinGeckoBaseURL = "https://api.coingecko.com/api/v3"
+
+// nativeCoinGeckoIDs maps chains to the CoinGecko ID of their gas token
+var nativeCoinGeckoIDs = map[ChainID]string{
+	Ethereum: "ethereum", Arbitrum: "ethereum", Optimism: "ethereum", Base: "ethereum",
+	ZkSync: "ethereum", Linea: "ethereum", Scroll: "ethereum", ZkEVM: "ethereum",
+	BSC:       "binancecoin",
+	Polygon:   "polygon-ecosystem-token",
+	Avalanche: "avalanche-2",
+	Fantom:    "fantom",
+	Cronos:    "crypto-com-chain",
+	Gnosis:    "xdai",
+	Celo:      "celo",
+	Moonbeam:  "moonbeam",
+	Mantle:    "mantle",
+	Mode:      "ethereum",
+	Blast:     "ethereum",
+	Manta:     "ethereum",
+}
+
+var nativePriceCache = NewCache(10 * time.Minute)
+
+// nativePriceUSD returns the USD price of a chain's gas token
+func nativePriceUSD(ctx context.Context, chain ChainID) (float64, error) {
+	coinID, ok := nativeCoinGeckoIDs[chain]
+	if !ok {
+		return 0, fmt.Errorf("no price source for %s", chain)
+	}
+	if cached, ok := nativePriceCache.Get("native:" + coinID); ok {
+		return cached.(float64), nil
+	}
+
+	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=usd", coinGeckoBaseURL, coinID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("coingecko returned status %d", resp.StatusCode)
+	}
+
+	var prices map[string]struct {
+		USD float64 `json:"usd"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return 0, err
+	}
+
+	price, ok := prices[coinID]
+	if !ok || price.USD <= 0 {
+		return 0, fmt.Errorf("no price for %s", coinID)
+	}
+
+	nativePriceCache.Set("native:"+coinID, price.USD)
+	return price.USD, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              REVOCATION ESTIMATE
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// RevocationCostOption is the cost of a revocation at one speed
+type RevocationCostOption struct {
+	PriorityFeeGwei float64 `json:"priorityFeeGwei"`
+	CostETH         float64 `json:"costEth"` // in the chain's native token
+	CostUSD         float64 `json:"costUsd"` // -1 = unknown price
+}
+
+// RevocationEstimateResponse is returned by /api/v1/revoke/estimate
+type RevocationEstimateResponse struct {
+	Chain        ChainID                         `json:"chain"`
+	GasLimit     uint64                          `json:"gasLimit"`
+	GasEstimated bool                            `json:"gasEstimated"` // false = default gas limit used
+	Fees         *FeeData                        `json:"fees"`
+	PriceUSD     float64                         `json:"nativePriceUsd"` // -1 = unknown
+	CostETH      float64                         `json:"costEth"`
+	CostUSD      float64                         `json:"costUsd"`
+	Speeds       map[string]RevocationCostOption `json:"speeds"`
+}
+
+// revocationCost computes the native and USD cost of gasLimit at baseFee + priorityFee (gwei)
+func revocationCost(gasLimit uint64, baseFeeGwei, priorityFeeGwei, priceUSD float64) RevocationCostOption {
+	costETH := float64(gasLimit) * (baseFeeGwei + priorityFeeGwei) * 1e9 / 1e18
+	costUSD := -1.0
+	if priceUSD > 0 {
+		costUSD = costETH * priceUSD
+	}
+	return RevocationCostOption{PriorityFeeGwei: priorityFeeGwei, CostETH: costETH, CostUSD: costUSD}
+}
+
+// estimateRevokeGas estimates approve(spender, 0) sent by wallet to token
+func (c *ChainClient) estimateRevokeGas(ctx context.Context, wallet, token, spender string) (uint64, error) {
+	return c.estimateGas(ctx, wallet, token, revokeCalldata(spender, false))
+}
+
+// estimateGas estimates a call of data sent by from to to
+func (c *ChainClient) estimateGas(ctx context.Context, from, to, data string) (uint64, error) {
+	var gasHex string
+	params := []interface{}{map[string]string{"from": from, "to": to, "data": data}}
+	if err := c.rpcCall(ctx, "eth_estimateGas", params, &gasHex); err != nil {
+		return 0, err
+	}
+	gas := parseHexUint64(gasHex)
+	if gas == 0 {
+		return 0, fmt.Errorf("invalid gas estimate %q", gasHex)
+	}
+	return gas, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              WALLET REVOCATION COST
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// RevocationEstimate is the cost of revoking every approval of a scan
+// (?includeRevocationCost=true). Gas is priced at the latest base fee plus the
+// median priority fee, like the "standard" option of /api/v1/revoke/estimate.
+type RevocationEstimate struct {
+	TotalRevocations  int                       `json:"totalRevocations"`
+	EstimatedGasUnits uint64                    `json:"estimatedGasUnits"`
+	EstimatedCostETH  float64                   `json:"estimatedCostEth"` // chains paying gas in ETH only
+	EstimatedCostUSD  float64                   `json:"estimatedCostUsd"` // chains with a known fee and price
+	Chains            []ChainRevocationEstimate `json:"chains"`
+}
+
+// ChainRevocationEstimate is the revocation cost on one chain
+type ChainRevocationEstimate struct {
+	Chain           ChainID `json:"chain"`
+	Revocations     int     `json:"revocations"`
+	GasUnits        uint64  `json:"gasUnits"`
+	BaseFeeGwei     float64 `json:"baseFeeGwei"`
+	PriorityFeeGwei float64 `json:"priorityFeeGwei"`
+	NativePriceUSD  float64 `json:"nativePriceUsd"`  // -1 = unknown
+	CostNative      float64 `json:"costNative"`      // in the chain's native token
+	CostUSD         float64 `json:"costUsd"`         // -1 = unknown
+	Error           string  `json:"error,omitempty"` // fee lookup failed, costs are 0
+}
+
+// RevocationCostEstimator prices the revocation of a scan's approvals
+type RevocationCostEstimator struct {
+	feeMarket *FeeMarketClient
+}
+
+// NewRevocationCostEstimator creates an estimator over the server's fee market client
+func NewRevocationCostEstimator(feeMarket *FeeMarketClient) *RevocationCostEstimator {
+	return &RevocationCostEstimator{feeMarket: feeMarket}
+}
+
+// Estimate prices approve(spender, 0) for each approval of result, chains looked up
+// concurrently. Gas is a constant per approval rather than an eth_estimateGas call each.
+func (e *RevocationCostEstimator) Estimate(ctx context.Context, result *WalletScanResult) *RevocationEstimate {
+	var chains []*ChainRevocationEstimate
+	byChain := make(map[ChainID]*ChainRevocationEstimate)
+	for _, approval := range result.Approvals {
+		chain, ok := byChain[approval.Chain]
+		if !ok {
+			chain = &ChainRevocationEstimate{Chain: approval.Chain, NativePriceUSD: -1, CostUSD: -1}
+			byChain[approval.Chain] = chain
+			chains = append(chains, chain)
+		}
+		chain.Revocations++
+		if approval.ViaPermit2 {
+			chain.GasUnits += permit2RevokeGasLimit
+		} else {
+			chain.GasUnits += defaultRevokeGasLimit
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, chain := range chains {
+		wg.Add(1)
+		go func(chain *ChainRevocationEstimate) {
+			defer wg.Done()
+			e.priceChain(ctx, chain)
+		}(chain)
+	}
+	wg.Wait()
+
+	estimate := &RevocationEstimate{Chains: make([]ChainRevocationEstimate, 0, len(chains))}
+	for _, chain := range chains {
+		estimate.TotalRevocations += chain.Revocations
+		estimate.EstimatedGasUnits += chain.GasUnits
+		if nativeCoinGeckoIDs[chain.Chain] == "ethereum" {
+			estimate.EstimatedCostETH += chain.CostNative
+		}
+		if chain.CostUSD > 0 {
+			estimate.EstimatedCostUSD += chain.CostUSD
+		}
+		estimate.Chains = append(estimate.Chains, *chain)
+	}
+	return estimate
+}
+
+// priceChain sets the fees, native price and costs of one chain's revocations
+func (e *RevocationCostEstimator) priceChain(ctx context.Context, chain *ChainRevocationEstimate) {
+	fees, err := e.feeMarket.GetCurrentFees(ctx, chain.Chain)
+	if err != nil {
+		chain.Error = "failed to fetch fees: " + redactURLs(err.Error())
+		return
+	}
+	chain.BaseFeeGwei, chain.PriorityFeeGwei = fees.BaseFeeGwei, fees.MaxPriorityFeeGwei
+	if price, err := nativePriceUSD(ctx, chain.Chain); err == nil {
+		chain.NativePriceUSD = price
+	}
+
+	cost := revocationCost(chain.GasUnits, fees.BaseFeeGwei, fees.MaxPriorityFeeGwei, chain.NativePriceUSD)
+	chain.CostNative = cost.CostETH
+	chain.CostUSD = cost.CostUSD
+}
+
+// Revocation cost estimate endpoint
+func (s *Server) handleRevokeEstimate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	chain := ChainID(strings.ToLower(query.Get("chain")))
+	if chain == "" {
+		chain = Ethereum
+	}
+	wallet, token, spender := query.Get("wallet"), query.Get("token"), query.Get("spender")
+
+	for name, addr := range map[string]string{"wallet": wallet, "token": token, "spender": spender} {
+		if !isValidEthereumAddress(addr) {
+			http.Error(w, fmt.Sprintf("%s parameter must be a 0x address", name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	chainsMu.RLock()
+	client, ok := s.chainClients[chain]
+	chainsMu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported chain: %s", chain), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	fees, err := s.feeMarket.GetCurrentFees(ctx, chain)
+	if err != nil {
+		http.Error(w, "failed to fetch fee data: "+redactURLs(err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	response := RevocationEstimateResponse{Chain: chain, Fees: fees, GasLimit: defaultRevokeGasLimit, PriceUSD: -1}
+	if gas, err := client.estimateRevokeGas(ctx, wallet, token, spender); err == nil {
+		response.GasLimit = gas
+		response.GasEstimated = true
+	}
+	if price, err := nativePriceUSD(ctx, chain); err == nil {
+		response.PriceUSD = price
+	}
+
+	response.Speeds = map[string]RevocationCostOption{
+		"slow":     revocationCost(response.GasLimit, fees.BaseFeeGwei, fees.SlowPriorityFeeGwei, response.PriceUSD),
+		"standard": revocationCost(response.GasLimit, fees.BaseFeeGwei, fees.MaxPriorityFeeGwei, response.PriceUSD),
+		"fast":     revocationCost(response.GasLimit, fees.BaseFeeGwei, fees.FastPriorityFeeGwei, response.PriceUSD),
+	}
+	response.CostETH = response.Speeds["standard"].CostETH
+	response.CostUSD = response.Speeds["standard"].CostUSD
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
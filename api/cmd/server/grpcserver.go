@@ -0,0 +1,311 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - gRPC API
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	sentinelv1 "github.com/sentinel-team/sentinel/api/proto/sentinel/v1"
+)
+
+// defaultGRPCPort is the gRPC listen port unless GRPC_PORT is set
+const defaultGRPCPort = "50051"
+
+// grpcService serves SentinelService from the same scanner and contract analyzer as
+// the HTTP handlers
+type grpcService struct {
+	sentinelv1.UnimplementedSentinelServiceServer
+	server *Server
+}
+
+// newGRPCServer creates a gRPC server for s, filtered, rate limited and authenticated
+// like the HTTP API. Passing the HTTP server's ipFilter and limiter shares each IP's
+// budget across both APIs; nil skips the check.
+func newGRPCServer(s *Server, ipFilter *IPFilter, limiter *RateLimiter) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := grpcAdmit(ctx, ipFilter, limiter); err != nil {
+				return nil, err
+			}
+			ctx, err := grpcAuthenticate(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := grpcAdmit(stream.Context(), ipFilter, limiter); err != nil {
+				return err
+			}
+			ctx, err := grpcAuthenticate(stream.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+		}),
+	)
+	sentinelv1.RegisterSentinelServiceServer(srv, &grpcService{server: s})
+	return srv
+}
+
+// serveGRPC serves the gRPC API on GRPC_PORT until srv is stopped
+func serveGRPC(srv *grpc.Server, logger Logger) {
+	port := getEnv("GRPC_PORT", defaultGRPCPort)
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		logger.Error("gRPC listen failed", Fields{"port": port, "error": errorText(err)})
+		return
+	}
+	logger.Info("Sentinel gRPC API running", Fields{"port": port})
+	if err := srv.Serve(listener); err != nil {
+		logger.Error("gRPC server error", Fields{"error": errorText(err)})
+	}
+}
+
+// authenticatedStream carries the authenticated context to stream handlers
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcAdmit applies IPFilter.Middleware and RateLimiter.Middleware to the caller's
+// peer address: PermissionDenied for filtered IPs, ResourceExhausted over the limit
+func grpcAdmit(ctx context.Context, ipFilter *IPFilter, limiter *RateLimiter) error {
+	ip := grpcPeerIP(ctx)
+	if ipFilter != nil && !ipFilter.Allows(net.ParseIP(ip)) {
+		return status.Error(codes.PermissionDenied, "forbidden")
+	}
+	if limiter != nil {
+		if wait := limiter.take(ip); wait > 0 {
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %.3fs", wait.Seconds())
+		}
+	}
+	return nil
+}
+
+// grpcPeerIP returns the IP of the connection's remote address
+func grpcPeerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// grpcAuthenticate applies AuthMiddleware's rules to the "authorization: Bearer <key>"
+// metadata and returns ctx with the caller's key
+func grpcAuthenticate(ctx context.Context) (context.Context, error) {
+	if apiKeys.Len() == 0 {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	rawKey, ok := "", false
+	if values := md.Get("authorization"); len(values) > 0 {
+		rawKey, ok = strings.CutPrefix(values[0], "Bearer ")
+	}
+	if !ok || rawKey == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing API key (authorization: Bearer <key>)")
+	}
+	key, ok := apiKeys.Lookup(rawKey)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, key), nil
+}
+
+// ScanWallet streams the approvals of each chain as it finishes, then the summary
+func (g *grpcService) ScanWallet(req *sentinelv1.ScanWalletRequest, stream grpc.ServerStreamingServer[sentinelv1.ScanWalletResponse]) error {
+	walletAddress := req.GetWallet()
+	if !isValidEthereumAddress(walletAddress) && !isValidSolanaAddress(walletAddress) {
+		return status.Error(codes.InvalidArgument, "wallet must be an Ethereum or Solana address")
+	}
+	if isValidEthereumAddress(walletAddress) {
+		normalized, err := NormalizeAddress(walletAddress)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		walletAddress = normalized
+	}
+	if !walletAllowedContext(stream.Context(), walletAddress) {
+		return status.Error(codes.PermissionDenied, "API key is not authorized for this wallet")
+	}
+
+	chains := walletChains(walletAddress, supportedChains())
+	if len(req.GetChains()) > 0 {
+		selected, err := resolveChains(req.GetChains())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		chains = selected
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), 30*time.Second)
+	defer cancel()
+
+	// Chains finish concurrently; a stream takes one message at a time
+	var mu sync.Mutex
+	send := func(event *sentinelv1.ScanWalletResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = stream.Send(event)
+	}
+	onChain := func(update ChainScanUpdate) {
+		if update.Error != "" {
+			send(&sentinelv1.ScanWalletResponse{Event: &sentinelv1.ScanWalletResponse_ChainError{
+				ChainError: &sentinelv1.ChainError{Chain: string(update.Chain), Error: update.Error},
+			}})
+			return
+		}
+		for _, approval := range update.Approvals {
+			send(&sentinelv1.ScanWalletResponse{Event: &sentinelv1.ScanWalletResponse_Approval{Approval: approvalToProto(approval)}})
+		}
+	}
+
+	result, err := g.server.scanWithUpdates(ctx, walletAddress, chains, req.GetForceRefresh(), onChain)
+	if err != nil {
+		requestID := requestIDOrNew(stream.Context())
+		g.server.logger().Error("gRPC scan failed", Fields{"request_id": requestID, "wallet": walletAddress, "error": errorText(err)})
+		return status.Errorf(codes.Internal, "failed to scan one or more chains (request %s)", requestID)
+	}
+
+	send(&sentinelv1.ScanWalletResponse{Event: &sentinelv1.ScanWalletResponse_Summary{Summary: summaryToProto(result)}})
+	return nil
+}
+
+// AnalyzeContract runs the contract analysis pipeline
+func (g *grpcService) AnalyzeContract(ctx context.Context, req *sentinelv1.AnalyzeContractRequest) (*sentinelv1.AnalyzeContractResponse, error) {
+	contractAddress := req.GetContract()
+	if !isValidEthereumAddress(contractAddress) {
+		return nil, status.Error(codes.InvalidArgument, "invalid contract address format")
+	}
+	chain := Ethereum
+	if req.GetChain() != "" {
+		chains, err := resolveChains([]string{req.GetChain()})
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		chain = chains[0]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	result, err := g.server.contractAnalyzer.AnalyzeContract(ctx, contractAddress, chain)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to encode analysis: %v", err))
+	}
+	return &sentinelv1.AnalyzeContractResponse{
+		Address:      result.Address,
+		Chain:        string(result.Chain),
+		BytecodeSize: int32(result.BytecodeSize),
+		OverallRisk:  int32(result.OverallRisk),
+		AnalyzedAt:   result.AnalyzedAt,
+		ResultJson:   resultJSON,
+	}, nil
+}
+
+// approvalToProto converts an approval to its gRPC message
+func approvalToProto(approval Approval) *sentinelv1.Approval {
+	return &sentinelv1.Approval{
+		Chain:          string(approval.Chain),
+		TokenAddress:   approval.TokenAddress,
+		TokenSymbol:    approval.TokenSymbol,
+		TokenType:      approval.TokenType,
+		SpenderAddress: approval.SpenderAddress,
+		SpenderName:    approval.SpenderName,
+		AllowanceRaw:   approval.AllowanceRaw,
+		AllowanceHuman: approval.AllowanceHuman,
+		AllowanceUsd:   approval.AllowanceUSD,
+		IsUnlimited:    approval.IsUnlimited,
+		RiskLevel:      approval.RiskLevel,
+		RiskReasons:    approval.RiskReasons,
+		LastUpdated:    approval.LastUpdated,
+		TxHash:         approval.TxHash,
+	}
+}
+
+// summaryToProto converts the counts and scores of a scan to its gRPC message
+func summaryToProto(result *WalletScanResult) *sentinelv1.ScanSummary {
+	chains := make([]string, len(result.ChainsScanned))
+	for i, chain := range result.ChainsScanned {
+		chains[i] = string(chain)
+	}
+	return &sentinelv1.ScanSummary{
+		Wallet:           result.WalletAddress,
+		ScanTimestamp:    result.ScanTimestamp,
+		OverallRiskScore: int32(result.OverallRiskScore),
+		TotalApprovals:   int32(result.TotalApprovals),
+		CriticalRisks:    int32(result.CriticalRisks),
+		Warnings:         int32(result.Warnings),
+		TotalValueAtRisk: result.TotalValueAtRisk,
+		ChainsScanned:    chains,
+		CacheHit:         result.CacheHit,
+	}
+}
+
+// newDecompilerGRPCClient connects to the decompiler's gRPC service at DECOMPILER_GRPC_URL
+// (host:port, plaintext inside the deployment), or returns nil when it isn't set
+func newDecompilerGRPCClient(logger Logger) sentinelv1.DecompilerServiceClient {
+	target := getEnv("DECOMPILER_GRPC_URL", "")
+	if target == "" {
+		return nil
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		loggerOr(logger).Warn("Invalid DECOMPILER_GRPC_URL, using the HTTP decompiler", Fields{"target": target, "error": errorText(err)})
+		return nil
+	}
+	return sentinelv1.NewDecompilerServiceClient(conn)
+}
+
+// decompileGRPC sends bytecode to the decompiler's gRPC service
+func (d *DecompilerClient) decompileGRPC(ctx context.Context, bytecode []byte) (*DecompilerResponse, error) {
+	if requestID := requestIDFrom(ctx); requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", requestID)
+	}
+	resp, err := d.grpc.Decompile(ctx, &sentinelv1.DecompileRequest{Bytecode: bytecode})
+	if err != nil {
+		return nil, fmt.Errorf("decompiler gRPC request failed: %w", err)
+	}
+	return &DecompilerResponse{
+		Success:    resp.GetSuccess(),
+		Opcodes:    resp.GetOpcodes(),
+		Functions:  resp.GetFunctions(),
+		Selectors:  resp.GetSelectors(),
+		IsProxy:    resp.GetIsProxy(),
+		HasSSTORE:  resp.GetHasSstore(),
+		HasCALL:    resp.GetHasCall(),
+		Complexity: int(resp.GetComplexity()),
+		Warnings:   resp.GetWarnings(),
+	}, nil
+}
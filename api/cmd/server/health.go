@@ -0,0 +1,144 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Liveness & Readiness Probes
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// readinessTimeout bounds each dependency check of /health/ready
+const readinessTimeout = 5 * time.Second
+
+// readinessCacheTTL is how long a /health/ready result answers later probes, so the
+// unauthenticated endpoint can't be used to fan out calls to every RPC endpoint
+const readinessCacheTTL = 5 * time.Second
+
+// readinessCacheKey holds the unhealthy dependencies of the last readiness check in
+// the server's response cache
+const readinessCacheKey = "health-ready"
+
+// pinger is implemented by services readiness can check; services without it (e.g.
+// test doubles) are assumed ready
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// pingService GETs a service's /health endpoint
+func pingService(ctx context.Context, client *http.Client, baseURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Ping checks that the decompiler is up
+func (d *DecompilerClient) Ping(ctx context.Context) error {
+	return pingService(ctx, d.client, d.baseURL)
+}
+
+// Ping checks that the analyzer is up
+func (a *AnalyzerClient) Ping(ctx context.Context) error {
+	return pingService(ctx, a.client, a.baseURL)
+}
+
+// readinessChecks returns the dependencies /health/ready checks, by name: every chain's
+// RPC endpoint and the decompiler and analyzer services
+func (s *Server) readinessChecks() map[string]func(context.Context) error {
+	checks := make(map[string]func(context.Context) error)
+
+	chainsMu.RLock()
+	for chain, client := range s.chainClients {
+		client := client
+		checks[string(chain)+"-rpc"] = func(ctx context.Context) error {
+			_, err := client.latestBlockNumber(ctx)
+			return err
+		}
+	}
+	chainsMu.RUnlock()
+
+	if s.contractAnalyzer != nil {
+		if p, ok := s.contractAnalyzer.decompiler.(pinger); ok {
+			checks["decompiler"] = p.Ping
+		}
+		if p, ok := s.contractAnalyzer.analyzer.(pinger); ok {
+			checks["analyzer"] = p.Ping
+		}
+	}
+	return checks
+}
+
+// Liveness probe - the process is serving HTTP
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "alive"})
+}
+
+// Readiness probe - 200 once every dependency answers, else 503 listing the ones that
+// don't. Results are reused for readinessCacheTTL.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	var unhealthy []string
+	if s.responses == nil {
+		unhealthy = s.checkReadiness(r.Context())
+	} else if cached, ok := s.responses.Get(readinessCacheKey); ok {
+		unhealthy = cached.([]string)
+	} else {
+		unhealthy = s.checkReadiness(r.Context())
+		s.responses.SetWithTTL(readinessCacheKey, unhealthy, readinessCacheTTL)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(unhealthy) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "not_ready", "unhealthy": unhealthy})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ready", "unhealthy": unhealthy})
+}
+
+// checkReadiness runs every readiness check concurrently, taking at most
+// readinessTimeout, and returns the sorted names of the failing dependencies
+func (s *Server) checkReadiness(ctx context.Context) []string {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		unhealthy = make([]string, 0)
+	)
+	for name, check := range s.readinessChecks() {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			if err := check(ctx); err != nil {
+				requestLogger(ctx, s.logger()).Warn("Readiness check failed", Fields{"dependency": name, "error": errorText(err)})
+				mu.Lock()
+				unhealthy = append(unhealthy, name)
+				mu.Unlock()
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	sort.Strings(unhealthy)
+	return unhealthy
+}
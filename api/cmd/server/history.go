@@ -0,0 +1,196 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Scan History
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// defaultHistoryDepth is the number of scans kept per wallet unless configured
+const defaultHistoryDepth = 20
+
+// defaultHistoryWallets is the number of wallets whose scans are kept unless configured
+const defaultHistoryWallets = 10_000
+
+// HistoryEntry is the risk posture of a wallet at one scan
+type HistoryEntry struct {
+	Timestamp        int64 `json:"timestamp"`
+	OverallRiskScore int   `json:"overallRiskScore"`
+	TotalApprovals   int   `json:"totalApprovals"`
+	CriticalRisks    int   `json:"criticalRisks"`
+	Warnings         int   `json:"warnings"`
+
+	// approvals are the scan's approvals, kept for /api/v1/diff
+	approvals []Approval
+}
+
+// ScanHistory keeps the last depth scans of up to maxWallets wallets in memory,
+// dropping the wallet scanned longest ago when a new one exceeds it. Retention is
+// independent of the scan cache TTL.
+type ScanHistory struct {
+	mu         sync.RWMutex
+	depth      int
+	maxWallets int
+	wallets    map[string]*historyRing
+	seq        uint64 // last historyRing.added handed out
+}
+
+// historyRing is a fixed-size ring buffer of entries; next is the slot written next
+// and added orders wallets by their last scan
+type historyRing struct {
+	entries []HistoryEntry
+	next    int
+	added   uint64
+}
+
+// NewScanHistory creates a history keeping depth entries (<= 0 = defaultHistoryDepth)
+// for each of up to maxWallets wallets (<= 0 = defaultHistoryWallets)
+func NewScanHistory(depth, maxWallets int) *ScanHistory {
+	if depth <= 0 {
+		depth = defaultHistoryDepth
+	}
+	if maxWallets <= 0 {
+		maxWallets = defaultHistoryWallets
+	}
+	return &ScanHistory{depth: depth, maxWallets: maxWallets, wallets: make(map[string]*historyRing)}
+}
+
+// historyKey normalizes a wallet address; Solana addresses are case-sensitive
+func historyKey(walletAddress string) string {
+	if isValidEthereumAddress(walletAddress) {
+		return strings.ToLower(walletAddress)
+	}
+	return walletAddress
+}
+
+// Add records a scan of wallet, overwriting the oldest entry once depth is reached
+func (h *ScanHistory) Add(walletAddress string, entry HistoryEntry) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := historyKey(walletAddress)
+	ring, ok := h.wallets[key]
+	if !ok {
+		if len(h.wallets) >= h.maxWallets {
+			h.evictOldest()
+		}
+		ring = &historyRing{entries: make([]HistoryEntry, 0, h.depth)}
+		h.wallets[key] = ring
+	}
+	h.seq++
+	ring.added = h.seq
+	if len(ring.entries) < h.depth {
+		ring.entries = append(ring.entries, entry)
+	} else {
+		ring.entries[ring.next] = entry
+	}
+	ring.next = (ring.next + 1) % h.depth
+}
+
+// evictOldest drops the wallet scanned longest ago; h.mu must be held
+func (h *ScanHistory) evictOldest() {
+	oldest := ""
+	for key, ring := range h.wallets {
+		if oldest == "" || ring.added < h.wallets[oldest].added {
+			oldest = key
+		}
+	}
+	delete(h.wallets, oldest)
+}
+
+// Get returns the wallet's recorded scans, oldest first
+func (h *ScanHistory) Get(walletAddress string) []HistoryEntry {
+	if h == nil {
+		return []HistoryEntry{}
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ring, ok := h.wallets[historyKey(walletAddress)]
+	if !ok {
+		return []HistoryEntry{}
+	}
+	if len(ring.entries) < h.depth {
+		return append([]HistoryEntry(nil), ring.entries...)
+	}
+	return append(append([]HistoryEntry(nil), ring.entries[ring.next:]...), ring.entries[:ring.next]...)
+}
+
+// Closest returns the wallet's recorded scan nearest to timestamp
+func (h *ScanHistory) Closest(walletAddress string, timestamp int64) (HistoryEntry, bool) {
+	var closest HistoryEntry
+	found := false
+	for _, entry := range h.Get(walletAddress) {
+		if !found || absInt64(entry.Timestamp-timestamp) < absInt64(closest.Timestamp-timestamp) {
+			closest, found = entry, true
+		}
+	}
+	return closest, found
+}
+
+// absInt64 returns the absolute value of n
+func absInt64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// ScanHistoryService is implemented by scanners that keep per-wallet scan history
+type ScanHistoryService interface {
+	History(walletAddress string) []HistoryEntry
+}
+
+// History returns the wallet's recorded scans, oldest first
+func (s *Scanner) History(walletAddress string) []HistoryEntry {
+	return s.history.Get(walletAddress)
+}
+
+// recordHistory appends a finished scan to the wallet's history
+func (s *Scanner) recordHistory(result *WalletScanResult) {
+	s.history.Add(result.WalletAddress, HistoryEntry{
+		Timestamp:        result.ScanTimestamp,
+		OverallRiskScore: result.OverallRiskScore,
+		TotalApprovals:   result.TotalApprovals,
+		CriticalRisks:    result.CriticalRisks,
+		Warnings:         result.Warnings,
+		approvals:        copyApprovals(result.Approvals),
+	})
+}
+
+// Scan history endpoint: GET /api/v1/history/{wallet}
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	walletAddress := strings.Trim(r.URL.Path[strings.Index(r.URL.Path, "/history")+len("/history"):], "/")
+	if !isValidEthereumAddress(walletAddress) && !isValidSolanaAddress(walletAddress) {
+		http.Error(w, "wallet must be an Ethereum or Solana address", http.StatusBadRequest)
+		return
+	}
+
+	history, ok := s.scanner.(ScanHistoryService)
+	if !ok {
+		http.Error(w, "scan history not available", http.StatusNotImplemented)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"wallet":  historyKey(walletAddress),
+		"history": history.History(walletAddress),
+	})
+}
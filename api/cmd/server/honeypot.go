@@ -0,0 +1,281 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Honeypot Detection
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// dexRouter is a Uniswap V2-style router and the wrapped native token it trades against
+type dexRouter struct {
+	Router        string
+	WrappedNative string
+}
+
+// honeypotRouters are the V2 routers tokens are test-traded on, per chain
+var honeypotRouters = map[ChainID]dexRouter{
+	Ethereum: {Router: "0x7a250d5630b4cf539739df2c5dacb4c659f2488d", WrappedNative: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"}, // Uniswap V2
+	BSC:      {Router: "0x10ed43c718714eb63d5aa57b78b54704e256024e", WrappedNative: "0xbb4cdb9cbd36b01bd1cbaebf2de08d9173bc095c"}, // PancakeSwap V2
+	Polygon:  {Router: "0xa5e0829caced8ffdd4de3c43696c57f7d7a678ff", WrappedNative: "0x0d500b1d8e8ef31e21c99d1db9a6444d3adf1270"}, // QuickSwap
+	Arbitrum: {Router: "0x1b02da8cb0d097eb8d57a175b88c7d8b47997506", WrappedNative: "0x82af49447d8a07e3bd95bd0d56f35241523fbab1"}, // SushiSwap
+	Base:     {Router: "0x4752ba5dbc23f44d87826276bf6fd6b1c372ad24", WrappedNative: "0x4200000000000000000000000000000000000006"}, // Uniswap V2
+}
+
+// Selectors of the simulated trade
+const (
+	getAmountsOutSelector     = "0xd06ca61f" // getAmountsOut(uint256,address[])
+	buyFeeOnTransferSelector  = "0xb6f9de95" // swapExactETHForTokensSupportingFeeOnTransferTokens(uint256,address[],address,uint256)
+	sellFeeOnTransferSelector = "0x791ac947" // swapExactTokensForETHSupportingFeeOnTransferTokens(uint256,uint256,address[],address,uint256)
+	balanceOfSelector         = "0x70a08231" // balanceOf(address)
+	getEthBalanceSelector     = "0x4d2301cc" // Multicall3 getEthBalance(address)
+)
+
+// honeypotTrader is the fresh address trades are simulated from, so no token can
+// have whitelisted it
+const honeypotTrader = "0x5e1f5e1f5e1f5e1f5e1f5e1f5e1f5e1f5e1f5e1f"
+
+// honeypotBuyWei is the native amount spent on the simulated buy (0.01 ETH/BNB/...)
+var honeypotBuyWei = big.NewInt(1e16)
+
+// honeypotFeeWarning is the round-trip loss, in percent, above which a token is flagged
+// for a hidden fee; V2 swap fees alone cost about 0.6%
+const honeypotFeeWarning = 10.0
+
+// HoneypotResult is the outcome of a simulated buy and sell of a token
+type HoneypotResult struct {
+	IsHoneypot bool `json:"is_honeypot"`
+	// HiddenFee is the share of the native amount spent that the round trip lost, in
+	// percent; it includes the DEX fees (~0.6% on V2 routers)
+	HiddenFee      float64 `json:"hidden_fee"`
+	Router         string  `json:"router"`
+	BuyAmountWei   string  `json:"buy_amount_wei"`
+	TokensExpected string  `json:"tokens_expected"` // quoted by the router
+	TokensReceived string  `json:"tokens_received"`
+	SellAmountWei  string  `json:"sell_amount_wei"` // native amount returned by the sell
+	Reason         string  `json:"reason,omitempty"`
+}
+
+// HoneypotChecker simulates buying and selling a token on the chain's V2 router.
+//
+// Selling needs the tokens from the buy, which separate eth_calls can't carry over,
+// so both run through eth_simulateV1 against one pinned block: first the buy alone to
+// learn the amount received, then the buy followed by an approve and a sell of exactly
+// that amount. The trader's native balance is overridden, and Multicall3's
+// getEthBalance around the sell measures what it returned.
+type HoneypotChecker struct {
+	clients map[ChainID]*ChainClient
+}
+
+// NewHoneypotChecker creates a checker over the shared chain clients
+func NewHoneypotChecker(clients map[ChainID]*ChainClient) *HoneypotChecker {
+	return &HoneypotChecker{clients: clients}
+}
+
+// simulatedCall is a call of an eth_simulateV1 block
+type simulatedCall struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Data  string `json:"data"`
+	Value string `json:"value,omitempty"`
+}
+
+// simulatedResult is the outcome of a simulated call
+type simulatedResult struct {
+	Status     string `json:"status"` // "0x1" = success
+	ReturnData string `json:"returnData"`
+	Error      *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// simulate runs calls in order within one block on top of block, the trader funded
+func (c *ChainClient) simulate(ctx context.Context, block uint64, calls []simulatedCall) ([]simulatedResult, error) {
+	params := []interface{}{
+		map[string]interface{}{
+			"blockStateCalls": []interface{}{
+				map[string]interface{}{
+					"stateOverrides": map[string]interface{}{
+						honeypotTrader: map[string]string{"balance": "0x56bc75e2d63100000"}, // 100 native
+					},
+					"calls": calls,
+				},
+			},
+			"validation": false,
+		},
+		fmt.Sprintf("0x%x", block),
+	}
+
+	var blocks []struct {
+		Calls []simulatedResult `json:"calls"`
+	}
+	if err := c.rpcCall(ctx, "eth_simulateV1", params, &blocks); err != nil {
+		return nil, err
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != len(calls) {
+		return nil, errors.New("eth_simulateV1 returned an unexpected number of results")
+	}
+	return blocks[0].Calls, nil
+}
+
+// ok reports whether a simulated call succeeded
+func (r simulatedResult) ok() bool {
+	return r.Status == "0x1" && r.Error == nil
+}
+
+// lastWord returns the last 32-byte word of the call's return data as an integer
+func (r simulatedResult) lastWord() *big.Int {
+	words := abiWords(r.ReturnData)
+	if len(words) == 0 {
+		return new(big.Int)
+	}
+	n, _ := new(big.Int).SetString(words[len(words)-1], 16)
+	return n
+}
+
+// abiUint encodes an unsigned integer word
+func abiUint(n *big.Int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// abiAddress encodes an address word
+func abiAddress(address string) string {
+	return strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(address), "0x")
+}
+
+// abiAddressArray encodes the tail of a dynamic address[] argument
+func abiAddressArray(addresses ...string) string {
+	out := fmt.Sprintf("%064x", len(addresses))
+	for _, address := range addresses {
+		out += abiAddress(address)
+	}
+	return out
+}
+
+// Check simulates a round trip through the token. Errors mean the check couldn't
+// run (unsupported chain, no pool, no eth_simulateV1), not that the token is safe.
+func (h *HoneypotChecker) Check(ctx context.Context, tokenAddress string, chain ChainID) (*HoneypotResult, error) {
+	dex, ok := honeypotRouters[chain]
+	if !ok {
+		return nil, fmt.Errorf("no DEX router for honeypot checks on %s", chain)
+	}
+	chainsMu.RLock()
+	client, ok := h.clients[chain]
+	chainsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	block, err := client.latestBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := strings.ToLower(tokenAddress)
+	deadline := new(big.Int).SetUint64(1 << 32)
+	buy := simulatedCall{
+		From: honeypotTrader,
+		To:   dex.Router,
+		Data: buyFeeOnTransferSelector + abiUint(new(big.Int)) + fmt.Sprintf("%064x", 128) +
+			abiAddress(honeypotTrader) + abiUint(deadline) + abiAddressArray(dex.WrappedNative, token),
+		Value: fmt.Sprintf("0x%x", honeypotBuyWei),
+	}
+
+	// Buy simulation: the router's quote, the buy, and what actually arrived
+	results, err := client.simulate(ctx, block, []simulatedCall{
+		{From: honeypotTrader, To: dex.Router, Data: getAmountsOutSelector + abiUint(honeypotBuyWei) + fmt.Sprintf("%064x", 64) +
+			abiAddressArray(dex.WrappedNative, token)},
+		buy,
+		{From: honeypotTrader, To: token, Data: balanceOfSelector + abiAddress(honeypotTrader)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !results[0].ok() {
+		return nil, fmt.Errorf("no %s liquidity for %s", dex.Router, token)
+	}
+
+	result := &HoneypotResult{
+		Router:         dex.Router,
+		BuyAmountWei:   honeypotBuyWei.String(),
+		TokensExpected: results[0].lastWord().String(),
+		TokensReceived: "0",
+		SellAmountWei:  "0",
+	}
+	if !results[1].ok() {
+		// Blocking buys doesn't trap holders, so it isn't called a honeypot
+		result.Reason = "buy reverted"
+		return result, nil
+	}
+	received := results[2].lastWord()
+	result.TokensReceived = received.String()
+	if received.Sign() == 0 {
+		result.IsHoneypot = true
+		result.HiddenFee = 100
+		result.Reason = "buy delivered no tokens"
+		return result, nil
+	}
+
+	// Sell simulation: buy again, approve the router and sell exactly what arrived
+	results, err = client.simulate(ctx, block, []simulatedCall{
+		buy,
+		{From: honeypotTrader, To: token, Data: approveSelector + abiAddress(dex.Router) + abiUint(received)},
+		{From: honeypotTrader, To: multicall3Address, Data: getEthBalanceSelector + abiAddress(honeypotTrader)},
+		{From: honeypotTrader, To: dex.Router, Data: sellFeeOnTransferSelector + abiUint(received) + abiUint(new(big.Int)) +
+			fmt.Sprintf("%064x", 160) + abiAddress(honeypotTrader) + abiUint(deadline) + abiAddressArray(token, dex.WrappedNative)},
+		{From: honeypotTrader, To: multicall3Address, Data: getEthBalanceSelector + abiAddress(honeypotTrader)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !results[0].ok() || !results[2].ok() || !results[4].ok() {
+		return nil, errors.New("sell simulation setup failed")
+	}
+
+	returned := new(big.Int).Sub(results[4].lastWord(), results[2].lastWord())
+	if !results[1].ok() || !results[3].ok() || returned.Sign() <= 0 {
+		result.IsHoneypot = true
+		result.HiddenFee = 100
+		result.Reason = "sell reverted or returned nothing"
+		return result, nil
+	}
+
+	result.SellAmountWei = returned.String()
+	lost := new(big.Float).SetInt(new(big.Int).Sub(honeypotBuyWei, returned))
+	fee, _ := new(big.Float).Quo(lost, new(big.Float).SetInt(honeypotBuyWei)).Float64()
+	result.HiddenFee = fee * 100
+	return result, nil
+}
+
+// contractRisk reports the check as the contract's ContractRisk. RiskScore is left
+// for the caller, which knows the analyzer's score.
+func (r *HoneypotResult) contractRisk(address string, chain ChainID) *ContractRisk {
+	risk := &ContractRisk{
+		Address:    strings.ToLower(address),
+		Chain:      chain,
+		IsHoneypot: r.IsHoneypot,
+		HiddenFee:  r.HiddenFee,
+		RiskLevel:  "safe",
+	}
+	switch {
+	case r.IsHoneypot:
+		risk.RiskLevel = "critical"
+		risk.Vulnerabilities = []string{"honeypot: " + r.Reason}
+	case r.HiddenFee > honeypotFeeWarning:
+		risk.RiskLevel = "warning"
+		risk.Vulnerabilities = []string{fmt.Sprintf("hidden transfer fee of %.1f%%", r.HiddenFee)}
+	}
+	return risk
+}
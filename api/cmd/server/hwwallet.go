@@ -0,0 +1,110 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Wallet Security Profile
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Wallet types reported in WalletScanResult.WalletType
+const (
+	walletTypeEOA          = "eoa"
+	walletTypeSafe         = "safe"
+	walletTypeSmartAccount = "smart_account"
+)
+
+// erc4337EntryPoints are the canonical ERC-4337 EntryPoint deployments (v0.6, v0.7)
+var erc4337EntryPoints = map[string]bool{
+	"0x5ff137d4b0fdcd49dca30c7cf57e578a026d2789": true,
+	"0x0000000071727de22e5e9d8baf0edac6f37da032": true,
+}
+
+// safeProxyMarker is the masterCopy() dispatch (PUSH32 0xa619486e...) in Gnosis Safe proxies
+const safeProxyMarker = "7fa619486e"
+
+// isGnosisSafeBytecode reports whether runtime bytecode is a Gnosis Safe proxy
+func isGnosisSafeBytecode(code []byte) bool {
+	return strings.Contains(fmt.Sprintf("%x", code), safeProxyMarker)
+}
+
+// getNativeBalance returns the wallet's gas token balance in whole tokens
+func (c *ChainClient) getNativeBalance(ctx context.Context, walletAddress string) (float64, error) {
+	var balanceHex string
+	if err := c.rpcCall(ctx, "eth_getBalance", []interface{}{walletAddress, "latest"}, &balanceHex); err != nil {
+		return 0, err
+	}
+	wei, ok := new(big.Int).SetString(strings.TrimPrefix(balanceHex, "0x"), 16)
+	if !ok {
+		return 0, fmt.Errorf("invalid balance %q", balanceHex)
+	}
+	balance, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return balance, nil
+}
+
+// profileWallet records the Ethereum balance and wallet type on the scan result
+func (c *ChainClient) profileWallet(ctx context.Context, result *WalletScanResult) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if balance, err := c.getNativeBalance(ctx, result.WalletAddress); err == nil {
+		if result.NativeBalance == nil {
+			result.NativeBalance = make(map[ChainID]float64)
+		}
+		result.NativeBalance[c.ChainID] = balance
+	} else {
+		requestLogger(ctx, c.logger()).Warn("Failed to fetch balance", Fields{"wallet": result.WalletAddress, "error": errorText(err)})
+	}
+
+	code, err := c.GetContractBytecode(ctx, result.WalletAddress)
+	switch {
+	case err != nil:
+		requestLogger(ctx, c.logger()).Warn("Failed to fetch code", Fields{"wallet": result.WalletAddress, "error": errorText(err)})
+	case len(code) == 0:
+		result.WalletType = walletTypeEOA
+	case isGnosisSafeBytecode(code):
+		result.WalletType = walletTypeSafe
+	default:
+		result.WalletType = walletTypeSmartAccount
+	}
+}
+
+// usesEntryPoint reports whether the wallet is a smart account or approved an ERC-4337 EntryPoint
+func usesEntryPoint(result *WalletScanResult) bool {
+	if result.WalletType == walletTypeSmartAccount {
+		return true
+	}
+	for _, approval := range result.Approvals {
+		if erc4337EntryPoints[strings.ToLower(approval.SpenderAddress)] {
+			return true
+		}
+	}
+	return false
+}
+
+// hardwareWalletRecommendations suggests a hardware signer for wallets holding
+// significant assets (HW_WALLET_RECOMMEND_ETH / HW_WALLET_RECOMMEND_USD). Safes
+// already require multiple signers and are skipped.
+func hardwareWalletRecommendations(result *WalletScanResult) []string {
+	if result.WalletType == walletTypeSafe {
+		return nil
+	}
+	if result.NativeBalance[Ethereum] <= config.HWWalletRecommendETH && result.TotalValueAtRisk <= config.HWWalletRecommendUSD {
+		return nil
+	}
+
+	recommendations := []string{"🔐 Consider using a hardware wallet (Ledger/Trezor) for wallets holding significant assets"}
+	if usesEntryPoint(result) {
+		recommendations = append(recommendations,
+			"🔐 Ledger and Trezor now support smart account (ERC-4337) wallets natively - use one as your account's signer")
+	}
+	return recommendations
+}
@@ -0,0 +1,235 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Wallet Identity
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default social identity APIs, overridden by LENS_API_URL and NEYNAR_API_URL
+const (
+	lensAPIURL   = "https://api.lens.dev"
+	neynarAPIURL = "https://api.neynar.com"
+)
+
+// identityCacheTTL is how long Lens handles and Farcaster names are reused
+const identityCacheTTL = time.Hour
+
+// identityLookupTimeout bounds the identity lookups of one scan
+const identityLookupTimeout = 5 * time.Second
+
+// WalletIdentity is who a wallet belongs to, as far as public name services and the
+// address book tell. Fields without an identity are empty, never null.
+type WalletIdentity struct {
+	ENSName       string   `json:"ensName"`
+	LensHandle    string   `json:"lensHandle"`
+	FarcasterName string   `json:"farcasterName"`
+	Labels        []string `json:"labels"` // address book label and type
+}
+
+// MarshalJSON encodes missing labels as [] rather than null
+func (w WalletIdentity) MarshalJSON() ([]byte, error) {
+	type plain WalletIdentity
+	if w.Labels == nil {
+		w.Labels = []string{}
+	}
+	return json.Marshal(plain(w))
+}
+
+// IdentityResolver looks up a wallet's Lens handle and Farcaster name
+type IdentityResolver struct {
+	lensURL   string
+	neynarURL string
+	neynarKey string // "" = Farcaster lookups disabled
+	client    *http.Client
+	cache     *Cache
+}
+
+// NewIdentityResolver creates a resolver caching each name for an hour
+func NewIdentityResolver(lensURL, neynarURL, neynarKey string, clock Clock) *IdentityResolver {
+	return &IdentityResolver{
+		lensURL:   strings.TrimSuffix(lensURL, "/"),
+		neynarURL: strings.TrimSuffix(neynarURL, "/"),
+		neynarKey: neynarKey,
+		client:    &http.Client{Transport: sharedServiceTransport(), Timeout: 10 * time.Second},
+		cache:     NewCacheWithClock(identityCacheTTL, clock),
+	}
+}
+
+// newIdentityResolverFromEnv returns a resolver when SOCIAL_IDENTITY=true (nil = ENS
+// and the address book only). Farcaster names need NEYNAR_API_KEY.
+func newIdentityResolverFromEnv() *IdentityResolver {
+	if getEnv("SOCIAL_IDENTITY", "") != "true" {
+		return nil
+	}
+	return NewIdentityResolver(getEnv("LENS_API_URL", lensAPIURL), getEnv("NEYNAR_API_URL", neynarAPIURL), getEnv("NEYNAR_API_KEY", ""), RealClock{})
+}
+
+// Resolve returns the wallet's Lens handle and Farcaster name, looked up concurrently.
+// Failed lookups are logged and leave their name empty.
+func (r *IdentityResolver) Resolve(ctx context.Context, walletAddress string, logger Logger) (lensHandle, farcasterName string) {
+	walletAddress = strings.ToLower(walletAddress)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		name, err := r.cachedLookup(ctx, "lens:"+walletAddress, func() (string, error) { return r.lensHandle(ctx, walletAddress) })
+		if err != nil {
+			logger.Debug("Lens profile lookup failed", Fields{"wallet": walletAddress, "error": errorText(err)})
+		}
+		lensHandle = name
+	}()
+	if r.neynarKey != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name, err := r.cachedLookup(ctx, "farcaster:"+walletAddress, func() (string, error) { return r.farcasterName(ctx, walletAddress) })
+			if err != nil {
+				logger.Debug("Farcaster name lookup failed", Fields{"wallet": walletAddress, "error": errorText(err)})
+			}
+			farcasterName = name
+		}()
+	}
+	wg.Wait()
+	return lensHandle, farcasterName
+}
+
+// cachedLookup returns the cached name under key or runs lookup; failures are not cached
+func (r *IdentityResolver) cachedLookup(ctx context.Context, key string, lookup func() (string, error)) (string, error) {
+	if cached, ok := r.cache.Get(key); ok {
+		return cached.(string), nil
+	}
+	name, err := lookup()
+	if err != nil {
+		return "", err
+	}
+	r.cache.Set(key, name)
+	return name, nil
+}
+
+// lensHandle returns the handle of the wallet's default Lens profile ("" if none)
+func (r *IdentityResolver) lensHandle(ctx context.Context, walletAddress string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     `query DefaultProfile($address: EthereumAddress!) { defaultProfile(request: {ethereumAddress: $address}) { handle } }`,
+		"variables": map[string]string{"address": walletAddress},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.lensURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("lens request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkHTTPStatus("lens", resp); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Data struct {
+			DefaultProfile *struct {
+				Handle string `json:"handle"`
+			} `json:"defaultProfile"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode lens response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return "", fmt.Errorf("lens error: %s", result.Errors[0].Message)
+	}
+	if result.Data.DefaultProfile == nil {
+		return "", nil
+	}
+	return result.Data.DefaultProfile.Handle, nil
+}
+
+// farcasterName returns the username of the first Farcaster account verified for the
+// wallet ("" if none), from Neynar
+func (r *IdentityResolver) farcasterName(ctx context.Context, walletAddress string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.neynarURL+"/v2/farcaster/user/bulk-by-address?addresses="+url.QueryEscape(walletAddress), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("x-api-key", r.neynarKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("neynar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil // no Farcaster account for the address
+	}
+	if err := checkHTTPStatus("neynar", resp); err != nil {
+		return "", err
+	}
+
+	var users map[string][]struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return "", fmt.Errorf("failed to decode neynar response: %w", err)
+	}
+	for address, accounts := range users {
+		if strings.EqualFold(address, walletAddress) && len(accounts) > 0 {
+			return accounts[0].Username, nil
+		}
+	}
+	return "", nil
+}
+
+// walletIdentity resolves the wallet's ENS name and, when configured, its Lens and
+// Farcaster names concurrently, and adds its address book label
+func (s *Scanner) walletIdentity(ctx context.Context, walletAddress string, chains []ChainID) WalletIdentity {
+	ctx, cancel := context.WithTimeout(ctx, identityLookupTimeout)
+	defer cancel()
+
+	identity := WalletIdentity{Labels: []string{}}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		identity.ENSName = s.walletENS(ctx, walletAddress, chains)
+	}()
+	if s.identity != nil && isValidEthereumAddress(walletAddress) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			identity.LensHandle, identity.FarcasterName = s.identity.Resolve(ctx, walletAddress, requestLogger(ctx, s.logger()))
+		}()
+	}
+	wg.Wait()
+
+	if label, ok := walletLabels.Get(walletAddress); ok {
+		identity.Labels = append(identity.Labels, label)
+	}
+	if labelType := walletLabels.Type(walletAddress); labelType != "" {
+		identity.Labels = append(identity.Labels, labelType)
+	}
+	return identity
+}
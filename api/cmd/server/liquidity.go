@@ -0,0 +1,222 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Liquidity Lock Analysis
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Selectors of the liquidity checks
+const (
+	getPairSelector             = "0xe6a43905" // getPair(address,address) on a V2 factory
+	getReservesSelector         = "0x0902f1ac" // getReserves() on a V2 pair
+	token0Selector              = "0x0dfe1681" // token0() on a V2 pair
+	totalSupplySelector         = "0x18160ddd" // totalSupply()
+	getNumLocksForTokenSelector = "0x1f2a1d2f" // UniCrypt getNumLocksForToken(address)
+	tokenLocksSelector          = "0xccebfa3f" // UniCrypt tokenLocks(address,uint256)
+)
+
+// liquidityFactories are the V2 factories whose token/wrapped-native pair is checked;
+// the wrapped native token is the honeypot router's
+var liquidityFactories = map[ChainID]string{
+	Ethereum: "0x5c69bee701ef814a2b6a3edd4b1652cb9cc5aa6f", // Uniswap V2
+	BSC:      "0xca143ce32fe78f1f7019d7d551a6402fc5350c73", // PancakeSwap V2
+	Polygon:  "0x5757371414417b8c6caad45baef941abc7d3ab32", // QuickSwap
+	Arbitrum: "0xc35dadb65012ec5796536bd9864ed8773abc74c4", // SushiSwap
+	Base:     "0x8909dc15e40173ff4699343b6eb8132c65e18ec6", // Uniswap V2
+}
+
+// liquidityLocker is a contract LP tokens are locked in
+type liquidityLocker struct {
+	Name string
+	// UniCrypt lockers expose unlock dates through tokenLocks
+	UniCrypt bool
+}
+
+// liquidityLockers are the known LP token lockers (lowercase)
+var liquidityLockers = map[string]liquidityLocker{
+	"0x663a5c229c09b049e36dcc11a9b0d4a8eb9db214": {Name: "UniCrypt V2", UniCrypt: true},
+	"0xc765bddb93b0d1c1a88282ba0fa6b2d00e3e0c83": {Name: "UniCrypt PancakeSwap V2", UniCrypt: true},
+	"0xe2fe530c047f2d85298b07d9333c05737f1435fb": {Name: "Team.Finance"},
+	"0x0c89c0407775dd89b12918b9c0aa42bf96518820": {Name: "Team.Finance BSC"},
+	"0x3ef7442df454ba6b7c1deec8ddf29cfb2d6e56c7": {Name: "Team.Finance Polygon"},
+}
+
+// Thresholds of the liquidity risk
+const (
+	// suspiciousHolderConcentration is the share of supply, in percent, held by the
+	// top 10 holders above which a token is flagged
+	suspiciousHolderConcentration = 80.0
+	// maxLocksRead caps the UniCrypt locks read for the unlock date
+	maxLocksRead = 5
+)
+
+// LiquidityRisk reports how easily a token's liquidity can be pulled
+type LiquidityRisk struct {
+	HasLockedLiquidity bool `json:"hasLockedLiquidity"`
+	// LockExpiry is the earliest unlock time of the UniCrypt locks (0 = unknown)
+	LockExpiry int64 `json:"lockExpiry"`
+	// PoolReserveUSD is the value of the token/wrapped-native V2 pool (0 = no pool,
+	// -1 = native price unknown)
+	PoolReserveUSD float64 `json:"poolReserveUsd"`
+	// TopHolderConcentration is the share of supply held by the top 10 holders, in
+	// percent (-1 = unknown)
+	TopHolderConcentration float64 `json:"topHolderConcentration"`
+}
+
+// LiquidityAnalyzer checks a token's V2 pool, LP locks and holder concentration
+type LiquidityAnalyzer struct {
+	clients map[ChainID]*ChainClient
+}
+
+// NewLiquidityAnalyzer creates an analyzer over the shared chain clients
+func NewLiquidityAnalyzer(clients map[ChainID]*ChainClient) *LiquidityAnalyzer {
+	return &LiquidityAnalyzer{clients: clients}
+}
+
+// callUint calls a view and returns its first word
+func (c *ChainClient) callUint(ctx context.Context, to, data string) (*big.Int, error) {
+	result, err := c.ethCall(ctx, to, data)
+	if err != nil {
+		return nil, err
+	}
+	words := abiWords(result)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty result from %s", to)
+	}
+	n, _ := new(big.Int).SetString(words[0], 16)
+	return n, nil
+}
+
+// Analyze checks the token on chain. Errors mean the check couldn't run (unsupported
+// chain, no factory), not that the liquidity is safe.
+func (l *LiquidityAnalyzer) Analyze(ctx context.Context, tokenAddress string, chain ChainID) (*LiquidityRisk, error) {
+	factory, ok := liquidityFactories[chain]
+	if !ok {
+		return nil, fmt.Errorf("no V2 factory for liquidity checks on %s", chain)
+	}
+	chainsMu.RLock()
+	client, ok := l.clients[chain]
+	chainsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	token := strings.ToLower(tokenAddress)
+	risk := &LiquidityRisk{TopHolderConcentration: client.topHolderConcentration(ctx, token)}
+
+	wrappedNative := honeypotRouters[chain].WrappedNative
+	pair := client.callAddress(ctx, factory, getPairSelector+abiAddress(token)+abiAddress(wrappedNative))
+	if pair == "" {
+		return risk, nil
+	}
+
+	// Pool value: twice the wrapped native side (18 decimals on every supported chain)
+	result, err := client.ethCall(ctx, pair, getReservesSelector)
+	if err != nil {
+		return nil, err
+	}
+	reserves := abiWords(result)
+	if len(reserves) < 2 {
+		return nil, fmt.Errorf("invalid getReserves result from %s", pair)
+	}
+	nativeReserve := reserves[1]
+	if strings.EqualFold(client.callAddress(ctx, pair, token0Selector), wrappedNative) {
+		nativeReserve = reserves[0]
+	}
+	risk.PoolReserveUSD = -1
+	if price, err := nativePriceUSD(ctx, chain); err == nil {
+		reserve, _ := new(big.Int).SetString(nativeReserve, 16)
+		native, _ := new(big.Float).Quo(new(big.Float).SetInt(reserve), big.NewFloat(1e18)).Float64()
+		risk.PoolReserveUSD = 2 * native * price
+	}
+
+	// LP tokens held by a known locker
+	for locker, info := range liquidityLockers {
+		balance, err := client.callUint(ctx, pair, balanceOfSelector+abiAddress(locker))
+		if err != nil || balance.Sign() == 0 {
+			continue
+		}
+		risk.HasLockedLiquidity = true
+		if info.UniCrypt {
+			if expiry := client.uniCryptLockExpiry(ctx, locker, pair); expiry > 0 && (risk.LockExpiry == 0 || expiry < risk.LockExpiry) {
+				risk.LockExpiry = expiry
+			}
+		}
+	}
+	return risk, nil
+}
+
+// uniCryptLockExpiry returns the earliest unlock date of the LP token's locks (0 = none read)
+func (c *ChainClient) uniCryptLockExpiry(ctx context.Context, locker, lpToken string) int64 {
+	count, err := c.callUint(ctx, locker, getNumLocksForTokenSelector+abiAddress(lpToken))
+	if err != nil || !count.IsInt64() {
+		return 0
+	}
+
+	var earliest int64
+	for i := int64(0); i < count.Int64() && i < maxLocksRead; i++ {
+		// (lockDate, amount, initialAmount, unlockDate, lockID, owner)
+		result, err := c.ethCall(ctx, locker, tokenLocksSelector+abiAddress(lpToken)+abiUint(big.NewInt(i)))
+		if err != nil {
+			continue
+		}
+		words := abiWords(result)
+		if len(words) < 4 {
+			continue
+		}
+		if unlock := int64(parseHexUint64(words[3])); unlock > 0 && (earliest == 0 || unlock < earliest) {
+			earliest = unlock
+		}
+	}
+	return earliest
+}
+
+// topHolderConcentration returns the share of supply held by the token's top 10
+// holders in percent, or -1 when the explorer or totalSupply() can't tell
+func (c *ChainClient) topHolderConcentration(ctx context.Context, tokenAddress string) float64 {
+	supply, err := c.callUint(ctx, tokenAddress, totalSupplySelector)
+	if err != nil || supply.Sign() == 0 {
+		return -1
+	}
+
+	var holders []struct {
+		Quantity string `json:"TokenHolderQuantity"`
+	}
+	if err := c.etherscanQuery(ctx, "module=token&action=tokenholderlist&contractaddress="+tokenAddress+"&page=1&offset=10", &holders); err != nil || len(holders) == 0 {
+		return -1
+	}
+
+	held := new(big.Int)
+	for _, holder := range holders {
+		if quantity, ok := new(big.Int).SetString(holder.Quantity, 10); ok {
+			held.Add(held, quantity)
+		}
+	}
+	share, _ := new(big.Float).Quo(new(big.Float).SetInt(held), new(big.Float).SetInt(supply)).Float64()
+	return share * 100
+}
+
+// vulnerabilities describes the rug pull risks of the token's liquidity
+func (r *LiquidityRisk) vulnerabilities() []string {
+	var found []string
+	if r.PoolReserveUSD != 0 && !r.HasLockedLiquidity {
+		found = append(found, "liquidity pool is not locked: the deployer can pull it")
+	}
+	if r.TopHolderConcentration > suspiciousHolderConcentration {
+		found = append(found, fmt.Sprintf("top 10 holders own %.1f%% of supply", r.TopHolderConcentration))
+	}
+	return found
+}
@@ -0,0 +1,117 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Structured Logging
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// maxLoggedBodyBytes caps the RPC request/response bodies logged at debug level
+const maxLoggedBodyBytes = 2048
+
+// Fields are the structured fields of a log line. Common keys: chain, wallet,
+// duration_ms, approvals_found, error.
+type Fields map[string]interface{}
+
+// Logger writes leveled JSON log lines
+type Logger interface {
+	Debug(msg string, fields Fields)
+	Info(msg string, fields Fields)
+	Warn(msg string, fields Fields)
+	Error(msg string, fields Fields)
+	// With returns a logger that adds fields to every line
+	With(fields Fields) Logger
+}
+
+// defaultLogger is used by package-level functions and by values built without a
+// logger (e.g. struct literals in tests). main replaces it once LOG_LEVEL is loaded.
+var defaultLogger = NewLogger(os.Getenv("LOG_LEVEL"))
+
+// zeroLogger is the zerolog-backed Logger
+type zeroLogger struct {
+	z zerolog.Logger
+}
+
+// NewLogger creates a JSON logger on stderr at level (debug, info, warn, error;
+// anything else = info)
+func NewLogger(level string) Logger {
+	return newLoggerTo(os.Stderr, level)
+}
+
+// newLoggerTo creates a JSON logger writing to w
+func newLoggerTo(w io.Writer, level string) Logger {
+	zerolog.TimestampFieldName = "ts"
+	zerolog.MessageFieldName = "msg"
+	zerolog.ErrorFieldName = "error"
+
+	parsed, err := zerolog.ParseLevel(strings.ToLower(strings.TrimSpace(level)))
+	if err != nil || parsed == zerolog.NoLevel {
+		parsed = zerolog.InfoLevel
+	}
+	return zeroLogger{z: zerolog.New(w).Level(parsed).With().Timestamp().Logger()}
+}
+
+func (l zeroLogger) Debug(msg string, fields Fields) {
+	l.z.Debug().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+func (l zeroLogger) Info(msg string, fields Fields) {
+	l.z.Info().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+func (l zeroLogger) Warn(msg string, fields Fields) {
+	l.z.Warn().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+func (l zeroLogger) Error(msg string, fields Fields) {
+	l.z.Error().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+func (l zeroLogger) With(fields Fields) Logger {
+	return zeroLogger{z: l.z.With().Fields(map[string]interface{}(fields)).Logger()}
+}
+
+// loggerOr returns logger, or defaultLogger when it is nil
+func loggerOr(logger Logger) Logger {
+	if logger == nil {
+		return defaultLogger
+	}
+	return logger
+}
+
+// errorText returns err's message with embedded URLs redacted, for the error field
+func errorText(err error) string {
+	return redactURLs(err.Error())
+}
+
+// truncateLogBody shortens a body to maxLoggedBodyBytes for debug logs
+func truncateLogBody(body []byte) string {
+	if len(body) > maxLoggedBodyBytes {
+		return string(body[:maxLoggedBodyBytes]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// readRPCBody reads a provider response, logging the exchange at debug level.
+// endpoint is redacted since RPC paths and explorer queries carry API keys.
+func (c *ChainClient) readRPCBody(resp *http.Response, provider, endpoint string, request []byte) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	c.logger().Debug("RPC exchange", Fields{
+		"provider": provider,
+		"endpoint": redactURLs(endpoint),
+		"status":   resp.StatusCode,
+		"request":  truncateLogBody(request),
+		"response": truncateLogBody(body),
+	})
+	return body, err
+}
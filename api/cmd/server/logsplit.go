@@ -0,0 +1,111 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Log Range Splitting
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxLogSplitDepth caps how many times a log query's block range is halved, so a
+// range is split into at most 2^16 queries
+const maxLogSplitDepth = 16
+
+// jsonRPCError is the error object of a JSON-RPC response
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonRPCError) Error() string { return e.Message }
+
+// isLogRangeTooLarge reports whether an eth_getLogs error asks for a smaller range:
+// -32005 (limit exceeded), or -32602 (invalid params) when the message is about the
+// range or result size. Other -32602 errors would fail in every half too.
+func isLogRangeTooLarge(err error) bool {
+	var rpcErr *jsonRPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	switch rpcErr.Code {
+	case -32005:
+		return true
+	case -32602:
+		message := strings.ToLower(rpcErr.Message)
+		for _, hint := range []string{"range", "limit", "exceed", "too many", "too large", "size"} {
+			if strings.Contains(message, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getLogsSplitting runs eth_getLogs with filter over blocks, halving the range and
+// querying each half when the provider rejects it as too large. Results stay in
+// ascending block order.
+func (c *ChainClient) getLogsSplitting(ctx context.Context, endpoint string, filter map[string]interface{}, blocks blockRange) ([]approvalLog, error) {
+	logs, err := c.getLogsWithRetry(ctx, endpoint, filter, fmt.Sprintf("0x%x", blocks.From), blocks.toBlockParam())
+	if err == nil || !isLogRangeTooLarge(err) {
+		return logs, err
+	}
+
+	to := blocks.To
+	if to == 0 {
+		var head string
+		if err := c.rpcCallURL(ctx, endpoint, "eth_blockNumber", []interface{}{}, &head); err != nil {
+			return nil, err
+		}
+		to = parseHexUint64(head)
+	}
+	requestLogger(ctx, c.logger()).Debug("Log range too large, splitting", Fields{"from_block": blocks.From, "to_block": to})
+	return c.getLogsBisecting(ctx, endpoint, filter, blocks.From, to, 1)
+}
+
+// getLogsBisecting queries the two halves of [from, to], splitting them further while
+// the provider keeps rejecting them, up to maxLogSplitDepth
+func (c *ChainClient) getLogsBisecting(ctx context.Context, endpoint string, filter map[string]interface{}, from, to uint64, depth int) ([]approvalLog, error) {
+	if from >= to {
+		return nil, fmt.Errorf("log range of block %d is still too large", from)
+	}
+	mid := from + (to-from)/2
+
+	logs := make([]approvalLog, 0)
+	for _, half := range [][2]uint64{{from, mid}, {mid + 1, to}} {
+		part, err := c.getLogsWithRetry(ctx, endpoint, filter, fmt.Sprintf("0x%x", half[0]), fmt.Sprintf("0x%x", half[1]))
+		if err != nil && isLogRangeTooLarge(err) && depth < maxLogSplitDepth {
+			part, err = c.getLogsBisecting(ctx, endpoint, filter, half[0], half[1], depth+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, part...)
+	}
+	return logs, nil
+}
+
+// getLogsWithRetry runs one eth_getLogs over [fromBlock, toBlock], retrying transient
+// failures
+func (c *ChainClient) getLogsWithRetry(ctx context.Context, endpoint string, filter map[string]interface{}, fromBlock, toBlock string) ([]approvalLog, error) {
+	query := make(map[string]interface{}, len(filter)+2)
+	for key, value := range filter {
+		query[key] = value
+	}
+	query["fromBlock"] = fromBlock
+	query["toBlock"] = toBlock
+
+	var logs []approvalLog
+	err := withRetry(ctx, defaultRetryAttempts, func() error {
+		var err error
+		logs, err = c.getLogsAlchemy(ctx, endpoint, query)
+		return err
+	})
+	return logs, err
+}
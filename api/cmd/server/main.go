@@ -18,20 +18,32 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"math/big"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	sentinelv1 "github.com/sentinel-team/sentinel/api/proto/sentinel/v1"
 )
 
 // loadEnvFile loads environment variables from a .env file
@@ -86,7 +98,7 @@ func loadEnv() bool {
 
 	for _, path := range envPaths {
 		if err := loadEnvFile(path); err == nil {
-			log.Printf("📁 Loaded environment from %s", path)
+			defaultLogger.Info("Loaded environment file", Fields{"path": path})
 			return true
 		}
 	}
@@ -98,9 +110,45 @@ func loadEnv() bool {
 // ═══════════════════════════════════════════════════════════════════════════════
 
 type Config struct {
-	Port     string
-	RPC      map[string]string
+	Port string
+	// RPC lists each chain's RPC URLs in failover order
+	RPC      map[string][]string
 	CacheTTL time.Duration
+	// MaxApprovalsPerChain caps how many approval events are processed per chain
+	MaxApprovalsPerChain int
+	// Hardware wallet recommendation thresholds (ETH balance, USD value at risk)
+	HWWalletRecommendETH float64
+	HWWalletRecommendUSD float64
+	// ChainMaturityWeight scales approval-count thresholds by how long a chain has been
+	// live (Ethereum = 1.0); thresholds are divided by the weight
+	ChainMaturityWeight map[ChainID]float64
+	// SubgraphURL is each chain's approvals subgraph, tried after Alchemy and Etherscan
+	SubgraphURL map[ChainID]string
+}
+
+// defaultChainMaturityWeights are the built-in ChainMaturityWeight values, roughly a
+// chain's age relative to Ethereum mainnet. Chains not listed weigh 1.0.
+var defaultChainMaturityWeights = map[ChainID]float64{
+	Ethereum:  1.0,
+	Gnosis:    0.6,
+	Fantom:    0.55,
+	Polygon:   0.55,
+	BSC:       0.5,
+	Avalanche: 0.5,
+	Celo:      0.5,
+	Arbitrum:  0.45,
+	Optimism:  0.45,
+	Moonbeam:  0.4,
+	Cronos:    0.4,
+	ZkSync:    0.3,
+	ZkEVM:     0.3,
+	Base:      0.3,
+	Linea:     0.3,
+	Scroll:    0.25,
+	Mantle:    0.2,
+	Manta:     0.2,
+	Mode:      0.15,
+	Blast:     0.15,
 }
 
 // getEnv returns environment variable or default value
@@ -111,12 +159,40 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvInt returns an integer environment variable or default value
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		defaultLogger.Warn("Invalid integer setting, using default", Fields{"key": key, "value": value, "default": fallback})
+		return fallback
+	}
+	return n
+}
+
+// getEnvFloat returns a float environment variable or default value
+func getEnvFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		defaultLogger.Warn("Invalid number setting, using default", Fields{"key": key, "value": value, "default": fallback})
+		return fallback
+	}
+	return f
+}
+
 // Initialize config from environment variables
 func initConfig() Config {
 	alchemyKey := getEnv("ALCHEMY_API_KEY", "demo") // Use env var!
 	return Config{
 		Port: getEnv("PORT", "8080"),
-		RPC: map[string]string{
+		RPC: withFallbackRPCs(map[string]string{
 			// 🔵 Ethereum & L2s (Alchemy) - API key from environment
 			"ethereum": "https://eth-mainnet.g.alchemy.com/v2/" + alchemyKey,
 			"arbitrum": "https://arb-mainnet.g.alchemy.com/v2/" + alchemyKey,
@@ -126,6 +202,10 @@ func initConfig() Config {
 			"linea":    "https://rpc.linea.build",
 			"scroll":   "https://rpc.scroll.io",
 			"zkevm":    "https://zkevm-rpc.com",
+			"mantle":   "https://rpc.mantle.xyz",
+			"mode":     "https://mainnet.mode.network",
+			"blast":    "https://rpc.blast.io",
+			"manta":    "https://pacific-rpc.manta.network/http",
 			// 🟡 Alt L1s
 			"bsc":       "https://bsc-mainnet.nodereal.io/v1/64a9df0874fb4a93b9d0a3849de012d3",
 			"polygon":   "https://polygon-rpc.com",
@@ -135,9 +215,62 @@ func initConfig() Config {
 			"gnosis":    "https://rpc.gnosischain.com",
 			"celo":      "https://forno.celo.org",
 			"moonbeam":  "https://rpc.api.moonbeam.network",
-		},
-		CacheTTL: 5 * time.Minute,
+			// Non-EVM
+			"solana": "https://api.mainnet-beta.solana.com",
+		}),
+		CacheTTL:             5 * time.Minute,
+		MaxApprovalsPerChain: getEnvInt("MAX_APPROVALS_PER_CHAIN", 500),
+		HWWalletRecommendETH: getEnvFloat("HW_WALLET_RECOMMEND_ETH", 5),
+		HWWalletRecommendUSD: getEnvFloat("HW_WALLET_RECOMMEND_USD", 10_000),
+		ChainMaturityWeight:  initChainMaturityWeights(),
+		SubgraphURL:          initSubgraphURLs(),
+	}
+}
+
+// withFallbackRPCs turns each chain's RPC URL into its failover list, appending the
+// comma-separated URLs of FALLBACK_RPCS_<CHAIN> (e.g. FALLBACK_RPCS_ETHEREUM)
+func withFallbackRPCs(primary map[string]string) map[string][]string {
+	rpcs := make(map[string][]string, len(primary))
+	for chain, url := range primary {
+		rpcs[chain] = []string{url}
+		for _, fallback := range strings.Split(os.Getenv("FALLBACK_RPCS_"+strings.ToUpper(chain)), ",") {
+			if fallback = strings.TrimSpace(fallback); fallback != "" {
+				rpcs[chain] = append(rpcs[chain], fallback)
+			}
+		}
+	}
+	return rpcs
+}
+
+// initChainMaturityWeights applies CHAIN_MATURITY_<CHAIN> overrides (e.g. CHAIN_MATURITY_BASE=0.4)
+// to the default weights
+func initChainMaturityWeights() map[ChainID]float64 {
+	weights := make(map[ChainID]float64, len(defaultChainMaturityWeights))
+	for chain, weight := range defaultChainMaturityWeights {
+		weights[chain] = weight
+	}
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		name, ok := strings.CutPrefix(key, "CHAIN_MATURITY_")
+		if !ok || name == "" {
+			continue
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight <= 0 {
+			defaultLogger.Warn("Invalid chain maturity, must be a positive number", Fields{"key": key, "value": value})
+			continue
+		}
+		weights[ChainID(strings.ToLower(name))] = weight
+	}
+	return weights
+}
+
+// chainMaturityWeight returns the configured maturity weight of chain (1.0 if unset)
+func chainMaturityWeight(chain ChainID) float64 {
+	if weight, ok := config.ChainMaturityWeight[chain]; ok {
+		return weight
 	}
+	return 1.0
 }
 
 // Global config instance
@@ -147,6 +280,56 @@ var config = initConfig()
 type EtherscanConfig struct {
 	APIKey   string
 	ChainIDs map[string]int
+	// Explorers maps chains to Etherscan-compatible explorers used instead of Etherscan v2
+	// (chains not on Etherscan v2 and chains added at runtime)
+	Explorers map[string]ExplorerConfig
+}
+
+// ExplorerConfig points at an Etherscan-compatible explorer API
+type ExplorerConfig struct {
+	URL    string
+	APIKey string
+}
+
+// cronosScanConfig is the CronoScan API, used for Cronos approvals
+func cronosScanConfig() ExplorerConfig {
+	return ExplorerConfig{
+		URL:    "https://api.cronoscan.com/api",
+		APIKey: getEnv("CRONOSCAN_API_KEY", ""),
+	}
+}
+
+// blastScanConfig is the BlastScan API, used for Blast approvals
+func blastScanConfig() ExplorerConfig {
+	return ExplorerConfig{
+		URL:    getEnv("BLASTSCAN_API_URL", "https://api.blastscan.io/api"),
+		APIKey: getEnv("BLASTSCAN_API_KEY", ""),
+	}
+}
+
+// modeScanConfig is the Modescan API, used for Mode approvals
+func modeScanConfig() ExplorerConfig {
+	return ExplorerConfig{
+		URL:    getEnv("MODESCAN_API_URL", "https://api.routescan.io/v2/network/mainnet/evm/34443/etherscan/api"),
+		APIKey: getEnv("MODESCAN_API_KEY", ""),
+	}
+}
+
+// explorerURL builds an explorer API URL for query on chain, preferring a chain-specific
+// explorer over Etherscan v2
+func explorerURL(chain ChainID, query string) (string, bool) {
+	chainsMu.RLock()
+	chainID, onEtherscan := etherscanConfig.ChainIDs[string(chain)]
+	explorer, hasExplorer := etherscanConfig.Explorers[string(chain)]
+	chainsMu.RUnlock()
+
+	switch {
+	case hasExplorer:
+		return fmt.Sprintf("%s?%s&apikey=%s", explorer.URL, query, explorer.APIKey), true
+	case onEtherscan:
+		return fmt.Sprintf("https://api.etherscan.io/v2/api?chainid=%d&%s&apikey=%s", chainID, query, etherscanConfig.APIKey), true
+	}
+	return "", false
 }
 
 // Initialize Etherscan config
@@ -166,6 +349,16 @@ func initEtherscanConfig() EtherscanConfig {
 			"zkevm":    1101,
 			"celo":     42220,
 			"moonbeam": 1284,
+			"cronos":   25,
+			"mantle":   5000,
+			"mode":     34443,
+			"blast":    81457,
+			"manta":    169,
+		},
+		Explorers: map[string]ExplorerConfig{
+			"cronos": cronosScanConfig(),
+			"blast":  blastScanConfig(),
+			"mode":   modeScanConfig(),
 		},
 	}
 }
@@ -212,6 +405,10 @@ const (
 	Linea    ChainID = "linea"
 	Scroll   ChainID = "scroll"
 	ZkEVM    ChainID = "zkevm"
+	Mantle   ChainID = "mantle"
+	Mode     ChainID = "mode"
+	Blast    ChainID = "blast"
+	Manta    ChainID = "manta"
 	// Alt L1s
 	BSC       ChainID = "bsc"
 	Polygon   ChainID = "polygon"
@@ -221,13 +418,64 @@ const (
 	Gnosis    ChainID = "gnosis"
 	Celo      ChainID = "celo"
 	Moonbeam  ChainID = "moonbeam"
+	// Non-EVM
+	Solana ChainID = "solana"
 )
 
 var AllChains = []ChainID{
 	// Ethereum ecosystem
 	Ethereum, Arbitrum, Optimism, Base, ZkSync, Linea, Scroll, ZkEVM,
+	Mantle, Mode, Blast, Manta,
 	// Alt L1s
 	BSC, Polygon, Avalanche, Fantom, Cronos, Gnosis, Celo, Moonbeam,
+	// Non-EVM
+	Solana,
+}
+
+// chainsMu guards runtime changes to AllChains, config.RPC, etherscanConfig and
+// the shared chain client maps (see registerChain)
+var chainsMu sync.RWMutex
+
+// supportedChains returns a snapshot of AllChains
+func supportedChains() []ChainID {
+	chainsMu.RLock()
+	defer chainsMu.RUnlock()
+	return append([]ChainID(nil), AllChains...)
+}
+
+// chainDisplayNames maps chain IDs to user-facing names for recommendations
+var chainDisplayNames = map[ChainID]string{
+	Ethereum:  "Ethereum",
+	Arbitrum:  "Arbitrum",
+	Optimism:  "Optimism",
+	Base:      "Base",
+	ZkSync:    "zkSync",
+	Linea:     "Linea",
+	Scroll:    "Scroll",
+	ZkEVM:     "Polygon zkEVM",
+	Mantle:    "Mantle",
+	Mode:      "Mode",
+	Blast:     "Blast",
+	Manta:     "Manta Pacific",
+	BSC:       "BSC",
+	Polygon:   "Polygon",
+	Avalanche: "Avalanche",
+	Fantom:    "Fantom",
+	Cronos:    "Cronos",
+	Gnosis:    "Gnosis",
+	Celo:      "Celo",
+	Moonbeam:  "Moonbeam",
+	Solana:    "Solana",
+	// OP Stack chain referenced by the Superchain bridge table
+	ChainID("zora"): "Zora",
+}
+
+// chainDisplayName returns the user-facing name of a chain
+func chainDisplayName(chain ChainID) string {
+	if name, ok := chainDisplayNames[chain]; ok {
+		return name
+	}
+	return string(chain)
 }
 
 // Approval represents a token approval
@@ -235,45 +483,158 @@ type Approval struct {
 	Chain          ChainID  `json:"chain"`
 	TokenAddress   string   `json:"tokenAddress"`
 	TokenSymbol    string   `json:"tokenSymbol"`
+	TokenName      string   `json:"tokenName,omitempty"`    // display name, at most 30 characters
+	TokenLogoURL   string   `json:"tokenLogoUrl,omitempty"` // for front-end display
+	TokenType      string   `json:"tokenType"`              // "ERC20", "ERC721" or "ERC1155"
+	IsNFT          bool     `json:"isNft"`                  // ApprovalForAll over an NFT collection
 	SpenderAddress string   `json:"spenderAddress"`
 	SpenderName    string   `json:"spenderName"`
+	SpenderENS     string   `json:"spenderEns,omitempty"`   // verified primary ENS name, prefixed to SpenderName
+	SpenderLabel   string   `json:"spenderLabel,omitempty"` // WALLET_LABELS address book entry of the spender
 	AllowanceRaw   string   `json:"allowanceRaw"`
 	AllowanceHuman string   `json:"allowanceHuman"`
+	AllowanceUSD   float64  `json:"allowanceUsd"` // -1 = unknown
 	IsUnlimited    bool     `json:"isUnlimited"`
-	RiskLevel      string   `json:"riskLevel"` // "critical", "warning", "safe"
+	IsSelfApproval bool     `json:"isSelfApproval"` // token approved itself as spender
+	RiskLevel      string   `json:"riskLevel"`      // "critical", "warning", "safe"
 	RiskReasons    []string `json:"riskReasons"`
-	LastUpdated    int64    `json:"lastUpdated"`
+	LastUpdated    int64    `json:"lastUpdated"` // block time of TxHash, 0 = unknown
+	// Source event of the approval, for explorer deep links
+	TxHash      string `json:"txHash,omitempty"`
+	LogIndex    int    `json:"logIndex"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	// Outgoing token transfers in transactions sent to the spender (-1 = unknown)
+	TransferFromCount     int    `json:"transferFromCount"`
+	LastTransferFromBlock uint64 `json:"lastTransferFromBlock,omitempty"`
+	LastTransferFromTime  int64  `json:"lastTransferFromTime,omitempty"` // unix seconds
+	// TokenTrustScore rates the token 0-100 from its age, holders and listings
+	TokenTrustScore int `json:"tokenTrustScore"`
+	// SpenderCategory is the on-chain classification of an unknown spender ("DEX Router", ...)
+	SpenderCategory string `json:"spenderCategory,omitempty"`
+	// SpenderTxCount is the unknown spender's transaction count (ENRICH_SPENDER_STATS)
+	SpenderTxCount int64 `json:"spenderTxCount,omitempty"`
+	// TransferCount is the number of the wallet's token transfers made by the spender
+	// (ANALYZE_TRANSFER_VOLUME)
+	TransferCount int `json:"transferCount,omitempty"`
+	// UniswapV4Hooks are the hook contracts of V4 pools the wallet holds liquidity in,
+	// set when the spender is the Uniswap V4 PoolManager
+	UniswapV4Hooks []string `json:"uniswapV4Hooks,omitempty"`
+	// ViaPermit2 marks allowances held in Permit2 rather than the token, which expire
+	// at ExpiresAt (unix seconds)
+	ViaPermit2 bool  `json:"viaPermit2"`
+	ExpiresAt  int64 `json:"expiresAt,omitempty"`
+	// SupportsPermit marks EIP-2612 tokens, where signing a permit message grants an
+	// allowance without a transaction; PermitNonce counts the wallet's executed permits
+	SupportsPermit bool   `json:"supportsPermit,omitempty"`
+	PermitNonce    uint64 `json:"permitNonce,omitempty"`
+
+	// trustScored is set once TokenTrustScore has been computed
+	trustScored bool
+	// txCounted is set once SpenderTxCount has been fetched
+	txCounted bool
+	// volumeAnalyzed is set once TransferCount has been counted; recentlyUsed when the
+	// spender's last transfer is within recentTransferWindow, by VolumeAnalyzer or at
+	// scoring time
+	volumeAnalyzed bool
+	recentlyUsed   bool
+	// ageDays is the age of LastUpdated at scoring time, -1 when it is unknown
+	ageDays int
 }
 
 // ContractRisk represents analyzed contract risk
 type ContractRisk struct {
-	Address         string   `json:"address"`
-	Chain           ChainID  `json:"chain"`
-	IsVerified      bool     `json:"isVerified"`
-	IsProxy         bool     `json:"isProxy"`
-	HasMint         bool     `json:"hasMint"`
-	HasBlacklist    bool     `json:"hasBlacklist"`
-	HasPause        bool     `json:"hasPause"`
+	Address    string  `json:"address"`
+	Chain      ChainID `json:"chain"`
+	IsVerified bool    `json:"isVerified"`
+	IsProxy    bool    `json:"isProxy"`
+	// ProxyType is "EIP-1167" (clone), "EIP-1967" or "UUPS"; empty when only the
+	// decompiler recognized the proxy
+	ProxyType string `json:"proxyType,omitempty"`
+	// ImplementationAddress is the logic contract of the proxy
+	ImplementationAddress string `json:"implementationAddress,omitempty"`
+	HasMint               bool   `json:"hasMint"`
+	HasBlacklist          bool   `json:"hasBlacklist"`
+	HasPause              bool   `json:"hasPause"`
+	// HasSelfDestruct is set when SELFDESTRUCT appears outside PUSH data
+	HasSelfDestruct bool     `json:"hasSelfDestruct"`
 	IsHoneypot      bool     `json:"isHoneypot"`
 	HiddenFee       float64  `json:"hiddenFee"`
 	OwnerPrivileges []string `json:"ownerPrivileges"`
 	RiskScore       int      `json:"riskScore"` // 0-100
 	RiskLevel       string   `json:"riskLevel"`
 	Vulnerabilities []string `json:"vulnerabilities"`
+	// IsSanctioned is set for addresses on the bundled OFAC list ("ofac") or flagged
+	// by the SANCTIONS_PROVIDER, named by SanctionSource
+	IsSanctioned   bool   `json:"isSanctioned"`
+	SanctionSource string `json:"sanctionSource,omitempty"`
+	// Liquidity is set when LIQUIDITY_CHECK=true and the token's liquidity could be checked
+	Liquidity *LiquidityRisk `json:"liquidity,omitempty"`
 }
 
 // WalletScan represents full wallet scan result
 type WalletScanResult struct {
-	WalletAddress    string         `json:"walletAddress"`
-	ScanTimestamp    int64          `json:"scanTimestamp"`
-	OverallRiskScore int            `json:"overallRiskScore"`
-	TotalApprovals   int            `json:"totalApprovals"`
-	CriticalRisks    int            `json:"criticalRisks"`
-	Warnings         int            `json:"warnings"`
-	ChainsScanned    []ChainID      `json:"chainsScanned"`
-	Approvals        []Approval     `json:"approvals"`
-	ContractRisks    []ContractRisk `json:"contractRisks"`
-	Recommendations  []string       `json:"recommendations"`
+	WalletAddress    string                  `json:"walletAddress"`
+	WalletLabel      string                  `json:"walletLabel,omitempty"` // manual label or ENS name
+	WalletENS        string                  `json:"walletEns,omitempty"`   // primary ENS name, Ethereum scans only
+	Identity         WalletIdentity          `json:"identity"`
+	ScanTimestamp    int64                   `json:"scanTimestamp"`
+	OverallRiskScore int                     `json:"overallRiskScore"`
+	TotalApprovals   int                     `json:"totalApprovals"`
+	CriticalRisks    int                     `json:"criticalRisks"`
+	Warnings         int                     `json:"warnings"`
+	ChainsScanned    []ChainID               `json:"chainsScanned"`
+	Approvals        []Approval              `json:"approvals"`
+	ContractRisks    []ContractRisk          `json:"contractRisks"`
+	Recommendations  []string                `json:"recommendations"`
+	TruncatedChains  []ChainID               `json:"truncatedChains,omitempty"`
+	ChainScanStats   map[ChainID]ChainResult `json:"chainScanStats"`
+	CachedAt         int64                   `json:"cachedAt"` // unix time the scan ran
+	CacheHit         bool                    `json:"cacheHit"`
+
+	// FilteredApprovals is the number of approvals matching the request's filters, and
+	// Total the number across all pages of them; NextCursor fetches the page after
+	// Approvals and is empty on the last page. All three are set by handleScan.
+	FilteredApprovals int    `json:"filteredApprovals"`
+	Total             int    `json:"total"`
+	NextCursor        string `json:"nextCursor,omitempty"`
+
+	// ProtocolExposures groups approvals by spender, highest USD exposure first
+	ProtocolExposures []ProtocolExposure `json:"protocolExposures"`
+	TotalValueAtRisk  float64            `json:"totalValueAtRisk"` // USD, approvals with a known value
+	// GroupedApprovals groups the matching approvals by spender across chains, set by
+	// handleScan for ?groupBy=spender
+	GroupedApprovals []GroupedApproval `json:"groupedApprovals,omitempty"`
+	// RevocationCost prices revoking every matching approval, set by handleScan for
+	// ?includeRevocationCost=true
+	RevocationCost *RevocationEstimate `json:"revocationCost,omitempty"`
+
+	// SanctionedAddresses lists the spenders on the bundled OFAC sanctions list or
+	// flagged by the SANCTIONS_PROVIDER; ContractRisks says which
+	SanctionedAddresses []string `json:"sanctionedAddresses"`
+	// HasTornadoCashHistory is set when the wallet sent a transaction to Tornado Cash
+	HasTornadoCashHistory bool `json:"hasTornadoCashHistory"`
+
+	// NativeBalance is the wallet's gas token balance (currently Ethereum only)
+	NativeBalance map[ChainID]float64 `json:"nativeBalance,omitempty"`
+	WalletType    string              `json:"walletType,omitempty"` // "eoa", "safe" or "smart_account"
+
+	// IsDAOTreasury is set for wallets labelled "dao_treasury" in the address book; their
+	// recommendations go through governance, with a draft proposal for critical approvals
+	IsDAOTreasury              bool   `json:"isDaoTreasury"`
+	GovernanceProposalTemplate string `json:"governanceProposalTemplate,omitempty"`
+
+	// truncatedTotals holds the untruncated event count for each truncated chain
+	truncatedTotals map[ChainID]int
+}
+
+// ChainResult reports how the scan of a single chain went
+type ChainResult struct {
+	ApprovalsFound int           `json:"approvalsFound"`
+	ScanDuration   time.Duration `json:"scanDuration"` // nanoseconds
+	Error          string        `json:"error,omitempty"`
+	Source         string        `json:"source,omitempty"` // "alchemy" or "etherscan"
+	// Skipped is set when the chain's provider circuit breaker was open
+	Skipped bool `json:"skipped,omitempty"`
 }
 
 // ═══════════════════════════════════════════════════════════════════════════════
@@ -294,6 +655,12 @@ var knownSpenders = map[string]string{
 	"0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad": "✅ Uniswap: Universal Router 2",
 	"0x4c60051384bd2d3c01bfc845cf5f4b44bcbe9de5": "✅ Uniswap: Universal Router (Permit2)",
 	"0x643770e279d5d0733f21d6dc03a8efbabf3255b4": "✅ Uniswap: Universal Router (Base)",
+	"0x000000000004444c5dc75cb358380d2e3de08a90": "✅ Uniswap V4: PoolManager",
+	"0x360e68faccca8ca495c1b759fd9eee466db9fb32": "✅ Uniswap V4: PoolManager (Arbitrum)",
+	"0x9a13f98cb987694c9f086b1f5eb990eea8264ec3": "✅ Uniswap V4: PoolManager (Optimism)",
+	"0x498581ff718922c3f8e6a244956af099b2652b2b": "✅ Uniswap V4: PoolManager (Base)",
+	"0x67366782805870060151383f4bbff9dab53e5cd6": "✅ Uniswap V4: PoolManager (Polygon)",
+	"0x28e2ea090877bf75740558f6bfb36a5ffee9e9df": "✅ Uniswap V4: PoolManager (BSC)",
 
 	// ═══════════════════════════════════════════════════════════════════════════
 	// DEX AGGREGATORS
@@ -350,6 +717,9 @@ var knownSpenders = map[string]string{
 	"0xc873fecbd354f5a56e00e710b90ef4201db2448d": "✅ Camelot: Router V2",
 	// Trader Joe
 	"0xb4315e873dbcf96ffd0acd8ea43f689d8c20fb30": "✅ TraderJoe: LB Router",
+	// Cronos DEXs
+	"0x145863eb42cf62847a6ca784e6416c1682b1b2ae": "✅ VVS Finance: Router",
+	"0x145677fc4d9b8f19b5d56d1820c48e0443049a30": "✅ MM Finance: Router",
 
 	// ═══════════════════════════════════════════════════════════════════════════
 	// NFT MARKETPLACES
@@ -404,6 +774,19 @@ var knownSpenders = map[string]string{
 	"0xabea9132b05a70803a4e85094fd0e1800777fbef": "✅ zkSync: Bridge",
 	"0xd19d4b5d358258f05d7b411e21a1460d11b0876f": "✅ Linea: Bridge",
 	"0x504a330327a089d8364c4ab3811ee26976d388ce": "✅ Scroll: Gateway",
+	// Superchain (OP Stack) bridges, see superchainBridges for where each is deployed
+	"0xbeb5fc579115071764c7423a4f12edde41f106ed": "✅ Optimism: Portal",
+	"0x25ace71c97b33cc4729cf772ae268934f7bab5fa": "✅ Optimism: L1 Cross-Domain Messenger",
+	"0x49048044d57e1c92a77f79988d21fa8faf74e97e": "✅ Base: Portal",
+	"0x866e82a600a1414e583f7f13623f1ac5d58b0afa": "✅ Base: L1 Cross-Domain Messenger",
+	"0x735adbbe72226bd52e818e7181953f42e3b0ff21": "✅ Mode: Bridge",
+	"0x8b34b14c7c7123459cf3076b8cb929be097d0c07": "✅ Mode: Portal",
+	"0x3e2ea9b92b7e48a52296fd261dc26fd995284631": "✅ Zora: Bridge",
+	"0x1a0ad011913a150f69f6a19df447a0cfd9551054": "✅ Zora: Portal",
+	"0x4200000000000000000000000000000000000007": "✅ Superchain: L2 Cross-Domain Messenger",
+	"0x4200000000000000000000000000000000000010": "✅ Superchain: L2 Standard Bridge",
+	"0x4200000000000000000000000000000000000014": "✅ Superchain: L2 ERC721 Bridge",
+	"0x4200000000000000000000000000000000000028": "✅ Superchain: Token Bridge",
 	// Third Party Bridges
 	"0x3a23f943181408eac424116af7b7790c94cb97a5": "✅ Socket: Gateway",
 	"0xc30141b657f4216252dc59af2e7cdb9d8792e1b0": "✅ Socket: Registry",
@@ -466,6 +849,7 @@ var knownSpenders = map[string]string{
 	"0x39aa39c021dfbae8fac545936693ac917d5e7563": "✅ Compound: cUSDC",
 	"0x4ddc2d193948926d02f9b1fe9e1daa0718270ed5": "✅ Compound: cETH",
 	"0xc36442b4a4522e871399cd717abdd847ab11fe88": "✅ Uniswap V3: NonfungiblePositionManager",
+	"0x0000000001e4ef00d069e71d6ba041b0a16f7ea0": "✅ Pendle: Router",
 
 	// ═══════════════════════════════════════════════════════════════════════════
 	// RELAY PROTOCOL - Cross-chain bridging (LEGIT but often abused in phishing)
@@ -517,6 +901,12 @@ var spenderRiskLevel = map[string]string{
 	"0x000000000022d473030f116ddee9f6b43ac78ba3": "safe", // Permit2
 	"0xef1c6e67703c7bd7107eed8303fbe6ec2554bf6b": "safe", // Universal Router
 	"0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad": "safe", // Universal Router 2
+	"0x000000000004444c5dc75cb358380d2e3de08a90": "safe", // Uniswap V4 PoolManager
+	"0x360e68faccca8ca495c1b759fd9eee466db9fb32": "safe", // Uniswap V4 PoolManager (Arbitrum)
+	"0x9a13f98cb987694c9f086b1f5eb990eea8264ec3": "safe", // Uniswap V4 PoolManager (Optimism)
+	"0x498581ff718922c3f8e6a244956af099b2652b2b": "safe", // Uniswap V4 PoolManager (Base)
+	"0x67366782805870060151383f4bbff9dab53e5cd6": "safe", // Uniswap V4 PoolManager (Polygon)
+	"0x28e2ea090877bf75740558f6bfb36a5ffee9e9df": "safe", // Uniswap V4 PoolManager (BSC)
 	// Aggregators
 	"0x1111111254eeb25477b68fb85ed929f73a960582": "safe", // 1inch V5
 	"0x111111125421ca6dc452d289314280a0f8842a65": "safe", // 1inch V6
@@ -536,6 +926,8 @@ var spenderRiskLevel = map[string]string{
 	"0xc3d688b66703497daa19211eedff47f25384cdc3": "safe", // Compound V3
 	"0xbbbbbbbbbb9cc5e90e3b3af64bdaf62c37eeffcb": "safe", // Morpho Blue
 	"0xc13e21b648a5ee794902342038ff3adab66be987": "safe", // Spark
+	// Yield
+	"0x0000000001e4ef00d069e71d6ba041b0a16f7ea0": "safe", // Pendle Router
 	// Bridges
 	"0x99c9fc46f92e8a1c0dec1b1747d010903e884be1": "safe", // Optimism
 	"0x8315177ab297ba92a06054ce80a67ed4dbd7ed3a": "safe", // Arbitrum
@@ -594,57 +986,302 @@ var knownTokens = map[string]string{
 type ChainClient struct {
 	ChainID ChainID
 	RPC     string
-	client  *http.Client
-}
-
-func NewChainClient(chainID ChainID, rpcURL string) *ChainClient {
-	return &ChainClient{
-		ChainID: chainID,
-		RPC:     rpcURL,
+	// FallbackRPCs are tried in order when RPC fails or answers with an error
+	FallbackRPCs []string
+	client       *http.Client
+	// ens resolves spender names (Ethereum mainnet only)
+	ens *ENSResolver
+	// log tags every line with the chain (nil = defaultLogger)
+	log Logger
+	// breaker skips the approval providers while they keep failing (nil = always call)
+	breaker *CircuitBreaker
+	// subgraph is the last-resort approvals source (nil = none configured)
+	subgraph *SubgraphClient
+}
+
+func NewChainClient(chainID ChainID, rpcURL string, logger Logger) *ChainClient {
+	return NewChainClientWithFallbacks(chainID, []string{rpcURL}, logger)
+}
+
+// NewChainClientWithFallbacks creates a client for the first of rpcURLs that fails over
+// to the others in order
+func NewChainClientWithFallbacks(chainID ChainID, rpcURLs []string, logger Logger) *ChainClient {
+	c := &ChainClient{
+		ChainID:      chainID,
+		RPC:          rpcURLs[0],
+		FallbackRPCs: rpcURLs[1:],
 		client: &http.Client{
-			Timeout: 60 * time.Second, // Increased for wallets with many approvals
+			Transport: sharedRPCTransport(),
+			Timeout:   60 * time.Second, // Increased for wallets with many approvals
 		},
+		log:     loggerOr(logger).With(Fields{"chain": chainID}),
+		breaker: NewCircuitBreaker(defaultBreakerThreshold, defaultBreakerResetTimeout, RealClock{}),
+	}
+	if chainID == Ethereum {
+		c.ens = NewENSResolver(c)
 	}
+	if url := config.SubgraphURL[chainID]; url != "" {
+		c.subgraph = NewSubgraphClient(url)
+	}
+	return c
+}
+
+// rpcURLs returns the client's RPC URLs in failover order
+func (c *ChainClient) rpcURLs() []string {
+	return append([]string{c.RPC}, c.FallbackRPCs...)
 }
 
-// GetApprovals fetches all ERC20 approvals for a wallet
-// Uses Alchemy first (faster), falls back to Etherscan
-func (c *ChainClient) GetApprovals(ctx context.Context, walletAddress string) ([]Approval, error) {
-	log.Printf("[%s] Scanning approvals for %s", c.ChainID, walletAddress)
+// isAlchemyEndpoint reports whether url is an Alchemy endpoint: the chain's configured
+// Alchemy endpoint or any alchemy.com URL
+func (c *ChainClient) isAlchemyEndpoint(url string) bool {
+	return strings.Contains(url, "alchemy.com") || url == alchemyConfig.Endpoints[string(c.ChainID)]
+}
 
-	// Try Alchemy first (faster, higher rate limits)
+// alchemyEndpoints returns the chain's Alchemy endpoints in failover order: the
+// configured one, then the alchemy.com URLs among its RPC URLs
+func (c *ChainClient) alchemyEndpoints() []string {
+	endpoints := make([]string, 0, 1)
 	if endpoint, ok := alchemyConfig.Endpoints[string(c.ChainID)]; ok {
-		approvals, err := c.getApprovalsAlchemy(ctx, walletAddress, endpoint)
-		if err == nil && len(approvals) > 0 {
-			return approvals, nil
+		endpoints = append(endpoints, endpoint)
+	}
+	for _, url := range c.rpcURLs() {
+		if c.isAlchemyEndpoint(url) && !slices.Contains(endpoints, url) {
+			endpoints = append(endpoints, url)
 		}
-		log.Printf("[%s] Alchemy scan returned %d, trying Etherscan...", c.ChainID, len(approvals))
 	}
+	return endpoints
+}
 
-	// Fallback to Etherscan
-	return c.getApprovalsEtherscan(ctx, walletAddress)
+// logger returns the client's logger
+func (c *ChainClient) logger() Logger {
+	if c.log == nil {
+		return defaultLogger.With(Fields{"chain": c.ChainID})
+	}
+	return c.log
 }
 
-// getApprovalsAlchemy uses Alchemy's eth_getLogs (faster, parallel-friendly)
-func (c *ChainClient) getApprovalsAlchemy(ctx context.Context, walletAddress string, endpoint string) ([]Approval, error) {
-	approvals := []Approval{}
+// ChainApprovals holds the active approvals found on a single chain
+type ChainApprovals struct {
+	Approvals []Approval
+	// TotalEvents is the number of approval events before MaxApprovalsPerChain was applied
+	TotalEvents int
+	Truncated   bool
+	// Source is the provider that produced the approvals ("alchemy", "etherscan",
+	// "subgraph", or "scan-state" when no blocks were added since the previous scan)
+	Source string
+	// Incomplete is set when the explorer answered with an error message instead of logs,
+	// or the NFT ApprovalForAll or Permit2 events could not be fetched
+	Incomplete bool
+	// Revoked lists the approvalKeys whose latest event in the scanned range set the
+	// allowance to zero, so incremental scans can drop them from stored state
+	Revoked []string
+}
 
-	// ERC20 Approval event signature
-	approvalTopic := "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
-	paddedWallet := "0x000000000000000000000000" + strings.TrimPrefix(strings.ToLower(walletAddress), "0x")
+// blockRange bounds an approval log query; To == 0 means "latest"
+type blockRange struct {
+	From uint64
+	To   uint64
+}
+
+// toBlockParam returns the JSON-RPC toBlock for the range
+func (b blockRange) toBlockParam() string {
+	if b.To == 0 {
+		return "latest"
+	}
+	return fmt.Sprintf("0x%x", b.To)
+}
 
-	// Use eth_getLogs via Alchemy RPC
+// toBlockQuery returns the Etherscan toBlock for the range
+func (b blockRange) toBlockQuery() string {
+	if b.To == 0 {
+		return "latest"
+	}
+	return strconv.FormatUint(b.To, 10)
+}
+
+// approvalKey identifies the approval of a token+spender pair
+func approvalKey(tokenAddress, spenderAddress string) string {
+	return strings.ToLower(tokenAddress + "-" + spenderAddress)
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// approvalLog is a raw log entry from eth_getLogs or the Etherscan logs API
+type approvalLog struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	BlockNumber string   `json:"blockNumber"`
+	TimeStamp   string   `json:"timeStamp"`
+	TxHash      string   `json:"transactionHash"`
+	LogIndex    string   `json:"logIndex"`
+	BlockHash   string   `json:"blockHash"`
+}
+
+// parseHexUint64 parses a 0x-prefixed hex quantity, returning 0 if invalid
+func parseHexUint64(s string) uint64 {
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// capApprovalLogs keeps the maxLogs most recent entries by block number.
+// The result stays in ascending block order so later events win during deduplication.
+func capApprovalLogs(logs []approvalLog, maxLogs int) ([]approvalLog, bool) {
+	if maxLogs <= 0 || len(logs) <= maxLogs {
+		return logs, false
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		return parseHexUint64(logs[i].BlockNumber) < parseHexUint64(logs[j].BlockNumber)
+	})
+	return logs[len(logs)-maxLogs:], true
+}
+
+// GetApprovals fetches all ERC20, NFT and Permit2 approvals for a wallet since genesis;
+// GetApprovalsInRange starts from a given block. Uses Alchemy first (faster), falls
+// back to Etherscan
+func (c *ChainClient) GetApprovals(ctx context.Context, walletAddress string) (*ChainApprovals, error) {
+	return c.GetApprovalsInRange(ctx, walletAddress, blockRange{})
+}
+
+// GetApprovalsInRange fetches the approvals set by events within blocks. Approvals
+// revoked within the range are reported in ChainApprovals.Revoked.
+func (c *ChainClient) GetApprovalsInRange(ctx context.Context, walletAddress string, blocks blockRange) (*ChainApprovals, error) {
+	ctx, span := tracer.Start(ctx, "ChainClient.GetApprovals", trace.WithAttributes(
+		attribute.String("chain", string(c.ChainID)),
+		attribute.String("wallet", walletAddress),
+		attribute.Int64("from_block", int64(blocks.From)),
+	))
+	defer span.End()
+
+	// A failing provider is skipped at once instead of tying up a worker until it times out
+	if err := c.breaker.Allow(); err != nil {
+		return nil, endSpan(span, err)
+	}
+
+	start := time.Now()
+	defer func() { scanDuration.WithLabelValues(string(c.ChainID)).Observe(time.Since(start).Seconds()) }()
+
+	type permit2Result struct {
+		approvals []Approval
+		revoked   []string
+		err       error
+	}
+	permit2Done := make(chan permit2Result, 1)
+	go func() {
+		approvals, revoked, err := c.getPermit2Approvals(ctx, walletAddress, blocks, time.Now().Unix())
+		permit2Done <- permit2Result{approvals, revoked, err}
+	}()
+
+	result, err := c.getApprovals(ctx, walletAddress, blocks)
+	permit2 := <-permit2Done
+	c.breaker.Record(err)
+	if err == nil {
+		if permit2.err != nil {
+			requestLogger(ctx, c.logger()).Warn("Permit2 lookup failed", Fields{"wallet": walletAddress, "error": errorText(permit2.err)})
+			result.Incomplete = true
+		} else {
+			result.Approvals = append(result.Approvals, permit2.approvals...)
+			result.Revoked = append(result.Revoked, permit2.revoked...)
+		}
+		span.SetAttributes(
+			attribute.Int("approvals_found", len(result.Approvals)),
+			attribute.String("rpc_provider", result.Source),
+		)
+	}
+	return result, endSpan(span, err)
+}
+
+func (c *ChainClient) getApprovals(ctx context.Context, walletAddress string, blocks blockRange) (*ChainApprovals, error) {
+	if blocks.From > 0 {
+		requestLogger(ctx, c.logger()).Info("Scanning approvals", Fields{"wallet": walletAddress, "from_block": blocks.From})
+	} else {
+		requestLogger(ctx, c.logger()).Info("Scanning approvals", Fields{"wallet": walletAddress})
+	}
+
+	// Try Alchemy first (faster, higher rate limits), failing over between its
+	// endpoints; the first that answers wins
+	for i, endpoint := range c.alchemyEndpoints() {
+		result, err := c.getApprovalsAlchemyEndpoint(ctx, walletAddress, endpoint, blocks)
+		// An incremental range often has no new approvals; that's not a reason to fall back
+		if err == nil && (len(result.Approvals) > 0 || blocks.From > 0) {
+			return result, nil
+		}
+		if err != nil {
+			rpcErrors.WithLabelValues(string(c.ChainID), "alchemy").Inc()
+			requestLogger(ctx, c.logger()).Warn("Alchemy scan failed, trying the next provider", Fields{"wallet": walletAddress, "endpoint": i, "error": errorText(err)})
+			continue
+		}
+		requestLogger(ctx, c.logger()).Info("Alchemy scan came back empty, trying Etherscan", Fields{"wallet": walletAddress, "approvals_found": len(result.Approvals)})
+		break
+	}
+
+	// Fallback to Etherscan
+	result, err := c.getApprovalsEtherscan(ctx, walletAddress, blocks)
+	if err != nil {
+		rpcErrors.WithLabelValues(string(c.ChainID), "etherscan").Inc()
+	}
+
+	// Then the chain's subgraph, for full scans only: it has no block range filter
+	if c.subgraph != nil && blocks.From == 0 && (err != nil || len(result.Approvals) == 0) {
+		requestLogger(ctx, c.logger()).Info("Etherscan scan failed or came back empty, trying the subgraph", Fields{"wallet": walletAddress})
+		subgraphResult, subgraphErr := c.getApprovalsSubgraph(ctx, walletAddress)
+		if subgraphErr == nil {
+			return subgraphResult, nil
+		}
+		rpcErrors.WithLabelValues(string(c.ChainID), "subgraph").Inc()
+		requestLogger(ctx, c.logger()).Warn("Subgraph scan failed", Fields{"wallet": walletAddress, "error": errorText(subgraphErr)})
+	}
+	return result, err
+}
+
+// getApprovalsAlchemyEndpoint scans one Alchemy endpoint: full scans read the current
+// allowances with alchemy_getTokenAllowances, falling back to replaying Approval
+// events with eth_getLogs, which incremental scans use directly. Replayed full scans
+// then confirm each ERC20 allowance's current value (incremental scans confirm the
+// merged set in Scanner.fetchApprovals).
+func (c *ChainClient) getApprovalsAlchemyEndpoint(ctx context.Context, walletAddress string, endpoint string, blocks blockRange) (*ChainApprovals, error) {
+	if _, unsupported := tokenAllowancesUnsupported.Load(endpoint); blocks.From == 0 && !unsupported {
+		result, err := c.getApprovalsAlchemyV2(ctx, walletAddress, endpoint)
+		if err == nil {
+			return result, nil
+		}
+		if isMethodNotFound(err) {
+			tokenAllowancesUnsupported.Store(endpoint, true)
+			requestLogger(ctx, c.logger()).Info("alchemy_getTokenAllowances not supported, using eth_getLogs", Fields{"wallet": walletAddress})
+		} else {
+			requestLogger(ctx, c.logger()).Warn("alchemy_getTokenAllowances failed, using eth_getLogs", Fields{"wallet": walletAddress, "error": errorText(err)})
+		}
+	}
+
+	result, err := c.getApprovalsAlchemy(ctx, walletAddress, endpoint, blocks)
+	if err != nil {
+		return nil, err
+	}
+	if blocks.From == 0 {
+		c.confirmAllowances(ctx, endpoint, walletAddress, result)
+	}
+	return result, nil
+}
+
+// getLogsAlchemy runs eth_getLogs with filter against an Alchemy endpoint
+func (c *ChainClient) getLogsAlchemy(ctx context.Context, endpoint string, filter map[string]interface{}) ([]approvalLog, error) {
 	rpcRequest := map[string]interface{}{
 		"jsonrpc": "2.0",
 		"method":  "eth_getLogs",
-		"params": []interface{}{
-			map[string]interface{}{
-				"fromBlock": "0x0",
-				"toBlock":   "latest",
-				"topics":    []string{approvalTopic, paddedWallet},
-			},
-		},
-		"id": 1,
+		"params":  []interface{}{filter},
+		"id":      1,
 	}
 
 	body, err := json.Marshal(rpcRequest)
@@ -665,31 +1302,66 @@ func (c *ChainClient) getApprovalsAlchemy(ctx context.Context, walletAddress str
 	defer resp.Body.Close()
 
 	var rpcResp struct {
-		Result []struct {
-			Address string   `json:"address"`
-			Topics  []string `json:"topics"`
-			Data    string   `json:"data"`
-		} `json:"result"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error"`
+		Result []approvalLog `json:"result"`
+		Error  *jsonRPCError `json:"error"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+	respBody, err := c.readRPCBody(resp, "alchemy", endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHTTPStatus("alchemy", resp); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
 		return nil, err
 	}
 
+	// JSON-RPC errors (bad filter, too many results) don't go away on retry
 	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("alchemy error: %s", rpcResp.Error.Message)
+		return nil, noRetry(fmt.Errorf("alchemy error: %w", rpcResp.Error))
+	}
+	return rpcResp.Result, nil
+}
+
+// getApprovalsAlchemy uses Alchemy's eth_getLogs (faster, parallel-friendly)
+func (c *ChainClient) getApprovalsAlchemy(ctx context.Context, walletAddress string, endpoint string, blocks blockRange) (*ChainApprovals, error) {
+	approvals := []Approval{}
+
+	// ERC20 Approval event signature
+	approvalTopic := "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+	paddedWallet := "0x000000000000000000000000" + strings.TrimPrefix(strings.ToLower(walletAddress), "0x")
+
+	// NFT ApprovalForAll events are fetched alongside the ERC20 ones
+	applyApprovalForAll := c.fetchApprovalForAll(ctx, walletAddress, endpoint, blocks)
+
+	// Use eth_getLogs via Alchemy RPC, splitting ranges the provider rejects as too large
+	logs, err := c.getLogsSplitting(ctx, endpoint, map[string]interface{}{
+		"topics": []string{approvalTopic, paddedWallet},
+	}, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	requestLogger(ctx, c.logger()).Debug("Alchemy returned approval events", Fields{"wallet": walletAddress, "events": len(logs)})
+
+	totalEvents := len(logs)
+	logs, truncated := capApprovalLogs(logs, config.MaxApprovalsPerChain)
+	if truncated {
+		requestLogger(ctx, c.logger()).Warn("Truncated to the most recent approval events", Fields{"wallet": walletAddress, "events": len(logs), "total_events": totalEvents})
 	}
 
-	log.Printf("[%s] Alchemy returned %d approval events", c.ChainID, len(rpcResp.Result))
+	// Read the symbols of every approved token in a few Multicall3 requests
+	c.prefetchTokenMetadata(ctx, approvalLogTokens(logs))
 
 	// Process logs - keep only latest approval per token-spender pair
 	latestApprovals := make(map[string]Approval)
+	revoked := make(map[string]bool)
 
-	for _, logEntry := range rpcResp.Result {
-		if len(logEntry.Topics) < 3 {
+	for _, logEntry := range logs {
+		// ERC721 Approval events carry the token id as a fourth topic and grant a single NFT;
+		// NFT contracts emitting a three-topic Approval have no ERC20 allowance either
+		if len(logEntry.Topics) != 3 || c.isNFTContract(logEntry.Address) {
 			continue
 		}
 
@@ -701,88 +1373,103 @@ func (c *ChainClient) getApprovalsAlchemy(ctx context.Context, walletAddress str
 		allowance := new(big.Int)
 		allowance.SetString(allowanceHex, 16)
 
-		// Skip revoked (zero) approvals
+		// A zero allowance revokes any earlier approval of the pair
+		key := approvalKey(tokenAddress, spenderAddress)
 		if allowance.Cmp(big.NewInt(0)) == 0 {
+			delete(latestApprovals, key)
+			revoked[key] = true
 			continue
 		}
+		delete(revoked, key)
+
+		approval := c.erc20Approval(ctx, tokenAddress, spenderAddress, allowance)
+		approval.TxHash = logEntry.TxHash
+		approval.LogIndex = int(parseHexUint64(logEntry.LogIndex))
+		approval.BlockNumber = parseHexUint64(logEntry.BlockNumber)
+		approval.LastUpdated = int64(parseHexUint64(logEntry.TimeStamp))
+		latestApprovals[key] = approval
+	}
 
-		// Check unlimited
-		maxUint256 := new(big.Int)
-		maxUint256.SetString("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
-		threshold := new(big.Int).Div(maxUint256, big.NewInt(2))
-		isUnlimited := allowance.Cmp(threshold) > 0
+	nftEvents, nftTruncated, nftOK := applyApprovalForAll(latestApprovals, revoked)
 
-		// Get token and spender info
-		tokenSymbol := getTokenSymbol(tokenAddress, c)
-		spenderName, spenderRisk := getSpenderInfo(spenderAddress)
+	for _, approval := range latestApprovals {
+		approvals = append(approvals, approval)
+	}
+
+	requestLogger(ctx, c.logger()).Info("Found active approvals", Fields{"wallet": walletAddress, "provider": "alchemy", "approvals_found": len(approvals)})
+	return &ChainApprovals{
+		Approvals:   approvals,
+		TotalEvents: totalEvents + nftEvents,
+		Truncated:   truncated || nftTruncated,
+		Source:      "alchemy",
+		Incomplete:  !nftOK,
+		Revoked:     sortedKeys(revoked),
+	}, nil
+}
 
-		// Set initial risk level based on spender trust level
-		// This will be refined in calculateRiskScores based on unlimited status
-		riskLevel := spenderRisk
-		riskReasons := []string{}
+// erc20Approval builds the approval of an ERC20 allowance, with its initial risk level
+// from the spender's trust level; calculateRiskScores refines it
+func (c *ChainClient) erc20Approval(ctx context.Context, tokenAddress, spenderAddress string, allowance *big.Int) Approval {
+	// Check unlimited (threshold depends on token decimals)
+	isUnlimited := isUnlimitedWithDecimals(allowance, c.tokenDecimalsOrDefault(ctx, tokenAddress))
 
-		if isUnlimited {
-			riskReasons = append(riskReasons, "Unlimited approval")
-		}
+	// Get token and spender info
+	tokenSymbol := getTokenSymbol(tokenAddress, c)
+	spenderName, spenderRisk := getSpenderInfo(spenderAddress)
+	spenderName, spenderRisk = c.resolveSpenderENS(ctx, spenderAddress, spenderName, spenderRisk)
 
-		key := tokenAddress + "-" + spenderAddress
-		latestApprovals[key] = Approval{
-			Chain:          c.ChainID,
-			TokenAddress:   tokenAddress,
-			TokenSymbol:    tokenSymbol,
-			SpenderAddress: spenderAddress,
-			SpenderName:    spenderName,
-			AllowanceRaw:   allowance.String(),
-			AllowanceHuman: formatAllowance(allowance),
-			IsUnlimited:    isUnlimited,
-			RiskLevel:      riskLevel,
-			RiskReasons:    riskReasons,
-			LastUpdated:    time.Now().Unix(),
-		}
+	riskReasons := []string{}
+	if isUnlimited {
+		riskReasons = append(riskReasons, "Unlimited approval")
 	}
 
-	for _, approval := range latestApprovals {
-		approvals = append(approvals, approval)
+	// A token that is its own spender can transferFrom on holders' behalf
+	isSelfApproval := strings.EqualFold(tokenAddress, spenderAddress)
+	if isSelfApproval {
+		riskReasons = append(riskReasons, selfApprovalReason)
 	}
 
-	log.Printf("[%s] Found %d active approvals via Alchemy", c.ChainID, len(approvals))
-	return approvals, nil
+	return Approval{
+		Chain:             c.ChainID,
+		TokenAddress:      tokenAddress,
+		TokenSymbol:       tokenSymbol,
+		TokenType:         tokenTypeERC20,
+		SpenderAddress:    spenderAddress,
+		SpenderName:       spenderName,
+		AllowanceRaw:      allowance.String(),
+		AllowanceHuman:    c.formatAllowanceForToken(ctx, allowance, tokenAddress),
+		AllowanceUSD:      allowanceUSDValue(allowance, tokenAddress, isUnlimited),
+		IsUnlimited:       isUnlimited,
+		IsSelfApproval:    isSelfApproval,
+		RiskLevel:         spenderRisk,
+		RiskReasons:       riskReasons,
+		TransferFromCount: -1,
+	}
 }
 
 // getApprovalsEtherscan uses Etherscan API v2 (fallback)
-func (c *ChainClient) getApprovalsEtherscan(ctx context.Context, walletAddress string) ([]Approval, error) {
+func (c *ChainClient) getApprovalsEtherscan(ctx context.Context, walletAddress string, blocks blockRange) (*ChainApprovals, error) {
 	approvals := []Approval{}
 
-	// Get chain ID for Etherscan v2
-	chainID, ok := etherscanConfig.ChainIDs[string(c.ChainID)]
-	if !ok {
-		log.Printf("[%s] Chain not supported by Etherscan v2, skipping", c.ChainID)
-		return approvals, nil
-	}
-
 	// ERC20 Approval event signature
 	approvalTopic := "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
 	paddedWallet := "0x000000000000000000000000" + strings.TrimPrefix(strings.ToLower(walletAddress), "0x")
 
-	// Etherscan API v2 endpoint
-	url := fmt.Sprintf(
-		"https://api.etherscan.io/v2/api?chainid=%d&module=logs&action=getLogs&fromBlock=0&toBlock=latest&topic0=%s&topic1=%s&apikey=%s",
-		chainID,
+	// Etherscan v2, or the chain's own explorer (CronoScan, runtime-added chains)
+	url, ok := explorerURL(c.ChainID, fmt.Sprintf(
+		"module=logs&action=getLogs&fromBlock=%d&toBlock=%s&topic0=%s&topic1=%s",
+		blocks.From,
+		blocks.toBlockQuery(),
 		approvalTopic,
 		paddedWallet,
-		etherscanConfig.APIKey,
-	)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	))
+	if !ok {
+		requestLogger(ctx, c.logger()).Warn("Chain not supported by Etherscan v2, skipping", nil)
+		return &ChainApprovals{Approvals: approvals, Source: "etherscan", Incomplete: true}, nil
 	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("Etherscan API call failed: %w", err)
-	}
-	defer resp.Body.Close()
+	// NFT ApprovalForAll events are fetched alongside the ERC20 ones
+	applyApprovalForAll := c.fetchApprovalForAll(ctx, walletAddress, "", blocks)
 
 	// Use RawMessage to handle both array and string responses
 	var rawResp struct {
@@ -791,49 +1478,87 @@ func (c *ChainClient) getApprovalsEtherscan(ctx context.Context, walletAddress s
 		Result  json.RawMessage `json:"result"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&rawResp); err != nil {
-		return nil, fmt.Errorf("failed to decode Etherscan response: %w", err)
+	err := withRetry(ctx, defaultRetryAttempts, func() error {
+		if err := explorerLimiter(c.ChainID).Wait(ctx); err != nil {
+			return noRetry(err)
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return noRetry(fmt.Errorf("failed to create request: %w", err))
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("Etherscan API call failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := c.readRPCBody(resp, "etherscan", url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to read Etherscan response: %w", err)
+		}
+		if err := checkHTTPStatus("etherscan", resp); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(respBody, &rawResp); err != nil {
+			return fmt.Errorf("failed to decode Etherscan response: %w", err)
+		}
+		if rawResp.Status == "1" || etherscanNoRecords(rawResp.Message, rawResp.Result) {
+			return nil
+		}
+		apiErr := &etherscanError{Message: rawResp.Message, Result: strings.Trim(string(rawResp.Result), `"`)}
+		if apiErr.rateLimited() {
+			return apiErr
+		}
+		return noRetry(apiErr)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if result is a string (error message) or array (logs)
-	if len(rawResp.Result) > 0 && rawResp.Result[0] == '"' {
-		// Result is a string - this is an error or "No records found"
-		var errMsg string
-		if err := json.Unmarshal(rawResp.Result, &errMsg); err != nil {
-			log.Printf("[%s] Failed to parse Etherscan message: %v", c.ChainID, err)
-		} else {
-			log.Printf("[%s] Etherscan returned message: %s", c.ChainID, errMsg)
+	// A string result is "No records found"; the wallet may still have NFT approvals
+	if len(rawResp.Result) == 0 || rawResp.Result[0] == '"' {
+		latestApprovals := make(map[string]Approval)
+		revoked := make(map[string]bool)
+		nftEvents, nftTruncated, nftOK := applyApprovalForAll(latestApprovals, revoked)
+		for _, approval := range latestApprovals {
+			approvals = append(approvals, approval)
 		}
-		return approvals, nil // Return empty, not an error
+		return &ChainApprovals{
+			Approvals:   approvals,
+			TotalEvents: nftEvents,
+			Truncated:   nftTruncated,
+			Source:      "etherscan",
+			Incomplete:  !nftOK,
+			Revoked:     sortedKeys(revoked),
+		}, nil
 	}
 
 	// Parse as array of logs
-	type LogEntry struct {
-		Address     string   `json:"address"`
-		Topics      []string `json:"topics"`
-		Data        string   `json:"data"`
-		BlockNumber string   `json:"blockNumber"`
-		TimeStamp   string   `json:"timeStamp"`
-		TxHash      string   `json:"transactionHash"`
-	}
-	var logs []LogEntry
+	var logs []approvalLog
 	if err := json.Unmarshal(rawResp.Result, &logs); err != nil {
-		log.Printf("[%s] Failed to parse logs: %v", c.ChainID, err)
-		return approvals, nil
+		return nil, fmt.Errorf("failed to decode Etherscan logs: %w", err)
 	}
 
-	if rawResp.Status != "1" && rawResp.Message != "No records found" {
-		log.Printf("[%s] Etherscan status: %s - %s", c.ChainID, rawResp.Status, rawResp.Message)
-		return approvals, nil
+	requestLogger(ctx, c.logger()).Debug("Etherscan returned approval events", Fields{"wallet": walletAddress, "events": len(logs)})
+
+	totalEvents := len(logs)
+	logs, truncated := capApprovalLogs(logs, config.MaxApprovalsPerChain)
+	if truncated {
+		requestLogger(ctx, c.logger()).Warn("Truncated to the most recent approval events", Fields{"wallet": walletAddress, "events": len(logs), "total_events": totalEvents})
 	}
 
-	log.Printf("[%s] Etherscan returned %d approval events", c.ChainID, len(logs))
+	// Read the symbols of every approved token in a few Multicall3 requests
+	c.prefetchTokenMetadata(ctx, approvalLogTokens(logs))
 
 	// Process approval events - keep track of latest approval per token+spender
 	latestApprovals := make(map[string]Approval)
+	revoked := make(map[string]bool)
 
 	for _, logEntry := range logs {
-		if len(logEntry.Topics) < 3 {
+		// ERC721 Approval events carry the token id as a fourth topic and grant a single NFT;
+		// NFT contracts emitting a three-topic Approval have no ERC20 allowance either
+		if len(logEntry.Topics) != 3 || c.isNFTContract(logEntry.Address) {
 			continue
 		}
 
@@ -845,59 +1570,39 @@ func (c *ChainClient) getApprovalsEtherscan(ctx context.Context, walletAddress s
 		allowance := new(big.Int)
 		allowance.SetString(allowanceHex, 16)
 
-		// Check if unlimited (max uint256 or very large)
-		maxUint256 := new(big.Int)
-		maxUint256.SetString("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
-		threshold := new(big.Int).Div(maxUint256, big.NewInt(2))
-		isUnlimited := allowance.Cmp(threshold) > 0
-
-		// Determine risk level
-		riskReasons := []string{}
-
-		if isUnlimited {
-			riskReasons = append(riskReasons, "Unlimited approval")
-		}
-
-		// Check if approval is still active (non-zero)
+		// Check if approval is still active (non-zero); a zero allowance revokes earlier ones
+		key := approvalKey(tokenAddress, spenderAddress)
 		if allowance.Cmp(big.NewInt(0)) == 0 {
-			continue // Skip revoked approvals
-		}
-
-		// Get token symbol from known tokens or fetch from chain
-		tokenSymbol := getTokenSymbol(tokenAddress, c)
-
-		// Get spender name from known spenders database
-		spenderName, spenderRisk := getSpenderInfo(spenderAddress)
-
-		// Set initial risk level based on spender trust level
-		// This will be refined in calculateRiskScores based on unlimited status
-		riskLevel := spenderRisk
-
-		key := tokenAddress + "-" + spenderAddress
-		approval := Approval{
-			Chain:          c.ChainID,
-			TokenAddress:   tokenAddress,
-			TokenSymbol:    tokenSymbol,
-			SpenderAddress: spenderAddress,
-			SpenderName:    spenderName,
-			AllowanceRaw:   allowance.String(),
-			AllowanceHuman: formatAllowance(allowance),
-			IsUnlimited:    isUnlimited,
-			RiskLevel:      riskLevel,
-			RiskReasons:    riskReasons,
-			LastUpdated:    time.Now().Unix(),
+			delete(latestApprovals, key)
+			revoked[key] = true
+			continue
 		}
+		delete(revoked, key)
 
+		approval := c.erc20Approval(ctx, tokenAddress, spenderAddress, allowance)
+		approval.TxHash = logEntry.TxHash
+		approval.LogIndex = int(parseHexUint64(logEntry.LogIndex))
+		approval.BlockNumber = parseHexUint64(logEntry.BlockNumber)
+		approval.LastUpdated = int64(parseHexUint64(logEntry.TimeStamp))
 		latestApprovals[key] = approval
 	}
 
+	nftEvents, nftTruncated, nftOK := applyApprovalForAll(latestApprovals, revoked)
+
 	// Convert map to slice
 	for _, approval := range latestApprovals {
 		approvals = append(approvals, approval)
 	}
 
-	log.Printf("[%s] Found %d active approvals for %s", c.ChainID, len(approvals), walletAddress)
-	return approvals, nil
+	requestLogger(ctx, c.logger()).Info("Found active approvals", Fields{"wallet": walletAddress, "provider": "etherscan", "approvals_found": len(approvals)})
+	return &ChainApprovals{
+		Approvals:   approvals,
+		TotalEvents: totalEvents + nftEvents,
+		Truncated:   truncated || nftTruncated,
+		Source:      "etherscan",
+		Incomplete:  !nftOK,
+		Revoked:     sortedKeys(revoked),
+	}, nil
 }
 
 // getTokenSymbol returns the token symbol from known tokens or fetches from chain
@@ -905,13 +1610,16 @@ func getTokenSymbol(tokenAddress string, c *ChainClient) string {
 	lowerAddr := strings.ToLower(tokenAddress)
 
 	// Check known tokens first
-	if symbol, ok := knownTokens[lowerAddr]; ok {
+	if symbol, ok := spenderDB.Token(lowerAddr); ok {
 		return symbol
 	}
 
-	// Try to fetch from chain via eth_call to symbol()
-	symbol, err := c.fetchTokenSymbol(tokenAddress)
-	if err == nil && symbol != "" {
+	// Then symbols read by a Multicall3 batch; otherwise call symbol() directly
+	if meta, ok := cachedTokenMetadata(c.ChainID, lowerAddr); ok {
+		if meta.Symbol != "" {
+			return meta.Symbol
+		}
+	} else if symbol, err := c.fetchTokenSymbol(tokenAddress); err == nil && symbol != "" {
 		return symbol
 	}
 
@@ -922,54 +1630,122 @@ func getTokenSymbol(tokenAddress string, c *ChainClient) string {
 	return "ERC20"
 }
 
+// approvalLogTokens returns the token addresses of ERC20 Approval events
+func approvalLogTokens(logs []approvalLog) []string {
+	tokens := make([]string, 0, len(logs))
+	for _, logEntry := range logs {
+		if len(logEntry.Topics) == 3 {
+			tokens = append(tokens, logEntry.Address)
+		}
+	}
+	return tokens
+}
+
 // fetchTokenSymbol calls symbol() on the token contract
 func (c *ChainClient) fetchTokenSymbol(tokenAddress string) (string, error) {
-	// symbol() function selector: 0x95d89b41
-	callData := "0x95d89b41"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := c.ethCall(ctx, tokenAddress, symbolSelector)
+	if err != nil {
+		return "", err
+	}
+
+	// Decode the result (ABI encoded string)
+	return decodeString(result), nil
+}
+
+// ethCall performs a read-only eth_call against the latest block and returns the hex result
+func (c *ChainClient) ethCall(ctx context.Context, to string, data string) (string, error) {
+	var result string
+	params := []interface{}{
+		map[string]string{
+			"to":   to,
+			"data": data,
+		},
+		"latest",
+	}
+	if err := c.rpcCall(ctx, "eth_call", params, &result); err != nil {
+		return "", err
+	}
+	return result, nil
+}
 
+// rpcCall performs a JSON-RPC request and decodes the result into result. The RPC URLs
+// are tried in order; a failed request or a rate limit moves on to the next, other
+// error responses (e.g. an eth_call revert) are returned as they are.
+func (c *ChainClient) rpcCall(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	var err error
+	for i, url := range c.rpcURLs() {
+		if err = c.rpcCallURL(ctx, url, method, params, result); err == nil || ctx.Err() != nil || !shouldFailOver(err) {
+			return err
+		}
+		if i < len(c.FallbackRPCs) {
+			requestLogger(ctx, c.logger()).Debug("RPC call failed, trying the next RPC URL", Fields{"method": method, "endpoint": i, "error": errorText(err)})
+		}
+	}
+	return err
+}
+
+// shouldFailOver reports whether another RPC URL may answer where one failed: on
+// transport errors, unreadable (e.g. 5xx) responses and rate limits. Other JSON-RPC
+// errors are the chain's answer and would be the same everywhere.
+func shouldFailOver(err error) bool {
+	var rpcErr *jsonRPCError
+	if !errors.As(err, &rpcErr) {
+		return true
+	}
+	message := strings.ToLower(rpcErr.Message)
+	return rpcErr.Code == http.StatusTooManyRequests || strings.Contains(message, "rate limit") || strings.Contains(message, "too many requests")
+}
+
+// rpcCallURL performs a JSON-RPC request against url
+func (c *ChainClient) rpcCallURL(ctx context.Context, url, method string, params []interface{}, result interface{}) error {
 	rpcRequest := map[string]interface{}{
 		"jsonrpc": "2.0",
-		"method":  "eth_call",
-		"params": []interface{}{
-			map[string]string{
-				"to":   tokenAddress,
-				"data": callData,
-			},
-			"latest",
-		},
-		"id": 1,
+		"method":  method,
+		"params":  params,
+		"id":      1,
 	}
 
 	body, err := json.Marshal(rpcRequest)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.RPC, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer resp.Body.Close()
 
 	var rpcResp struct {
-		Result string `json:"result"`
+		Result json.RawMessage `json:"result"`
+		Error  *jsonRPCError   `json:"error"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
-		return "", err
+	respBody, err := c.readRPCBody(resp, "rpc", url, body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return err
 	}
 
-	// Decode the result (ABI encoded string)
-	return decodeString(rpcResp.Result), nil
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s error: %w", method, rpcResp.Error)
+	}
+	if len(rpcResp.Result) == 0 {
+		return fmt.Errorf("%s error: empty result", method)
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
 }
 
 // decodeString decodes an ABI-encoded string from eth_call result
@@ -1011,17 +1787,43 @@ func decodeString(hexData string) string {
 
 // getSpenderInfo returns spender name and risk level
 func getSpenderInfo(spenderAddress string) (string, string) {
-	lowerAddr := strings.ToLower(spenderAddress)
+	// Sanctioned addresses are critical whatever the databases or overrides say
+	if sanctionsList.IsSanctioned(spenderAddress) {
+		return sanctionedSpenderName, "critical"
+	}
 
-	// Check known spenders
-	if name, ok := knownSpenders[lowerAddr]; ok {
+	name, riskLevel := lookupSpender(spenderAddress)
+
+	// Admin overrides take precedence over every spender database
+	if override, ok := riskOverrides.Get(spenderAddress); ok {
+		return name, override.RiskLevel
+	}
+	return name, riskLevel
+}
+
+// lookupSpender returns the spender's name and risk level from the spender databases
+func lookupSpender(spenderAddress string) (string, string) {
+	lowerAddr := strings.ToLower(spenderAddress)
+
+	// Check known spenders
+	if name, riskLevel, ok := spenderDB.Spender(lowerAddr); ok {
 		// Check if this spender has a custom risk level
-		if riskLevel, hasRisk := spenderRiskLevel[lowerAddr]; hasRisk {
+		if riskLevel != "" {
 			return name, riskLevel // Known risky spender (drainer, etc.)
 		}
 		return name, "safe" // Known legitimate protocol
 	}
 
+	// MEV bots get approvals through normal swap flows - legitimate, but worth watching
+	if _, ok := knownMEVBots[lowerAddr]; ok {
+		return mevBotSpenderName, "warning"
+	}
+
+	// Canonical bridges loaded at runtime (see BridgeRegistryUpdater)
+	if name, ok := dynamicSpenders.Get(lowerAddr); ok {
+		return name, "safe"
+	}
+
 	// Unknown spender - return formatted address with warning
 	if len(spenderAddress) >= 10 {
 		return spenderAddress[:6] + "..." + spenderAddress[len(spenderAddress)-4:], "warning"
@@ -1048,24 +1850,43 @@ func getTokenDecimals(tokenAddress string) int {
 }
 
 // formatAllowance converts big.Int to human-readable format
-// Uses default 18 decimals - for token-specific decimals use formatAllowanceForToken
+// Uses default 18 decimals - for token-specific decimals use ChainClient.formatAllowanceForToken
 func formatAllowance(amount *big.Int) string {
 	return formatAllowanceWithDecimals(amount, 18)
 }
 
-// formatAllowanceForToken converts big.Int to human-readable format with token-specific decimals
-func formatAllowanceForToken(amount *big.Int, tokenAddress string) string {
-	return formatAllowanceWithDecimals(amount, getTokenDecimals(tokenAddress))
+// formatAllowanceForToken converts big.Int to human-readable format with the token's
+// decimals as read from chain
+func (c *ChainClient) formatAllowanceForToken(ctx context.Context, amount *big.Int, tokenAddress string) string {
+	return formatAllowanceWithDecimals(amount, c.tokenDecimalsOrDefault(ctx, tokenAddress))
 }
 
-// formatAllowanceWithDecimals converts big.Int to human-readable format with specific decimals
-func formatAllowanceWithDecimals(amount *big.Int, decimals int) string {
-	// Check for unlimited
+// unlimitedTokenAmount is the whole-token allowance treated as unlimited.
+// No real token supply comes close, so anything above it is an "infinite" approval
+// (e.g. type(uint128).max) regardless of the token's decimals.
+const unlimitedTokenAmount = 1e18
+
+// isUnlimitedForToken reports whether amount is an unlimited approval for tokenAddress
+func isUnlimitedForToken(amount *big.Int, tokenAddress string) bool {
+	return isUnlimitedWithDecimals(amount, getTokenDecimals(tokenAddress))
+}
+
+// isUnlimitedWithDecimals reports whether amount is an unlimited approval for a token with decimals
+func isUnlimitedWithDecimals(amount *big.Int, decimals int) bool {
 	maxUint256 := new(big.Int)
 	maxUint256.SetString("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
-	threshold := new(big.Int).Div(maxUint256, big.NewInt(2))
+	if amount.Cmp(new(big.Int).Div(maxUint256, big.NewInt(2))) > 0 {
+		return true
+	}
 
-	if amount.Cmp(threshold) > 0 {
+	threshold := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	threshold.Mul(threshold, big.NewInt(unlimitedTokenAmount))
+	return amount.Cmp(threshold) >= 0
+}
+
+// formatAllowanceWithDecimals converts big.Int to human-readable format with specific decimals
+func formatAllowanceWithDecimals(amount *big.Int, decimals int) string {
+	if isUnlimitedWithDecimals(amount, decimals) {
 		return "UNLIMITED"
 	}
 
@@ -1088,55 +1909,20 @@ func formatAllowanceWithDecimals(amount *big.Int, decimals int) string {
 
 // GetContractBytecode fetches contract bytecode for analysis
 func (c *ChainClient) GetContractBytecode(ctx context.Context, contractAddress string) ([]byte, error) {
-	log.Printf("[%s] Fetching bytecode for %s", c.ChainID, contractAddress)
+	requestLogger(ctx, c.logger()).Debug("Fetching bytecode", Fields{"contract": contractAddress})
 
-	// JSON-RPC call: eth_getCode
-	rpcRequest := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "eth_getCode",
-		"params":  []interface{}{contractAddress, "latest"},
-		"id":      1,
-	}
-
-	body, err := json.Marshal(rpcRequest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal RPC request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.RPC, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("RPC call failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var rpcResp struct {
-		Result string `json:"result"`
-		Error  *struct {
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
-		return nil, fmt.Errorf("failed to decode RPC response: %w", err)
-	}
-
-	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("RPC error: %s", rpcResp.Error.Message)
+	var code string
+	if err := c.rpcCall(ctx, "eth_getCode", []interface{}{contractAddress, "latest"}, &code); err != nil {
+		return nil, fmt.Errorf("RPC error: %w", err)
 	}
 
 	// Decode hex bytecode
-	bytecode, err := hex.DecodeString(strings.TrimPrefix(rpcResp.Result, "0x"))
+	bytecode, err := hex.DecodeString(strings.TrimPrefix(code, "0x"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode bytecode: %w", err)
 	}
 
-	log.Printf("[%s] Fetched %d bytes of bytecode for %s", c.ChainID, len(bytecode), contractAddress)
+	requestLogger(ctx, c.logger()).Debug("Fetched bytecode", Fields{"contract": contractAddress, "bytes": len(bytecode)})
 	return bytecode, nil
 }
 
@@ -1146,139 +1932,469 @@ func (c *ChainClient) GetContractBytecode(ctx context.Context, contractAddress s
 
 type Scanner struct {
 	clients map[ChainID]*ChainClient
-	cache   *Cache
-}
-
-func NewScanner() *Scanner {
+	// solana scans SPL token delegations (nil = Solana not configured)
+	solana *SolanaClient
+	cache  CacheStore
+	clock  Clock
+	trust  *TokenTrustScorer
+	// state holds each wallet's lastScannedBlock per chain for incremental scans (nil = always full)
+	state *ScanStateStore
+	// classifier categorizes unknown spenders from on-chain signals (nil = disabled)
+	classifier *ContractClassifier
+	// spenderStats counts unknown spenders' transactions (nil = disabled)
+	spenderStats *SpenderStatsEnricher
+	// volume counts the wallet's transfers through each approval (nil = disabled)
+	volume *VolumeAnalyzer
+	// identity looks up the wallet's Lens and Farcaster names (nil = ENS only)
+	identity *IdentityResolver
+	// tornado checks the wallet's transactions for Tornado Cash (nil = disabled)
+	tornado *TornadoCashChecker
+	// sanctions screens spenders with the SANCTIONS_PROVIDER (nil = bundled list only)
+	sanctions SanctionsAPIClient
+	// maxConcurrency caps how many chains are scanned at once (<= 0 = defaultMaxConcurrency)
+	maxConcurrency int
+	// history keeps each wallet's recent risk scores (nil = not recorded)
+	history *ScanHistory
+	// prices values limited allowances with CoinGecko prices (nil = stablecoins only)
+	prices *PriceEnricher
+	// webhooks receives every completed scan (nil = disabled)
+	webhooks *WebhookDispatcher
+	// log is the scanner's logger (nil = defaultLogger)
+	log Logger
+}
+
+// defaultMaxConcurrency is the number of chains scanned in parallel unless configured
+const defaultMaxConcurrency = 4
+
+// Options tunes a Scanner; the zero value keeps the defaults
+type Options struct {
+	// MaxConcurrency caps how many chains are scanned at once (default 4)
+	MaxConcurrency int
+	// HistoryDepth is the number of scans kept per wallet (default 20)
+	HistoryDepth int
+	// HistoryWallets is the number of wallets whose scans are kept (default 10,000)
+	HistoryWallets int
+	// Logger receives the scanner's and its chain clients' logs (default defaultLogger)
+	Logger Logger
+}
+
+// newChainClients creates a client for every configured EVM chain
+func newChainClients(logger Logger) map[ChainID]*ChainClient {
 	clients := make(map[ChainID]*ChainClient)
+	for chain, rpcs := range config.RPC {
+		if isEVMChain(ChainID(chain)) {
+			clients[ChainID(chain)] = NewChainClientWithFallbacks(ChainID(chain), rpcs, logger)
+		}
+	}
+	return clients
+}
 
-	for chain, rpc := range config.RPC {
-		clients[ChainID(chain)] = NewChainClient(ChainID(chain), rpc)
+// newSolanaClient creates the Solana client from config.RPC (nil if not configured)
+func newSolanaClient(logger Logger) *SolanaClient {
+	rpcs := config.RPC[string(Solana)]
+	if len(rpcs) == 0 || rpcs[0] == "" {
+		return nil
 	}
+	return NewSolanaClient(rpcs[0], logger)
+}
+
+// NewScanner creates a scanner for every configured chain
+func NewScanner(opts ...Options) *Scanner {
+	return NewScannerWithClock(RealClock{}, opts...)
+}
+
+// NewScannerWithClock creates a scanner that timestamps results with clock
+func NewScannerWithClock(clock Clock, opts ...Options) *Scanner {
+	options := mergeOptions(opts)
+	logger := loggerOr(options.Logger)
+	clients := newChainClients(logger)
 
+	cache := NewCacheWithClock(config.CacheTTL, clock)
 	return &Scanner{
-		clients: clients,
-		cache:   NewCache(config.CacheTTL),
+		clients:    clients,
+		solana:     newSolanaClient(logger),
+		cache:      cache,
+		clock:      clock,
+		trust:      NewTokenTrustScorer(clock),
+		state:      NewScanStateStore("", 0),
+		classifier: NewContractClassifier(cache),
+		tornado:    NewTornadoCashChecker(clock),
+		sanctions:  sanctionsAPI,
+
+		maxConcurrency: options.MaxConcurrency,
+		history:        NewScanHistory(options.HistoryDepth, options.HistoryWallets),
+		prices:         NewPriceEnricher(cache),
+		spenderStats:   newSpenderStatsFromEnv(cache),
+		volume:         newVolumeAnalyzerFromEnv(clock),
+		identity:       newIdentityResolverFromEnv(),
+		log:            logger,
 	}
 }
 
-// ScanWallet performs sequential multi-chain scan with rate limiting
-// Etherscan free tier: 3 calls/sec max
-func (s *Scanner) ScanWallet(ctx context.Context, walletAddress string, chains []ChainID) (*WalletScanResult, error) {
-	log.Printf("Starting multi-chain scan for %s across %d chains", walletAddress, len(chains))
+// mergeOptions returns the last of opts, or the zero Options
+func mergeOptions(opts []Options) Options {
+	if len(opts) == 0 {
+		return Options{}
+	}
+	return opts[len(opts)-1]
+}
 
-	result := &WalletScanResult{
-		WalletAddress: walletAddress,
-		ScanTimestamp: time.Now().Unix(),
-		ChainsScanned: chains,
-		Approvals:     []Approval{},
-		ContractRisks: []ContractRisk{},
+// logger returns the scanner's logger
+func (s *Scanner) logger() Logger {
+	return loggerOr(s.log)
+}
+
+// concurrency returns how many chains may be scanned at once
+func (s *Scanner) concurrency() int {
+	if s.maxConcurrency <= 0 {
+		return defaultMaxConcurrency
 	}
+	return s.maxConcurrency
+}
 
-	// Sequential scan with rate limiting
-	// Alchemy supports 25 req/sec, Etherscan free tier 5 req/sec
-	// Using 100ms as safe middle ground
+// scanCacheKey identifies a scan by wallet and chain set (chain order doesn't matter;
+// case only matters for Solana addresses, see historyKey).
+// Scans that skipped the Tornado Cash check are cached apart from complete ones.
+func scanCacheKey(walletAddress string, chains []ChainID, tornadoSkipped bool) string {
+	sorted := make([]string, len(chains))
 	for i, chain := range chains {
-		// Rate limit: wait 100ms between calls (except first)
-		if i > 0 {
-			time.Sleep(100 * time.Millisecond)
+		sorted[i] = string(chain)
+	}
+	sort.Strings(sorted)
+
+	key := historyKey(walletAddress) + strings.Join(sorted, ",")
+	if tornadoSkipped {
+		key += ";skipTCCheck"
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "scan:" + hex.EncodeToString(sum[:])
+}
+
+// ScanWallet scans chains in parallel (up to maxConcurrency at once). Explorer calls are
+// rate limited per chain by each client's token bucket.
+// Results are cached for config.CacheTTL unless forceRefresh is set.
+func (s *Scanner) ScanWallet(ctx context.Context, walletAddress string, chains []ChainID, forceRefresh bool) (*WalletScanResult, error) {
+	return s.ScanWalletStream(ctx, walletAddress, chains, forceRefresh, nil)
+}
+
+// ScanWalletStream scans like ScanWallet and calls onChain as each chain finishes.
+// onChain is called from the scanning goroutines and may be nil.
+func (s *Scanner) ScanWalletStream(ctx context.Context, walletAddress string, chains []ChainID, forceRefresh bool, onChain func(ChainScanUpdate)) (*WalletScanResult, error) {
+	ctx, span := tracer.Start(ctx, "Scanner.ScanWallet", trace.WithAttributes(
+		attribute.String("wallet", walletAddress),
+		attribute.Int("chains", len(chains)),
+	))
+	defer span.End()
+
+	result, err := s.scanWallet(ctx, walletAddress, chains, forceRefresh, onChain)
+	if err == nil {
+		span.SetAttributes(
+			attribute.Int("approvals_found", len(result.Approvals)),
+			attribute.Bool("cache_hit", result.CacheHit),
+		)
+	}
+	return result, endSpan(span, err)
+}
+
+func (s *Scanner) scanWallet(ctx context.Context, walletAddress string, chains []ChainID, forceRefresh bool, onChain func(ChainScanUpdate)) (*WalletScanResult, error) {
+	cacheKey := scanCacheKey(walletAddress, chains, tornadoCheckSkipped(ctx))
+	if !forceRefresh {
+		cached, ok := s.cache.Get(cacheKey)
+		traceCacheLookup(ctx, cacheKey, ok)
+		if ok {
+			hit := *cached.(*WalletScanResult)
+			hit.Approvals = append([]Approval(nil), hit.Approvals...)
+			hit.CacheHit = true
+			requestLogger(ctx, s.logger()).Info("Serving cached scan", Fields{"wallet": walletAddress, "chains": len(chains)})
+			if onChain != nil {
+				for _, update := range chainUpdates(&hit) {
+					onChain(update)
+				}
+			}
+			return &hit, nil
 		}
+	}
+
+	activeScans.Inc()
+	defer activeScans.Dec()
+	scanStart := time.Now()
+
+	label, _ := walletLabels.Get(walletAddress)
+	requestLogger(ctx, s.logger()).Info("Starting multi-chain scan", Fields{"wallet": walletAddress, "label": label, "chains": len(chains)})
+
+	// Resolve the wallet's identity alongside the chain scans
+	identityDone := make(chan WalletIdentity, 1)
+	go func() { identityDone <- s.walletIdentity(ctx, walletAddress, chains) }()
 
+	result := &WalletScanResult{
+		WalletAddress:  walletAddress,
+		IsDAOTreasury:  isDAOTreasury(walletAddress),
+		ScanTimestamp:  s.clock.Now().Unix(),
+		CachedAt:       s.clock.Now().Unix(),
+		ChainsScanned:  chains,
+		Approvals:      []Approval{},
+		ContractRisks:  []ContractRisk{},
+		ChainScanStats: make(map[ChainID]ChainResult, len(chains)),
+	}
+
+	// Scan chains in parallel. Each goroutine fills its own slot so approvals keep the
+	// requested chain order; shared result fields are written under resultMu.
+	type chainScan struct {
+		approvals   []Approval
+		truncated   bool
+		totalEvents int
+	}
+	type chainError struct {
+		chain ChainID
+		err   error
+	}
+	scans := make([]chainScan, len(chains))
+	errs := make(chan chainError, len(chains))
+	sem := make(chan struct{}, s.concurrency())
+	var resultMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, chain := range chains {
+		// fetch gets the chain's approvals and annotate enriches them
+		var (
+			fetch    func() (*ChainApprovals, error)
+			annotate func([]Approval)
+			skip     string
+		)
+		chainsMu.RLock()
 		client, ok := s.clients[chain]
-		if !ok {
-			log.Printf("No client for chain %s", chain)
+		chainsMu.RUnlock()
+		switch {
+		case !chainSupportsWallet(chain, walletAddress):
+			skip = "wallet address format not supported on chain"
+		case chain == Solana && s.solana != nil:
+			fetch = func() (*ChainApprovals, error) { return s.solana.GetApprovals(ctx, walletAddress) }
+			annotate = func([]Approval) {} // token accounts carry no approval time
+		case ok:
+			// Get approvals, only from blocks added since the previous scan when possible
+			fetch = func() (*ChainApprovals, error) { return s.fetchApprovals(ctx, client, walletAddress, forceRefresh) }
+			annotate = func(approvals []Approval) { s.annotateChainApprovals(ctx, client, walletAddress, approvals) }
+		default:
+			requestLogger(ctx, s.logger()).Warn("No client for chain", Fields{"chain": chain, "wallet": walletAddress})
+			skip = "no client configured for chain"
+		}
+		if skip != "" {
+			resultMu.Lock()
+			result.ChainScanStats[chain] = ChainResult{Error: skip}
+			resultMu.Unlock()
+			if onChain != nil {
+				onChain(ChainScanUpdate{Chain: chain, Error: skip})
+			}
 			continue
 		}
 
-		// Get approvals
-		approvals, err := client.GetApprovals(ctx, walletAddress)
-		if err != nil {
-			log.Printf("Error scanning %s: %v", chain, err)
-			continue
+		wg.Add(1)
+		go func(i int, chain ChainID, fetch func() (*ChainApprovals, error), annotate func([]Approval)) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			started := time.Now()
+			chainResult, err := fetch()
+			if errors.Is(err, ErrCircuitOpen) {
+				requestLogger(ctx, s.logger()).Warn("Provider circuit open, skipping chain", Fields{"chain": chain, "wallet": walletAddress})
+				resultMu.Lock()
+				result.ChainScanStats[chain] = ChainResult{ScanDuration: time.Since(started), Skipped: true}
+				resultMu.Unlock()
+				if onChain != nil {
+					onChain(ChainScanUpdate{Chain: chain})
+				}
+				return
+			}
+			if err != nil {
+				resultMu.Lock()
+				result.ChainScanStats[chain] = ChainResult{
+					ScanDuration: time.Since(started),
+					Error:        redactURLs(err.Error()),
+				}
+				resultMu.Unlock()
+				errs <- chainError{chain: chain, err: err}
+				if onChain != nil {
+					onChain(ChainScanUpdate{Chain: chain, Error: redactURLs(err.Error())})
+				}
+				return
+			}
+
+			annotate(chainResult.Approvals)
+			if onChain != nil {
+				// Score a copy so streamed approvals carry their final risk levels
+				scored := &WalletScanResult{Approvals: copyApprovals(chainResult.Approvals)}
+				s.calculateRiskScores(scored)
+				onChain(ChainScanUpdate{Chain: chain, Approvals: scored.Approvals})
+			}
+
+			scans[i] = chainScan{
+				approvals:   chainResult.Approvals,
+				truncated:   chainResult.Truncated,
+				totalEvents: chainResult.TotalEvents,
+			}
+			resultMu.Lock()
+			result.ChainScanStats[chain] = ChainResult{
+				ApprovalsFound: len(chainResult.Approvals),
+				ScanDuration:   time.Since(started),
+				Source:         chainResult.Source,
+			}
+			resultMu.Unlock()
+		}(i, chain, fetch, annotate)
+	}
+	wg.Wait()
+	close(errs)
+
+	for chainErr := range errs {
+		requestLogger(ctx, s.logger()).Error("Chain scan failed", Fields{"chain": chainErr.chain, "wallet": walletAddress, "error": errorText(chainErr.err)})
+	}
+	for i, chain := range chains {
+		result.Approvals = append(result.Approvals, scans[i].approvals...)
+		if scans[i].truncated {
+			result.TruncatedChains = append(result.TruncatedChains, chain)
+			if result.truncatedTotals == nil {
+				result.truncatedTotals = make(map[ChainID]int)
+			}
+			result.truncatedTotals[chain] = scans[i].totalEvents
 		}
+	}
+
+	result.Identity = <-identityDone
+	result.WalletENS = result.Identity.ENSName
+	result.WalletLabel = walletLabel(walletAddress, result.Identity.ENSName)
 
-		result.Approvals = append(result.Approvals, approvals...)
+	// Balance and wallet type drive the hardware wallet recommendation
+	if slices.Contains(chains, Ethereum) {
+		chainsMu.RLock()
+		client, ok := s.clients[Ethereum]
+		chainsMu.RUnlock()
+		if ok {
+			client.profileWallet(ctx, result)
+			s.checkTornadoCash(ctx, client, result)
+		}
 	}
 
+	if s.state != nil {
+		if err := s.state.Save(); err != nil {
+			requestLogger(ctx, s.logger()).Error("Failed to persist scan state", Fields{"error": errorText(err)})
+		}
+	}
+
+	// Screen spenders the bundled sanctions list doesn't cover with the live provider
+	providerSanctioned := s.screenSpenders(ctx, result.Approvals)
+
 	// Calculate risk scores
 	s.calculateRiskScores(result)
 
+	// Aggregate exposure per protocol (after scoring so risk levels are final)
+	result.ProtocolExposures = buildProtocolExposures(result.Approvals)
+	result.SanctionedAddresses = sanctionedSpenders(result.Approvals)
+	provider := ""
+	if s.sanctions != nil {
+		provider = s.sanctions.Name()
+	}
+	result.ContractRisks = append(result.ContractRisks, sanctionedContractRisks(result.Approvals, providerSanctioned, provider)...)
+	for _, exposure := range result.ProtocolExposures {
+		result.TotalValueAtRisk += exposure.totalUSD
+	}
+
 	// Generate recommendations
 	s.generateRecommendations(result)
 
-	log.Printf("Scan complete: %d approvals, %d critical risks",
-		len(result.Approvals), result.CriticalRisks)
+	requestLogger(ctx, s.logger()).Info("Scan complete", Fields{
+		"wallet":          walletAddress,
+		"approvals_found": len(result.Approvals),
+		"critical_risks":  result.CriticalRisks,
+		"duration_ms":     time.Since(scanStart).Milliseconds(),
+	})
+	s.recordHistory(result)
+	observeApprovals(result.Approvals)
+	s.webhooks.Dispatch(result)
+
+	// Only cache complete scans so transient chain failures aren't served for a whole TTL
+	if scanComplete(result) {
+		cached := *result
+		cached.Approvals = append([]Approval(nil), result.Approvals...)
+		s.cache.Set(cacheKey, &cached)
+	}
 
 	return result, nil
 }
 
-func (s *Scanner) calculateRiskScores(result *WalletScanResult) {
-	totalRisk := 0
+// scanComplete reports whether every chain of a scan succeeded
+func scanComplete(result *WalletScanResult) bool {
+	for _, stats := range result.ChainScanStats {
+		if stats.Error != "" || stats.Skipped {
+			return false
+		}
+	}
+	return true
+}
 
-	// First pass: calculate individual risk scores and update levels
-	for i, approval := range result.Approvals {
-		riskScore := 0
-		initialRiskLevel := approval.RiskLevel
-		isTrustedProtocol := initialRiskLevel == "safe"
-		isDrainer := initialRiskLevel == "critical" // Known drainer/scam
+// annotateChainApprovals enriches one chain's approvals with labels, token info and
+// on-chain risk signals
+func (s *Scanner) annotateChainApprovals(ctx context.Context, client *ChainClient, walletAddress string, approvals []Approval) {
+	// Price lookups run alongside the rest of the enrichment
+	applyPrices := s.prices.priceApprovals(approvals)
+	defer applyPrices()
 
-		// Base risk from initial approval level
-		switch initialRiskLevel {
-		case "critical":
-			riskScore = 50 // Drainer = very high base score
-			result.Approvals[i].RiskReasons = append(result.Approvals[i].RiskReasons, "🚨 Known malicious contract")
-		case "warning":
-			riskScore = 15 // Unknown or Relay-type
-		case "safe":
-			riskScore = 2 // Trusted protocol
-		}
-
-		// Additional risk factors
-		if approval.IsUnlimited {
-			if isDrainer {
-				riskScore += 20 // Drainer + unlimited = maximum danger
-				result.Approvals[i].RiskReasons = append(result.Approvals[i].RiskReasons, "Unlimited allowance to dangerous contract!")
-			} else if isTrustedProtocol {
-				riskScore += 8 // Trusted + unlimited = just a warning
-				result.Approvals[i].RiskReasons = append(result.Approvals[i].RiskReasons, "Unlimited allowance (consider reducing)")
-			} else {
-				riskScore += 15 // Unknown + unlimited = suspicious
-				result.Approvals[i].RiskReasons = append(result.Approvals[i].RiskReasons, "Unlimited allowance to unknown contract")
-			}
+	// Date approvals by their transaction's block; undated ones keep 0 (unknown age)
+	client.annotateBlockTimestamps(ctx, walletAddress, approvals)
+	for i := range approvals {
+		if label, ok := walletLabels.Get(approvals[i].SpenderAddress); ok {
+			approvals[i].SpenderLabel = label
 		}
-
-		if strings.HasPrefix(approval.SpenderName, "0x") || approval.SpenderName == "Unknown" {
-			riskScore += 10
-			result.Approvals[i].RiskReasons = append(result.Approvals[i].RiskReasons, "Unknown spender contract")
+	}
+	client.annotateSpenderENS(ctx, approvals)
+	client.annotateTokenInfo(ctx, approvals)
+	client.annotateUniswapV4Hooks(ctx, walletAddress, approvals)
+	client.checkPendleExpiry(ctx, approvals, s.clock.Now().Unix())
+	client.annotatePermitSupport(ctx, walletAddress, approvals)
+	if s.classifier != nil {
+		s.classifier.ClassifyApprovals(ctx, client, approvals)
+	}
+	if s.spenderStats != nil {
+		s.spenderStats.EnrichApprovals(ctx, client, approvals)
+	}
+	if s.trust != nil {
+		s.trust.ScoreApprovals(ctx, client, approvals)
+	}
+	if len(approvals) > 0 {
+		txTargets := client.analyzeTransferFromUsage(ctx, walletAddress, approvals)
+		if s.volume != nil {
+			s.volume.AnalyzeApprovals(ctx, client, walletAddress, approvals, txTargets)
 		}
+	}
+}
 
-		// SMART RISK LEVEL ASSIGNMENT:
-		// critical = ONLY for actual dangerous situations
-		// warning = unlimited on trusted OR any unknown
-		// safe = limited approval on trusted protocol
-		if isDrainer {
-			// Keep critical - this is a real threat
+func (s *Scanner) calculateRiskScores(result *WalletScanResult) {
+	totalRisk := 0
+
+	// First pass: score each approval with the risk rules and update levels.
+	// The rules (rules/default.json or RULES_FILE) encode the SMART RISK LEVEL ASSIGNMENT:
+	// critical = ONLY for actual dangerous situations
+	// warning = unlimited on trusted OR any unknown
+	// safe = limited approval on trusted protocol
+	now := s.clock.Now()
+	for i := range result.Approvals {
+		result.Approvals[i].ageDays = approvalAgeDays(result.Approvals[i], now)
+		result.Approvals[i].recentlyUsed = isRecentlyUsed(result.Approvals[i], now)
+		// Superchain bridge addresses are only trusted on the chains they're deployed on
+		crossCheckSuperchainBridge(&result.Approvals[i])
+		annotateMEVBot(&result.Approvals[i])
+		applyRiskOverride(&result.Approvals[i])
+		outcome := riskRules.Evaluate(result.Approvals[i])
+
+		result.Approvals[i].RiskReasons = append(result.Approvals[i].RiskReasons, outcome.Reasons...)
+		if outcome.RiskLevel != "" {
+			result.Approvals[i].RiskLevel = outcome.RiskLevel
+		}
+		// ApprovalForAll hands over the whole collection, whatever its allowance fields say
+		if result.Approvals[i].IsNFT && isUnknownSpender(result.Approvals[i]) {
 			result.Approvals[i].RiskLevel = "critical"
-		} else if isTrustedProtocol {
-			if approval.IsUnlimited {
-				// Trusted + unlimited = warning (yellow), not critical
-				result.Approvals[i].RiskLevel = "warning"
-			} else {
-				// Trusted + limited = safe (green)
-				result.Approvals[i].RiskLevel = "safe"
-			}
-		} else {
-			// Unknown contract
-			if approval.IsUnlimited {
-				// Unknown + unlimited = critical (could be dangerous)
-				result.Approvals[i].RiskLevel = "critical"
-			} else {
-				// Unknown + limited = warning
-				result.Approvals[i].RiskLevel = "warning"
-			}
 		}
 
-		totalRisk += riskScore
+		totalRisk += outcome.RiskPoints
 	}
 
 	// Second pass: count final risk levels (no double counting!)
@@ -1295,13 +2411,48 @@ func (s *Scanner) calculateRiskScores(result *WalletScanResult) {
 	result.OverallRiskScore = min(100, totalRisk)
 }
 
+// Approval-count recommendation thresholds for a chain of maturity weight 1.0
+const (
+	manyApprovalsThreshold        = 20
+	consolidateApprovalsThreshold = 10
+)
+
 func (s *Scanner) generateRecommendations(result *WalletScanResult) {
 	recommendations := []string{}
 
-	// Critical risk recommendations
-	if result.CriticalRisks > 0 {
+	// Truncated scans are incomplete - say so before anything else
+	for _, chain := range result.TruncatedChains {
+		recommendations = append(recommendations,
+			fmt.Sprintf("⚠️ Results truncated for %s: showing %s most recent of %s approvals",
+				chainDisplayName(chain), formatCount(config.MaxApprovalsPerChain), formatCount(result.truncatedTotals[chain])))
+	}
+
+	if result.HasTornadoCashHistory {
+		recommendations = append(recommendations, tornadoCashWarning)
+	}
+
+	// High-exposure protocols
+	for _, exposure := range result.ProtocolExposures {
+		if exposure.totalUSD < highExposureUSD {
+			break // sorted by exposure
+		}
 		recommendations = append(recommendations,
-			fmt.Sprintf("🚨 URGENT: Revoke %d critical approvals immediately", result.CriticalRisks))
+			fmt.Sprintf("💰 You have %s exposure to %s across %d tokens",
+				formatUSDCompact(exposure.totalUSD), exposure.Protocol, exposure.TokenCount))
+	}
+
+	recommendations = append(recommendations, hardwareWalletRecommendations(result)...)
+
+	// Critical risk recommendations. A DAO treasury can only revoke through governance.
+	if result.CriticalRisks > 0 {
+		if result.IsDAOTreasury {
+			recommendations = append(recommendations,
+				fmt.Sprintf("🚨 URGENT: Submit a governance proposal to revoke %d critical approvals", result.CriticalRisks))
+			result.GovernanceProposalTemplate = governanceProposalTemplate(result)
+		} else {
+			recommendations = append(recommendations,
+				fmt.Sprintf("🚨 URGENT: Revoke %d critical approvals immediately", result.CriticalRisks))
+		}
 	}
 
 	// Unlimited approval recommendations
@@ -1335,8 +2486,19 @@ func (s *Scanner) generateRecommendations(result *WalletScanResult) {
 			fmt.Sprintf("🔍 %d approvals are to unknown contracts. Verify these are legitimate.", unknownCount))
 	}
 
+	// Approval counts are normalized by chain maturity: a chain with weight w allows
+	// threshold/w approvals, so long-lived Ethereum wallets aren't flagged for their age
+	chainApprovalCount := make(map[ChainID]int)
+	for _, a := range result.Approvals {
+		chainApprovalCount[a.Chain]++
+	}
+
 	// General security tips
-	if result.TotalApprovals > 20 {
+	weightedApprovals := 0.0
+	for chain, count := range chainApprovalCount {
+		weightedApprovals += float64(count) * chainMaturityWeight(chain)
+	}
+	if weightedApprovals > manyApprovalsThreshold {
 		recommendations = append(recommendations,
 			"📝 You have many active approvals. Consider periodic cleanup of unused ones.")
 	}
@@ -1347,18 +2509,18 @@ func (s *Scanner) generateRecommendations(result *WalletScanResult) {
 	}
 
 	// Chain-specific recommendations
-	chainApprovalCount := make(map[ChainID]int)
-	for _, a := range result.Approvals {
-		chainApprovalCount[a.Chain]++
-	}
-
 	for chain, count := range chainApprovalCount {
-		if count > 10 {
+		if float64(count) > consolidateApprovalsThreshold/chainMaturityWeight(chain) {
 			recommendations = append(recommendations,
 				fmt.Sprintf("📊 %d approvals on %s - consider consolidating", count, chain))
 		}
 	}
 
+	// Show labelled wallets by name rather than raw address
+	for i, rec := range recommendations {
+		recommendations[i] = walletLabels.Apply(rec)
+	}
+
 	result.Recommendations = recommendations
 }
 
@@ -1366,10 +2528,23 @@ func (s *Scanner) generateRecommendations(result *WalletScanResult) {
 //                          SERVICE CLIENTS
 // ═══════════════════════════════════════════════════════════════════════════════
 
+// DecompilerService decompiles contract bytecode; DecompilerClient is the HTTP implementation
+type DecompilerService interface {
+	Analyze(ctx context.Context, bytecode []byte) (*DecompilerResponse, error)
+}
+
+// AnalyzerService scores contract security; AnalyzerClient is the HTTP implementation
+type AnalyzerService interface {
+	Analyze(ctx context.Context, address string, chain string, bytecode []byte) (*AnalyzerResponse, error)
+}
+
 // DecompilerClient connects to the Rust decompiler service
 type DecompilerClient struct {
 	baseURL string
 	client  *http.Client
+	// grpc is preferred over HTTP when DECOMPILER_GRPC_URL is set
+	grpc sentinelv1.DecompilerServiceClient
+	log  Logger
 }
 
 // DecompilerResponse represents the decompiler analysis result
@@ -1385,20 +2560,45 @@ type DecompilerResponse struct {
 	Warnings   []string `json:"warnings"`
 }
 
-func NewDecompilerClient() *DecompilerClient {
+func NewDecompilerClient(logger Logger) *DecompilerClient {
 	decompilerURL := os.Getenv("DECOMPILER_URL")
 	if decompilerURL == "" {
 		decompilerURL = "http://localhost:3000"
 	}
 	return &DecompilerClient{
 		baseURL: decompilerURL,
-		client:  &http.Client{Timeout: 60 * time.Second},
+		client:  &http.Client{Transport: sharedServiceTransport(), Timeout: 60 * time.Second},
+		grpc:    newDecompilerGRPCClient(logger),
+		log:     logger,
 	}
 }
 
+// logger returns the client's logger
+func (d *DecompilerClient) logger() Logger {
+	return loggerOr(d.log)
+}
+
 // Analyze sends bytecode to the Rust decompiler for analysis
 func (d *DecompilerClient) Analyze(ctx context.Context, bytecode []byte) (*DecompilerResponse, error) {
-	log.Printf("Sending %d bytes to decompiler", len(bytecode))
+	ctx, span := tracer.Start(ctx, "DecompilerClient.Analyze", trace.WithAttributes(
+		attribute.Int("bytecode_size", len(bytecode)),
+	))
+	defer span.End()
+
+	result, err := d.analyze(ctx, bytecode)
+	return result, endSpan(span, err)
+}
+
+func (d *DecompilerClient) analyze(ctx context.Context, bytecode []byte) (*DecompilerResponse, error) {
+	requestLogger(ctx, d.logger()).Debug("Sending bytecode to decompiler", Fields{"bytes": len(bytecode)})
+
+	if d.grpc != nil {
+		result, err := d.decompileGRPC(ctx, bytecode)
+		if err == nil {
+			return result, nil
+		}
+		requestLogger(ctx, d.logger()).Warn("Decompiler gRPC failed, falling back to HTTP", Fields{"error": errorText(err)})
+	}
 
 	reqBody := map[string]interface{}{
 		"bytecode": hex.EncodeToString(bytecode),
@@ -1413,6 +2613,8 @@ func (d *DecompilerClient) Analyze(ctx context.Context, bytecode []byte) (*Decom
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	injectTraceContext(ctx, req)
+	setRequestIDHeader(ctx, req)
 
 	resp, err := d.client.Do(req)
 	if err != nil {
@@ -1437,6 +2639,7 @@ func (d *DecompilerClient) Analyze(ctx context.Context, bytecode []byte) (*Decom
 type AnalyzerClient struct {
 	baseURL string
 	client  *http.Client
+	log     Logger
 }
 
 // AnalyzerResponse represents the security analysis result
@@ -1462,20 +2665,37 @@ type PatternMatch struct {
 	IsMalicious bool   `json:"is_malicious"`
 }
 
-func NewAnalyzerClient() *AnalyzerClient {
+func NewAnalyzerClient(logger Logger) *AnalyzerClient {
 	analyzerURL := os.Getenv("ANALYZER_URL")
 	if analyzerURL == "" {
 		analyzerURL = "http://localhost:5000"
 	}
 	return &AnalyzerClient{
 		baseURL: analyzerURL,
-		client:  &http.Client{Timeout: 60 * time.Second},
+		client:  &http.Client{Transport: sharedServiceTransport(), Timeout: 60 * time.Second},
+		log:     logger,
 	}
 }
 
+// logger returns the client's logger
+func (a *AnalyzerClient) logger() Logger {
+	return loggerOr(a.log)
+}
+
 // Analyze sends contract data to the Python analyzer for security scoring
 func (a *AnalyzerClient) Analyze(ctx context.Context, address string, chain string, bytecode []byte) (*AnalyzerResponse, error) {
-	log.Printf("Sending contract %s to analyzer", address)
+	ctx, span := tracer.Start(ctx, "AnalyzerClient.Analyze", trace.WithAttributes(
+		attribute.String("chain", chain),
+		attribute.String("contract", address),
+	))
+	defer span.End()
+
+	result, err := a.analyze(ctx, address, chain, bytecode)
+	return result, endSpan(span, err)
+}
+
+func (a *AnalyzerClient) analyze(ctx context.Context, address string, chain string, bytecode []byte) (*AnalyzerResponse, error) {
+	requestLogger(ctx, a.logger()).Debug("Sending contract to analyzer", Fields{"chain": chain, "contract": address})
 
 	reqBody := map[string]interface{}{
 		"address":  address,
@@ -1492,6 +2712,8 @@ func (a *AnalyzerClient) Analyze(ctx context.Context, address string, chain stri
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	injectTraceContext(ctx, req)
+	setRequestIDHeader(ctx, req)
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -1525,39 +2747,100 @@ type ContractAnalysisResult struct {
 	SecurityReport *AnalyzerResponse   `json:"security_report"`
 	OverallRisk    int                 `json:"overall_risk"`
 	AnalyzedAt     int64               `json:"analyzed_at"`
-}
+	// Set when HONEYPOT_CHECK=true and the token could be test-traded
+	Honeypot     *HoneypotResult `json:"honeypot,omitempty"`
+	ContractRisk *ContractRisk   `json:"contract_risk,omitempty"`
+	// DiamondFacets lists the facets of an EIP-2535 Diamond; OverallRisk includes theirs
+	DiamondFacets []string `json:"diamond_facets,omitempty"`
+	// Deployment, from the explorer's contract creation record; all zero when unavailable
+	DeployerAddress string `json:"deployer_address,omitempty"`
+	CreatedAt       int64  `json:"created_at,omitempty"`
+	AgeInDays       int    `json:"age_in_days"`
+	// Verification is the explorer's verified source record; nil when unverified or unknown
+	Verification *VerificationInfo `json:"verification,omitempty"`
+}
+
+// Contracts deployed less than youngContractAge ago get youngContractPenalty risk points
+const (
+	youngContractAge     = 7 * 24 * time.Hour
+	youngContractPenalty = 15
+)
 
 // ContractAnalyzer orchestrates decompiler + analyzer
 type ContractAnalyzer struct {
 	chainClients map[ChainID]*ChainClient
-	decompiler   *DecompilerClient
-	analyzer     *AnalyzerClient
-	cache        *Cache
+	decompiler   DecompilerService
+	analyzer     AnalyzerService
+	cache        CacheStore
+	patterns     *BytecodePatternMatcher
+	honeypot     *HoneypotChecker   // nil = honeypot checks disabled
+	liquidity    *LiquidityAnalyzer // nil = liquidity checks disabled
+	sanctions    SanctionsAPIClient // nil = bundled sanctions list only
+	log          Logger
 }
 
-func NewContractAnalyzer(chainClients map[ChainID]*ChainClient) *ContractAnalyzer {
-	return &ContractAnalyzer{
+func NewContractAnalyzer(chainClients map[ChainID]*ChainClient, logger Logger) *ContractAnalyzer {
+	return NewContractAnalyzerWithServices(chainClients, NewDecompilerClient(logger), NewAnalyzerClient(logger), logger)
+}
+
+// NewContractAnalyzerWithServices creates an analyzer backed by the given decompiler and
+// analyzer, e.g. mocks in tests
+func NewContractAnalyzerWithServices(chainClients map[ChainID]*ChainClient, decompiler DecompilerService, analyzer AnalyzerService, logger Logger) *ContractAnalyzer {
+	ca := &ContractAnalyzer{
 		chainClients: chainClients,
-		decompiler:   NewDecompilerClient(),
-		analyzer:     NewAnalyzerClient(),
+		decompiler:   decompiler,
+		analyzer:     analyzer,
 		cache:        NewCache(10 * time.Minute),
+		patterns:     NewBytecodePatternMatcher(bytecodePatternRules),
+		sanctions:    sanctionsAPI,
+		log:          logger,
+	}
+	if os.Getenv("HONEYPOT_CHECK") == "true" {
+		ca.honeypot = NewHoneypotChecker(chainClients)
 	}
+	if os.Getenv("LIQUIDITY_CHECK") == "true" {
+		ca.liquidity = NewLiquidityAnalyzer(chainClients)
+	}
+	return ca
+}
+
+// logger returns the analyzer's logger
+func (ca *ContractAnalyzer) logger() Logger {
+	return loggerOr(ca.log)
 }
 
 // AnalyzeContract performs full analysis pipeline
 func (ca *ContractAnalyzer) AnalyzeContract(ctx context.Context, address string, chain ChainID) (*ContractAnalysisResult, error) {
+	ctx, span := tracer.Start(ctx, "ContractAnalyzer.AnalyzeContract", trace.WithAttributes(
+		attribute.String("chain", string(chain)),
+		attribute.String("contract", address),
+	))
+	defer span.End()
+
+	result, err := ca.analyzeContract(ctx, address, chain, true)
+	return result, endSpan(span, err)
+}
+
+// analyzeContract runs the pipeline; recursive analyzes the facets of a Diamond and the
+// implementation of a clone too, which is off for those themselves
+func (ca *ContractAnalyzer) analyzeContract(ctx context.Context, address string, chain ChainID, recursive bool) (*ContractAnalysisResult, error) {
 	cacheKey := fmt.Sprintf("analysis:%s:%s", chain, address)
 
 	// Check cache
-	if cached, ok := ca.cache.Get(cacheKey); ok {
-		log.Printf("Cache hit for %s on %s", address, chain)
+	cached, ok := ca.cache.Get(cacheKey)
+	traceCacheLookup(ctx, cacheKey, ok)
+	if ok {
+		requestLogger(ctx, ca.logger()).Debug("Serving cached analysis", Fields{"chain": chain, "contract": address})
 		return cached.(*ContractAnalysisResult), nil
 	}
 
-	log.Printf("Starting full analysis for %s on %s", address, chain)
+	requestLogger(ctx, ca.logger()).Info("Starting full analysis", Fields{"chain": chain, "contract": address})
+	start := time.Now()
 
 	// Step 1: Fetch bytecode
+	chainsMu.RLock()
 	client, ok := ca.chainClients[chain]
+	chainsMu.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("unsupported chain: %s", chain)
 	}
@@ -1581,7 +2864,7 @@ func (ca *ContractAnalyzer) AnalyzeContract(ctx context.Context, address string,
 	// Step 2: Decompile (non-blocking errors)
 	decompResult, err := ca.decompiler.Analyze(ctx, bytecode)
 	if err != nil {
-		log.Printf("Decompiler warning: %v", err)
+		requestLogger(ctx, ca.logger()).Warn("Decompiler failed", Fields{"chain": chain, "contract": address, "error": errorText(err)})
 	} else {
 		result.Decompilation = decompResult
 	}
@@ -1589,40 +2872,316 @@ func (ca *ContractAnalyzer) AnalyzeContract(ctx context.Context, address string,
 	// Step 3: Security analysis (non-blocking errors)
 	analyzerResult, err := ca.analyzer.Analyze(ctx, address, string(chain), bytecode)
 	if err != nil {
-		log.Printf("Analyzer warning: %v", err)
+		requestLogger(ctx, ca.logger()).Warn("Analyzer failed", Fields{"chain": chain, "contract": address, "error": errorText(err)})
 	} else {
 		result.SecurityReport = analyzerResult
 		result.OverallRisk = analyzerResult.RiskScore
 	}
 
+	// Step 4: Simulated buy and sell (optional, non-blocking errors)
+	if ca.honeypot != nil {
+		honeypot, err := ca.honeypot.Check(ctx, address, chain)
+		if err != nil {
+			requestLogger(ctx, ca.logger()).Debug("Honeypot check skipped", Fields{"chain": chain, "contract": address, "error": errorText(err)})
+		} else {
+			result.Honeypot = honeypot
+			result.ContractRisk = honeypot.contractRisk(address, chain)
+			if honeypot.IsHoneypot {
+				result.OverallRisk = 100
+			}
+			result.ContractRisk.RiskScore = result.OverallRisk
+		}
+	}
+
+	// Step 5: Opcode sequences of known exploit constructs
+	if matches := ca.patterns.Match(disassemble(bytecode)); len(matches) > 0 {
+		if result.ContractRisk == nil {
+			result.ContractRisk = &ContractRisk{
+				Address:   strings.ToLower(address),
+				Chain:     chain,
+				RiskScore: result.OverallRisk,
+				RiskLevel: "safe",
+			}
+		}
+		for _, match := range matches {
+			result.ContractRisk.Vulnerabilities = append(result.ContractRisk.Vulnerabilities, match.vulnerability())
+			if match.Rule.Severity == "critical" || result.ContractRisk.RiskLevel == "safe" {
+				result.ContractRisk.RiskLevel = match.Rule.Severity
+			}
+		}
+	}
+
+	// Step 6: SELFDESTRUCT, which lets whoever controls the owner wipe funds and storage
+	if hasSelfDestruct(bytecode) {
+		result.OverallRisk = min(result.OverallRisk+selfDestructPenalty, 100)
+		if result.ContractRisk == nil {
+			result.ContractRisk = &ContractRisk{
+				Address:   strings.ToLower(address),
+				Chain:     chain,
+				RiskLevel: "safe",
+			}
+		}
+		result.ContractRisk.HasSelfDestruct = true
+		result.ContractRisk.RiskScore = result.OverallRisk
+		result.ContractRisk.Vulnerabilities = append(result.ContractRisk.Vulnerabilities, selfDestructVulnerability)
+		if result.ContractRisk.RiskLevel == "safe" {
+			result.ContractRisk.RiskLevel = "warning"
+		}
+	}
+
+	// Step 7: Deployment age and deployer; freshly deployed contracts are riskier
+	if creation, err := client.getContractCreation(ctx, address); err != nil {
+		requestLogger(ctx, ca.logger()).Debug("Contract creation lookup failed", Fields{"chain": chain, "contract": address, "error": errorText(err)})
+	} else {
+		result.DeployerAddress = creation.Deployer
+		result.CreatedAt = creation.Timestamp
+		result.AgeInDays = int(time.Unix(result.AnalyzedAt, 0).Sub(time.Unix(creation.Timestamp, 0)).Hours() / 24)
+		if time.Unix(result.AnalyzedAt, 0).Sub(time.Unix(creation.Timestamp, 0)) < youngContractAge {
+			result.OverallRisk = min(result.OverallRisk+youngContractPenalty, 100)
+			if result.ContractRisk != nil {
+				result.ContractRisk.RiskScore = result.OverallRisk
+			}
+		}
+	}
+
+	// Step 8: Source verification; unverified contracts can't be audited by anyone
+	if verification, err := client.getSourceVerification(ctx, address); err != nil {
+		requestLogger(ctx, ca.logger()).Debug("Source verification lookup failed", Fields{"chain": chain, "contract": address, "error": errorText(err)})
+	} else {
+		result.Verification = verification
+		if verification == nil {
+			result.OverallRisk = min(result.OverallRisk+unverifiedContractPenalty, 100)
+		}
+		if result.ContractRisk == nil {
+			result.ContractRisk = &ContractRisk{
+				Address:   strings.ToLower(address),
+				Chain:     chain,
+				RiskLevel: "safe",
+			}
+		}
+		result.ContractRisk.IsVerified = verification != nil
+		result.ContractRisk.RiskScore = result.OverallRisk
+	}
+
+	// Step 9: Liquidity pool, LP locks and holder concentration (optional, non-blocking errors)
+	if ca.liquidity != nil {
+		liquidity, err := ca.liquidity.Analyze(ctx, address, chain)
+		if err != nil {
+			requestLogger(ctx, ca.logger()).Debug("Liquidity check skipped", Fields{"chain": chain, "contract": address, "error": errorText(err)})
+		} else {
+			if result.ContractRisk == nil {
+				result.ContractRisk = &ContractRisk{
+					Address:   strings.ToLower(address),
+					Chain:     chain,
+					RiskScore: result.OverallRisk,
+					RiskLevel: "safe",
+				}
+			}
+			result.ContractRisk.Liquidity = liquidity
+			if vulnerabilities := liquidity.vulnerabilities(); len(vulnerabilities) > 0 {
+				result.ContractRisk.Vulnerabilities = append(result.ContractRisk.Vulnerabilities, vulnerabilities...)
+				if result.ContractRisk.RiskLevel == "safe" {
+					result.ContractRisk.RiskLevel = "warning"
+				}
+			}
+		}
+	}
+
+	// Step 10: Proxies - EIP-1167 clones from their bytecode, others from their storage
+	// slots, so proxies are still caught when the decompiler is down
+	var proxy ProxyInfo
+	implementation, isClone := minimalProxyImplementation(bytecode)
+	if isClone {
+		proxy = ProxyInfo{IsProxy: true, Type: proxyTypeEIP1167, ImplementationAddress: implementation}
+	} else if proxy, err = client.DetectProxy(ctx, address); err != nil {
+		requestLogger(ctx, ca.logger()).Debug("Proxy detection failed", Fields{"chain": chain, "contract": address, "error": errorText(err)})
+	}
+	if proxy.IsProxy || (result.Decompilation != nil && result.Decompilation.IsProxy) {
+		if result.ContractRisk == nil {
+			result.ContractRisk = &ContractRisk{
+				Address:   strings.ToLower(address),
+				Chain:     chain,
+				RiskScore: result.OverallRisk,
+				RiskLevel: "safe",
+			}
+		}
+		result.ContractRisk.IsProxy = true
+		result.ContractRisk.ProxyType = proxy.Type
+		result.ContractRisk.ImplementationAddress = proxy.ImplementationAddress
+	}
+
+	// Step 11: A clone's risk is entirely its implementation's
+	if isClone && recursive {
+		ca.analyzeCloneImplementation(ctx, result, implementation)
+	}
+
+	// Step 12: Diamond facets, any of which can carry the malicious logic
+	if recursive {
+		ca.analyzeDiamondFacets(ctx, client, result)
+	}
+
+	// Step 13: Sanctions screening (bundled OFAC list, then the SANCTIONS_PROVIDER)
+	if source, sanctioned := sanctionSource(ctx, ca.sanctions, address, requestLogger(ctx, ca.logger())); sanctioned {
+		if result.ContractRisk == nil {
+			result.ContractRisk = &ContractRisk{Address: strings.ToLower(address), Chain: chain}
+		}
+		result.ContractRisk.IsSanctioned = true
+		result.ContractRisk.SanctionSource = source
+		result.ContractRisk.RiskScore = 100
+		result.ContractRisk.RiskLevel = "critical"
+		result.OverallRisk = 100
+	}
+
 	// Cache result
 	ca.cache.Set(cacheKey, result)
 
-	log.Printf("Analysis complete for %s: risk=%d, bytecode=%d bytes",
-		address, result.OverallRisk, result.BytecodeSize)
+	requestLogger(ctx, ca.logger()).Info("Analysis complete", Fields{
+		"chain":         chain,
+		"contract":      address,
+		"overall_risk":  result.OverallRisk,
+		"bytecode_size": result.BytecodeSize,
+		"duration_ms":   time.Since(start).Milliseconds(),
+	})
 
 	return result, nil
 }
 
+// analyzeCloneImplementation raises an EIP-1167 clone's OverallRisk to its
+// implementation's
+func (ca *ContractAnalyzer) analyzeCloneImplementation(ctx context.Context, result *ContractAnalysisResult, implementation string) {
+	implementationResult, err := ca.analyzeContract(ctx, implementation, result.Chain, false)
+	if err != nil {
+		requestLogger(ctx, ca.logger()).Debug("Clone implementation analysis failed", Fields{"chain": result.Chain, "implementation": implementation, "error": errorText(err)})
+		return
+	}
+	result.OverallRisk = max(result.OverallRisk, implementationResult.OverallRisk)
+	result.ContractRisk.RiskScore = result.OverallRisk
+}
+
+// analyzeDiamondFacets lists the facets of a Diamond and raises its OverallRisk to
+// the riskiest facet's
+func (ca *ContractAnalyzer) analyzeDiamondFacets(ctx context.Context, client *ChainClient, result *ContractAnalysisResult) {
+	facets, err := client.DiamondFacets(ctx, result.Address)
+	if err != nil {
+		requestLogger(ctx, ca.logger()).Debug("Diamond facet enumeration failed", Fields{"chain": result.Chain, "contract": result.Address, "error": errorText(err)})
+		return
+	}
+	if len(facets) == 0 {
+		return
+	}
+	result.DiamondFacets = facets
+	if len(facets) > maxDiamondFacets {
+		requestLogger(ctx, ca.logger()).Warn("Diamond has too many facets, analyzing the first ones", Fields{"chain": result.Chain, "contract": result.Address, "facets": len(facets)})
+		facets = facets[:maxDiamondFacets]
+	}
+
+	for _, facet := range facets {
+		if strings.EqualFold(facet, result.Address) {
+			continue // the loupe facet is often the Diamond itself
+		}
+		facetResult, err := ca.analyzeContract(ctx, facet, result.Chain, false)
+		if err != nil {
+			requestLogger(ctx, ca.logger()).Debug("Facet analysis failed", Fields{"chain": result.Chain, "facet": facet, "error": errorText(err)})
+			continue
+		}
+		result.OverallRisk = max(result.OverallRisk, facetResult.OverallRisk)
+	}
+	if result.ContractRisk != nil {
+		result.ContractRisk.RiskScore = result.OverallRisk
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                                  CLOCK
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// Clock abstracts the current time so cache expiry and scan timestamps are testable
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock reads the system clock
+type RealClock struct{}
+
+func (RealClock) Now() time.Time { return time.Now() }
+
+// MockClock is a manually controlled clock for tests
+type MockClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewMockClock(now time.Time) *MockClock {
+	return &MockClock{now: now}
+}
+
+func (m *MockClock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Advance moves the clock forward by d
+func (m *MockClock) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+// SetNow sets the clock to t
+func (m *MockClock) SetNow(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = t
+}
+
 // ═══════════════════════════════════════════════════════════════════════════════
 //                                  CACHE
 // ═══════════════════════════════════════════════════════════════════════════════
 
+// CacheStore is implemented by the in-memory Cache and the Redis-backed RedisCache
+type CacheStore interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
+}
+
 type Cache struct {
-	data map[string]cacheEntry
-	mu   sync.RWMutex
-	ttl  time.Duration
+	data  map[string]cacheEntry
+	mu    sync.RWMutex
+	ttl   time.Duration
+	clock Clock
+	// lastSweep is when SetWithTTL last dropped the expired entries
+	lastSweep time.Time
 }
 
+// cacheSweepInterval is how often SetWithTTL drops expired entries, which Get only skips
+const cacheSweepInterval = time.Minute
+
 type cacheEntry struct {
 	value     interface{}
 	expiresAt time.Time
 }
 
-func NewCache(ttl time.Duration) *Cache {
+// NewCache returns a RedisCache when REDIS_URL is set, otherwise an in-memory Cache
+func NewCache(ttl time.Duration) CacheStore {
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		cache, err := NewRedisCache(redisURL, ttl)
+		if err == nil {
+			return cache
+		}
+		defaultLogger.Warn("Redis cache disabled, using in-memory cache", Fields{"error": errorText(err)})
+	}
+	return NewCacheWithClock(ttl, RealClock{})
+}
+
+// NewCacheWithClock creates a cache whose expiry is measured with clock
+func NewCacheWithClock(ttl time.Duration, clock Clock) *Cache {
 	return &Cache{
-		data: make(map[string]cacheEntry),
-		ttl:  ttl,
+		data:      make(map[string]cacheEntry),
+		ttl:       ttl,
+		clock:     clock,
+		lastSweep: clock.Now(),
 	}
 }
 
@@ -1631,19 +3190,36 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 	defer c.mu.RUnlock()
 
 	entry, ok := c.data[key]
-	if !ok || time.Now().After(entry.expiresAt) {
+	if !ok || c.clock.Now().After(entry.expiresAt) {
+		observeCacheLookup(false)
 		return nil, false
 	}
+	observeCacheLookup(true)
 	return entry.value, true
 }
 
 func (c *Cache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores value for ttl instead of the cache's default TTL
+func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	now := c.clock.Now()
+	if now.Sub(c.lastSweep) >= cacheSweepInterval {
+		for k, entry := range c.data {
+			if now.After(entry.expiresAt) {
+				delete(c.data, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
 	c.data[key] = cacheEntry{
 		value:     value,
-		expiresAt: time.Now().Add(c.ttl),
+		expiresAt: now.Add(ttl),
 	}
 }
 
@@ -1655,49 +3231,94 @@ type Server struct {
 	scanner          ScannerService
 	contractAnalyzer *ContractAnalyzer
 	chainClients     map[ChainID]*ChainClient
-}
-
-func NewServer() *Server {
-	clients := make(map[ChainID]*ChainClient)
-	for chain, rpc := range config.RPC {
-		clients[ChainID(chain)] = NewChainClient(ChainID(chain), rpc)
-	}
-
+	feeMarket        *FeeMarketClient
+	revocationCost   *RevocationCostEstimator
+	jobs             *ScanJobQueue
+	webhooks         *WebhookDispatcher
+	wsScanInterval   time.Duration
+	watches          *watchLimiter
+	responses        *Cache // encoded scan responses and their ETags
+	log              Logger
+}
+
+func NewServer(logger Logger) *Server {
+	logger = loggerOr(logger)
+	clients := newChainClients(logger)
+
+	cache := NewCache(config.CacheTTL)
+	webhooks := NewWebhookDispatcherFromEnv(logger)
+	feeMarket := NewFeeMarketClient(clients)
 	scanner := &Scanner{
-		clients: clients,
-		cache:   NewCache(config.CacheTTL),
+		clients:    clients,
+		solana:     newSolanaClient(logger),
+		cache:      cache,
+		clock:      RealClock{},
+		trust:      NewTokenTrustScorer(RealClock{}),
+		state:      NewScanStateStore(getEnv("SCAN_STATE_FILE", "scan-state.json"), getEnvInt("SCAN_STATE_MAX_WALLETS", defaultScanStateWallets)),
+		classifier: NewContractClassifier(cache),
+		tornado:    NewTornadoCashChecker(RealClock{}),
+		sanctions:  sanctionsAPI,
+
+		maxConcurrency: getEnvInt("SCAN_MAX_CONCURRENCY", defaultMaxConcurrency),
+		history:        NewScanHistory(getEnvInt("SCAN_HISTORY_DEPTH", defaultHistoryDepth), getEnvInt("SCAN_HISTORY_MAX_WALLETS", defaultHistoryWallets)),
+		prices:         NewPriceEnricher(cache),
+		spenderStats:   newSpenderStatsFromEnv(cache),
+		volume:         newVolumeAnalyzerFromEnv(RealClock{}),
+		identity:       newIdentityResolverFromEnv(),
+		webhooks:       webhooks,
+		log:            logger,
 	}
 
 	return &Server{
 		scanner:          scanner,
-		contractAnalyzer: NewContractAnalyzer(clients),
+		contractAnalyzer: NewContractAnalyzer(clients, logger),
 		chainClients:     clients,
+		feeMarket:        feeMarket,
+		revocationCost:   NewRevocationCostEstimator(feeMarket),
+		jobs:             NewScanJobQueue(scanner, getEnvInt("SCAN_WORKERS", defaultScanWorkers), RealClock{}, logger),
+		webhooks:         webhooks,
+		wsScanInterval:   getEnvDuration("WS_SCAN_INTERVAL", defaultWSScanInterval),
+		watches:          newWatchLimiter(getEnvInt("WS_MAX_WATCHES", defaultWSMaxWatches)),
+		responses:        NewCacheWithClock(config.CacheTTL, RealClock{}),
+		log:              logger,
 	}
 }
 
 // ScannerService describes the wallet scanning operations consumed by HTTP handlers.
 type ScannerService interface {
-	ScanWallet(ctx context.Context, walletAddress string, chains []ChainID) (*WalletScanResult, error)
+	ScanWallet(ctx context.Context, walletAddress string, chains []ChainID, forceRefresh bool) (*WalletScanResult, error)
 }
 
-func NewServerWithScanner(scanner ScannerService) *Server {
-	clients := make(map[ChainID]*ChainClient)
-	for chain, rpc := range config.RPC {
-		clients[ChainID(chain)] = NewChainClient(ChainID(chain), rpc)
-	}
+func NewServerWithScanner(scanner ScannerService, logger Logger) *Server {
+	logger = loggerOr(logger)
+	clients := newChainClients(logger)
+	feeMarket := NewFeeMarketClient(clients)
 	return &Server{
 		scanner:          scanner,
-		contractAnalyzer: NewContractAnalyzer(clients),
+		contractAnalyzer: NewContractAnalyzer(clients, logger),
 		chainClients:     clients,
+		feeMarket:        feeMarket,
+		revocationCost:   NewRevocationCostEstimator(feeMarket),
+		jobs:             NewScanJobQueue(scanner, getEnvInt("SCAN_WORKERS", defaultScanWorkers), RealClock{}, logger),
+		webhooks:         NewWebhookDispatcherFromEnv(logger),
+		wsScanInterval:   getEnvDuration("WS_SCAN_INTERVAL", defaultWSScanInterval),
+		watches:          newWatchLimiter(getEnvInt("WS_MAX_WATCHES", defaultWSMaxWatches)),
+		responses:        NewCacheWithClock(config.CacheTTL, RealClock{}),
+		log:              logger,
 	}
 }
 
+// logger returns the server's logger
+func (s *Server) logger() Logger {
+	return loggerOr(s.log)
+}
+
 // CORS middleware
 func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Admin-Key")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -1714,12 +3335,13 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":  "healthy",
 		"service": "sentinel-api",
-		"version": "1.0.0",
+		"version": apiVersion,
 		"endpoints": map[string]string{
 			"scan":          "GET /api/v1/scan?wallet=0x...&chains=ethereum,polygon",
 			"analyze":       "GET /api/v1/analyze?contract=0x...&chain=ethereum",
 			"analyze_batch": "POST /api/v1/analyze/batch",
 			"chains":        "GET /api/v1/chains",
+			"docs":          "GET /api/v1/docs",
 		},
 		"services": map[string]string{
 			"decompiler": os.Getenv("DECOMPILER_URL"),
@@ -1729,76 +3351,222 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 // Scan wallet endpoint
-func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+// isValidEthereumAddress reports whether addr is a 0x-prefixed 20-byte hex address
+func isValidEthereumAddress(addr string) bool {
+	if len(addr) != 42 || !strings.HasPrefix(addr, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(addr[2:])
+	return err == nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// isValidSolanaAddress reports whether addr is a base58 public key (32-44 chars)
+func isValidSolanaAddress(addr string) bool {
+	if len(addr) < 32 || len(addr) > 44 {
+		return false
+	}
+	for _, ch := range addr {
+		if !strings.ContainsRune(base58Alphabet, ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeWallet validates a wallet address, answering 400 with a JSON error when
+// it's malformed. Malformed addresses are rejected before fanning out RPC calls to
+// every chain; EVM wallets are scanned and logged in lowercase, so mixed case must be
+// an EIP-55 checksum.
+func normalizeWallet(w http.ResponseWriter, walletAddress string) (string, bool) {
+	if !isValidEthereumAddress(walletAddress) && !isValidSolanaAddress(walletAddress) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":             "invalid_wallet_address",
+			"address":           walletAddress,
+			"supported_formats": []string{"ethereum (0x...)", "solana (base58)"},
+		})
+		return "", false
+	}
+	if !isValidEthereumAddress(walletAddress) {
+		return walletAddress, true
+	}
+
+	normalized, err := NormalizeAddress(walletAddress)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "invalid_address_checksum",
+			"address": walletAddress,
+			"message": err.Error(),
+		})
+		return "", false
+	}
+	return normalized, true
+}
+
+// parseScanRequest reads the wallet and chains query parameters of a scan request,
+// answering invalid requests itself
+func parseScanRequest(w http.ResponseWriter, r *http.Request) (string, []ChainID, bool) {
 	walletAddress := r.URL.Query().Get("wallet")
 	if walletAddress == "" {
 		http.Error(w, "wallet parameter required", http.StatusBadRequest)
-		return
+		return "", nil, false
 	}
 
-	// Parse chains (default: all)
-	chainsParam := r.URL.Query().Get("chains")
-	chains := AllChains
+	walletAddress, ok := normalizeWallet(w, walletAddress)
+	if !ok {
+		return "", nil, false
+	}
 
-	if chainsParam != "" {
-		validChains := make(map[ChainID]struct{}, len(AllChains))
-		for _, chain := range AllChains {
-			validChains[chain] = struct{}{}
+	// Parse chains (default: all chains the address format exists on)
+	chains := walletChains(walletAddress, supportedChains())
+	if chainsParam := r.URL.Query().Get("chains"); chainsParam != "" {
+		selected, err := resolveChains(strings.Split(chainsParam, ","))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return "", nil, false
 		}
+		chains = selected
+	}
 
-		seen := make(map[ChainID]struct{})
-		selected := make([]ChainID, 0)
-		invalid := make([]string, 0)
+	return walletAddress, chains, true
+}
 
-		for _, raw := range strings.Split(chainsParam, ",") {
-			trimmed := strings.TrimSpace(raw)
-			if trimmed == "" {
-				continue
-			}
+// resolveChains validates requested chain names (case-insensitive, blanks and
+// duplicates ignored) against the supported chains
+func resolveChains(names []string) ([]ChainID, error) {
+	validChains := make(map[ChainID]struct{})
+	for _, chain := range supportedChains() {
+		validChains[chain] = struct{}{}
+	}
 
-			chainID := ChainID(strings.ToLower(trimmed))
-			if _, ok := validChains[chainID]; !ok {
-				invalid = append(invalid, trimmed)
-				continue
-			}
+	seen := make(map[ChainID]struct{})
+	selected := make([]ChainID, 0)
+	invalid := make([]string, 0)
 
-			if _, already := seen[chainID]; !already {
-				seen[chainID] = struct{}{}
-				selected = append(selected, chainID)
-			}
+	for _, raw := range names {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			continue
 		}
 
-		if len(invalid) > 0 {
-			http.Error(w, fmt.Sprintf("unsupported chains: %s", strings.Join(invalid, ", ")), http.StatusBadRequest)
-			return
+		chainID := ChainID(strings.ToLower(trimmed))
+		if _, ok := validChains[chainID]; !ok {
+			invalid = append(invalid, trimmed)
+			continue
 		}
 
-		if len(selected) == 0 {
-			http.Error(w, "no valid chains provided", http.StatusBadRequest)
-			return
+		if _, already := seen[chainID]; !already {
+			seen[chainID] = struct{}{}
+			selected = append(selected, chainID)
 		}
+	}
 
-		chains = selected
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("unsupported chains: %s", strings.Join(invalid, ", "))
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no valid chains provided")
+	}
+	return selected, nil
+}
+
+// writeScanFailed logs a failed scan and answers 500 with a generic scan_failed error.
+// Scanner errors can embed RPC URLs (and their API keys): they are logged under the
+// request ID the client gets back, never returned.
+func (s *Server) writeScanFailed(w http.ResponseWriter, r *http.Request, logMessage, walletAddress string, err error) {
+	requestID := requestIDOrNew(r.Context())
+	s.logger().Error(logMessage, Fields{"request_id": requestID, "wallet": walletAddress, "error": errorText(err)})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-ID", requestID)
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     "scan_failed",
+		"message":   "Failed to scan one or more chains. Please try again.",
+		"requestId": requestID,
+	})
+}
+
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	walletAddress, chains, ok := parseScanRequest(w, r)
+	if !ok {
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	result, err := s.scanner.ScanWallet(ctx, walletAddress, chains)
+	// ?refresh=true bypasses the scan cache, ?skipTCCheck=true the Tornado Cash check
+	forceRefresh := r.URL.Query().Get("refresh") == "true"
+	if r.URL.Query().Get("skipTCCheck") == "true" {
+		ctx = withoutTornadoCheck(ctx)
+	}
+
+	limit, err := parsePageSize(r.URL.Query().Get("limit"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cursor := r.URL.Query().Get("cursor")
+	filter, err := parseApprovalFilter(r.URL.Query(), chains)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	groupBy := r.URL.Query().Get("groupBy")
+	if groupBy != "" && groupBy != "spender" {
+		http.Error(w, "groupBy must be spender", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(result)
+	result, err := s.scanner.ScanWallet(ctx, walletAddress, chains, forceRefresh)
+	if err != nil {
+		s.writeScanFailed(w, r, "Scan failed", walletAddress, err)
+		return
+	}
+
+	page := *result
+	filterApprovals(&page, filter)
+	if groupBy == "spender" {
+		// Groups cover every matching approval, not just the page
+		page.GroupedApprovals = groupApprovalsBySpender(page.Approvals)
+	}
+	if r.URL.Query().Get("includeRevocationCost") == "true" {
+		page.RevocationCost = s.revocationCost.Estimate(ctx, &page)
+	}
+
+	// Accept: text/csv exports every matching approval (after cursor, if given)
+	csvExport := wantsCSV(r)
+	if csvExport {
+		limit = len(page.Approvals)
+	}
+	if err := paginateApprovals(&page, cursor, limit); err != nil {
+		http.Error(w, "invalid cursor: restart from the first page", http.StatusBadRequest)
+		return
+	}
+
+	if csvExport {
+		writeApprovalsCSV(w, &page)
+		return
+	}
+	if s.responses == nil {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+		return
+	}
+	s.writeScanResponse(w, r, walletAddress, chains, &page, forceRefresh)
 }
 
 // Get supported chains
 func (s *Server) handleChains(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"chains": AllChains,
+		"chains": supportedChains(),
 	})
 }
 
@@ -1823,7 +3591,7 @@ func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
 		chain = ChainID(strings.ToLower(chainParam))
 		// Validate chain
 		validChain := false
-		for _, c := range AllChains {
+		for _, c := range supportedChains() {
 			if c == chain {
 				validChain = true
 				break
@@ -1888,14 +3656,16 @@ func (s *Server) handleBatchAnalyze(w http.ResponseWriter, r *http.Request) {
 	var mu sync.Mutex
 
 	for _, contract := range req.Contracts {
+		// Capture by value so each goroutine analyzes its own contract
+		addr, chain := contract.Address, contract.Chain
+		if chain == "" {
+			chain = Ethereum
+		}
+
 		wg.Add(1)
-		go func(addr string, chain ChainID) {
+		go func() {
 			defer wg.Done()
 
-			if chain == "" {
-				chain = Ethereum
-			}
-
 			result, err := s.contractAnalyzer.AnalyzeContract(ctx, addr, chain)
 			mu.Lock()
 			defer mu.Unlock()
@@ -1905,7 +3675,7 @@ func (s *Server) handleBatchAnalyze(w http.ResponseWriter, r *http.Request) {
 			} else {
 				results = append(results, result)
 			}
-		}(contract.Address, contract.Chain)
+		}()
 	}
 
 	wg.Wait()
@@ -1937,20 +3707,115 @@ func main() {
                      API Server v1.0.0
 
   Endpoints:
+    GET  /health/live           - Liveness probe
+    GET  /health/ready          - Readiness probe (RPC endpoints, decompiler, analyzer)
+    GET  /metrics               - Prometheus metrics
     GET  /api/v1/scan           - Scan wallet approvals
+    GET  /api/v1/scan/stream    - Stream scan results (Server-Sent Events)
+    POST /api/v1/scan/batch     - Scan up to 20 wallets at once
+    GET  /ws/scan               - Watch a wallet for new approvals (WebSocket)
+    POST /api/v1/scan/async     - Queue a scan, poll GET /api/v1/jobs/{id}
+    GET  /api/v1/jobs/{id}      - Status and result of a queued scan
     GET  /api/v1/analyze        - Analyze contract (decompiler + security)
     POST /api/v1/analyze/batch  - Batch analyze contracts
     GET  /api/v1/chains         - List supported chains
+    GET  /api/v1/openapi.json   - OpenAPI 3.0 specification
+    GET  /api/v1/docs           - API documentation (Swagger UI)
+    GET  /api/v1/labels         - List wallet labels (POST to add, admin)
+    POST /api/v1/revoke         - Build unsigned revocation transactions
+    GET  /api/v1/revoke/estimate - Estimate revocation cost
+    GET  /api/v1/allowance/history - Approval events of a token+spender pair
+    GET  /api/v1/history/{wallet} - Risk scores of the wallet's recent scans
+    POST /api/v1/diff           - Changes since the scan closest to a timestamp
+    GET  /api/v1/admin/chains   - List registered chains (admin)
+    POST /api/v1/admin/chains   - Register a custom EVM chain (admin)
+    POST /api/v1/admin/keys     - Add an API key by hash (admin)
+    POST /api/v1/admin/webhooks/test - Send a test scan result to WEBHOOK_URL (admin)
+    POST /api/v1/admin/rules/reload - Reload risk rules (admin)
+    POST /api/v1/admin/reload   - Reload spender and token data files (admin)
+    POST /api/v1/admin/risk/override - Pin a spender's risk level (admin)
+    GET  /api/v1/admin/risk/overrides - List risk overrides (admin)
+    DELETE /api/v1/admin/risk/overrides/{address} - Remove a risk override (admin)
+    GET  /api/v2/...            - v1 public routes with enveloped responses
 	`)
 
-	server := NewServer()
+	// LOG_LEVEL may come from the .env file, which is loaded after defaultLogger is created
+	logger := NewLogger(getEnv("LOG_LEVEL", "info"))
+	defaultLogger = logger
+
+	server := NewServer(logger)
+
+	// Resume incremental scans from the blocks reached before the last restart
+	if scanner, ok := server.scanner.(*Scanner); ok {
+		if err := scanner.state.Load(); err != nil {
+			logger.Error("Failed to load scan state", Fields{"error": errorText(err)})
+		}
+	}
+
+	// Restore chains registered at runtime in previous runs
+	customChainsFile := getEnv("CUSTOM_CHAINS_FILE", "custom-chains.json")
+	if err := server.loadCustomChains(customChainsFile); err != nil {
+		logger.Error("Failed to load custom chains", Fields{"error": errorText(err)})
+	}
+
+	// Tracing is a no-op unless OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTracing := initTracing(context.Background())
+
+	// Keep canonical L2 bridge addresses current (re-fetched every 24h)
+	bridgeCtx, stopBridgeUpdates := context.WithCancel(context.Background())
+	NewBridgeRegistryUpdater(dynamicSpenders).Start(bridgeCtx)
+
+	// Merge data/*.json into the known spenders and tokens (re-read every 10 minutes)
+	spenderDBCtx, stopSpenderDBReloads := context.WithCancel(context.Background())
+	spenderDB.Start(spenderDBCtx)
 
 	// Routes
 	http.HandleFunc("/health", corsMiddleware(server.handleHealth))
-	http.HandleFunc("/api/v1/scan", corsMiddleware(server.handleScan))
-	http.HandleFunc("/api/v1/chains", corsMiddleware(server.handleChains))
-	http.HandleFunc("/api/v1/analyze", corsMiddleware(server.handleAnalyze))
-	http.HandleFunc("/api/v1/analyze/batch", corsMiddleware(server.handleBatchAnalyze))
+	http.HandleFunc("/health/live", corsMiddleware(server.handleLive))
+	http.HandleFunc("/health/ready", corsMiddleware(server.handleReady))
+	http.HandleFunc("/metrics", metricsHandler())
+	http.HandleFunc("/api/v1/scan", corsMiddleware(deprecatedV1(server.handleScan)))
+	http.HandleFunc("/api/v1/scan/stream", corsMiddleware(deprecatedV1(server.handleScanStream)))
+	http.HandleFunc("/api/v1/scan/batch", corsMiddleware(deprecatedV1(server.handleScanBatch)))
+	http.HandleFunc("/ws/scan", server.handleScanWebSocket)
+	http.HandleFunc("/api/v1/scan/async", corsMiddleware(deprecatedV1(server.handleScanAsync)))
+	http.HandleFunc("/api/v1/jobs/", corsMiddleware(deprecatedV1(server.handleJob)))
+	http.HandleFunc("/api/v1/chains", corsMiddleware(deprecatedV1(server.handleChains)))
+	http.HandleFunc("/api/v1/labels", corsMiddleware(deprecatedV1(server.handleLabels)))
+	http.HandleFunc("/api/v1/openapi.json", corsMiddleware(openAPIHandler()))
+	http.HandleFunc("/api/v1/docs", corsMiddleware(handleDocs))
+	http.HandleFunc("/api/v1/analyze", corsMiddleware(deprecatedV1(server.handleAnalyze)))
+	http.HandleFunc("/api/v1/analyze/batch", corsMiddleware(deprecatedV1(server.handleBatchAnalyze)))
+	http.HandleFunc("/api/v1/revoke", corsMiddleware(deprecatedV1(server.handleRevoke)))
+	http.HandleFunc("/api/v1/revoke/estimate", corsMiddleware(deprecatedV1(server.handleRevokeEstimate)))
+	http.HandleFunc("/api/v1/allowance/history", corsMiddleware(deprecatedV1(server.handleAllowanceHistory)))
+	http.HandleFunc("/api/v1/history/", corsMiddleware(deprecatedV1(server.handleHistory)))
+	http.HandleFunc("/api/v1/diff", corsMiddleware(deprecatedV1(server.handleDiff)))
+	http.HandleFunc("/api/v1/admin/chains", corsMiddleware(adminMiddleware(server.handleAdminChains)))
+	http.HandleFunc("/api/v1/admin/keys", corsMiddleware(adminMiddleware(server.handleAdminKeys)))
+	http.HandleFunc("/api/v1/admin/webhooks/test", corsMiddleware(adminMiddleware(server.handleWebhookTest)))
+	http.HandleFunc("/api/v1/admin/reload", corsMiddleware(adminMiddleware(server.handleAdminReload)))
+	http.HandleFunc("/api/v1/admin/rules/reload", corsMiddleware(adminMiddleware(server.handleReloadRules)))
+	http.HandleFunc("/api/v1/admin/risk/override", corsMiddleware(adminMiddleware(server.handleRiskOverride)))
+	http.HandleFunc("/api/v1/admin/risk/overrides", corsMiddleware(adminMiddleware(server.handleRiskOverrides)))
+	http.HandleFunc("/api/v1/admin/risk/overrides/", corsMiddleware(adminMiddleware(server.handleRiskOverrides)))
+
+	// v2: same handlers, responses wrapped in a ResponseEnvelope
+	http.HandleFunc("/api/v2/scan", corsMiddleware(server.handleScanV2))
+	// Event streams can't be enveloped; v2 serves the same stream
+	http.HandleFunc("/api/v2/scan/stream", corsMiddleware(server.handleScanStream))
+	http.HandleFunc("/api/v2/scan/batch", corsMiddleware(envelopeMiddleware(server.handleScanBatch)))
+	http.HandleFunc("/api/v2/scan/async", corsMiddleware(envelopeMiddleware(server.handleScanAsync)))
+	http.HandleFunc("/api/v2/jobs/", corsMiddleware(envelopeMiddleware(server.handleJob)))
+	http.HandleFunc("/api/v2/chains", corsMiddleware(envelopeMiddleware(server.handleChains)))
+	http.HandleFunc("/api/v2/labels", corsMiddleware(envelopeMiddleware(server.handleLabels)))
+	http.HandleFunc("/api/v2/analyze", corsMiddleware(envelopeMiddleware(server.handleAnalyze)))
+	http.HandleFunc("/api/v2/analyze/batch", corsMiddleware(envelopeMiddleware(server.handleBatchAnalyze)))
+	http.HandleFunc("/api/v2/revoke", corsMiddleware(envelopeMiddleware(server.handleRevoke)))
+	http.HandleFunc("/api/v2/revoke/estimate", corsMiddleware(envelopeMiddleware(server.handleRevokeEstimate)))
+	http.HandleFunc("/api/v2/allowance/history", corsMiddleware(envelopeMiddleware(server.handleAllowanceHistory)))
+	http.HandleFunc("/api/v2/history/", corsMiddleware(envelopeMiddleware(server.handleHistory)))
+	http.HandleFunc("/api/v2/diff", corsMiddleware(envelopeMiddleware(server.handleDiff)))
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -1958,32 +3823,87 @@ func main() {
 		port = config.Port
 	}
 
+	ipFilter, limiter := NewIPFilterFromEnv(), NewRateLimiterFromEnv()
 	httpServer := &http.Server{
 		Addr:         ":" + port,
+		Handler:      tracingMiddleware(RequestIDMiddleware(ipFilter.Middleware(limiter.Middleware(AuthMiddleware(http.DefaultServeMux.ServeHTTP))))),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 60 * time.Second,
 	}
 
+	// gRPC API on GRPC_PORT, over the same scanner and analyzer (GRPC_ENABLED=true)
+	var grpcServer *grpc.Server
+	if getEnv("GRPC_ENABLED", "false") == "true" {
+		grpcServer = newGRPCServer(server, ipFilter, limiter)
+		go serveGRPC(grpcServer, logger)
+	}
+
 	// Graceful shutdown
 	go func() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
 
-		log.Println("Shutting down...")
+		logger.Info("Shutting down", nil)
+		stopBridgeUpdates()
+		stopSpenderDBReloads()
+		if err := saveCustomChains(customChainsFile); err != nil {
+			logger.Error("Failed to persist custom chains", Fields{"error": errorText(err)})
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := httpServer.Shutdown(ctx); err != nil {
-			log.Printf("Shutdown error: %v", err)
+			logger.Error("Shutdown failed", Fields{"error": errorText(err)})
+		}
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+		if err := server.webhooks.Shutdown(ctx); err != nil {
+			logger.Error("Webhook deliveries still pending at shutdown", Fields{"error": errorText(err)})
+		}
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", Fields{"error": errorText(err)})
 		}
 	}()
 
-	log.Printf("🚀 Sentinel API running on http://localhost:%s", port)
-	log.Printf("📡 Scanning %d chains", len(AllChains))
+	logger.Info("Sentinel API running", Fields{"port": port})
+	logger.Info("Scanning chains", Fields{"chains": len(supportedChains())})
 
 	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatalf("Server error: %v", err)
+		logger.Error("Server error", Fields{"error": errorText(err)})
+		os.Exit(1)
+	}
+}
+
+// urlPattern matches http(s) URLs embedded in error messages
+var urlPattern = regexp.MustCompile(`https?://[^\s"']+`)
+
+// redactURLs reduces URLs in a message to scheme and host, since RPC paths and
+// query strings carry API keys
+func redactURLs(msg string) string {
+	return urlPattern.ReplaceAllStringFunc(msg, func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return "[redacted-url]"
+		}
+		return u.Scheme + "://" + u.Host
+	})
+}
+
+// formatCount formats an integer with thousands separators (12456 -> "12,456")
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
 	}
+	return b.String()
 }
 
 func min(a, b int) int {
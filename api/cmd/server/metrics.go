@@ -0,0 +1,84 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Prometheus Metrics
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	scanDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sentinel_scan_duration_seconds",
+		Help:    "Time to fetch a wallet's approvals on one chain.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"chain"})
+
+	cacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sentinel_cache_hits_total",
+		Help: "Cache lookups that found a live entry.",
+	})
+
+	cacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sentinel_cache_misses_total",
+		Help: "Cache lookups that found nothing or an expired entry.",
+	})
+
+	rpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sentinel_rpc_errors_total",
+		Help: "Failed approval log queries by chain and provider (alchemy, etherscan, subgraph).",
+	}, []string{"chain", "provider"})
+
+	approvalsFound = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sentinel_approvals_found_total",
+		Help: "Approvals returned by completed scans by chain and risk level.",
+	}, []string{"chain", "risk_level"})
+
+	activeScans = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sentinel_active_scans",
+		Help: "Wallet scans in progress.",
+	})
+)
+
+// observeCacheLookup counts a cache hit or miss
+func observeCacheLookup(hit bool) {
+	if hit {
+		cacheHits.Inc()
+	} else {
+		cacheMisses.Inc()
+	}
+}
+
+// observeApprovals counts the approvals of a completed scan
+func observeApprovals(approvals []Approval) {
+	for _, approval := range approvals {
+		approvalsFound.WithLabelValues(string(approval.Chain), approval.RiskLevel).Inc()
+	}
+}
+
+// metricsHandler serves Prometheus metrics. When METRICS_AUTH_TOKEN is set, scrapers
+// must send it as a bearer token.
+func metricsHandler() http.HandlerFunc {
+	metrics := promhttp.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := os.Getenv("METRICS_AUTH_TOKEN"); token != "" {
+			provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				http.Error(w, "invalid metrics token", http.StatusUnauthorized)
+				return
+			}
+		}
+		metrics.ServeHTTP(w, r)
+	}
+}
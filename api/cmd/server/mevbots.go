@@ -0,0 +1,33 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - MEV Bots
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import "strings"
+
+// mevBotSpenderName is the spender name of every known MEV bot. Bots end up with
+// approvals through ordinary swap flows, so they are flagged for monitoring rather
+// than scored as unknown contracts.
+const mevBotSpenderName = "⚠️ MEV Bot: Legitimate but monitor"
+
+// knownMEVBots maps MEV/sandwich bot addresses (lowercase) to their operator labels
+var knownMEVBots = map[string]string{
+	"0x6b75d8af000000e20b7a7ddf000ba900b4009a80": "Flashbots: MEV Bot",
+	"0xae2fc483527b8ef99eb5d9b44875f005ba1fae13": "jaredfromsubway.eth",
+	"0x00000000003b3cc22af3ae1eac0440bcee416b40": "MEV Bot: 0x0000...6b40",
+	"0x000000000035b5e5ad9019092c665357240f594e": "MEV Bot: 0x0000...594e",
+	"0xa69babef1ca67a37ffaf7a485dfff3382056e78c": "MEV Bot: 0xa69b...e78c",
+	"0x56178a0d5f301baf6cf3e1cd53d9863437345bf9": "MEV Bot: 0x5617...5bf9",
+	"0x98c3d3183c4b8a650614ad179a1a98be0a8d6b8e": "MEV Bot: 0x98c3...6b8e",
+}
+
+// annotateMEVBot names the operator of an MEV bot spender in the approval's reasons
+func annotateMEVBot(approval *Approval) {
+	if operator, ok := knownMEVBots[strings.ToLower(approval.SpenderAddress)]; ok {
+		approval.RiskReasons = append(approval.RiskReasons, "🤖 Spender is a known MEV bot ("+operator+")")
+	}
+}
@@ -0,0 +1,317 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Multicall3 Token Metadata
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// multicall3Address is the Multicall3 deployment, at the same address on every supported chain
+const multicall3Address = "0xca11bde05977b3631167028862be2a173976ca11"
+
+// Selectors of the batched calls
+const (
+	aggregate3Selector = "0x82ad56cb" // aggregate3((address,bool,bytes)[])
+	symbolSelector     = "0x95d89b41" // symbol()
+	decimalsSelector   = "0x313ce567" // decimals()
+)
+
+// maxMulticallCalls caps the calls of one aggregate3 request (symbol, decimals and two
+// supportsInterface = 4 per token)
+const maxMulticallCalls = 100
+
+// multicallCall is one call of an aggregate3 batch; Data is 0x-prefixed calldata
+type multicallCall struct {
+	Target string
+	Data   string
+}
+
+// multicallResult is the outcome of one aggregate3 call; ReturnData is 0x-prefixed
+type multicallResult struct {
+	Success    bool
+	ReturnData string
+}
+
+// encodeAggregate3 ABI-encodes aggregate3 over calls, each allowed to fail
+func encodeAggregate3(calls []multicallCall) string {
+	word := func(n int) string { return fmt.Sprintf("%064x", n) }
+
+	tuples := make([]string, len(calls))
+	for i, call := range calls {
+		data := strings.TrimPrefix(call.Data, "0x")
+		if rem := len(data) % 64; rem != 0 {
+			data += strings.Repeat("0", 64-rem)
+		}
+		tuples[i] = strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(call.Target), "0x") +
+			word(1) + // allowFailure
+			word(96) + // offset of callData within the tuple
+			word(len(strings.TrimPrefix(call.Data, "0x"))/2) + data
+	}
+
+	var sb strings.Builder
+	sb.WriteString(aggregate3Selector)
+	sb.WriteString(word(32)) // offset of the array
+	sb.WriteString(word(len(calls)))
+	offset := 32 * len(calls) // tuples start after the offset table
+	for _, tuple := range tuples {
+		sb.WriteString(word(offset))
+		offset += len(tuple) / 2
+	}
+	for _, tuple := range tuples {
+		sb.WriteString(tuple)
+	}
+	return sb.String()
+}
+
+// decodeAggregate3 decodes the (bool success, bytes returnData)[] result of aggregate3
+func decodeAggregate3(result string) ([]multicallResult, error) {
+	data := strings.TrimPrefix(result, "0x")
+	// readWord returns the word at byte offset as an int, failing on out-of-range values
+	readWord := func(offset int) (int, error) {
+		if offset < 0 || len(data) < (offset+32)*2 {
+			return 0, fmt.Errorf("aggregate3 result truncated at byte %d", offset)
+		}
+		n, ok := new(big.Int).SetString(data[offset*2:(offset+32)*2], 16)
+		if !ok || !n.IsInt64() || n.Int64() > int64(len(data)) {
+			return 0, fmt.Errorf("invalid aggregate3 word at byte %d", offset)
+		}
+		return int(n.Int64()), nil
+	}
+
+	arrayStart, err := readWord(0)
+	if err != nil {
+		return nil, err
+	}
+	count, err := readWord(arrayStart)
+	if err != nil {
+		return nil, err
+	}
+	base := arrayStart + 32
+
+	results := make([]multicallResult, count)
+	for i := range results {
+		tupleOffset, err := readWord(base + 32*i)
+		if err != nil {
+			return nil, err
+		}
+		tuple := base + tupleOffset
+		success, err := readWord(tuple)
+		if err != nil {
+			return nil, err
+		}
+		bytesOffset, err := readWord(tuple + 32)
+		if err != nil {
+			return nil, err
+		}
+		length, err := readWord(tuple + bytesOffset)
+		if err != nil {
+			return nil, err
+		}
+		start := (tuple + bytesOffset + 32) * 2
+		if len(data) < start+length*2 {
+			return nil, fmt.Errorf("aggregate3 return data %d truncated", i)
+		}
+		results[i] = multicallResult{Success: success == 1, ReturnData: "0x" + data[start:start+length*2]}
+	}
+	return results, nil
+}
+
+// aggregate3 executes calls in one eth_call to Multicall3
+func (c *ChainClient) aggregate3(ctx context.Context, calls []multicallCall) ([]multicallResult, error) {
+	result, err := c.ethCall(ctx, multicall3Address, encodeAggregate3(calls))
+	if err != nil {
+		return nil, err
+	}
+	results, err := decodeAggregate3(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("aggregate3 returned %d results for %d calls", len(results), len(calls))
+	}
+	return results, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              TOKEN METADATA CACHE
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// tokenMetadata is a token's symbol, decimals and standard as read on chain
+type tokenMetadata struct {
+	Symbol   string // "" = symbol() failed or isn't a string
+	Decimals int    // -1 = unknown
+	Standard string // ERC20, ERC721, ERC1155 or UNKNOWN
+}
+
+// tokenMetadataCaches maps ChainID to a *sync.Map of lowercase token address ->
+// tokenMetadata, shared by every ChainClient of the chain
+var tokenMetadataCaches sync.Map
+
+// tokenMetadataCache returns the token metadata cache of a chain
+func tokenMetadataCache(chain ChainID) *sync.Map {
+	cache, _ := tokenMetadataCaches.LoadOrStore(chain, &sync.Map{})
+	return cache.(*sync.Map)
+}
+
+// cachedTokenMetadata returns the cached metadata of a token
+func cachedTokenMetadata(chain ChainID, tokenAddress string) (tokenMetadata, bool) {
+	meta, ok := tokenMetadataCache(chain).Load(strings.ToLower(tokenAddress))
+	if !ok {
+		return tokenMetadata{}, false
+	}
+	return meta.(tokenMetadata), true
+}
+
+// BatchTokenMetadataFetcher buffers tokens whose metadata is unknown and reads their
+// symbol(), decimals() and ERC-165 support of ERC721 and ERC1155 through Multicall3,
+// maxMulticallCalls calls per request
+type BatchTokenMetadataFetcher struct {
+	client  *ChainClient
+	pending []string
+	queued  map[string]bool
+}
+
+// NewBatchTokenMetadataFetcher creates a fetcher for the client's chain
+func NewBatchTokenMetadataFetcher(client *ChainClient) *BatchTokenMetadataFetcher {
+	return &BatchTokenMetadataFetcher{client: client, queued: make(map[string]bool)}
+}
+
+// Add buffers a token unless it is a known token or already cached
+func (f *BatchTokenMetadataFetcher) Add(tokenAddress string) {
+	token := strings.ToLower(tokenAddress)
+	if f.queued[token] {
+		return
+	}
+	if _, known := spenderDB.Token(token); known {
+		return
+	}
+	if _, cached := cachedTokenMetadata(f.client.ChainID, token); cached {
+		return
+	}
+	f.queued[token] = true
+	f.pending = append(f.pending, token)
+}
+
+// Flush fetches the buffered tokens into the chain's metadata cache. Tokens of a
+// failed request stay uncached, so lookups fall back to single calls.
+func (f *BatchTokenMetadataFetcher) Flush(ctx context.Context) error {
+	pending := f.pending
+	f.pending = nil
+	f.queued = make(map[string]bool)
+
+	cache := tokenMetadataCache(f.client.ChainID)
+	tokensPerRequest := maxMulticallCalls / 4
+
+	var firstErr error
+	for start := 0; start < len(pending); start += tokensPerRequest {
+		tokens := pending[start:min(start+tokensPerRequest, len(pending))]
+
+		calls := make([]multicallCall, 0, 4*len(tokens))
+		for _, token := range tokens {
+			calls = append(calls,
+				multicallCall{Target: token, Data: symbolSelector},
+				multicallCall{Target: token, Data: decimalsSelector},
+				multicallCall{Target: token, Data: supportsInterfaceSelector + padSelector(erc721InterfaceID)},
+				multicallCall{Target: token, Data: supportsInterfaceSelector + padSelector(erc1155InterfaceID)})
+		}
+
+		results, err := f.client.aggregate3(ctx, calls)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		for i, token := range tokens {
+			meta := tokenMetadata{Decimals: -1}
+			if symbol := results[4*i]; symbol.Success {
+				meta.Symbol = decodeString(symbol.ReturnData)
+			}
+			if decimals := results[4*i+1]; decimals.Success {
+				if words := abiWords(decimals.ReturnData); len(words) > 0 {
+					if n, ok := new(big.Int).SetString(words[0], 16); ok && n.IsInt64() && n.Int64() <= 255 {
+						meta.Decimals = int(n.Int64())
+					}
+				}
+			}
+			meta.Standard = tokenStandard(results[4*i+2], results[4*i+3], meta.Decimals)
+			cache.Store(token, meta)
+		}
+	}
+	return firstErr
+}
+
+// tokenStandard derives a token's standard from its supportsInterface answers for
+// ERC721 and ERC1155: a contract reporting neither is ERC20 if it has decimals()
+func tokenStandard(erc721, erc1155 multicallResult, decimals int) string {
+	supports := func(result multicallResult) bool {
+		words := abiWords(result.ReturnData)
+		return result.Success && len(words) == 1 && strings.TrimLeft(words[0], "0") == "1"
+	}
+	switch {
+	case supports(erc1155):
+		return tokenTypeERC1155
+	case supports(erc721):
+		return tokenTypeERC721
+	case decimals >= 0:
+		return tokenTypeERC20
+	default:
+		return tokenTypeUnknown
+	}
+}
+
+// DetectTokenStandard returns "ERC20", "ERC721", "ERC1155" or "UNKNOWN" for a token,
+// reading its ERC-165 interfaces in the same Multicall3 request as its symbol. Results
+// are cached per chain for the life of the process.
+func (c *ChainClient) DetectTokenStandard(ctx context.Context, tokenAddress string) (string, error) {
+	token := strings.ToLower(tokenAddress)
+	if meta, ok := cachedTokenMetadata(c.ChainID, token); ok && meta.Standard != "" {
+		return meta.Standard, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	fetcher := NewBatchTokenMetadataFetcher(c)
+	fetcher.pending = []string{token} // known tokens too, which Add skips
+	if err := fetcher.Flush(ctx); err != nil {
+		return tokenTypeUnknown, err
+	}
+	meta, _ := cachedTokenMetadata(c.ChainID, token)
+	return meta.Standard, nil
+}
+
+// isNFTContract reports whether a token was detected as ERC721 or ERC1155 by an
+// earlier metadata batch; it makes no calls
+func (c *ChainClient) isNFTContract(tokenAddress string) bool {
+	meta, ok := cachedTokenMetadata(c.ChainID, tokenAddress)
+	return ok && (meta.Standard == tokenTypeERC721 || meta.Standard == tokenTypeERC1155)
+}
+
+// prefetchTokenMetadata reads the metadata of a scan's tokens in batches before the
+// approvals are built, so getTokenSymbol rarely needs a call per token
+func (c *ChainClient) prefetchTokenMetadata(ctx context.Context, tokenAddresses []string) {
+	fetcher := NewBatchTokenMetadataFetcher(c)
+	for _, token := range tokenAddresses {
+		fetcher.Add(token)
+	}
+	if len(fetcher.pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := fetcher.Flush(ctx); err != nil {
+		requestLogger(ctx, c.logger()).Debug("Multicall3 token metadata fetch failed, falling back to single calls", Fields{"tokens": len(tokenAddresses), "error": errorText(err)})
+	}
+}
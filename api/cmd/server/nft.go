@@ -0,0 +1,199 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - NFT Approvals
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// approvalForAllTopic is ApprovalForAll(address indexed owner, address indexed operator, bool approved),
+// shared by ERC-721 and ERC-1155
+const approvalForAllTopic = "0x17307eab39ab6107e8899845ad3d59bd9653f200f220920489ca2b5937696c31"
+
+// Token standards reported in Approval.TokenType
+const (
+	tokenTypeERC20   = "ERC20"
+	tokenTypeERC721  = "ERC721"
+	tokenTypeERC1155 = "ERC1155"
+	// tokenTypeUnknown is a contract that matched no standard
+	tokenTypeUnknown = "UNKNOWN"
+)
+
+// allTokensAllowance is the human-readable allowance of an ApprovalForAll,
+// which covers every token id of the collection
+const allTokensAllowance = "ALL TOKENS"
+
+// approvalForAllAllowance replaces both allowance fields of ERC1155 approvals,
+// which have no amount at all
+const approvalForAllAllowance = "ApprovalForAll"
+
+// ERC-165 interface IDs of the NFT standards
+const (
+	erc721InterfaceID  = "80ac58cd"
+	erc1155InterfaceID = "d9b67a26"
+)
+
+const approvalForAllReason = "Operator can transfer every NFT in the collection"
+
+// getApprovalForAllLogs fetches the wallet's ApprovalForAll events via Alchemy,
+// or via Etherscan when endpoint is empty
+func (c *ChainClient) getApprovalForAllLogs(ctx context.Context, walletAddress string, endpoint string, blocks blockRange) ([]approvalLog, error) {
+	paddedWallet := padTopicAddress(walletAddress)
+
+	if endpoint != "" {
+		return c.getLogsSplitting(ctx, endpoint, map[string]interface{}{
+			"topics": []string{approvalForAllTopic, paddedWallet},
+		}, blocks)
+	}
+
+	var logs []approvalLog
+	query := fmt.Sprintf(
+		"module=logs&action=getLogs&fromBlock=%d&toBlock=%s&topic0=%s&topic0_1_opr=and&topic1=%s",
+		blocks.From,
+		blocks.toBlockQuery(),
+		approvalForAllTopic,
+		paddedWallet,
+	)
+	if err := c.etherscanQuery(ctx, query, &logs); err != nil {
+		if strings.Contains(err.Error(), "No records found") {
+			return []approvalLog{}, nil
+		}
+		return nil, err
+	}
+	return logs, nil
+}
+
+// applyApprovalForAllLogs folds ApprovalForAll events into the latest approvals
+// per collection+operator pair; approved=false revokes the pair
+func (c *ChainClient) applyApprovalForAllLogs(ctx context.Context, logs []approvalLog, latestApprovals map[string]Approval, revoked map[string]bool) {
+	collections := make(map[string]nftCollection)
+	for _, logEntry := range logs {
+		if len(logEntry.Topics) < 3 || !strings.EqualFold(logEntry.Topics[0], approvalForAllTopic) {
+			continue
+		}
+
+		collectionAddress := logEntry.Address
+		operatorAddress := "0x" + logEntry.Topics[2][26:]
+
+		approved := new(big.Int)
+		approved.SetString(strings.TrimPrefix(logEntry.Data, "0x"), 16)
+
+		key := approvalKey(collectionAddress, operatorAddress)
+		if approved.Sign() == 0 {
+			delete(latestApprovals, key)
+			revoked[key] = true
+			continue
+		}
+		delete(revoked, key)
+
+		collection, ok := collections[strings.ToLower(collectionAddress)]
+		if !ok {
+			collection = c.detectNFTCollection(ctx, collectionAddress)
+			collections[strings.ToLower(collectionAddress)] = collection
+		}
+
+		operatorName, operatorRisk := getSpenderInfo(operatorAddress)
+		operatorName, operatorRisk = c.resolveSpenderENS(ctx, operatorAddress, operatorName, operatorRisk)
+
+		allowanceRaw, allowanceHuman := "1", allTokensAllowance
+		if collection.TokenType == tokenTypeERC1155 {
+			allowanceRaw, allowanceHuman = approvalForAllAllowance, approvalForAllAllowance
+		}
+
+		// ApprovalForAll is always unlimited; calculateRiskScores makes unknown operators critical
+		latestApprovals[key] = Approval{
+			Chain:             c.ChainID,
+			TokenAddress:      collectionAddress,
+			TokenSymbol:       collection.Symbol,
+			TokenType:         collection.TokenType,
+			IsNFT:             true,
+			SpenderAddress:    operatorAddress,
+			SpenderName:       operatorName,
+			AllowanceRaw:      allowanceRaw,
+			AllowanceHuman:    allowanceHuman,
+			AllowanceUSD:      -1,
+			IsUnlimited:       true,
+			RiskLevel:         operatorRisk,
+			RiskReasons:       []string{approvalForAllReason},
+			LastUpdated:       int64(parseHexUint64(logEntry.TimeStamp)),
+			TxHash:            logEntry.TxHash,
+			LogIndex:          int(parseHexUint64(logEntry.LogIndex)),
+			BlockNumber:       parseHexUint64(logEntry.BlockNumber),
+			TransferFromCount: -1,
+		}
+	}
+}
+
+// nftCollection is the detected standard and display symbol of an NFT contract
+type nftCollection struct {
+	TokenType string
+	Symbol    string
+}
+
+// detectNFTCollection checks ERC-165 for ERC1155 before calling symbol(): pure
+// ERC1155 contracts usually have no symbol(), so their name() is used instead
+func (c *ChainClient) detectNFTCollection(ctx context.Context, collectionAddress string) nftCollection {
+	callCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// A scan's metadata batch may already have read the collection's interfaces
+	isERC1155 := false
+	if meta, ok := cachedTokenMetadata(c.ChainID, collectionAddress); ok && meta.Standard != "" {
+		isERC1155 = meta.Standard == tokenTypeERC1155
+	} else {
+		isERC1155 = c.callBool(callCtx, collectionAddress, supportsInterfaceSelector+padSelector(erc1155InterfaceID))
+	}
+	if !isERC1155 {
+		return nftCollection{TokenType: tokenTypeERC721, Symbol: getTokenSymbol(collectionAddress, c)}
+	}
+
+	collection := nftCollection{TokenType: tokenTypeERC1155}
+	collection.Symbol, _ = spenderDB.Token(collectionAddress)
+	if collection.Symbol == "" {
+		if result, err := c.ethCall(callCtx, collectionAddress, nameSelector); err == nil {
+			collection.Symbol = capTokenName(decodeString(result))
+		}
+	}
+	if collection.Symbol == "" && len(collectionAddress) >= 10 {
+		collection.Symbol = collectionAddress[:6] + "..." + collectionAddress[len(collectionAddress)-4:]
+	}
+	return collection
+}
+
+// fetchApprovalForAll runs getApprovalForAllLogs in the background; the returned
+// func waits for it and folds the events into latestApprovals. It reports false
+// when the NFT approvals could not be fetched.
+func (c *ChainClient) fetchApprovalForAll(ctx context.Context, walletAddress string, endpoint string, blocks blockRange) func(latestApprovals map[string]Approval, revoked map[string]bool) (int, bool, bool) {
+	done := make(chan struct{})
+	var logs []approvalLog
+	var err error
+	go func() {
+		defer close(done)
+		logs, err = c.getApprovalForAllLogs(ctx, walletAddress, endpoint, blocks)
+	}()
+
+	return func(latestApprovals map[string]Approval, revoked map[string]bool) (int, bool, bool) {
+		<-done
+		if err != nil {
+			requestLogger(ctx, c.logger()).Warn("ApprovalForAll lookup failed", Fields{"wallet": walletAddress, "error": errorText(err)})
+			return 0, false, false
+		}
+
+		totalEvents := len(logs)
+		logs, truncated := capApprovalLogs(logs, config.MaxApprovalsPerChain)
+		if truncated {
+			requestLogger(ctx, c.logger()).Warn("Truncated to the most recent ApprovalForAll events", Fields{"wallet": walletAddress, "events": len(logs), "total_events": totalEvents})
+		}
+		c.applyApprovalForAllLogs(ctx, logs, latestApprovals, revoked)
+		return totalEvents, truncated, true
+	}
+}
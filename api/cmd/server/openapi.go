@@ -0,0 +1,151 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - OpenAPI Specification
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIYAML documents the endpoints; its component schemas are filled in by buildOpenAPISpec
+//
+//go:embed openapi/openapi.yaml
+var openAPIYAML []byte
+
+// openAPISchemaTypes are the Go types whose JSON schemas are generated into
+// components.schemas, along with every type they reference
+var openAPISchemaTypes = []interface{}{
+	&WalletScanResult{},
+	&ContractAnalysisResult{},
+	&Approval{},
+	&ContractRisk{},
+}
+
+// buildOpenAPISpec converts the embedded YAML to JSON and adds the schemas reflected
+// from the response structs, so the documented responses can't drift from the code
+func buildOpenAPISpec() ([]byte, error) {
+	var spec map[string]interface{}
+	if err := yaml.Unmarshal(openAPIYAML, &spec); err != nil {
+		return nil, fmt.Errorf("openapi.yaml: %w", err)
+	}
+	components, _ := spec["components"].(map[string]interface{})
+	if components == nil {
+		components = make(map[string]interface{})
+		spec["components"] = components
+	}
+	schemas, _ := components["schemas"].(map[string]interface{})
+	if schemas == nil {
+		schemas = make(map[string]interface{})
+		components["schemas"] = schemas
+	}
+
+	reflector := &jsonschema.Reflector{Anonymous: true, AllowAdditionalProperties: true}
+	for _, value := range openAPISchemaTypes {
+		generated, err := json.Marshal(reflector.Reflect(value))
+		if err != nil {
+			return nil, err
+		}
+		// JSON Schema keeps definitions under $defs, OpenAPI under components.schemas
+		generated = []byte(strings.ReplaceAll(string(generated), "#/$defs/", "#/components/schemas/"))
+
+		var schema struct {
+			Defs map[string]interface{} `json:"$defs"`
+		}
+		if err := json.Unmarshal(generated, &schema); err != nil {
+			return nil, err
+		}
+		for name, definition := range schema.Defs {
+			schemas[name] = openAPISchema(definition)
+		}
+	}
+
+	return json.Marshal(spec)
+}
+
+// openAPISchema rewrites JSON Schema constructs OpenAPI 3.0 lacks: the `true` schema
+// (any value, e.g. interface{} fields) becomes {}. The map is rewritten in place.
+func openAPISchema(schema interface{}) interface{} {
+	switch value := schema.(type) {
+	case bool:
+		return map[string]interface{}{}
+	case map[string]interface{}:
+		for key, child := range value {
+			switch key {
+			case "properties", "$defs":
+				for name, property := range child.(map[string]interface{}) {
+					child.(map[string]interface{})[name] = openAPISchema(property)
+				}
+			case "items":
+				value[key] = openAPISchema(child)
+			case "additionalProperties":
+				// additionalProperties may be a boolean in OpenAPI 3.0 too
+				if _, ok := child.(bool); !ok {
+					value[key] = openAPISchema(child)
+				}
+			case "anyOf", "oneOf", "allOf":
+				for i, option := range child.([]interface{}) {
+					child.([]interface{})[i] = openAPISchema(option)
+				}
+			}
+		}
+		return value
+	default:
+		return schema
+	}
+}
+
+// openAPIHandler serves the spec as JSON. It is built once; a broken spec fails at
+// startup rather than on the first request.
+func openAPIHandler() http.HandlerFunc {
+	spec, err := buildOpenAPISpec()
+	if err != nil {
+		panic(fmt.Sprintf("invalid OpenAPI spec: %v", err))
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "GET method required", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(spec)
+	}
+}
+
+// swaggerUIHTML renders /api/v1/openapi.json with Swagger UI from unpkg
+const swaggerUIHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>SENTINEL SHIELD API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// API docs endpoint - Swagger UI over the OpenAPI spec
+func handleDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "GET method required", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(swaggerUIHTML))
+}
@@ -0,0 +1,136 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Approval Pagination
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Page sizes of the scan endpoint's approval list
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// sortApprovalsForPaging orders approvals most severe first, then by token, chain,
+// spender, token type and Permit2, so every approval has a stable position a cursor
+// can point at
+func sortApprovalsForPaging(approvals []Approval) {
+	sort.SliceStable(approvals, func(i, j int) bool {
+		a, b := approvals[i], approvals[j]
+		if ra, rb := riskLevelRank[a.RiskLevel], riskLevelRank[b.RiskLevel]; ra != rb {
+			return ra > rb
+		}
+		if ta, tb := strings.ToLower(a.TokenAddress), strings.ToLower(b.TokenAddress); ta != tb {
+			return ta < tb
+		}
+		if a.Chain != b.Chain {
+			return a.Chain < b.Chain
+		}
+		if sa, sb := strings.ToLower(a.SpenderAddress), strings.ToLower(b.SpenderAddress); sa != sb {
+			return sa < sb
+		}
+		if a.TokenType != b.TokenType {
+			return a.TokenType < b.TokenType
+		}
+		return !a.ViaPermit2 && b.ViaPermit2
+	})
+}
+
+// cursorKey identifies an approval within a scan: a token can be approved to the same
+// spender directly and through Permit2
+func cursorKey(approval Approval) []string {
+	return []string{
+		string(approval.Chain),
+		strings.ToLower(approval.TokenAddress),
+		strings.ToLower(approval.SpenderAddress),
+		approval.TokenType,
+		strconv.FormatBool(approval.ViaPermit2),
+	}
+}
+
+// encodeCursor returns the opaque cursor of an approval: its cursorKey
+func encodeCursor(approval Approval) string {
+	key, _ := json.Marshal(cursorKey(approval))
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// decodeCursor returns the cursorKey a cursor was made from
+func decodeCursor(cursor string) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var key []string
+	if err := json.Unmarshal(raw, &key); err != nil || len(key) != len(cursorKey(Approval{})) {
+		return nil, errInvalidCursor
+	}
+	return key, nil
+}
+
+// parsePageSize reads the limit query parameter (default defaultPageSize, capped at maxPageSize)
+func parsePageSize(raw string) (int, error) {
+	if raw == "" {
+		return defaultPageSize, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return limit, nil
+}
+
+// paginateApprovals sorts the result's approvals and keeps the page of up to limit approvals
+// after cursor, setting Total and NextCursor. Summary fields are left untouched.
+func paginateApprovals(result *WalletScanResult, cursor string, limit int) error {
+	approvals := append([]Approval(nil), result.Approvals...)
+	sortApprovalsForPaging(approvals)
+
+	start := 0
+	if cursor != "" {
+		key, err := decodeCursor(cursor)
+		if err != nil {
+			return err
+		}
+		start = -1
+		for i, approval := range approvals {
+			if slices.Equal(cursorKey(approval), key) {
+				start = i + 1
+				break
+			}
+		}
+		// The approval was revoked or the scan changed since the cursor was issued
+		if start < 0 {
+			return errInvalidCursor
+		}
+	}
+
+	end := len(approvals)
+	if start+limit < end {
+		end = start + limit
+	}
+	result.Total = len(approvals)
+	result.Approvals = approvals[start:end]
+	result.NextCursor = ""
+	if end < len(approvals) {
+		result.NextCursor = encodeCursor(approvals[end-1])
+	}
+	return nil
+}
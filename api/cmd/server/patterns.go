@@ -0,0 +1,168 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Bytecode Pattern Matching
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PatternRule is an opcode sequence that hints at an exploitable construct. The
+// opcodes must appear in order within Window instructions of the first one.
+type PatternRule struct {
+	Name     string
+	Sequence []string
+	Severity string // "warning" or "critical"
+	Window   int
+}
+
+// bytecodePatternRules are the rules every analyzed contract is matched against
+var bytecodePatternRules = []PatternRule{
+	{
+		// External call, then a storage read-modify-write before looping back: state
+		// updated after the call, as flash-loan-funded reentrancy exploits. Plenty of
+		// safe code matches too, so it only warns.
+		Name:     "reentrancy via flash loan",
+		Sequence: []string{"CALL", "SLOAD", "SSTORE", "JUMP"},
+		Severity: "warning",
+		Window:   32,
+	},
+	{
+		// DELEGATECALL right after reading calldata: the caller picks the code that
+		// runs with the contract's storage
+		Name:     "proxy hijack via calldata delegatecall",
+		Sequence: []string{"CALLDATALOAD", "DELEGATECALL"},
+		Severity: "critical",
+		Window:   12,
+	},
+}
+
+// BytecodeMatch is a rule found in a contract's bytecode
+type BytecodeMatch struct {
+	Rule   PatternRule
+	Offset int // index of the first matched instruction
+}
+
+// vulnerability describes the match for ContractRisk.Vulnerabilities
+func (m BytecodeMatch) vulnerability() string {
+	return fmt.Sprintf("%s pattern (%s) at instruction %d", m.Rule.Name, strings.Join(m.Rule.Sequence, " → "), m.Offset)
+}
+
+// BytecodePatternMatcher finds PatternRules in opcode streams
+type BytecodePatternMatcher struct {
+	rules []PatternRule
+}
+
+// NewBytecodePatternMatcher creates a matcher for rules
+func NewBytecodePatternMatcher(rules []PatternRule) *BytecodePatternMatcher {
+	return &BytecodePatternMatcher{rules: rules}
+}
+
+// Match returns the first occurrence of each rule in opcodes, an ordered instruction stream
+func (m *BytecodePatternMatcher) Match(opcodes []string) []BytecodeMatch {
+	var matches []BytecodeMatch
+	for _, rule := range m.rules {
+		if offset, ok := matchSequence(opcodes, rule.Sequence, rule.Window); ok {
+			matches = append(matches, BytecodeMatch{Rule: rule, Offset: offset})
+		}
+	}
+	return matches
+}
+
+// matchSequence finds sequence in order within window instructions of its first opcode
+func matchSequence(opcodes, sequence []string, window int) (int, bool) {
+	if len(sequence) == 0 {
+		return 0, false
+	}
+	for start, opcode := range opcodes {
+		if opcode != sequence[0] {
+			continue
+		}
+		next := 1
+		for i := start + 1; i < len(opcodes) && i-start < window && next < len(sequence); i++ {
+			if opcodes[i] == sequence[next] {
+				next++
+			}
+		}
+		if next == len(sequence) {
+			return start, true
+		}
+	}
+	return 0, false
+}
+
+// opcodeNames names the opcodes pattern rules can refer to; others disassemble as
+// their hex byte
+var opcodeNames = map[byte]string{
+	0x00: "STOP", 0x01: "ADD", 0x14: "EQ", 0x15: "ISZERO",
+	0x33: "CALLER", 0x35: "CALLDATALOAD", 0x36: "CALLDATASIZE", 0x37: "CALLDATACOPY",
+	0x3b: "EXTCODESIZE", 0x3d: "RETURNDATASIZE", 0x3e: "RETURNDATACOPY",
+	0x50: "POP", 0x51: "MLOAD", 0x52: "MSTORE", 0x54: "SLOAD", 0x55: "SSTORE",
+	0x56: "JUMP", 0x57: "JUMPI", 0x5b: "JUMPDEST", 0x5f: "PUSH0",
+	0xf0: "CREATE", 0xf1: "CALL", 0xf2: "CALLCODE", 0xf3: "RETURN", 0xf4: "DELEGATECALL",
+	0xf5: "CREATE2", 0xfa: "STATICCALL", 0xfd: "REVERT", 0xfe: "INVALID", 0xff: "SELFDESTRUCT",
+}
+
+// disassemble turns runtime bytecode into its ordered opcode stream, skipping PUSH data.
+// The decompiler's DecompilerResponse.Opcodes is the set of distinct opcodes, which
+// can't be matched for sequences.
+func disassemble(bytecode []byte) []string {
+	opcodes := make([]string, 0, len(bytecode))
+	for i := 0; i < len(bytecode); i++ {
+		op := bytecode[i]
+		switch name, ok := opcodeNames[op]; {
+		case op >= 0x60 && op <= 0x7f: // PUSH1..PUSH32
+			opcodes = append(opcodes, fmt.Sprintf("PUSH%d", op-0x5f))
+			i += int(op - 0x5f)
+		case ok:
+			opcodes = append(opcodes, name)
+		default:
+			opcodes = append(opcodes, fmt.Sprintf("0x%02x", op))
+		}
+	}
+	return opcodes
+}
+
+// selfDestructPenalty is added to the risk score of contracts containing SELFDESTRUCT
+const selfDestructPenalty = 20
+
+// selfDestructVulnerability is reported for contracts containing SELFDESTRUCT
+const selfDestructVulnerability = "Contract contains SELFDESTRUCT — owner can destroy funds"
+
+// hasSelfDestruct reports whether SELFDESTRUCT (0xff) appears as an instruction of
+// the runtime bytecode. PUSH arguments are skipped so 0xff bytes of literals (masks,
+// max uint values) don't count, and so is the Solidity metadata trailer, which is
+// CBOR data rather than code.
+func hasSelfDestruct(bytecode []byte) bool {
+	code := stripMetadata(bytecode)
+	for i := 0; i < len(code); i++ {
+		switch op := code[i]; {
+		case op >= 0x60 && op <= 0x7f: // PUSH1..PUSH32
+			i += int(op - 0x5f)
+		case op == 0xff:
+			return true
+		}
+	}
+	return false
+}
+
+// stripMetadata drops the CBOR metadata solc appends to runtime bytecode, whose
+// length is given by the last two bytes. Bytecode without a plausible trailer is
+// returned whole.
+func stripMetadata(bytecode []byte) []byte {
+	if len(bytecode) < 2 {
+		return bytecode
+	}
+	length := int(bytecode[len(bytecode)-2])<<8 | int(bytecode[len(bytecode)-1])
+	// The CBOR map starts with 0xa1..0xa5 (a map of 1 to 5 entries)
+	start := len(bytecode) - 2 - length
+	if length == 0 || start < 0 || bytecode[start] < 0xa1 || bytecode[start] > 0xa5 {
+		return bytecode
+	}
+	return bytecode[:start]
+}
@@ -0,0 +1,63 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Pendle PT Expiry
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+// pendleRouter is Pendle's router, the usual spender of PT/YT approvals
+const pendleRouter = "0x0000000001e4ef00d069e71d6ba041b0a16f7ea0"
+
+// Pendle principal token selectors
+const (
+	pendleExpirySelector = "0xe184c9be" // expiry()
+	pendleYTSelector     = "0x781c18db" // YT(), only implemented by principal tokens
+)
+
+// expiredPTReason is added to Pendle Router approvals of matured principal tokens
+const expiredPTReason = "⚠️ Pendle PT has expired — underlying is now redeemable by anyone with your approval"
+
+// checkPendleExpiry flags approvals of Pendle principal tokens to the Pendle Router once
+// the PT has matured. A token counts as a PT when it reports its YT; expiry() then gives
+// the maturity. Tokens whose calls fail are left alone.
+func (c *ChainClient) checkPendleExpiry(ctx context.Context, approvals []Approval, now int64) {
+	expired := make(map[string]bool) // lowercase token -> matured PT
+	for i := range approvals {
+		if !strings.EqualFold(approvals[i].SpenderAddress, pendleRouter) {
+			continue
+		}
+		token := strings.ToLower(approvals[i].TokenAddress)
+		isExpired, checked := expired[token]
+		if !checked {
+			isExpired = c.pendlePTExpired(ctx, token, now)
+			expired[token] = isExpired
+		}
+		if isExpired {
+			approvals[i].RiskReasons = append(approvals[i].RiskReasons, expiredPTReason)
+		}
+	}
+}
+
+// pendlePTExpired reports whether token is a Pendle PT whose expiry is before now
+func (c *ChainClient) pendlePTExpired(ctx context.Context, token string, now int64) bool {
+	if c.callAddress(ctx, token, pendleYTSelector) == "" {
+		return false
+	}
+	result, err := c.ethCall(ctx, token, pendleExpirySelector)
+	if err != nil {
+		return false
+	}
+	words := abiWords(result)
+	if len(words) == 0 {
+		return false
+	}
+	expiry := parseHexUint64(words[0])
+	return expiry > 0 && int64(expiry) < now
+}
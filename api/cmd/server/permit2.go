@@ -0,0 +1,196 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Permit2 Allowances
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// permit2Address is Uniswap's Permit2, deployed at the same address on every chain
+const permit2Address = "0x000000000022d473030f116ddee9f6b43ac78ba3"
+
+// Permit2 AllowanceTransfer events. Allowances granted by signature (permit) never
+// show up as ERC20 Approval events; Permit2 logs them itself.
+const (
+	// Approval(address indexed owner, address indexed token, address indexed spender, uint160 amount, uint48 expiration)
+	permit2ApprovalTopic = "0xda9fa7c1b00402c17d0161b249b1ab8bbec047c5a52207b9c112deffd817036b"
+	// Permit(address indexed owner, address indexed token, address indexed spender, uint160 amount, uint48 expiration, uint48 nonce)
+	permit2PermitTopic = "0xc6a377bfc4eb120024a8ac08eef205be16b817020812c73223e81d1bdb9708ec"
+	// Lockdown(address indexed owner, address token, address spender)
+	permit2LockdownTopic = "0x89b1add15eff56b3dfe299ad94e01f2b52fbcb80ae1a3baea6ae8c04cb2b98a4"
+)
+
+// permit2Key identifies a Permit2 allowance; it is kept apart from the direct
+// ERC20 approval of the same token+spender pair
+func permit2Key(tokenAddress, spenderAddress string) string {
+	return "permit2:" + approvalKey(tokenAddress, spenderAddress)
+}
+
+// approvalStateKey identifies an approval in stored scan state
+func approvalStateKey(approval Approval) string {
+	if approval.ViaPermit2 {
+		return permit2Key(approval.TokenAddress, approval.SpenderAddress)
+	}
+	return approvalKey(approval.TokenAddress, approval.SpenderAddress)
+}
+
+// getPermit2Approvals returns the wallet's active Permit2 allowances set within blocks,
+// and the permit2Keys of allowances the range revoked or that have expired by now
+func (c *ChainClient) getPermit2Approvals(ctx context.Context, walletAddress string, blocks blockRange, now int64) ([]Approval, []string, error) {
+	var logs []approvalLog
+	for _, topic := range []string{permit2ApprovalTopic, permit2PermitTopic, permit2LockdownTopic} {
+		topicLogs, err := c.getPermit2Logs(ctx, walletAddress, topic, blocks)
+		if err != nil {
+			return nil, nil, err
+		}
+		logs = append(logs, topicLogs...)
+	}
+
+	// Events of all three topics are replayed in chain order so later ones win
+	sort.SliceStable(logs, func(i, j int) bool {
+		bi, bj := parseHexUint64(logs[i].BlockNumber), parseHexUint64(logs[j].BlockNumber)
+		if bi != bj {
+			return bi < bj
+		}
+		return parseHexUint64(logs[i].LogIndex) < parseHexUint64(logs[j].LogIndex)
+	})
+
+	latest := make(map[string]Approval)
+	revoked := make(map[string]bool)
+	for _, logEntry := range logs {
+		if len(logEntry.Topics) < 2 {
+			continue
+		}
+		words := abiWords(logEntry.Data)
+
+		var tokenAddress, spenderAddress string
+		amount, expiration := new(big.Int), uint64(0)
+		if strings.EqualFold(logEntry.Topics[0], permit2LockdownTopic) {
+			// Lockdown zeroes the allowance; token and spender are not indexed
+			if len(words) < 2 {
+				continue
+			}
+			tokenAddress, spenderAddress = "0x"+words[0][24:], "0x"+words[1][24:]
+		} else {
+			isAllowanceEvent := strings.EqualFold(logEntry.Topics[0], permit2ApprovalTopic) || strings.EqualFold(logEntry.Topics[0], permit2PermitTopic)
+			if !isAllowanceEvent || len(logEntry.Topics) < 4 || len(words) < 2 {
+				continue
+			}
+			tokenAddress, spenderAddress = "0x"+logEntry.Topics[2][26:], "0x"+logEntry.Topics[3][26:]
+			amount.SetString(words[0], 16)
+			expirationWord, _ := new(big.Int).SetString(words[1], 16)
+			expiration = expirationWord.Uint64()
+		}
+
+		// A zero amount or a past expiration leaves nothing to spend; expiration 0
+		// means the allowance only lasted for the block it was set in
+		key := permit2Key(tokenAddress, spenderAddress)
+		if amount.Sign() == 0 || int64(expiration) <= now {
+			delete(latest, key)
+			revoked[key] = true
+			continue
+		}
+		delete(revoked, key)
+
+		isUnlimited := isUnlimitedWithDecimals(amount, c.tokenDecimalsOrDefault(ctx, tokenAddress))
+		spenderName, spenderRisk := getSpenderInfo(spenderAddress)
+		spenderName, spenderRisk = c.resolveSpenderENS(ctx, spenderAddress, spenderName, spenderRisk)
+
+		riskReasons := []string{fmt.Sprintf("Permit2 allowance, expires %s", time.Unix(int64(expiration), 0).UTC().Format("2006-01-02"))}
+		if isUnlimited {
+			riskReasons = append(riskReasons, "Unlimited approval")
+		}
+
+		latest[key] = Approval{
+			Chain:             c.ChainID,
+			TokenAddress:      tokenAddress,
+			TokenSymbol:       getTokenSymbol(tokenAddress, c),
+			TokenType:         tokenTypeERC20,
+			SpenderAddress:    spenderAddress,
+			SpenderName:       spenderName,
+			AllowanceRaw:      amount.String(),
+			AllowanceHuman:    c.formatAllowanceForToken(ctx, amount, tokenAddress),
+			AllowanceUSD:      allowanceUSDValue(amount, tokenAddress, isUnlimited),
+			IsUnlimited:       isUnlimited,
+			ViaPermit2:        true,
+			ExpiresAt:         int64(expiration),
+			RiskLevel:         spenderRisk,
+			RiskReasons:       riskReasons,
+			LastUpdated:       int64(parseHexUint64(logEntry.TimeStamp)),
+			TxHash:            logEntry.TxHash,
+			LogIndex:          int(parseHexUint64(logEntry.LogIndex)),
+			BlockNumber:       parseHexUint64(logEntry.BlockNumber),
+			TransferFromCount: -1,
+		}
+	}
+
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	approvals := make([]Approval, 0, len(keys))
+	for _, key := range keys {
+		approvals = append(approvals, latest[key])
+	}
+	requestLogger(ctx, c.logger()).Info("Found active Permit2 allowances", Fields{"wallet": walletAddress, "approvals_found": len(approvals)})
+	return approvals, sortedKeys(revoked), nil
+}
+
+// getPermit2Logs fetches the Permit2 events of one topic owned by the wallet within blocks
+func (c *ChainClient) getPermit2Logs(ctx context.Context, walletAddress, topic string, blocks blockRange) ([]approvalLog, error) {
+	paddedWallet := padTopicAddress(walletAddress)
+
+	if endpoint, ok := alchemyConfig.Endpoints[string(c.ChainID)]; ok {
+		logs, err := c.getLogsAlchemy(ctx, endpoint, map[string]interface{}{
+			"fromBlock": fmt.Sprintf("0x%x", blocks.From),
+			"toBlock":   blocks.toBlockParam(),
+			"address":   permit2Address,
+			"topics":    []string{topic, paddedWallet},
+		})
+		if err == nil {
+			return logs, nil
+		}
+		requestLogger(ctx, c.logger()).Warn("Alchemy Permit2 lookup failed, trying Etherscan", Fields{"error": errorText(err)})
+	}
+
+	var logs []approvalLog
+	query := fmt.Sprintf(
+		"module=logs&action=getLogs&fromBlock=%d&toBlock=%s&address=%s&topic0=%s&topic0_1_opr=and&topic1=%s",
+		blocks.From,
+		blocks.toBlockQuery(),
+		permit2Address,
+		topic,
+		paddedWallet,
+	)
+	if err := c.etherscanQuery(ctx, query, &logs); err != nil {
+		if strings.Contains(err.Error(), "No records found") {
+			return []approvalLog{}, nil
+		}
+		return nil, err
+	}
+	return logs, nil
+}
+
+// dropExpiredApprovals removes Permit2 allowances that expired by now, the way
+// zero allowances are dropped
+func dropExpiredApprovals(approvals []Approval, now int64) []Approval {
+	active := make([]Approval, 0, len(approvals))
+	for _, approval := range approvals {
+		if approval.ExpiresAt != 0 && approval.ExpiresAt <= now {
+			continue
+		}
+		active = append(active, approval)
+	}
+	return active
+}
@@ -0,0 +1,150 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Token Prices
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenPriceTTL is how long a CoinGecko token price is cached
+const tokenPriceTTL = 10 * time.Minute
+
+// maxPriceLookups caps concurrent CoinGecko requests per chain scan
+const maxPriceLookups = 4
+
+// PriceEnricher values allowances with CoinGecko token prices
+type PriceEnricher struct {
+	cache  CacheStore
+	client *http.Client
+}
+
+// NewPriceEnricher creates an enricher caching prices in cache
+func NewPriceEnricher(cache CacheStore) *PriceEnricher {
+	return &PriceEnricher{cache: cache, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// GetUSDValue returns the USD value of rawAmount of a token
+func (p *PriceEnricher) GetUSDValue(tokenAddress string, chain ChainID, rawAmount *big.Int) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	price, err := p.tokenPriceUSD(ctx, tokenAddress, chain)
+	if err != nil {
+		return 0, err
+	}
+
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(cachedTokenDecimals(chain, tokenAddress))), nil))
+	tokens, _ := new(big.Float).Quo(new(big.Float).SetInt(rawAmount), divisor).Float64()
+	return tokens * price, nil
+}
+
+// tokenPriceUSD returns the CoinGecko USD price of a token. Unpriced tokens are cached
+// too, so each is looked up at most once per tokenPriceTTL.
+func (p *PriceEnricher) tokenPriceUSD(ctx context.Context, tokenAddress string, chain ChainID) (float64, error) {
+	platform, ok := coinGeckoPlatforms[chain]
+	if !ok {
+		return 0, fmt.Errorf("no CoinGecko platform for %s", chain)
+	}
+	tokenAddress = strings.ToLower(tokenAddress)
+	cacheKey := "price:" + platform + ":" + tokenAddress
+	if cached, ok := p.cache.Get(cacheKey); ok {
+		if price := cached.(float64); price > 0 {
+			return price, nil
+		}
+		return 0, fmt.Errorf("no price for %s on %s", tokenAddress, chain)
+	}
+
+	url := fmt.Sprintf("%s/simple/token_price/%s?contract_addresses=%s&vs_currencies=usd", coinGeckoBaseURL, platform, tokenAddress)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("coingecko returned status %d", resp.StatusCode)
+	}
+
+	var prices map[string]struct {
+		USD float64 `json:"usd"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return 0, err
+	}
+
+	price := prices[tokenAddress].USD
+	p.cache.SetWithTTL(cacheKey, price, tokenPriceTTL)
+	if price <= 0 {
+		return 0, fmt.Errorf("no price for %s on %s", tokenAddress, chain)
+	}
+	return price, nil
+}
+
+// priceApprovals starts valuing the approvals whose USD value is still unknown and returns
+// a func that waits for the lookups and sets AllowanceUSD. The approvals are only read
+// up front, so the rest of the enrichment can run meanwhile.
+func (p *PriceEnricher) priceApprovals(approvals []Approval) func() {
+	if p == nil {
+		return func() {}
+	}
+
+	type lookup struct {
+		index  int
+		token  string
+		chain  ChainID
+		amount *big.Int
+	}
+	var lookups []lookup
+	for i, approval := range approvals {
+		// Unlimited and NFT approvals have no bounded value
+		if approval.AllowanceUSD >= 0 || approval.IsUnlimited || approval.IsNFT {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(approval.AllowanceRaw, 10)
+		if !ok {
+			continue
+		}
+		lookups = append(lookups, lookup{index: i, token: approval.TokenAddress, chain: approval.Chain, amount: amount})
+	}
+
+	values := make([]float64, len(lookups))
+	sem := make(chan struct{}, maxPriceLookups)
+	var wg sync.WaitGroup
+	for i, l := range lookups {
+		wg.Add(1)
+		go func(i int, l lookup) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			value, err := p.GetUSDValue(l.token, l.chain, l.amount)
+			if err != nil {
+				value = -1
+			}
+			values[i] = value
+		}(i, l)
+	}
+
+	return func() {
+		wg.Wait()
+		for i, l := range lookups {
+			approvals[l.index].AllowanceUSD = values[i]
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Proxy Detection
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Storage slots proxies keep their implementation and admin in
+const (
+	// bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+	eip1967ImplementationSlot = "0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"
+	// bytes32(uint256(keccak256("eip1967.proxy.admin")) - 1), set by Transparent proxies
+	eip1967AdminSlot = "0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103"
+	// keccak256("PROXIABLE"), the EIP-1822 (UUPS) implementation slot
+	eip1822ProxiableSlot = "0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7"
+)
+
+// Proxy types reported in ContractRisk.ProxyType
+const (
+	proxyTypeEIP1167 = "EIP-1167" // minimal proxy (clone)
+	proxyTypeEIP1967 = "EIP-1967"
+	proxyTypeUUPS    = "UUPS" // EIP-1822
+)
+
+// EIP-1167 minimal proxy runtime code around the 20-byte implementation address
+var (
+	minimalProxyPrefix = []byte{0x36, 0x3d, 0x3d, 0x37, 0x3d, 0x3d, 0x3d, 0x36, 0x3d, 0x73}
+	minimalProxySuffix = []byte{0x5a, 0xf4, 0x3d, 0x82, 0x80, 0x3e, 0x90, 0x3d, 0x91, 0x60, 0x2b, 0x57, 0xfd, 0x5b, 0xf3}
+)
+
+// EIP-2535 Diamond loupe
+const (
+	diamondLoupeInterfaceID = "48e2b093"   // IDiamondLoupe
+	facetAddressesSelector  = "0x52ef6b2c" // facetAddresses()
+)
+
+// maxDiamondFacets caps the facets of a Diamond that are analyzed
+const maxDiamondFacets = 32
+
+// ProxyInfo is what a contract's proxy storage slots reveal
+type ProxyInfo struct {
+	IsProxy               bool
+	Type                  string // proxyTypeEIP1967 or proxyTypeUUPS
+	ImplementationAddress string // "" when only the admin slot is set
+	AdminAddress          string
+}
+
+// DetectProxy reads the EIP-1967 implementation and admin slots and the EIP-1822 slot
+// of a contract; a non-zero value in any of them marks it a proxy. It works without the
+// decompiler, whose IsProxy is lost when it is down.
+func (c *ChainClient) DetectProxy(ctx context.Context, address string) (ProxyInfo, error) {
+	var info ProxyInfo
+	for _, slot := range []string{eip1967ImplementationSlot, eip1822ProxiableSlot, eip1967AdminSlot} {
+		var value string
+		if err := c.rpcCall(ctx, "eth_getStorageAt", []interface{}{address, slot, "latest"}, &value); err != nil {
+			return ProxyInfo{}, err
+		}
+		slotAddress, ok := storageSlotAddress(value)
+		if !ok {
+			continue
+		}
+		info.IsProxy = true
+		switch {
+		case slot == eip1967AdminSlot:
+			info.AdminAddress = slotAddress
+		case info.ImplementationAddress == "":
+			info.ImplementationAddress = slotAddress
+		}
+		if info.Type == "" {
+			info.Type = proxyTypeEIP1967
+			if slot == eip1822ProxiableSlot {
+				info.Type = proxyTypeUUPS
+			}
+		}
+	}
+	return info, nil
+}
+
+// minimalProxyImplementation returns the implementation an EIP-1167 clone delegates
+// to, read from bytes 10-30 of its runtime code
+func minimalProxyImplementation(bytecode []byte) (string, bool) {
+	if len(bytecode) < 45 || !bytes.HasPrefix(bytecode, minimalProxyPrefix) || !bytes.Equal(bytecode[30:45], minimalProxySuffix) {
+		return "", false
+	}
+	return "0x" + hex.EncodeToString(bytecode[10:30]), true
+}
+
+// storageSlotAddress returns the address held in a non-zero 32-byte storage value
+func storageSlotAddress(value string) (string, bool) {
+	word := strings.TrimPrefix(value, "0x")
+	if len(word) != 64 || strings.Trim(word, "0") == "" {
+		return "", false
+	}
+	return "0x" + strings.ToLower(word[24:]), true
+}
+
+// DiamondFacets returns the facet addresses of an EIP-2535 Diamond, or nil when the
+// contract doesn't report IDiamondLoupe through ERC-165
+func (c *ChainClient) DiamondFacets(ctx context.Context, address string) ([]string, error) {
+	if !c.callBool(ctx, address, supportsInterfaceSelector+padSelector(diamondLoupeInterfaceID)) {
+		return nil, nil
+	}
+	result, err := c.ethCall(ctx, address, facetAddressesSelector)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAddressArray(result)
+}
+
+// decodeAddressArray decodes an eth_call result holding a single address[]
+func decodeAddressArray(result string) ([]string, error) {
+	words := abiWords(result)
+	wordAt := func(i int) (int, bool) {
+		if i < 0 || i >= len(words) {
+			return 0, false
+		}
+		n, ok := new(big.Int).SetString(words[i], 16)
+		if !ok || !n.IsInt64() || n.Int64() > int64(len(words)*32) {
+			return 0, false
+		}
+		return int(n.Int64()), true
+	}
+
+	offset, ok := wordAt(0)
+	if !ok || offset%32 != 0 {
+		return nil, fmt.Errorf("invalid address[] offset")
+	}
+	count, ok := wordAt(offset / 32)
+	start := offset/32 + 1
+	if !ok || start+count > len(words) {
+		return nil, fmt.Errorf("address[] result truncated")
+	}
+
+	addresses := make([]string, count)
+	for i := range addresses {
+		addresses[i] = "0x" + words[start+i][24:]
+	}
+	return addresses, nil
+}
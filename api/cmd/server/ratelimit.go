@@ -0,0 +1,159 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Rate Limiting
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of the per-IP request rate limit
+const (
+	defaultRateLimitRPS   = 10.0
+	defaultRateLimitBurst = 20
+)
+
+// rateLimitIdleTTL is how long an IP's bucket is kept after its last request
+const rateLimitIdleTTL = 5 * time.Minute
+
+// rateLimitExempt are paths probes and scrapers hit that are never limited
+var rateLimitExempt = map[string]bool{
+	"/health":       true,
+	"/health/live":  true,
+	"/health/ready": true,
+	"/metrics":      true,
+}
+
+// RateLimiter limits requests per remote IP with a token bucket per IP
+type RateLimiter struct {
+	rps   float64
+	burst float64
+	clock Clock
+
+	// buckets maps remote IP to *ipBucket
+	buckets sync.Map
+
+	sweepMu   sync.Mutex
+	lastSweep time.Time
+}
+
+// ipBucket holds the tokens of one remote IP
+type ipBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	// last is the time of the IP's last request
+	last time.Time
+}
+
+// NewRateLimiter allows each IP burst requests at once, refilled at rps per second
+// (rps <= 0 and burst <= 0 use the defaults)
+func NewRateLimiter(rps float64, burst int, clock Clock) *RateLimiter {
+	if rps <= 0 {
+		rps = defaultRateLimitRPS
+	}
+	if burst <= 0 {
+		burst = defaultRateLimitBurst
+	}
+	return &RateLimiter{rps: rps, burst: float64(burst), clock: clock, lastSweep: clock.Now()}
+}
+
+// NewRateLimiterFromEnv creates a limiter configured by RATE_LIMIT_RPS and RATE_LIMIT_BURST
+func NewRateLimiterFromEnv() *RateLimiter {
+	return NewRateLimiter(
+		getEnvFloat("RATE_LIMIT_RPS", defaultRateLimitRPS),
+		getEnvInt("RATE_LIMIT_BURST", defaultRateLimitBurst),
+		RealClock{},
+	)
+}
+
+// Middleware rejects requests over the caller's limit with 429 and a Retry-After
+// of the (fractional) seconds until the next token. /health and /metrics are exempt.
+func (rl *RateLimiter) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rateLimitExempt[r.URL.Path] {
+			next(w, r)
+			return
+		}
+
+		if wait := rl.take(clientIP(r)); wait > 0 {
+			w.Header().Set("Retry-After", strconv.FormatFloat(wait.Seconds(), 'f', 3, 64))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// take spends one of ip's tokens, returning 0 on success or the wait until one is available
+func (rl *RateLimiter) take(ip string) time.Duration {
+	now := rl.clock.Now()
+	rl.sweep(now)
+
+	value, _ := rl.buckets.LoadOrStore(ip, &ipBucket{tokens: rl.burst, last: now})
+	bucket := value.(*ipBucket)
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * rl.rps
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0
+	}
+	return time.Duration((1 - bucket.tokens) / rl.rps * float64(time.Second))
+}
+
+// sweep drops buckets idle for rateLimitIdleTTL, at most once per minute
+func (rl *RateLimiter) sweep(now time.Time) {
+	rl.sweepMu.Lock()
+	if now.Sub(rl.lastSweep) < time.Minute {
+		rl.sweepMu.Unlock()
+		return
+	}
+	rl.lastSweep = now
+	rl.sweepMu.Unlock()
+
+	rl.buckets.Range(func(key, value interface{}) bool {
+		bucket := value.(*ipBucket)
+		bucket.mu.Lock()
+		idle := now.Sub(bucket.last) >= rateLimitIdleTTL
+		bucket.mu.Unlock()
+		if idle {
+			rl.buckets.Delete(key)
+		}
+		return true
+	})
+}
+
+// clientIP returns the request's client IP: the one IPFilter.Middleware resolved behind
+// TRUSTED_PROXIES, otherwise the remote IP. X-Forwarded-For from other clients is
+// ignored since any client can set it to dodge its limit.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPContextKey{}).(string); ok && ip != "" {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// remoteIP returns the IP of the request's remote address
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
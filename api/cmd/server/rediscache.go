@@ -0,0 +1,193 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Redis Cache
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis calls are bounded so an unreachable Redis only costs a cache miss
+const (
+	redisOpTimeout   = 250 * time.Millisecond
+	redisRetryPeriod = 5 * time.Second
+	redisKeyPrefix   = "sentinel:"
+)
+
+// cacheValueTypes are the types RedisCache can decode, keyed by their %T name.
+// Values of other types are not cached in Redis.
+var cacheValueTypes = cacheTypeRegistry(
+	&WalletScanResult{},
+	&ContractAnalysisResult{},
+	&FeeData{},
+	tokenDisplayInfo{},
+	SpenderClassification{},
+	ensSpenderEntry{},
+	float64(0),
+	int(0),
+	int64(0),
+	"",
+)
+
+func cacheTypeRegistry(samples ...interface{}) map[string]reflect.Type {
+	types := make(map[string]reflect.Type, len(samples))
+	for _, sample := range samples {
+		types[fmt.Sprintf("%T", sample)] = reflect.TypeOf(sample)
+	}
+	return types
+}
+
+// redisEntry is the JSON stored under a key; Type picks the Go type Value decodes into
+type redisEntry struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// redisClients shares one connection pool per REDIS_URL across caches
+var redisClients sync.Map
+
+// RedisCache is a Cache shared by every API instance. Values are stored as JSON
+// with the cache TTL as the Redis expiry.
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+	// downUntil (unix nanoseconds) skips Redis for a while after it failed
+	downUntil atomic.Int64
+}
+
+// NewRedisCache creates a cache stored in the Redis at redisURL (redis://...)
+func NewRedisCache(redisURL string, ttl time.Duration) (*RedisCache, error) {
+	client, ok := redisClients.Load(redisURL)
+	if !ok {
+		options, err := redis.ParseURL(redisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		options.DialTimeout = redisOpTimeout
+		options.ReadTimeout = redisOpTimeout
+		options.WriteTimeout = redisOpTimeout
+		options.MaxRetries = -1
+		client, _ = redisClients.LoadOrStore(redisURL, redis.NewClient(options))
+	}
+	return &RedisCache{client: client.(*redis.Client), ttl: ttl}, nil
+}
+
+// available reports whether Redis should be tried; failures back off for redisRetryPeriod
+func (c *RedisCache) available() bool {
+	return time.Now().UnixNano() >= c.downUntil.Load()
+}
+
+// markDown logs the first failure of an outage and skips Redis for redisRetryPeriod
+func (c *RedisCache) markDown(err error) {
+	if c.downUntil.Swap(time.Now().Add(redisRetryPeriod).UnixNano()) == 0 {
+		defaultLogger.Warn("Redis cache unavailable, falling through to sources", Fields{"error": errorText(err)})
+	}
+}
+
+// markUp clears a recorded outage
+func (c *RedisCache) markUp() {
+	if c.downUntil.Swap(0) != 0 {
+		defaultLogger.Info("Redis cache available again", nil)
+	}
+}
+
+func (c *RedisCache) Get(key string) (interface{}, bool) {
+	if !c.available() {
+		observeCacheLookup(false)
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	raw, err := c.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		c.markUp()
+		observeCacheLookup(false)
+		return nil, false
+	}
+	if err != nil {
+		c.markDown(err)
+		observeCacheLookup(false)
+		return nil, false
+	}
+	c.markUp()
+	value, ok := decodeCacheEntry(raw)
+	observeCacheLookup(ok)
+	return value, ok
+}
+
+func (c *RedisCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores value for ttl instead of the cache's default TTL
+func (c *RedisCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	if !c.available() {
+		return
+	}
+	raw, ok := encodeCacheEntry(value)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := c.client.Set(ctx, redisKeyPrefix+key, raw, ttl).Err(); err != nil {
+		c.markDown(err)
+		return
+	}
+	c.markUp()
+}
+
+// encodeCacheEntry returns the Redis JSON of value; false when its type is not registered
+func encodeCacheEntry(value interface{}) ([]byte, bool) {
+	typeName := fmt.Sprintf("%T", value)
+	if _, ok := cacheValueTypes[typeName]; !ok {
+		return nil, false
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	raw, err := json.Marshal(redisEntry{Type: typeName, Value: encoded})
+	return raw, err == nil
+}
+
+// decodeCacheEntry decodes Redis JSON into the registered type it was stored as.
+// Pointers decode into a fresh value; plain types are returned by value.
+func decodeCacheEntry(raw []byte) (interface{}, bool) {
+	var entry redisEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false
+	}
+	valueType, ok := cacheValueTypes[entry.Type]
+	if !ok {
+		return nil, false
+	}
+
+	if valueType.Kind() == reflect.Pointer {
+		value := reflect.New(valueType.Elem())
+		if err := json.Unmarshal(entry.Value, value.Interface()); err != nil {
+			return nil, false
+		}
+		return value.Interface(), true
+	}
+	value := reflect.New(valueType)
+	if err := json.Unmarshal(entry.Value, value.Interface()); err != nil {
+		return nil, false
+	}
+	return value.Elem().Interface(), true
+}
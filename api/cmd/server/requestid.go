@@ -0,0 +1,66 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Request IDs
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// requestIDPattern accepts client-supplied request IDs that are safe to log and echo
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDKey carries the request ID in the request context
+type requestIDKey struct{}
+
+// RequestIDMiddleware tags each request with the caller's X-Request-ID, or a new UUID
+// when it is missing or malformed, and echoes it in the X-Request-ID response header.
+// Logs of the request and calls to the decompiler and analyzer carry it.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID)))
+	})
+}
+
+// requestIDFrom returns the request ID of ctx ("" outside a request)
+func requestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// requestIDOrNew returns the request ID of ctx, or a new one for error reports
+// outside a request
+func requestIDOrNew(ctx context.Context) string {
+	if requestID := requestIDFrom(ctx); requestID != "" {
+		return requestID
+	}
+	return uuid.NewString()
+}
+
+// requestLogger returns logger with the request_id field of ctx, if any
+func requestLogger(ctx context.Context, logger Logger) Logger {
+	if requestID := requestIDFrom(ctx); requestID != "" {
+		return logger.With(Fields{"request_id": requestID})
+	}
+	return logger
+}
+
+// setRequestIDHeader forwards the request ID of ctx to an internal service
+func setRequestIDHeader(ctx context.Context, req *http.Request) {
+	if requestID := requestIDFrom(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Retries
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// defaultRetryAttempts is the number of tries withRetry makes unless told otherwise
+const defaultRetryAttempts = 3
+
+// retryBaseDelay is the wait before the first retry; it doubles per retry (overridable in tests)
+var retryBaseDelay = 500 * time.Millisecond
+
+// retryJitter is the fraction a retry delay is randomly shortened or lengthened by
+const retryJitter = 0.2
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// noRetry wraps err so withRetry returns it without retrying
+func noRetry(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// withRetry calls fn up to maxAttempts times (<= 0 = defaultRetryAttempts), waiting
+// 500ms, 1s, 2s, ... ±20% between attempts. Errors wrapped with noRetry are returned
+// at once; if ctx ends while waiting, ctx.Err() is returned.
+func withRetry(ctx context.Context, maxAttempts int, fn func() error) error {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryAttempts
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt+1 >= maxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay returns the jittered wait after the given failed attempt (0-based)
+func retryDelay(attempt int) time.Duration {
+	delay := float64(retryBaseDelay) * float64(int64(1)<<attempt)
+	return time.Duration(delay * (1 + retryJitter*(2*rand.Float64()-1)))
+}
+
+// checkHTTPStatus returns nil for 2xx responses. Rate limits (429) and server errors
+// are retryable; other client errors are not.
+func checkHTTPStatus(provider string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err := fmt.Errorf("%s returned HTTP %d", provider, resp.StatusCode)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return noRetry(err)
+	}
+	return err
+}
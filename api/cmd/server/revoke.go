@@ -0,0 +1,239 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Revocation Transactions
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Function selectors of the revocation calls
+const (
+	approveSelector           = "0x095ea7b3" // approve(address,uint256)
+	setApprovalForAllSelector = "0xa22cb465" // setApprovalForAll(address,bool)
+	permit2ApproveSelector    = "0x87517c45" // Permit2 approve(address,address,uint160,uint48)
+)
+
+// maxRevokeApprovals caps the revocations built per request
+const maxRevokeApprovals = 50
+
+// RevokeApproval identifies an approval to revoke; fields match Approval so scan
+// results can be sent back as they are
+type RevokeApproval struct {
+	Chain          ChainID `json:"chain"`
+	TokenAddress   string  `json:"tokenAddress"`
+	SpenderAddress string  `json:"spenderAddress"`
+	IsNFT          bool    `json:"isNft"`      // revoke with setApprovalForAll(spender, false)
+	ViaPermit2     bool    `json:"viaPermit2"` // revoke with Permit2 approve(token, spender, 0, 0)
+}
+
+// RevokeTransaction is an unsigned EIP-1559 transaction revoking one approval.
+// Quantities are hex strings, as wallets expect them in eth_sendTransaction.
+type RevokeTransaction struct {
+	Chain                ChainID `json:"chain"`
+	TokenAddress         string  `json:"tokenAddress"`
+	SpenderAddress       string  `json:"spenderAddress"`
+	Method               string  `json:"method"`
+	Type                 string  `json:"type"` // "0x2" = EIP-1559
+	From                 string  `json:"from"`
+	To                   string  `json:"to"`
+	Data                 string  `json:"data"`
+	Value                string  `json:"value"`
+	ChainID              string  `json:"chainId"`
+	GasLimit             string  `json:"gasLimit"`
+	GasEstimated         bool    `json:"gasEstimated"` // false = default gas limit used
+	MaxFeePerGas         string  `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string  `json:"maxPriorityFeePerGas"`
+}
+
+// RevokeError reports an approval no transaction could be built for
+type RevokeError struct {
+	Chain          ChainID `json:"chain"`
+	TokenAddress   string  `json:"tokenAddress"`
+	SpenderAddress string  `json:"spenderAddress"`
+	Error          string  `json:"error"`
+}
+
+// RevokeResponse is returned by POST /api/v1/revoke
+type RevokeResponse struct {
+	Wallet       string              `json:"wallet"`
+	Transactions []RevokeTransaction `json:"transactions"`
+	Errors       []RevokeError       `json:"errors"`
+}
+
+// revokeCalldata encodes approve(spender, 0), or setApprovalForAll(spender, false) for NFTs
+func revokeCalldata(spender string, isNFT bool) string {
+	selector := approveSelector
+	if isNFT {
+		selector = setApprovalForAllSelector
+	}
+	// Both take the spender and a zero word (amount 0 / approved false)
+	return selector + strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(spender), "0x") +
+		strings.Repeat("0", 64)
+}
+
+// permit2RevokeCalldata encodes Permit2 approve(token, spender, 0, 0), which zeroes
+// the allowance; the token's own approval of Permit2 is left alone
+func permit2RevokeCalldata(token, spender string) string {
+	return permit2ApproveSelector +
+		strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(token), "0x") +
+		strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(spender), "0x") +
+		strings.Repeat("0", 128)
+}
+
+// gweiToHexWei converts a gwei amount to a hex wei quantity
+func gweiToHexWei(gwei float64) string {
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(1e9)).Int(nil)
+	return "0x" + wei.Text(16)
+}
+
+// numericChainID returns the EIP-155 chain ID of a chain, asking the RPC for chains
+// without a configured ID
+func (c *ChainClient) numericChainID(ctx context.Context) (uint64, error) {
+	chainsMu.RLock()
+	id, ok := etherscanConfig.ChainIDs[string(c.ChainID)]
+	chainsMu.RUnlock()
+	if ok {
+		return uint64(id), nil
+	}
+
+	var idHex string
+	if err := c.rpcCall(ctx, "eth_chainId", []interface{}{}, &idHex); err != nil {
+		return 0, err
+	}
+	if parsed := parseHexUint64(idHex); parsed != 0 {
+		return parsed, nil
+	}
+	return 0, fmt.Errorf("invalid chain ID %q", idHex)
+}
+
+// buildRevokeTransaction builds the unsigned revocation of one approval. Fees use the
+// standard priority fee and allow the base fee to double before inclusion.
+func (s *Server) buildRevokeTransaction(ctx context.Context, wallet string, approval RevokeApproval) (RevokeTransaction, error) {
+	chainsMu.RLock()
+	client, ok := s.chainClients[approval.Chain]
+	chainsMu.RUnlock()
+	if !ok {
+		return RevokeTransaction{}, fmt.Errorf("unsupported chain: %s", approval.Chain)
+	}
+
+	chainID, err := client.numericChainID(ctx)
+	if err != nil {
+		return RevokeTransaction{}, fmt.Errorf("failed to fetch chain ID: %w", err)
+	}
+	fees, err := s.feeMarket.GetCurrentFees(ctx, approval.Chain)
+	if err != nil {
+		return RevokeTransaction{}, fmt.Errorf("failed to fetch fee data: %w", err)
+	}
+
+	tx := RevokeTransaction{
+		Chain:                approval.Chain,
+		TokenAddress:         strings.ToLower(approval.TokenAddress),
+		SpenderAddress:       strings.ToLower(approval.SpenderAddress),
+		Method:               "approve(address,uint256)",
+		Type:                 "0x2",
+		From:                 strings.ToLower(wallet),
+		To:                   strings.ToLower(approval.TokenAddress),
+		Data:                 revokeCalldata(approval.SpenderAddress, approval.IsNFT),
+		Value:                "0x0",
+		ChainID:              fmt.Sprintf("0x%x", chainID),
+		GasLimit:             fmt.Sprintf("0x%x", defaultRevokeGasLimit),
+		MaxFeePerGas:         gweiToHexWei(2*fees.EstimatedNextBaseFee + fees.MaxPriorityFeeGwei),
+		MaxPriorityFeePerGas: gweiToHexWei(fees.MaxPriorityFeeGwei),
+	}
+	switch {
+	case approval.ViaPermit2:
+		// The allowance lives in Permit2, so approving 0 on the token would leave it live
+		tx.Method = "approve(address,address,uint160,uint48)"
+		tx.To = permit2Address
+		tx.Data = permit2RevokeCalldata(approval.TokenAddress, approval.SpenderAddress)
+		tx.GasLimit = fmt.Sprintf("0x%x", permit2RevokeGasLimit)
+	case approval.IsNFT:
+		tx.Method = "setApprovalForAll(address,bool)"
+	}
+	if gas, err := client.estimateGas(ctx, tx.From, tx.To, tx.Data); err == nil {
+		tx.GasLimit = fmt.Sprintf("0x%x", gas)
+		tx.GasEstimated = true
+	}
+	return tx, nil
+}
+
+// Revocation endpoint - builds unsigned revocation transactions for the wallet to sign.
+// It never takes keys or signs: the transactions are sent by the user's wallet.
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Wallet    string           `json:"wallet"`
+		Approvals []RevokeApproval `json:"approvals"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if !isValidEthereumAddress(req.Wallet) {
+		http.Error(w, "wallet must be a 0x address", http.StatusBadRequest)
+		return
+	}
+	if !walletAllowed(r, req.Wallet) {
+		writeAuthError(w, http.StatusForbidden, "API key is not authorized for this wallet")
+		return
+	}
+	if len(req.Approvals) == 0 {
+		http.Error(w, "no approvals provided", http.StatusBadRequest)
+		return
+	}
+	if len(req.Approvals) > maxRevokeApprovals {
+		http.Error(w, fmt.Sprintf("max %d approvals per request", maxRevokeApprovals), http.StatusBadRequest)
+		return
+	}
+	for i, approval := range req.Approvals {
+		if !isValidEthereumAddress(approval.TokenAddress) || !isValidEthereumAddress(approval.SpenderAddress) {
+			http.Error(w, fmt.Sprintf("approvals[%d]: tokenAddress and spenderAddress must be 0x addresses", i), http.StatusBadRequest)
+			return
+		}
+		req.Approvals[i].Chain = ChainID(strings.ToLower(string(approval.Chain)))
+		if req.Approvals[i].Chain == "" {
+			req.Approvals[i].Chain = Ethereum
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	response := RevokeResponse{
+		Wallet:       strings.ToLower(req.Wallet),
+		Transactions: make([]RevokeTransaction, 0, len(req.Approvals)),
+		Errors:       make([]RevokeError, 0),
+	}
+	for _, approval := range req.Approvals {
+		tx, err := s.buildRevokeTransaction(ctx, req.Wallet, approval)
+		if err != nil {
+			response.Errors = append(response.Errors, RevokeError{
+				Chain:          approval.Chain,
+				TokenAddress:   strings.ToLower(approval.TokenAddress),
+				SpenderAddress: strings.ToLower(approval.SpenderAddress),
+				Error:          errorText(err),
+			})
+			continue
+		}
+		response.Transactions = append(response.Transactions, tx)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
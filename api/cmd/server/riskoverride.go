@@ -0,0 +1,188 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Risk Overrides
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RiskOverride pins the risk level of a spender, e.g. a drainer the threat feed
+// doesn't know yet
+type RiskOverride struct {
+	Address   string `json:"address"`
+	RiskLevel string `json:"riskLevel"` // "critical", "warning" or "safe"
+	Reason    string `json:"reason"`
+	ExpiresAt int64  `json:"expiresAt,omitempty"` // unix seconds, 0 = never
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// RiskOverrideStore holds admin risk overrides in memory until they expire
+type RiskOverrideStore struct {
+	mu        sync.RWMutex
+	overrides map[string]RiskOverride // lowercase address
+	clock     Clock
+}
+
+// NewRiskOverrideStore creates an empty store that expires overrides by clock
+func NewRiskOverrideStore(clock Clock) *RiskOverrideStore {
+	return &RiskOverrideStore{overrides: make(map[string]RiskOverride), clock: clock}
+}
+
+// riskOverrides is consulted by getSpenderInfo before any spender database
+var riskOverrides = NewRiskOverrideStore(RealClock{})
+
+// expired reports whether the override is no longer active at now
+func (o RiskOverride) expired(now int64) bool {
+	return o.ExpiresAt != 0 && o.ExpiresAt <= now
+}
+
+// Set adds or replaces the override of an address
+func (st *RiskOverrideStore) Set(override RiskOverride) {
+	override.Address = strings.ToLower(override.Address)
+	override.CreatedAt = st.clock.Now().Unix()
+
+	st.mu.Lock()
+	st.overrides[override.Address] = override
+	st.mu.Unlock()
+}
+
+// Get returns the active override of an address
+func (st *RiskOverrideStore) Get(address string) (RiskOverride, bool) {
+	st.mu.RLock()
+	override, ok := st.overrides[strings.ToLower(address)]
+	st.mu.RUnlock()
+	if !ok || override.expired(st.clock.Now().Unix()) {
+		return RiskOverride{}, false
+	}
+	return override, true
+}
+
+// Delete removes the override of an address, reporting whether it existed
+func (st *RiskOverrideStore) Delete(address string) bool {
+	address = strings.ToLower(address)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.overrides[address]
+	delete(st.overrides, address)
+	return ok
+}
+
+// List returns the active overrides sorted by address, dropping expired ones
+func (st *RiskOverrideStore) List() []RiskOverride {
+	now := st.clock.Now().Unix()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	active := make([]RiskOverride, 0, len(st.overrides))
+	for address, override := range st.overrides {
+		if override.expired(now) {
+			delete(st.overrides, address)
+			continue
+		}
+		active = append(active, override)
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Address < active[j].Address })
+	return active
+}
+
+// applyRiskOverride pins an approval's spender risk level before scoring. Overrides are
+// applied again at scoring time so cached and incrementally merged approvals pick them up.
+// Sanctioned spenders can't be overridden.
+func applyRiskOverride(approval *Approval) {
+	if isSanctionedSpender(*approval) {
+		return
+	}
+	override, ok := riskOverrides.Get(approval.SpenderAddress)
+	if !ok {
+		return
+	}
+	approval.RiskLevel = override.RiskLevel
+	if override.Reason != "" {
+		approval.RiskReasons = append(approval.RiskReasons, "🛑 "+override.Reason)
+	}
+}
+
+// auditLog records an admin action together with the caller's address
+func auditLog(r *http.Request, format string, args ...interface{}) {
+	defaultLogger.Info("audit", Fields{"event": fmt.Sprintf(format, args...), "remote_addr": r.RemoteAddr})
+}
+
+// Risk override endpoint - POST sets an override
+func (s *Server) handleRiskOverride(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var override RiskOverride
+	if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if !isValidEthereumAddress(override.Address) {
+		http.Error(w, "address must be a 0x address", http.StatusBadRequest)
+		return
+	}
+	switch override.RiskLevel {
+	case "critical", "warning", "safe":
+	default:
+		http.Error(w, "riskLevel must be critical, warning or safe", http.StatusBadRequest)
+		return
+	}
+	if override.ExpiresAt != 0 && override.ExpiresAt <= riskOverrides.clock.Now().Unix() {
+		http.Error(w, "expiresAt must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	riskOverrides.Set(override)
+	override, _ = riskOverrides.Get(override.Address)
+	auditLog(r, "risk override set: %s -> %s (%q, expires %d)", override.Address, override.RiskLevel, override.Reason, override.ExpiresAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(override)
+}
+
+// Risk overrides endpoint - GET lists active overrides, DELETE .../{address} removes one
+func (s *Server) handleRiskOverrides(w http.ResponseWriter, r *http.Request) {
+	address := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/risk/overrides"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && address == "":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"overrides": riskOverrides.List(),
+		})
+
+	case r.Method == http.MethodDelete && address != "":
+		if !isValidEthereumAddress(address) {
+			http.Error(w, "address must be a 0x address", http.StatusBadRequest)
+			return
+		}
+		if !riskOverrides.Delete(address) {
+			http.Error(w, "no override for "+address, http.StatusNotFound)
+			return
+		}
+		auditLog(r, "risk override removed: %s", strings.ToLower(address))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"address": strings.ToLower(address),
+			"removed": true,
+		})
+
+	default:
+		http.Error(w, "GET /overrides or DELETE /overrides/{address} required", http.StatusMethodNotAllowed)
+	}
+}
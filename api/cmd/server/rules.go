@@ -0,0 +1,304 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Approval Risk Rules Engine
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRulesJSON reproduces the built-in risk scoring; RULES_FILE overrides it
+//
+//go:embed rules/default.json
+var defaultRulesJSON []byte
+
+// RuleCondition matches an approval. Every set field must match; conditions are
+// evaluated against the approval as returned by the chain client, i.e. RiskLevel
+// is the spender trust level ("safe", "warning", "critical") before scoring.
+type RuleCondition struct {
+	IsUnlimited    *bool  `json:"isUnlimited,omitempty"`
+	RiskLevel      string `json:"riskLevel,omitempty"`
+	UnknownSpender *bool  `json:"unknownSpender,omitempty"`
+	// LowTrustToken matches tokens scored below lowTrustScore (unscored tokens never match)
+	LowTrustToken *bool `json:"lowTrustToken,omitempty"`
+	// SelfApproval matches tokens that approved themselves as spender (honeypot pattern)
+	SelfApproval *bool `json:"selfApproval,omitempty"`
+	// FrequentlyUsed matches spenders that moved the wallet's tokens frequentTransferFromCount+ times
+	FrequentlyUsed *bool `json:"frequentlyUsed,omitempty"`
+	// ClassifiedSpender matches spenders ContractClassifier assigned a category
+	ClassifiedSpender *bool `json:"classifiedSpender,omitempty"`
+	// MinAgeDays matches approvals set at least this many days ago (approvals without
+	// a timestamp never match)
+	MinAgeDays *int `json:"minAgeDays,omitempty"`
+	// MinSpenderTxCount and MaxSpenderTxCount bound the spender's transaction count
+	// (approvals without a count never match)
+	MinSpenderTxCount *int64 `json:"minSpenderTxCount,omitempty"`
+	MaxSpenderTxCount *int64 `json:"maxSpenderTxCount,omitempty"`
+	// RecentlyUsed matches spenders that moved the wallet's tokens within
+	// recentTransferWindow (approvals whose last transfer time is unknown never match)
+	RecentlyUsed *bool `json:"recentlyUsed,omitempty"`
+}
+
+// RuleAction is applied to an approval when its rule matches
+type RuleAction struct {
+	AddRiskPoints int    `json:"addRiskPoints"`
+	AddReason     string `json:"addReason,omitempty"`
+	SetRiskLevel  string `json:"setRiskLevel,omitempty"`
+}
+
+// RiskRule is a single scoring rule
+type RiskRule struct {
+	ID          string        `json:"id"`
+	Description string        `json:"description,omitempty"`
+	Condition   RuleCondition `json:"condition"`
+	Action      RuleAction    `json:"action"`
+}
+
+// RuleOutcome is the accumulated effect of all matching rules on one approval
+type RuleOutcome struct {
+	RiskPoints int
+	Reasons    []string
+	RiskLevel  string // empty = keep current level
+}
+
+// RulesEngine evaluates risk rules and supports reloading them at runtime
+type RulesEngine struct {
+	mu     sync.RWMutex
+	rules  []RiskRule
+	source string
+}
+
+var validRiskLevels = map[string]bool{"critical": true, "warning": true, "safe": true}
+
+// parseRiskRules decodes and validates a rules document
+func parseRiskRules(data []byte) ([]RiskRule, error) {
+	var doc struct {
+		Rules []RiskRule `json:"rules"`
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields() // catch typos like "isUnlimted"
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid rules JSON: %w", err)
+	}
+
+	if len(doc.Rules) == 0 {
+		return nil, errors.New("rules file contains no rules")
+	}
+
+	seen := make(map[string]bool, len(doc.Rules))
+	for i, rule := range doc.Rules {
+		where := fmt.Sprintf("rule #%d", i+1)
+		if rule.ID != "" {
+			where = fmt.Sprintf("rule #%d (%s)", i+1, rule.ID)
+		}
+
+		switch {
+		case rule.ID == "":
+			return nil, fmt.Errorf("%s: missing id", where)
+		case seen[rule.ID]:
+			return nil, fmt.Errorf("%s: duplicate id", where)
+		case rule.Condition.IsUnlimited == nil && rule.Condition.RiskLevel == "" && rule.Condition.UnknownSpender == nil && rule.Condition.LowTrustToken == nil && rule.Condition.SelfApproval == nil && rule.Condition.FrequentlyUsed == nil && rule.Condition.ClassifiedSpender == nil && rule.Condition.MinAgeDays == nil && rule.Condition.MinSpenderTxCount == nil && rule.Condition.MaxSpenderTxCount == nil && rule.Condition.RecentlyUsed == nil:
+			return nil, fmt.Errorf("%s: condition must set at least one of isUnlimited, riskLevel, unknownSpender, lowTrustToken, selfApproval, frequentlyUsed, classifiedSpender, minAgeDays, minSpenderTxCount, maxSpenderTxCount, recentlyUsed", where)
+		case rule.Condition.MinAgeDays != nil && *rule.Condition.MinAgeDays < 0:
+			return nil, fmt.Errorf("%s: condition.minAgeDays must not be negative", where)
+		case rule.Condition.MinSpenderTxCount != nil && *rule.Condition.MinSpenderTxCount < 0, rule.Condition.MaxSpenderTxCount != nil && *rule.Condition.MaxSpenderTxCount < 0:
+			return nil, fmt.Errorf("%s: condition.minSpenderTxCount and maxSpenderTxCount must not be negative", where)
+		case rule.Condition.RiskLevel != "" && !validRiskLevels[rule.Condition.RiskLevel]:
+			return nil, fmt.Errorf("%s: condition.riskLevel %q must be critical, warning or safe", where, rule.Condition.RiskLevel)
+		case rule.Action.SetRiskLevel != "" && !validRiskLevels[rule.Action.SetRiskLevel]:
+			return nil, fmt.Errorf("%s: action.setRiskLevel %q must be critical, warning or safe", where, rule.Action.SetRiskLevel)
+		case rule.Action.AddRiskPoints < -100 || rule.Action.AddRiskPoints > 100:
+			return nil, fmt.Errorf("%s: action.addRiskPoints must be between -100 and 100", where)
+		case rule.Action.AddRiskPoints == 0 && rule.Action.AddReason == "" && rule.Action.SetRiskLevel == "":
+			return nil, fmt.Errorf("%s: action has no effect", where)
+		}
+		seen[rule.ID] = true
+	}
+
+	return doc.Rules, nil
+}
+
+// NewRulesEngine loads rules from path, or the embedded defaults when path is empty
+func NewRulesEngine(path string) (*RulesEngine, error) {
+	engine := &RulesEngine{}
+	if err := engine.Load(path); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// Load replaces the active rules. On error the previous rules stay active.
+func (e *RulesEngine) Load(path string) error {
+	data, source := defaultRulesJSON, "embedded default"
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read rules file: %w", err)
+		}
+		data, source = fileData, path
+	}
+
+	rules, err := parseRiskRules(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.source = source
+	e.mu.Unlock()
+
+	defaultLogger.Info("Loaded risk rules", Fields{"rules": len(rules), "source": source})
+	return nil
+}
+
+// Count returns the number of active rules
+func (e *RulesEngine) Count() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.rules)
+}
+
+// Evaluate applies every matching rule to the approval. All conditions see the
+// original approval, so rule order only affects the order of reasons and which
+// setRiskLevel wins (the last one).
+func (e *RulesEngine) Evaluate(approval Approval) RuleOutcome {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	outcome := RuleOutcome{}
+	for _, rule := range e.rules {
+		if !rule.Condition.matches(approval) {
+			continue
+		}
+		outcome.RiskPoints += rule.Action.AddRiskPoints
+		if rule.Action.AddReason != "" {
+			outcome.Reasons = append(outcome.Reasons, rule.Action.AddReason)
+		}
+		if rule.Action.SetRiskLevel != "" {
+			outcome.RiskLevel = rule.Action.SetRiskLevel
+		}
+	}
+	return outcome
+}
+
+func (c RuleCondition) matches(approval Approval) bool {
+	if c.IsUnlimited != nil && *c.IsUnlimited != approval.IsUnlimited {
+		return false
+	}
+	if c.RiskLevel != "" && c.RiskLevel != approval.RiskLevel {
+		return false
+	}
+	if c.UnknownSpender != nil && *c.UnknownSpender != isUnknownSpender(approval) {
+		return false
+	}
+	if c.LowTrustToken != nil && *c.LowTrustToken != isLowTrustToken(approval) {
+		return false
+	}
+	if c.SelfApproval != nil && *c.SelfApproval != approval.IsSelfApproval {
+		return false
+	}
+	if c.FrequentlyUsed != nil && *c.FrequentlyUsed != isFrequentlyUsed(approval) {
+		return false
+	}
+	if c.ClassifiedSpender != nil && *c.ClassifiedSpender != (approval.SpenderCategory != "") {
+		return false
+	}
+	if c.MinAgeDays != nil && (approval.LastUpdated == 0 || approval.ageDays < *c.MinAgeDays) {
+		return false
+	}
+	if c.MinSpenderTxCount != nil && (!approval.txCounted || approval.SpenderTxCount < *c.MinSpenderTxCount) {
+		return false
+	}
+	if c.MaxSpenderTxCount != nil && (!approval.txCounted || approval.SpenderTxCount > *c.MaxSpenderTxCount) {
+		return false
+	}
+	if c.RecentlyUsed != nil && (!transferRecencyKnown(approval) || *c.RecentlyUsed != approval.recentlyUsed) {
+		return false
+	}
+	return true
+}
+
+// approvalAgeDays returns the whole days since the approval's LastUpdated, or -1
+// when it has none
+func approvalAgeDays(approval Approval, now time.Time) int {
+	if approval.LastUpdated == 0 {
+		return -1
+	}
+	return int(now.Sub(time.Unix(approval.LastUpdated, 0)) / (24 * time.Hour))
+}
+
+// selfApprovalReason is added by the chain clients when token == spender
+const selfApprovalReason = "🚨 Self-approval: token is its own spender — classic honeypot pattern"
+
+// isUnknownSpender reports whether the spender has no name in the spender database
+func isUnknownSpender(approval Approval) bool {
+	name := approval.SpenderName
+	if approval.SpenderENS != "" {
+		// A self-chosen ENS name doesn't make a spender known
+		name = strings.TrimSuffix(strings.TrimPrefix(name, approval.SpenderENS+" ("), ")")
+	}
+	return strings.HasPrefix(name, "0x") || name == "Unknown"
+}
+
+// isLowTrustToken reports whether the approved token has a low trust score
+func isLowTrustToken(approval Approval) bool {
+	return approval.trustScored && approval.TokenTrustScore < lowTrustScore
+}
+
+// initRiskRules loads RULES_FILE, falling back to the embedded defaults if it is invalid
+func initRiskRules() *RulesEngine {
+	engine, err := NewRulesEngine(os.Getenv("RULES_FILE"))
+	if err == nil {
+		return engine
+	}
+
+	defaultLogger.Warn("Failed to load risk rules, using embedded defaults", Fields{"error": errorText(err)})
+	engine, err = NewRulesEngine("")
+	if err != nil {
+		defaultLogger.Error("Embedded risk rules are invalid", Fields{"error": errorText(err)})
+		os.Exit(1)
+	}
+	return engine
+}
+
+// Global risk rules engine
+var riskRules = initRiskRules()
+
+// Reload risk rules from RULES_FILE without a restart
+func (s *Server) handleReloadRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := riskRules.Load(os.Getenv("RULES_FILE")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	riskRules.mu.RLock()
+	source := riskRules.source
+	riskRules.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"reloaded": true,
+		"rules":    riskRules.Count(),
+		"source":   source,
+	})
+}
@@ -0,0 +1,88 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - OFAC Sanctions List
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// sanctionedSpenderName is the spender name shown for addresses on the sanctions list
+const sanctionedSpenderName = "🚨 OFAC SANCTIONED"
+
+// sanctionsJSON is the OFAC SDN list of Ethereum addresses, refreshed weekly by the
+// sanctions-list workflow and bundled into the binary at build time
+//
+//go:embed data/sanctions.json
+var sanctionsJSON []byte
+
+// SanctionsChecker matches addresses against a sanctions list
+type SanctionsChecker struct {
+	addresses map[string]bool
+}
+
+// NewSanctionsChecker parses a JSON array of sanctioned addresses
+func NewSanctionsChecker(data []byte) (*SanctionsChecker, error) {
+	var addresses []string
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		return nil, fmt.Errorf("invalid sanctions list: %w", err)
+	}
+	checker := &SanctionsChecker{addresses: make(map[string]bool, len(addresses))}
+	for _, address := range addresses {
+		if !isValidEthereumAddress(address) {
+			return nil, fmt.Errorf("invalid sanctioned address %q", address)
+		}
+		checker.addresses[strings.ToLower(address)] = true
+	}
+	return checker, nil
+}
+
+// IsSanctioned reports whether an address is on the list
+func (s *SanctionsChecker) IsSanctioned(address string) bool {
+	return s.addresses[strings.ToLower(address)]
+}
+
+// Len returns the number of sanctioned addresses
+func (s *SanctionsChecker) Len() int {
+	return len(s.addresses)
+}
+
+// sanctionsList is the bundled sanctions list; an invalid list fails the build's tests
+// and the server's startup rather than silently matching nothing
+var sanctionsList = mustSanctionsChecker(sanctionsJSON)
+
+func mustSanctionsChecker(data []byte) *SanctionsChecker {
+	checker, err := NewSanctionsChecker(data)
+	if err != nil {
+		panic(err)
+	}
+	return checker
+}
+
+// isSanctionedSpender reports whether an approval's spender is on the bundled list or
+// was flagged by the sanctions provider during the scan
+func isSanctionedSpender(approval Approval) bool {
+	return approval.SpenderName == sanctionedSpenderName || sanctionsList.IsSanctioned(approval.SpenderAddress)
+}
+
+// sanctionedSpenders returns the sanctioned spender addresses of approvals, lowercase
+// and in first-seen order
+func sanctionedSpenders(approvals []Approval) []string {
+	seen := make(map[string]bool)
+	sanctioned := make([]string, 0)
+	for _, approval := range approvals {
+		spender := strings.ToLower(approval.SpenderAddress)
+		if !seen[spender] && isSanctionedSpender(approval) {
+			seen[spender] = true
+			sanctioned = append(sanctioned, spender)
+		}
+	}
+	return sanctioned
+}
@@ -0,0 +1,236 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Sanctions Screening API
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// chainalysisBaseURL is the Chainalysis free sanctions screening API
+const chainalysisBaseURL = "https://public.chainalysis.com/api/v1/address/"
+
+// sanctionsCacheTTL is how long an address screening result is reused
+const sanctionsCacheTTL = time.Hour
+
+// maxSanctionsConcurrency caps parallel screening requests of a scan
+const maxSanctionsConcurrency = 10
+
+// bundledSanctionsSource is the SanctionSource of addresses on the bundled OFAC list
+const bundledSanctionsSource = "ofac"
+
+// SanctionsAPIClient screens addresses against a live sanctions provider
+type SanctionsAPIClient interface {
+	// IsSanctioned reports whether the provider lists the address
+	IsSanctioned(ctx context.Context, address string) (bool, error)
+	// Name is the provider reported as SanctionSource
+	Name() string
+}
+
+// noopSanctionsClient is used when no provider is configured (SANCTIONS_PROVIDER=none);
+// only the bundled list is checked
+type noopSanctionsClient struct{}
+
+func (noopSanctionsClient) IsSanctioned(context.Context, string) (bool, error) { return false, nil }
+func (noopSanctionsClient) Name() string                                       { return "none" }
+
+// ChainalysisClient screens addresses with the Chainalysis sanctions API
+type ChainalysisClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	cache   *Cache
+}
+
+// NewChainalysisClient creates a client caching each address's result for an hour
+func NewChainalysisClient(baseURL, apiKey string, clock Clock) *ChainalysisClient {
+	return &ChainalysisClient{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Transport: sharedServiceTransport(), Timeout: 10 * time.Second},
+		cache:   NewCacheWithClock(sanctionsCacheTTL, clock),
+	}
+}
+
+// Name returns "chainalysis"
+func (c *ChainalysisClient) Name() string {
+	return "chainalysis"
+}
+
+// IsSanctioned reports whether Chainalysis has any identification for the address
+func (c *ChainalysisClient) IsSanctioned(ctx context.Context, address string) (bool, error) {
+	address = strings.ToLower(address)
+	if cached, ok := c.cache.Get(address); ok {
+		return cached.(bool), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+address, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("chainalysis request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkHTTPStatus("chainalysis", resp); err != nil {
+		return false, err
+	}
+
+	var body struct {
+		Identifications []struct {
+			Category string `json:"category"`
+		} `json:"identifications"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("failed to decode chainalysis response: %w", err)
+	}
+
+	sanctioned := len(body.Identifications) > 0
+	c.cache.Set(address, sanctioned)
+	return sanctioned, nil
+}
+
+// newSanctionsAPIClient loads the provider named by SANCTIONS_PROVIDER ("chainalysis"
+// or "none", the default). Chainalysis needs CHAINALYSIS_API_KEY.
+func newSanctionsAPIClient() SanctionsAPIClient {
+	switch strings.ToLower(getEnv("SANCTIONS_PROVIDER", "none")) {
+	case "chainalysis":
+		apiKey := getEnv("CHAINALYSIS_API_KEY", "")
+		if apiKey == "" {
+			defaultLogger.Warn("SANCTIONS_PROVIDER=chainalysis without CHAINALYSIS_API_KEY, using the bundled list only", nil)
+			return noopSanctionsClient{}
+		}
+		return NewChainalysisClient(getEnv("CHAINALYSIS_API_URL", chainalysisBaseURL), apiKey, RealClock{})
+	case "none", "":
+		return noopSanctionsClient{}
+	default:
+		defaultLogger.Warn("Unknown SANCTIONS_PROVIDER, using the bundled list only", Fields{"provider": getEnv("SANCTIONS_PROVIDER", "")})
+		return noopSanctionsClient{}
+	}
+}
+
+// sanctionsAPI is the configured sanctions provider, shared so its cache is too
+var sanctionsAPI = newSanctionsAPIClient()
+
+// screenAddresses checks addresses with the provider, at most maxSanctionsConcurrency
+// at a time, and returns the sanctioned ones (lowercase). Failed checks are logged and
+// count as not sanctioned.
+func screenAddresses(ctx context.Context, client SanctionsAPIClient, addresses []string, logger Logger) map[string]bool {
+	sanctioned := make(map[string]bool)
+	if client == nil {
+		return sanctioned
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxSanctionsConcurrency)
+	for _, address := range addresses {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			listed, err := client.IsSanctioned(ctx, address)
+			if err != nil {
+				loggerOr(logger).Warn("Sanctions check failed", Fields{"provider": client.Name(), "address": address, "error": errorText(err)})
+				return
+			}
+			if listed {
+				mu.Lock()
+				sanctioned[strings.ToLower(address)] = true
+				mu.Unlock()
+			}
+		}(address)
+	}
+	wg.Wait()
+	return sanctioned
+}
+
+// screenSpenders checks the scan's spenders that aren't on the bundled list with the
+// provider and marks their approvals like bundled sanctioned spenders. It returns the
+// provider-sanctioned spenders (lowercase).
+func (s *Scanner) screenSpenders(ctx context.Context, approvals []Approval) map[string]bool {
+	seen := make(map[string]bool)
+	spenders := make([]string, 0)
+	for _, approval := range approvals {
+		spender := strings.ToLower(approval.SpenderAddress)
+		if !seen[spender] && isValidEthereumAddress(spender) && !sanctionsList.IsSanctioned(spender) {
+			seen[spender] = true
+			spenders = append(spenders, spender)
+		}
+	}
+
+	sanctioned := screenAddresses(ctx, s.sanctions, spenders, requestLogger(ctx, s.logger()))
+	for i := range approvals {
+		if sanctioned[strings.ToLower(approvals[i].SpenderAddress)] {
+			approvals[i].SpenderName = sanctionedSpenderName
+			approvals[i].RiskLevel = "critical"
+		}
+	}
+	return sanctioned
+}
+
+// sanctionedContractRisks reports each sanctioned spender of approvals once, with the
+// list or provider that sanctions it
+func sanctionedContractRisks(approvals []Approval, providerSanctioned map[string]bool, provider string) []ContractRisk {
+	seen := make(map[string]bool)
+	risks := make([]ContractRisk, 0)
+	for _, approval := range approvals {
+		spender := strings.ToLower(approval.SpenderAddress)
+		if seen[spender] {
+			continue
+		}
+		source := ""
+		switch {
+		case sanctionsList.IsSanctioned(spender):
+			source = bundledSanctionsSource
+		case providerSanctioned[spender]:
+			source = provider
+		default:
+			continue
+		}
+		seen[spender] = true
+		risks = append(risks, ContractRisk{
+			Address:         spender,
+			Chain:           approval.Chain,
+			IsSanctioned:    true,
+			SanctionSource:  source,
+			OwnerPrivileges: []string{},
+			RiskScore:       100,
+			RiskLevel:       "critical",
+			Vulnerabilities: []string{},
+		})
+	}
+	return risks
+}
+
+// sanctionSource returns the list or provider that sanctions address, if any
+func sanctionSource(ctx context.Context, client SanctionsAPIClient, address string, logger Logger) (string, bool) {
+	if sanctionsList.IsSanctioned(address) {
+		return bundledSanctionsSource, true
+	}
+	if client == nil {
+		return "", false
+	}
+	listed, err := client.IsSanctioned(ctx, address)
+	if err != nil {
+		loggerOr(logger).Warn("Sanctions check failed", Fields{"provider": client.Name(), "address": address, "error": errorText(err)})
+		return "", false
+	}
+	return client.Name(), listed
+}
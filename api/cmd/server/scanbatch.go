@@ -0,0 +1,147 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Batch Wallet Scans
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limits of a batch scan request
+const (
+	maxBatchWallets     = 20
+	maxBatchConcurrency = 5
+)
+
+// BatchScanError reports a wallet of a batch whose scan failed. Like single scans it
+// carries a generic error and the request ID its details are logged under.
+type BatchScanError struct {
+	Wallet    string `json:"wallet"`
+	Error     string `json:"error"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+}
+
+// BatchScanResponse is the response of POST /api/v1/scan/batch. Results and errors
+// keep the order of the requested wallets.
+type BatchScanResponse struct {
+	Results []*WalletScanResult `json:"results"`
+	Errors  []BatchScanError    `json:"errors"`
+	Total   int                 `json:"total"`
+	Success int                 `json:"success"`
+	Failed  int                 `json:"failed"`
+}
+
+// Batch scan endpoint - scans up to maxBatchWallets wallets concurrently, each through
+// the scanner's cache, within one shared 120s deadline
+func (s *Server) handleScanBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Wallets []string `json:"wallets"`
+		Chains  []string `json:"chains"`
+		Refresh bool     `json:"refresh"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Wallets) == 0 {
+		http.Error(w, "no wallets provided", http.StatusBadRequest)
+		return
+	}
+	if len(req.Wallets) > maxBatchWallets {
+		http.Error(w, fmt.Sprintf("max %d wallets per batch", maxBatchWallets), http.StatusBadRequest)
+		return
+	}
+	for _, wallet := range req.Wallets {
+		if !isValidEthereumAddress(wallet) && !isValidSolanaAddress(wallet) {
+			http.Error(w, fmt.Sprintf("invalid wallet address: %s", wallet), http.StatusBadRequest)
+			return
+		}
+		// Wallet-scoped API keys can't see the batch's wallets before the body is read
+		if !walletAllowed(r, wallet) {
+			writeAuthError(w, http.StatusForbidden, "API key is not authorized for wallet "+wallet)
+			return
+		}
+	}
+
+	// Without explicit chains each wallet is scanned on the chains its address format exists on
+	chains := supportedChains()
+	defaultChains := len(req.Chains) == 0
+	if !defaultChains {
+		selected, err := resolveChains(req.Chains)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		chains = selected
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 120*time.Second)
+	defer cancel()
+
+	results := make([]*WalletScanResult, len(req.Wallets))
+	failures := make([]error, len(req.Wallets))
+
+	sem := make(chan struct{}, min(len(req.Wallets), maxBatchConcurrency))
+	var wg sync.WaitGroup
+	for i, wallet := range req.Wallets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				failures[i] = ctx.Err()
+				return
+			}
+			scanChains := chains
+			if defaultChains {
+				scanChains = walletChains(wallet, chains)
+			}
+			results[i], failures[i] = s.scanner.ScanWallet(ctx, wallet, scanChains, req.Refresh)
+		}()
+	}
+	wg.Wait()
+
+	response := BatchScanResponse{
+		Results: make([]*WalletScanResult, 0, len(req.Wallets)),
+		Errors:  make([]BatchScanError, 0),
+		Total:   len(req.Wallets),
+	}
+	requestID := requestIDOrNew(r.Context())
+	for i, wallet := range req.Wallets {
+		if err := failures[i]; err != nil {
+			// Logged in full like writeScanFailed; the client gets the generic error and ID
+			s.logger().Warn("Batch scan of wallet failed", Fields{"request_id": requestID, "wallet": wallet, "error": errorText(err)})
+			response.Errors = append(response.Errors, BatchScanError{
+				Wallet:    wallet,
+				Error:     "scan_failed",
+				Message:   "Failed to scan one or more chains. Please try again.",
+				RequestID: requestID,
+			})
+			continue
+		}
+		response.Results = append(response.Results, results[i])
+	}
+	response.Success = len(response.Results)
+	response.Failed = len(response.Errors)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,107 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Approval Filters
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// approvalFilter selects the approvals a scan response returns; zero fields match everything
+type approvalFilter struct {
+	riskLevels  []string
+	chains      []ChainID
+	tokenTypes  []string
+	isUnlimited *bool
+	spender     string
+}
+
+// splitList splits a comma-separated query value, dropping empty entries
+func splitList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseApprovalFilter reads the risk, tokenType, isUnlimited and spender query parameters.
+// chains are the already validated chains of the scan.
+func parseApprovalFilter(query url.Values, chains []ChainID) (approvalFilter, error) {
+	filter := approvalFilter{chains: chains}
+
+	for _, level := range splitList(query.Get("risk")) {
+		level = strings.ToLower(level)
+		if _, ok := riskLevelRank[level]; !ok {
+			return filter, fmt.Errorf("invalid risk level %q: use critical, warning or safe", level)
+		}
+		filter.riskLevels = append(filter.riskLevels, level)
+	}
+
+	for _, tokenType := range splitList(query.Get("tokenType")) {
+		tokenType = strings.ToUpper(tokenType)
+		if tokenType != tokenTypeERC20 && tokenType != tokenTypeERC721 && tokenType != tokenTypeERC1155 {
+			return filter, fmt.Errorf("invalid token type %q: use ERC20, ERC721 or ERC1155", tokenType)
+		}
+		filter.tokenTypes = append(filter.tokenTypes, tokenType)
+	}
+
+	if raw := query.Get("isUnlimited"); raw != "" {
+		isUnlimited, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("isUnlimited must be true or false")
+		}
+		filter.isUnlimited = &isUnlimited
+	}
+
+	if spender := query.Get("spender"); spender != "" {
+		if !isValidEthereumAddress(spender) {
+			return filter, fmt.Errorf("spender must be a 0x address")
+		}
+		filter.spender = strings.ToLower(spender)
+	}
+	return filter, nil
+}
+
+// matches reports whether approval passes every set filter
+func (f approvalFilter) matches(approval Approval) bool {
+	if len(f.riskLevels) > 0 && !slices.Contains(f.riskLevels, approval.RiskLevel) {
+		return false
+	}
+	if len(f.chains) > 0 && !slices.Contains(f.chains, approval.Chain) {
+		return false
+	}
+	if len(f.tokenTypes) > 0 && !slices.Contains(f.tokenTypes, approval.TokenType) {
+		return false
+	}
+	if f.isUnlimited != nil && *f.isUnlimited != approval.IsUnlimited {
+		return false
+	}
+	if f.spender != "" && strings.ToLower(approval.SpenderAddress) != f.spender {
+		return false
+	}
+	return true
+}
+
+// filterApprovals keeps the result's approvals that match f and sets FilteredApprovals.
+// TotalApprovals and the other summary fields still describe the whole scan.
+func filterApprovals(result *WalletScanResult, f approvalFilter) {
+	filtered := make([]Approval, 0, len(result.Approvals))
+	for _, approval := range result.Approvals {
+		if f.matches(approval) {
+			filtered = append(filtered, approval)
+		}
+	}
+	result.Approvals = filtered
+	result.FilteredApprovals = len(filtered)
+}
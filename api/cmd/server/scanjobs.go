@@ -0,0 +1,260 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Async Scan Jobs
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultScanWorkers = 5
+	scanJobQueueSize   = 100
+	scanJobTimeout     = 5 * time.Minute
+	// scanJobTTL is how long a job can be polled after it was created
+	scanJobTTL = 10 * time.Minute
+)
+
+// Scan job statuses
+const (
+	jobPending  = "pending"
+	jobRunning  = "running"
+	jobComplete = "complete"
+	jobFailed   = "failed"
+)
+
+// ScanJobStatus is returned by GET /api/v1/jobs/{id}
+type ScanJobStatus struct {
+	JobID  string            `json:"jobId"`
+	Status string            `json:"status"`
+	Result *WalletScanResult `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// scanJob is a queued scan with the parameters of the GET /api/v1/scan request it
+// replaces
+type scanJob struct {
+	id           string
+	wallet       string
+	chains       []ChainID
+	forceRefresh bool
+	filter       approvalFilter
+	cursor       string
+	limit        int
+	expiresAt    time.Time
+
+	mu     sync.Mutex
+	status string
+	result *WalletScanResult
+	err    string
+}
+
+// snapshot returns the job's current status
+func (j *scanJob) snapshot() ScanJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return ScanJobStatus{JobID: j.id, Status: j.status, Result: j.result, Error: j.err}
+}
+
+// ScanJobQueue runs scans in a pool of workers fed by a buffered channel. Jobs are
+// kept in memory until they expire, so they don't survive a restart.
+type ScanJobQueue struct {
+	scanner ScannerService
+	workers int
+	clock   Clock
+	log     Logger
+
+	jobs  sync.Map // id -> *scanJob
+	queue chan *scanJob
+	start sync.Once
+}
+
+// NewScanJobQueue creates a queue whose workers start with the first job
+func NewScanJobQueue(scanner ScannerService, workers int, clock Clock, logger Logger) *ScanJobQueue {
+	if workers < 1 {
+		workers = defaultScanWorkers
+	}
+	return &ScanJobQueue{
+		scanner: scanner,
+		workers: workers,
+		clock:   clock,
+		log:     logger,
+		queue:   make(chan *scanJob, scanJobQueueSize),
+	}
+}
+
+// logger returns the queue's logger
+func (q *ScanJobQueue) logger() Logger {
+	return loggerOr(q.log)
+}
+
+// Enqueue stores and queues a job, returning false when the queue is full
+func (q *ScanJobQueue) Enqueue(job *scanJob) bool {
+	q.start.Do(func() {
+		for i := 0; i < q.workers; i++ {
+			go q.work()
+		}
+	})
+	q.sweep()
+
+	job.id = uuid.NewString()
+	job.status = jobPending
+	job.expiresAt = q.clock.Now().Add(scanJobTTL)
+
+	select {
+	case q.queue <- job:
+		q.jobs.Store(job.id, job)
+		return true
+	default:
+		return false
+	}
+}
+
+// Get returns an unexpired job
+func (q *ScanJobQueue) Get(id string) (*scanJob, bool) {
+	value, ok := q.jobs.Load(id)
+	if !ok {
+		return nil, false
+	}
+	job := value.(*scanJob)
+	if !q.clock.Now().Before(job.expiresAt) {
+		q.jobs.Delete(id)
+		return nil, false
+	}
+	return job, true
+}
+
+// sweep drops expired jobs
+func (q *ScanJobQueue) sweep() {
+	now := q.clock.Now()
+	q.jobs.Range(func(id, value interface{}) bool {
+		if !now.Before(value.(*scanJob).expiresAt) {
+			q.jobs.Delete(id)
+		}
+		return true
+	})
+}
+
+// work runs queued jobs until the process exits
+func (q *ScanJobQueue) work() {
+	for job := range q.queue {
+		q.run(job)
+	}
+}
+
+// run scans a job's wallet and applies the request's filters and page to the result
+func (q *ScanJobQueue) run(job *scanJob) {
+	job.mu.Lock()
+	job.status = jobRunning
+	job.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), scanJobTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := q.scanner.ScanWallet(ctx, job.wallet, job.chains, job.forceRefresh)
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if err != nil {
+		// As in handleScan, scanner errors can embed RPC URLs and stay in the logs
+		q.logger().Error("Scan job failed", Fields{"job_id": job.id, "wallet": job.wallet, "error": errorText(err)})
+		job.status = jobFailed
+		job.err = "Failed to scan one or more chains. Please try again."
+		return
+	}
+
+	page := *result
+	filterApprovals(&page, job.filter)
+	if err := paginateApprovals(&page, job.cursor, job.limit); err != nil {
+		job.status = jobFailed
+		job.err = "invalid cursor: restart from the first page"
+		return
+	}
+	job.status = jobComplete
+	job.result = &page
+	q.logger().Info("Scan job complete", Fields{"job_id": job.id, "wallet": job.wallet, "duration_ms": time.Since(start).Milliseconds()})
+}
+
+// Async scan endpoint - queues a scan with the parameters of GET /api/v1/scan and
+// returns a job to poll
+func (s *Server) handleScanAsync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	walletAddress, chains, ok := parseScanRequest(w, r)
+	if !ok {
+		return
+	}
+	limit, err := parsePageSize(r.URL.Query().Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := parseApprovalFilter(r.URL.Query(), chains)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job := &scanJob{
+		wallet:       walletAddress,
+		chains:       chains,
+		forceRefresh: r.URL.Query().Get("refresh") == "true",
+		filter:       filter,
+		cursor:       r.URL.Query().Get("cursor"),
+		limit:        limit,
+	}
+	if !s.jobs.Enqueue(job) {
+		http.Error(w, "scan queue is full, retry later", http.StatusServiceUnavailable)
+		return
+	}
+
+	pollURL := "/api/v1/jobs/" + job.id
+	if strings.HasPrefix(r.URL.Path, "/api/v2/") {
+		pollURL = "/api/v2/jobs/" + job.id
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", pollURL)
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"jobId":   job.id,
+		"status":  jobPending,
+		"pollUrl": pollURL,
+	})
+}
+
+// Job status endpoint - GET /api/v1/jobs/{id}
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "GET method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	job, ok := s.jobs.Get(id)
+	if !ok {
+		http.Error(w, "job not found or expired", http.StatusNotFound)
+		return
+	}
+	if !walletAllowed(r, job.wallet) {
+		writeAuthError(w, http.StatusForbidden, "API key is not authorized for this wallet")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(job.snapshot())
+}
@@ -0,0 +1,351 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Incremental Scan State
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultScanStateWallets caps the wallets kept in scan state (SCAN_STATE_MAX_WALLETS)
+const defaultScanStateWallets = 10_000
+
+// walletScanState is what earlier scans learned about one wallet: the last block whose
+// approval events were processed and the active approvals as of that block, per chain
+type walletScanState struct {
+	LastScannedBlock map[ChainID]uint64     `json:"lastScannedBlock"`
+	Approvals        map[ChainID][]Approval `json:"approvals"`
+	Updated          uint64                 `json:"updated"` // store-wide update sequence, for eviction
+}
+
+// ScanStateStore lets repeat scans query only the blocks added since the previous
+// scan instead of the wallet's whole history. It is persisted to SCAN_STATE_FILE.
+// Beyond maxWallets, the wallets updated longest ago are evicted.
+type ScanStateStore struct {
+	mu         sync.RWMutex
+	wallets    map[string]*walletScanState // lowercase wallet address
+	dirty      bool
+	sequence   uint64
+	maxWallets int
+
+	path   string // empty keeps the state in memory only
+	saveMu sync.Mutex
+}
+
+// NewScanStateStore creates an empty store of up to maxWallets wallets persisted to path
+func NewScanStateStore(path string, maxWallets int) *ScanStateStore {
+	if maxWallets <= 0 {
+		maxWallets = defaultScanStateWallets
+	}
+	return &ScanStateStore{
+		wallets:    make(map[string]*walletScanState),
+		maxWallets: maxWallets,
+		path:       path,
+	}
+}
+
+// copyApprovals deep-copies approvals so callers can't mutate stored state
+func copyApprovals(approvals []Approval) []Approval {
+	copied := make([]Approval, len(approvals))
+	for i, approval := range approvals {
+		approval.RiskReasons = append([]string(nil), approval.RiskReasons...)
+		approval.UniswapV4Hooks = append([]string(nil), approval.UniswapV4Hooks...)
+		copied[i] = approval
+	}
+	return copied
+}
+
+// Get returns the stored approvals of a wallet on chain and the block they are current to
+func (st *ScanStateStore) Get(walletAddress string, chain ChainID) ([]Approval, uint64, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	state, ok := st.wallets[strings.ToLower(walletAddress)]
+	if !ok {
+		return nil, 0, false
+	}
+	lastBlock, ok := state.LastScannedBlock[chain]
+	if !ok {
+		return nil, 0, false
+	}
+	return copyApprovals(state.Approvals[chain]), lastBlock, true
+}
+
+// Set records the active approvals of a wallet on chain as of lastBlock
+func (st *ScanStateStore) Set(walletAddress string, chain ChainID, lastBlock uint64, approvals []Approval) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	wallet := strings.ToLower(walletAddress)
+	state, ok := st.wallets[wallet]
+	if !ok {
+		state = &walletScanState{
+			LastScannedBlock: make(map[ChainID]uint64),
+			Approvals:        make(map[ChainID][]Approval),
+		}
+		st.wallets[wallet] = state
+	}
+	st.sequence++
+	state.Updated = st.sequence
+	if !ok {
+		st.evict()
+	}
+	state.LastScannedBlock[chain] = lastBlock
+	state.Approvals[chain] = copyApprovals(approvals)
+	st.dirty = true
+}
+
+// evict drops the wallets updated longest ago until at most maxWallets remain.
+// Callers hold st.mu.
+func (st *ScanStateStore) evict() {
+	excess := len(st.wallets) - st.maxWallets
+	if excess <= 0 {
+		return
+	}
+	wallets := make([]string, 0, len(st.wallets))
+	for wallet := range st.wallets {
+		wallets = append(wallets, wallet)
+	}
+	sort.Slice(wallets, func(i, j int) bool {
+		return st.wallets[wallets[i]].Updated < st.wallets[wallets[j]].Updated
+	})
+	for _, wallet := range wallets[:excess] {
+		delete(st.wallets, wallet)
+	}
+	st.dirty = true
+}
+
+// Delete drops the state of a wallet on chain so its next scan starts from block 0
+func (st *ScanStateStore) Delete(walletAddress string, chain ChainID) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	state, ok := st.wallets[strings.ToLower(walletAddress)]
+	if !ok {
+		return
+	}
+	if _, ok := state.LastScannedBlock[chain]; !ok {
+		return
+	}
+	delete(state.LastScannedBlock, chain)
+	delete(state.Approvals, chain)
+	st.dirty = true
+}
+
+// Load reads the state file; a missing file is not an error
+func (st *ScanStateStore) Load() error {
+	if st.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(st.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	wallets := make(map[string]*walletScanState)
+	if err := json.Unmarshal(data, &wallets); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", st.path, err)
+	}
+	var sequence uint64
+	for wallet, state := range wallets {
+		if state.LastScannedBlock == nil || state.Approvals == nil {
+			delete(wallets, wallet)
+			continue
+		}
+		sequence = max(sequence, state.Updated)
+	}
+
+	st.mu.Lock()
+	st.wallets = wallets
+	st.sequence = sequence
+	st.dirty = false
+	st.evict()
+	st.mu.Unlock()
+	return nil
+}
+
+// Save writes the state file if it changed, atomically via rename. A failed write
+// leaves the state dirty so the next save retries it.
+func (st *ScanStateStore) Save() error {
+	if st.path == "" {
+		return nil
+	}
+	st.saveMu.Lock()
+	defer st.saveMu.Unlock()
+
+	st.mu.Lock()
+	if !st.dirty {
+		st.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(st.wallets)
+	// Cleared before writing so changes made during the write mark it dirty again
+	st.dirty = false
+	st.mu.Unlock()
+
+	if err == nil {
+		err = st.writeFile(data)
+	}
+	if err != nil {
+		st.mu.Lock()
+		st.dirty = true
+		st.mu.Unlock()
+	}
+	return err
+}
+
+// writeFile replaces the state file with data via a temporary file and rename
+func (st *ScanStateStore) writeFile(data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(st.path), ".scan-state-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), st.path)
+}
+
+// mergeApprovals applies the approvals and revocations of newer blocks to earlier state.
+// Pairs approved again replace their stored approval in place; new pairs are appended.
+func mergeApprovals(previous, updates []Approval, revoked []string) []Approval {
+	revokedKeys := make(map[string]bool, len(revoked))
+	for _, key := range revoked {
+		revokedKeys[key] = true
+	}
+
+	merged := make([]Approval, 0, len(previous)+len(updates))
+	index := make(map[string]int, len(previous)+len(updates))
+	for _, approval := range previous {
+		key := approvalStateKey(approval)
+		if revokedKeys[key] {
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, approval)
+	}
+	for _, approval := range updates {
+		key := approvalStateKey(approval)
+		if i, ok := index[key]; ok {
+			merged[i] = approval
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, approval)
+	}
+	return merged
+}
+
+// incrementalRefreshKey marks a scan context whose forceRefresh keeps the scan state
+type incrementalRefreshKey struct{}
+
+// withIncrementalRefresh returns ctx in which forceRefresh only bypasses the result
+// cache: the stored block cursor is kept rather than rescanning from block 0
+func withIncrementalRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, incrementalRefreshKey{}, true)
+}
+
+// incrementalRefresh reports whether ctx keeps the scan state on forceRefresh
+func incrementalRefresh(ctx context.Context) bool {
+	incremental, _ := ctx.Value(incrementalRefreshKey{}).(bool)
+	return incremental
+}
+
+// refreshSpenderInfo re-resolves the spender name and risk level of stored approvals,
+// so spender database, sanctions and override changes reach wallets with scan state
+func (c *ChainClient) refreshSpenderInfo(ctx context.Context, approvals []Approval) {
+	for i := range approvals {
+		approval := &approvals[i]
+		name, risk := getSpenderInfo(approval.SpenderAddress)
+		approval.SpenderName, approval.RiskLevel = c.resolveSpenderENS(ctx, approval.SpenderAddress, name, risk)
+	}
+}
+
+// latestBlockNumber returns the chain's current block number
+func (c *ChainClient) latestBlockNumber(ctx context.Context) (uint64, error) {
+	var blockHex string
+	if err := c.rpcCall(ctx, "eth_blockNumber", []interface{}{}, &blockHex); err != nil {
+		return 0, err
+	}
+	block := parseHexUint64(blockHex)
+	if block == 0 {
+		return 0, fmt.Errorf("invalid block number %q", blockHex)
+	}
+	return block, nil
+}
+
+// fetchApprovals returns the wallet's active approvals on the client's chain. When an
+// earlier scan left state behind, only events after its last scanned block are queried
+// and merged into the stored approvals; forceRefresh rescans from block 0 unless ctx
+// asks for an incremental refresh.
+func (s *Scanner) fetchApprovals(ctx context.Context, client *ChainClient, walletAddress string, forceRefresh bool) (*ChainApprovals, error) {
+	if s.state == nil {
+		return client.GetApprovals(ctx, walletAddress)
+	}
+	// Don't wait on the head block of a chain whose providers are failing
+	if client.breaker.State() == CircuitOpen {
+		return nil, ErrCircuitOpen
+	}
+
+	head, err := client.latestBlockNumber(ctx)
+	if err != nil {
+		client.logger().Warn("Failed to fetch latest block, scanning full history", Fields{"wallet": walletAddress, "error": errorText(err)})
+		return client.GetApprovals(ctx, walletAddress)
+	}
+
+	previous, lastBlock, incremental := s.state.Get(walletAddress, client.ChainID)
+	if forceRefresh && !incrementalRefresh(ctx) || lastBlock > head {
+		incremental = false
+	}
+
+	blocks := blockRange{To: head}
+	if incremental {
+		// Only the block cursor is incremental: spender info is resolved on every scan
+		client.refreshSpenderInfo(ctx, previous)
+		if lastBlock == head {
+			result := &ChainApprovals{Approvals: dropExpiredApprovals(previous, s.clock.Now().Unix()), Source: "scan-state"}
+			client.confirmStoredAllowances(ctx, walletAddress, result)
+			return result, nil
+		}
+		blocks.From = lastBlock + 1
+	}
+
+	result, err := client.GetApprovalsInRange(ctx, walletAddress, blocks)
+	if err != nil {
+		return nil, err
+	}
+	if incremental {
+		// Stored Permit2 allowances may have expired since the previous scan
+		result.Approvals = dropExpiredApprovals(mergeApprovals(previous, result.Approvals, result.Revoked), s.clock.Now().Unix())
+		// Stored allowances may also have been spent by transferFrom, which logs no Approval
+		client.confirmStoredAllowances(ctx, walletAddress, result)
+	}
+
+	// Dropped or missing events may hide revocations, so only complete ranges are kept
+	if result.Truncated || result.Incomplete {
+		s.state.Delete(walletAddress, client.ChainID)
+	} else {
+		s.state.Set(walletAddress, client.ChainID, head, result.Approvals)
+	}
+	return result, nil
+}
@@ -0,0 +1,176 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Streaming Scans
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ChainScanUpdate reports one finished chain of a wallet scan
+type ChainScanUpdate struct {
+	Chain     ChainID
+	Approvals []Approval
+	Error     string // redacted; empty when the chain scanned fine
+}
+
+// StreamingScannerService is implemented by scanners that can report chains as they finish
+type StreamingScannerService interface {
+	ScanWalletStream(ctx context.Context, walletAddress string, chains []ChainID, forceRefresh bool, onChain func(ChainScanUpdate)) (*WalletScanResult, error)
+}
+
+// ScanSummary is a WalletScanResult without its approvals
+type ScanSummary struct {
+	WalletAddress    string                  `json:"walletAddress"`
+	WalletLabel      string                  `json:"walletLabel,omitempty"`
+	WalletENS        string                  `json:"walletEns,omitempty"`
+	Identity         WalletIdentity          `json:"identity"`
+	ScanTimestamp    int64                   `json:"scanTimestamp"`
+	OverallRiskScore int                     `json:"overallRiskScore"`
+	TotalApprovals   int                     `json:"totalApprovals"`
+	CriticalRisks    int                     `json:"criticalRisks"`
+	Warnings         int                     `json:"warnings"`
+	TotalValueAtRisk float64                 `json:"totalValueAtRisk"`
+	ChainsScanned    []ChainID               `json:"chainsScanned"`
+	TruncatedChains  []ChainID               `json:"truncatedChains,omitempty"`
+	ChainScanStats   map[ChainID]ChainResult `json:"chainScanStats"`
+	CacheHit         bool                    `json:"cacheHit"`
+}
+
+// summarizeScan returns the counts and risk scores of a scan
+func summarizeScan(result *WalletScanResult) ScanSummary {
+	return ScanSummary{
+		WalletAddress:    result.WalletAddress,
+		WalletLabel:      result.WalletLabel,
+		WalletENS:        result.WalletENS,
+		Identity:         result.Identity,
+		ScanTimestamp:    result.ScanTimestamp,
+		OverallRiskScore: result.OverallRiskScore,
+		TotalApprovals:   result.TotalApprovals,
+		CriticalRisks:    result.CriticalRisks,
+		Warnings:         result.Warnings,
+		TotalValueAtRisk: result.TotalValueAtRisk,
+		ChainsScanned:    result.ChainsScanned,
+		TruncatedChains:  result.TruncatedChains,
+		ChainScanStats:   result.ChainScanStats,
+		CacheHit:         result.CacheHit,
+	}
+}
+
+// chainUpdates splits a finished scan into per-chain updates, in chain order
+func chainUpdates(result *WalletScanResult) []ChainScanUpdate {
+	updates := make([]ChainScanUpdate, 0, len(result.ChainsScanned))
+	for _, chain := range result.ChainsScanned {
+		update := ChainScanUpdate{Chain: chain, Error: result.ChainScanStats[chain].Error}
+		for _, approval := range result.Approvals {
+			if approval.Chain == chain {
+				update.Approvals = append(update.Approvals, approval)
+			}
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// scanWithUpdates scans the wallet, calling onChain as each chain finishes. Scanners
+// that can't stream still report every chain, just all at the end.
+func (s *Server) scanWithUpdates(ctx context.Context, walletAddress string, chains []ChainID, forceRefresh bool, onChain func(ChainScanUpdate)) (*WalletScanResult, error) {
+	if streaming, ok := s.scanner.(StreamingScannerService); ok {
+		return streaming.ScanWalletStream(ctx, walletAddress, chains, forceRefresh, onChain)
+	}
+	result, err := s.scanner.ScanWallet(ctx, walletAddress, chains, forceRefresh)
+	if err != nil {
+		return nil, err
+	}
+	for _, update := range chainUpdates(result) {
+		onChain(update)
+	}
+	return result, nil
+}
+
+// sseWriter writes Server-Sent Events; events from concurrent chain scans are serialized
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// send writes one event with a JSON payload; event "" is the default message event
+func (sw *sseWriter) send(event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		defaultLogger.Error("Failed to encode stream event", Fields{"event": event, "error": errorText(err)})
+		return
+	}
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if event != "" {
+		fmt.Fprintf(sw.w, "event: %s\n", event)
+	}
+	fmt.Fprintf(sw.w, "data: %s\n\n", data)
+	sw.flusher.Flush()
+}
+
+// Streaming scan endpoint: each approval is sent as a data event once its chain finishes,
+// failed chains as "error" events, and the scan summary as a final "done" event
+func (s *Server) handleScanStream(w http.ResponseWriter, r *http.Request) {
+	walletAddress, chains, ok := parseScanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	forceRefresh := r.URL.Query().Get("refresh") == "true"
+	if r.URL.Query().Get("skipTCCheck") == "true" {
+		ctx = withoutTornadoCheck(ctx)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := &sseWriter{w: w, flusher: flusher}
+	onChain := func(update ChainScanUpdate) {
+		if update.Error != "" {
+			events.send("error", map[string]interface{}{"chain": update.Chain, "error": update.Error})
+			return
+		}
+		for _, approval := range update.Approvals {
+			events.send("", approval)
+		}
+	}
+
+	result, err := s.scanWithUpdates(ctx, walletAddress, chains, forceRefresh, onChain)
+	if err != nil {
+		requestID := requestIDOrNew(r.Context())
+		s.logger().Error("Streaming scan failed", Fields{"request_id": requestID, "wallet": walletAddress, "error": errorText(err)})
+		events.send("error", map[string]interface{}{
+			"error":     "scan_failed",
+			"message":   "Failed to scan one or more chains. Please try again.",
+			"requestId": requestID,
+		})
+		return
+	}
+
+	events.send("done", summarizeScan(result))
+}
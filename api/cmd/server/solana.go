@@ -0,0 +1,198 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Solana SPL Token Delegations
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// SPL token programs whose accounts can carry a delegate
+const (
+	splTokenProgramID     = "TokenkegQfeZyiNwAJbNbGWPFXCQw5Fd4z6g5SrJWxH"
+	splToken2022ProgramID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+)
+
+// tokenTypeSPL marks approvals that are SPL token delegations
+const tokenTypeSPL = "SPL"
+
+// splUnlimitedAmount is u64::MAX, the delegation wallets grant for "unlimited"
+var splUnlimitedAmount = new(big.Int).SetUint64(^uint64(0))
+
+// isEVMChain reports whether chain is served by a ChainClient; Solana has its own client
+func isEVMChain(chain ChainID) bool {
+	return chain != Solana
+}
+
+// chainSupportsWallet reports whether a wallet address is in the chain's format
+func chainSupportsWallet(chain ChainID, walletAddress string) bool {
+	if chain == Solana {
+		return isValidSolanaAddress(walletAddress)
+	}
+	return isValidEthereumAddress(walletAddress)
+}
+
+// walletChains narrows chains to those the wallet's address format exists on
+func walletChains(walletAddress string, chains []ChainID) []ChainID {
+	matched := make([]ChainID, 0, len(chains))
+	for _, chain := range chains {
+		if chainSupportsWallet(chain, walletAddress) {
+			matched = append(matched, chain)
+		}
+	}
+	return matched
+}
+
+// SolanaClient reads SPL token delegations over Solana JSON-RPC
+type SolanaClient struct {
+	RPC    string
+	client *http.Client
+	log    Logger
+}
+
+// NewSolanaClient creates a client for a Solana RPC endpoint
+func NewSolanaClient(rpcURL string, logger Logger) *SolanaClient {
+	return &SolanaClient{
+		RPC:    rpcURL,
+		client: &http.Client{Transport: sharedRPCTransport(), Timeout: 30 * time.Second},
+		log:    loggerOr(logger).With(Fields{"chain": Solana}),
+	}
+}
+
+// logger returns the client's logger
+func (c *SolanaClient) logger() Logger {
+	return loggerOr(c.log)
+}
+
+// splTokenAccount is a jsonParsed token account of getTokenAccountsByOwner
+type splTokenAccount struct {
+	Pubkey  string `json:"pubkey"`
+	Account struct {
+		Data struct {
+			Parsed struct {
+				Info struct {
+					Mint            string `json:"mint"`
+					Delegate        string `json:"delegate"`
+					DelegatedAmount *struct {
+						Amount         string `json:"amount"`
+						UIAmountString string `json:"uiAmountString"`
+					} `json:"delegatedAmount"`
+				} `json:"info"`
+			} `json:"parsed"`
+		} `json:"data"`
+	} `json:"account"`
+}
+
+// rpcCall performs a Solana JSON-RPC request and decodes the result into result
+func (c *SolanaClient) rpcCall(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.RPC, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkHTTPStatus("solana", resp); err != nil {
+		return err
+	}
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *jsonRPCError   `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s error: %w", method, rpcResp.Error)
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// GetApprovals returns the wallet's SPL token accounts that delegate a non-zero amount,
+// as approvals of the mint to the delegate
+func (c *SolanaClient) GetApprovals(ctx context.Context, walletAddress string) (*ChainApprovals, error) {
+	approvals := []Approval{}
+	for _, program := range []string{splTokenProgramID, splToken2022ProgramID} {
+		var result struct {
+			Value []splTokenAccount `json:"value"`
+		}
+		params := []interface{}{walletAddress, map[string]string{"programId": program}, map[string]string{"encoding": "jsonParsed"}}
+		if err := c.rpcCall(ctx, "getTokenAccountsByOwner", params, &result); err != nil {
+			return nil, err
+		}
+
+		for _, account := range result.Value {
+			info := account.Account.Data.Parsed.Info
+			if info.Delegate == "" || info.DelegatedAmount == nil {
+				continue
+			}
+			amount, ok := new(big.Int).SetString(info.DelegatedAmount.Amount, 10)
+			if !ok || amount.Sign() == 0 {
+				continue
+			}
+			approvals = append(approvals, splApproval(info.Mint, info.Delegate, amount, info.DelegatedAmount.UIAmountString))
+		}
+	}
+
+	requestLogger(ctx, c.logger()).Info("Found active approvals", Fields{"wallet": walletAddress, "provider": "solana", "approvals_found": len(approvals)})
+	return &ChainApprovals{
+		Approvals:   approvals,
+		TotalEvents: len(approvals),
+		Source:      "solana",
+		Revoked:     []string{},
+	}, nil
+}
+
+// splApproval builds the approval of an SPL delegation of amount (base units; uiAmount
+// is the decimal-adjusted amount)
+func splApproval(mint, delegate string, amount *big.Int, uiAmount string) Approval {
+	isUnlimited := amount.Cmp(splUnlimitedAmount) >= 0
+	spenderName, spenderRisk := getSpenderInfo(delegate)
+
+	riskReasons := []string{}
+	allowanceHuman := uiAmount
+	if isUnlimited {
+		riskReasons = append(riskReasons, "Unlimited approval")
+		allowanceHuman = "Unlimited"
+	}
+
+	tokenSymbol := mint
+	if len(mint) > 8 {
+		tokenSymbol = mint[:4] + "..." + mint[len(mint)-4:]
+	}
+
+	return Approval{
+		Chain:             Solana,
+		TokenAddress:      mint,
+		TokenSymbol:       tokenSymbol,
+		TokenType:         tokenTypeSPL,
+		SpenderAddress:    delegate,
+		SpenderName:       spenderName,
+		AllowanceRaw:      amount.String(),
+		AllowanceHuman:    allowanceHuman,
+		AllowanceUSD:      -1,
+		IsUnlimited:       isUnlimited,
+		RiskLevel:         spenderRisk,
+		RiskReasons:       riskReasons,
+		TransferFromCount: -1,
+	}
+}
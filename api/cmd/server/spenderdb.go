@@ -0,0 +1,206 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Spender & Token Database
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spenderDataRefreshInterval is how often the data files are re-read
+const spenderDataRefreshInterval = 10 * time.Minute
+
+// SpenderDatabase holds the spender names, spender risk levels and token symbols used
+// by scans. The compiled-in maps are the defaults; entries of the JSON data files are
+// merged over them, so new drainers ship without a deployment.
+type SpenderDatabase struct {
+	mu         sync.RWMutex
+	spenders   map[string]string // address -> name
+	riskLevels map[string]string // address -> "safe" / "warning" / "critical"
+	tokens     map[string]string // address -> symbol
+
+	spendersFile   string
+	riskLevelsFile string
+	tokensFile     string
+}
+
+// NewSpenderDatabase creates a database holding the compiled-in maps, reloaded from
+// the given files (a file that doesn't exist contributes nothing)
+func NewSpenderDatabase(spendersFile, riskLevelsFile, tokensFile string) *SpenderDatabase {
+	return &SpenderDatabase{
+		spenders:       knownSpenders,
+		riskLevels:     spenderRiskLevel,
+		tokens:         knownTokens,
+		spendersFile:   spendersFile,
+		riskLevelsFile: riskLevelsFile,
+		tokensFile:     tokensFile,
+	}
+}
+
+// spenderDB is read by getSpenderInfo and getTokenSymbol
+var spenderDB = NewSpenderDatabase(
+	getEnv("SPENDERS_FILE", "data/spenders.json"),
+	getEnv("SPENDER_RISK_FILE", "data/spender-risk.json"),
+	getEnv("TOKENS_FILE", "data/tokens.json"),
+)
+
+// loadAddressMap reads a {"address": "value"} file merged over base. check validates
+// values; a missing file returns base unchanged.
+func loadAddressMap(path string, base map[string]string, check func(string) error) (map[string]string, int, error) {
+	if path == "" {
+		return base, 0, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return base, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	merged := make(map[string]string, len(base)+len(entries))
+	for address, value := range base {
+		merged[address] = value
+	}
+	for address, value := range entries {
+		if !isValidEthereumAddress(address) {
+			return nil, 0, fmt.Errorf("%s: invalid address %q", path, address)
+		}
+		if err := check(value); err != nil {
+			return nil, 0, fmt.Errorf("%s: %s: %w", path, address, err)
+		}
+		merged[strings.ToLower(address)] = value
+	}
+	return merged, len(entries), nil
+}
+
+// Reload re-reads the data files. Either all three maps are replaced or, if any file
+// is invalid, none is.
+func (d *SpenderDatabase) Reload() error {
+	nonEmpty := func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("empty value")
+		}
+		return nil
+	}
+	riskLevel := func(value string) error {
+		if _, ok := riskLevelRank[value]; !ok {
+			return fmt.Errorf("risk level must be safe, warning or critical, got %q", value)
+		}
+		return nil
+	}
+
+	spenders, spenderEntries, err := loadAddressMap(d.spendersFile, knownSpenders, nonEmpty)
+	if err != nil {
+		return err
+	}
+	riskLevels, riskEntries, err := loadAddressMap(d.riskLevelsFile, spenderRiskLevel, riskLevel)
+	if err != nil {
+		return err
+	}
+	tokens, tokenEntries, err := loadAddressMap(d.tokensFile, knownTokens, nonEmpty)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.spenders, d.riskLevels, d.tokens = spenders, riskLevels, tokens
+	d.mu.Unlock()
+
+	defaultLogger.Debug("Loaded spender data files", Fields{
+		"spender_entries":    spenderEntries,
+		"risk_level_entries": riskEntries,
+		"token_entries":      tokenEntries,
+	})
+	return nil
+}
+
+// Spender returns the name and, if it has one, the risk level of a known spender
+func (d *SpenderDatabase) Spender(address string) (name, riskLevel string, ok bool) {
+	address = strings.ToLower(address)
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	name, ok = d.spenders[address]
+	return name, d.riskLevels[address], ok
+}
+
+// Token returns the symbol of a known token
+func (d *SpenderDatabase) Token(address string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	symbol, ok := d.tokens[strings.ToLower(address)]
+	return symbol, ok
+}
+
+// Counts returns the number of spenders, risk levels and tokens loaded
+func (d *SpenderDatabase) Counts() (spenders, riskLevels, tokens int) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.spenders), len(d.riskLevels), len(d.tokens)
+}
+
+// Start reloads now and then every 10 minutes until ctx is cancelled. A failed reload
+// keeps the previous data.
+func (d *SpenderDatabase) Start(ctx context.Context) {
+	reload := func() {
+		if err := d.Reload(); err != nil {
+			defaultLogger.Error("Failed to reload spender data", Fields{"error": errorText(err)})
+		}
+	}
+	reload()
+
+	go func() {
+		ticker := time.NewTicker(spenderDataRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reload()
+			}
+		}
+	}()
+}
+
+// Reload spender and token data files without a restart
+func (s *Server) handleAdminReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := spenderDB.Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	spenders, riskLevels, tokens := spenderDB.Counts()
+	auditLog(r, "spender data reloaded: %d spenders, %d risk levels, %d tokens", spenders, riskLevels, tokens)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"reloaded":   true,
+		"spenders":   spenders,
+		"riskLevels": riskLevels,
+		"tokens":     tokens,
+	})
+}
@@ -0,0 +1,129 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Spender Transaction Counts
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// spenderStatsTTL is how long a spender's transaction count is cached
+const spenderStatsTTL = time.Hour
+
+// busySpenderTxCount is the transaction count from which a contract spender counts
+// as widely used; Etherscan's txlist pages reach at most this many transactions
+const busySpenderTxCount = 10_000
+
+// SpenderStatsEnricher counts the transactions of unknown spenders: the nonce of an
+// EOA, or for a contract 0, 1 (some) or busySpenderTxCount (that many or more)
+type SpenderStatsEnricher struct {
+	cache CacheStore
+}
+
+// NewSpenderStatsEnricher creates an enricher caching counts in cache for an hour
+func NewSpenderStatsEnricher(cache CacheStore) *SpenderStatsEnricher {
+	return &SpenderStatsEnricher{cache: cache}
+}
+
+// newSpenderStatsFromEnv returns an enricher when ENRICH_SPENDER_STATS=true (nil =
+// disabled, the lookups cost an RPC or Etherscan call per unknown spender)
+func newSpenderStatsFromEnv(cache CacheStore) *SpenderStatsEnricher {
+	if getEnv("ENRICH_SPENDER_STATS", "") != "true" {
+		return nil
+	}
+	return NewSpenderStatsEnricher(cache)
+}
+
+// EnrichApprovals sets SpenderTxCount on approvals to unknown spenders, looking up
+// each spender once
+func (e *SpenderStatsEnricher) EnrichApprovals(ctx context.Context, client *ChainClient, approvals []Approval) {
+	counts := make(map[string]int64)
+	for i := range approvals {
+		if !isUnknownSpender(approvals[i]) {
+			continue
+		}
+		spender := strings.ToLower(approvals[i].SpenderAddress)
+		count, ok := counts[spender]
+		if !ok {
+			var err error
+			if count, err = e.TxCount(ctx, client, spender); err != nil {
+				requestLogger(ctx, client.logger()).Debug("Spender transaction count failed", Fields{"spender": spender, "error": errorText(err)})
+				count = -1
+			}
+			counts[spender] = count
+		}
+		if count < 0 {
+			continue
+		}
+		approvals[i].SpenderTxCount = count
+		approvals[i].txCounted = true
+	}
+}
+
+// TxCount returns the number of transactions of an address on the client's chain; for
+// contracts only whether there are none, some or busySpenderTxCount+
+func (e *SpenderStatsEnricher) TxCount(ctx context.Context, client *ChainClient, address string) (int64, error) {
+	address = strings.ToLower(address)
+	cacheKey := "txcount:" + string(client.ChainID) + ":" + address
+	if cached, ok := e.cache.Get(cacheKey); ok {
+		return cached.(int64), nil
+	}
+
+	var code string
+	if err := client.rpcCall(ctx, "eth_getCode", []interface{}{address, "latest"}, &code); err != nil {
+		return 0, fmt.Errorf("RPC error: %w", err)
+	}
+
+	var count int64
+	if code == "" || code == "0x" {
+		// An EOA's nonce is the number of transactions it sent
+		var nonce string
+		if err := client.rpcCall(ctx, "eth_getTransactionCount", []interface{}{address, "latest"}, &nonce); err != nil {
+			return 0, fmt.Errorf("RPC error: %w", err)
+		}
+		count = int64(parseHexUint64(nonce))
+	} else {
+		// A contract's nonce only counts the contracts it created - probe the
+		// transactions sent to it instead: the first one, then the 10,000th
+		hasTx, err := client.hasTransactionAt(ctx, address, 1)
+		if err != nil {
+			return 0, err
+		}
+		if hasTx {
+			count = 1
+			busy, err := client.hasTransactionAt(ctx, address, busySpenderTxCount)
+			if err != nil {
+				return 0, err
+			}
+			if busy {
+				count = busySpenderTxCount
+			}
+		}
+	}
+
+	e.cache.SetWithTTL(cacheKey, count, spenderStatsTTL)
+	return count, nil
+}
+
+// hasTransactionAt reports whether the explorer lists an n-th (1-based) transaction of
+// address, fetching a single row
+func (c *ChainClient) hasTransactionAt(ctx context.Context, address string, n int) (bool, error) {
+	var txs []struct {
+		Hash string `json:"hash"`
+	}
+	query := fmt.Sprintf("module=account&action=txlist&address=%s&startblock=0&endblock=99999999&page=%d&offset=1&sort=asc", address, n)
+	if err := c.etherscanQuery(ctx, query, &txs); err != nil {
+		if strings.Contains(err.Error(), "No transactions found") {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(txs) > 0, nil
+}
@@ -0,0 +1,209 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - The Graph Subgraph Client
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// subgraphPageSize is the most entities The Graph returns per query
+const subgraphPageSize = 1000
+
+// subgraphApprovalsQuery pages through a wallet's approval events by id
+const subgraphApprovalsQuery = `query($owner: String!, $lastID: String!, $first: Int!) {
+  approvals(first: $first, orderBy: id, where: {owner: $owner, id_gt: $lastID}) {
+    id
+    token { id symbol }
+    spender
+    value
+    transaction { timestamp }
+  }
+}`
+
+// initSubgraphURLs reads SUBGRAPH_URL_<CHAIN> (e.g. SUBGRAPH_URL_FANTOM), the GraphQL
+// endpoint of an ERC20 approvals subgraph on The Graph or a self-hosted graph node
+func initSubgraphURLs() map[ChainID]string {
+	urls := make(map[ChainID]string)
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		name, ok := strings.CutPrefix(key, "SUBGRAPH_URL_")
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		urls[ChainID(strings.ToLower(name))] = value
+	}
+	return urls
+}
+
+// SubgraphClient queries an approvals subgraph over GraphQL
+type SubgraphClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewSubgraphClient creates a client for the subgraph at url
+func NewSubgraphClient(url string) *SubgraphClient {
+	return &SubgraphClient{
+		url:    url,
+		client: &http.Client{Transport: sharedServiceTransport(), Timeout: 30 * time.Second},
+	}
+}
+
+// subgraphApproval is an approval event as the subgraph stores it; BigInts are decimal
+// strings
+type subgraphApproval struct {
+	ID    string `json:"id"`
+	Token struct {
+		ID     string `json:"id"`
+		Symbol string `json:"symbol"`
+	} `json:"token"`
+	Spender     string `json:"spender"`
+	Value       string `json:"value"`
+	Transaction struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"transaction"`
+}
+
+// query runs a GraphQL query and decodes its data into result
+func (s *SubgraphClient) query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkHTTPStatus("subgraph", resp); err != nil {
+		return err
+	}
+
+	var graphResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&graphResp); err != nil {
+		return fmt.Errorf("failed to decode subgraph response: %w", err)
+	}
+	if len(graphResp.Errors) > 0 {
+		return fmt.Errorf("subgraph error: %s", graphResp.Errors[0].Message)
+	}
+	return json.Unmarshal(graphResp.Data, result)
+}
+
+// Approvals returns the approval events of owner, at most limit of them (<= 0 = all)
+func (s *SubgraphClient) Approvals(ctx context.Context, owner string, limit int) ([]subgraphApproval, bool, error) {
+	var events []subgraphApproval
+	lastID := ""
+	for {
+		var page struct {
+			Approvals []subgraphApproval `json:"approvals"`
+		}
+		variables := map[string]interface{}{"owner": strings.ToLower(owner), "lastID": lastID, "first": subgraphPageSize}
+		if err := s.query(ctx, subgraphApprovalsQuery, variables, &page); err != nil {
+			return nil, false, err
+		}
+		events = append(events, page.Approvals...)
+		if limit > 0 && len(events) >= limit {
+			return events[:limit], len(events) > limit || len(page.Approvals) == subgraphPageSize, nil
+		}
+		if len(page.Approvals) < subgraphPageSize {
+			return events, false, nil
+		}
+		lastID = page.Approvals[len(page.Approvals)-1].ID
+	}
+}
+
+// getApprovalsSubgraph builds the active ERC20 approvals of a wallet from the chain's
+// subgraph, the last resort on chains with poor explorer and Alchemy coverage
+func (c *ChainClient) getApprovalsSubgraph(ctx context.Context, walletAddress string) (*ChainApprovals, error) {
+	if c.subgraph == nil {
+		return nil, fmt.Errorf("no subgraph configured for %s", c.ChainID)
+	}
+	events, truncated, err := c.subgraph.Approvals(ctx, walletAddress, config.MaxApprovalsPerChain)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		requestLogger(ctx, c.logger()).Warn("Truncated subgraph approval events", Fields{"wallet": walletAddress, "events": len(events)})
+	}
+
+	// Replay oldest first so the latest event of each pair wins
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, _ := strconv.ParseInt(events[i].Transaction.Timestamp, 10, 64)
+		tj, _ := strconv.ParseInt(events[j].Transaction.Timestamp, 10, 64)
+		return ti < tj
+	})
+
+	tokens := make([]string, 0, len(events))
+	for _, event := range events {
+		tokens = append(tokens, event.Token.ID)
+	}
+	c.prefetchTokenMetadata(ctx, tokens)
+
+	latestApprovals := make(map[string]Approval)
+	revoked := make(map[string]bool)
+	for _, event := range events {
+		tokenAddress := strings.ToLower(event.Token.ID)
+		spenderAddress := strings.ToLower(event.Spender)
+		allowance, ok := new(big.Int).SetString(event.Value, 10)
+		if !ok || !isValidEthereumAddress(tokenAddress) || !isValidEthereumAddress(spenderAddress) {
+			continue
+		}
+
+		key := approvalKey(tokenAddress, spenderAddress)
+		if allowance.Sign() == 0 {
+			delete(latestApprovals, key)
+			revoked[key] = true
+			continue
+		}
+		delete(revoked, key)
+
+		approval := c.erc20Approval(ctx, tokenAddress, spenderAddress, allowance)
+		if event.Token.Symbol != "" {
+			if _, known := spenderDB.Token(tokenAddress); !known {
+				approval.TokenSymbol = event.Token.Symbol
+			}
+		}
+		approval.LastUpdated, _ = strconv.ParseInt(event.Transaction.Timestamp, 10, 64)
+		latestApprovals[key] = approval
+	}
+
+	approvals := make([]Approval, 0, len(latestApprovals))
+	for _, approval := range latestApprovals {
+		approvals = append(approvals, approval)
+	}
+
+	requestLogger(ctx, c.logger()).Info("Found active approvals", Fields{"wallet": walletAddress, "provider": "subgraph", "approvals_found": len(approvals)})
+	return &ChainApprovals{
+		Approvals:   approvals,
+		TotalEvents: len(events),
+		Truncated:   truncated,
+		Source:      "subgraph",
+		Revoked:     sortedKeys(revoked),
+	}, nil
+}
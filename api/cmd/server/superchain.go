@@ -0,0 +1,99 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Superchain Bridges
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// opStackChains are the Superchain members whose L2 predeploys are shared
+var opStackChains = []ChainID{Optimism, Base, Mode, ChainID("zora")}
+
+// BridgeInfo describes a canonical bridge contract and the chains it is deployed on
+type BridgeInfo struct {
+	Name string
+	// Chains are the networks where the address is this bridge. L1 contracts of an
+	// OP Stack chain live on Ethereum; L2 predeploys share one address on every member.
+	Chains []ChainID
+	// SharedBy lists the OP Stack chains served by the same implementation
+	SharedBy           []ChainID
+	IsSuperchainBridge bool
+}
+
+// superchainBridges maps bridge addresses (lowercase) to their deployments
+var superchainBridges = map[string]BridgeInfo{
+	// L2 predeploys, identical on every OP Stack chain
+	"0x4200000000000000000000000000000000000007": {Name: "L2 Cross-Domain Messenger", Chains: opStackChains, SharedBy: opStackChains, IsSuperchainBridge: true},
+	"0x4200000000000000000000000000000000000010": {Name: "L2 Standard Bridge", Chains: opStackChains, SharedBy: opStackChains, IsSuperchainBridge: true},
+	"0x4200000000000000000000000000000000000014": {Name: "L2 ERC721 Bridge", Chains: opStackChains, SharedBy: opStackChains, IsSuperchainBridge: true},
+	"0x4200000000000000000000000000000000000028": {Name: "Superchain Token Bridge", Chains: opStackChains, SharedBy: opStackChains, IsSuperchainBridge: true},
+
+	// L1 contracts on Ethereum, one deployment per chain
+	"0x99c9fc46f92e8a1c0dec1b1747d010903e884be1": {Name: "Optimism: L1 Standard Bridge", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{Optimism}, IsSuperchainBridge: true},
+	"0xbeb5fc579115071764c7423a4f12edde41f106ed": {Name: "Optimism: Portal", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{Optimism}, IsSuperchainBridge: true},
+	"0x25ace71c97b33cc4729cf772ae268934f7bab5fa": {Name: "Optimism: L1 Cross-Domain Messenger", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{Optimism}, IsSuperchainBridge: true},
+	"0x3154cf16ccdb4c6d922629664174b904d80f2c35": {Name: "Base: L1 Standard Bridge", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{Base}, IsSuperchainBridge: true},
+	"0x49048044d57e1c92a77f79988d21fa8faf74e97e": {Name: "Base: Portal", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{Base}, IsSuperchainBridge: true},
+	"0x866e82a600a1414e583f7f13623f1ac5d58b0afa": {Name: "Base: L1 Cross-Domain Messenger", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{Base}, IsSuperchainBridge: true},
+	"0x735adbbe72226bd52e818e7181953f42e3b0ff21": {Name: "Mode: L1 Standard Bridge", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{"mode"}, IsSuperchainBridge: true},
+	"0x8b34b14c7c7123459cf3076b8cb929be097d0c07": {Name: "Mode: Portal", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{"mode"}, IsSuperchainBridge: true},
+	"0x3e2ea9b92b7e48a52296fd261dc26fd995284631": {Name: "Zora: L1 Standard Bridge", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{"zora"}, IsSuperchainBridge: true},
+	"0x1a0ad011913a150f69f6a19df447a0cfd9551054": {Name: "Zora: Portal", Chains: []ChainID{Ethereum}, SharedBy: []ChainID{"zora"}, IsSuperchainBridge: true},
+}
+
+// lookupBridge returns the bridge deployed at address, if any
+func lookupBridge(address string) (BridgeInfo, bool) {
+	info, ok := superchainBridges[strings.ToLower(address)]
+	return info, ok
+}
+
+// deployedOn reports whether the bridge exists at its address on chain
+func (b BridgeInfo) deployedOn(chain ChainID) bool {
+	for _, c := range b.Chains {
+		if c == chain {
+			return true
+		}
+	}
+	return false
+}
+
+// chainNames joins the display names of chains
+func chainNames(chains []ChainID) string {
+	names := make([]string, len(chains))
+	for i, chain := range chains {
+		names[i] = chainDisplayName(chain)
+	}
+	return strings.Join(names, ", ")
+}
+
+// crossCheckSuperchainBridge validates an approval to a Superchain bridge address against
+// the chain it was made on. The addresses are only bridges where they are deployed; the
+// same address on another chain is an unknown contract and is scored like one.
+func crossCheckSuperchainBridge(approval *Approval) {
+	info, ok := lookupBridge(approval.SpenderAddress)
+	if !ok || approval.Chain == "" {
+		return
+	}
+
+	if !info.deployedOn(approval.Chain) {
+		addr := approval.SpenderAddress
+		if len(addr) >= 10 {
+			approval.SpenderName = addr[:6] + "..." + addr[len(addr)-4:]
+		}
+		approval.RiskLevel = "warning"
+		approval.RiskReasons = append(approval.RiskReasons, fmt.Sprintf(
+			"⚠️ %s is a bridge address on %s, not on %s", info.Name, chainNames(info.Chains), chainDisplayName(approval.Chain)))
+		return
+	}
+
+	if len(info.SharedBy) > 1 {
+		approval.RiskReasons = append(approval.RiskReasons, fmt.Sprintf(
+			"🌉 Superchain %s shared by %s", info.Name, chainNames(info.SharedBy)))
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - OpenTelemetry Tracing
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates SENTINEL spans; it is a no-op until initTracing installs an exporter
+var tracer = otel.Tracer("github.com/sentinel-team/sentinel/api")
+
+// initTracing exports spans over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+// W3C trace context propagation is always enabled so traceparent headers pass through.
+// The returned function flushes pending spans on shutdown.
+func initTracing(ctx context.Context) func(context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }
+	}
+
+	// The exporter reads OTEL_EXPORTER_OTLP_* (endpoint, headers, TLS) itself
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		defaultLogger.Warn("Failed to create OTLP exporter, tracing disabled", Fields{"error": errorText(err)})
+		return func(context.Context) error { return nil }
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(getEnv("OTEL_SERVICE_NAME", "sentinel-api")),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	defaultLogger.Info("Exporting traces", Fields{"endpoint": os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")})
+	return provider.Shutdown
+}
+
+// endSpan records err on span and returns it
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, redactURLs(err.Error()))
+	}
+	return err
+}
+
+// traceCacheLookup records a cache hit or miss as an event on the current span
+func traceCacheLookup(ctx context.Context, key string, hit bool) {
+	name := "cache.miss"
+	if hit {
+		name = "cache.hit"
+	}
+	trace.SpanFromContext(ctx).AddEvent(name, trace.WithAttributes(attribute.String("cache.key", key)))
+}
+
+// injectTraceContext adds traceparent headers to an outgoing request
+func injectTraceContext(ctx context.Context, req *http.Request) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// statusRecorder captures the response status for the request span
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streaming responses working behind the middleware
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades through the middleware
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// tracingMiddleware starts a server span per request, continuing the caller's trace
+// when the request carries a traceparent header, and returns its trace ID in X-Trace-ID
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+		if spanContext := span.SpanContext(); spanContext.HasTraceID() {
+			w.Header().Set("X-Trace-ID", spanContext.TraceID().String())
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
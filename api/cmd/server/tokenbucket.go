@@ -0,0 +1,112 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Token Bucket
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultEtherscanCallsPerSecond is the Etherscan free tier's limit per API key
+const defaultEtherscanCallsPerSecond = 5
+
+// etherscanCallInterval spaces the calls made with one explorer API key
+// (ETHERSCAN_CALLS_PER_SEC, default 5)
+var etherscanCallInterval = callInterval(getEnvInt("ETHERSCAN_CALLS_PER_SEC", defaultEtherscanCallsPerSecond))
+
+// callInterval converts a calls-per-second limit to the spacing between calls
+func callInterval(perSecond int) time.Duration {
+	if perSecond <= 0 {
+		perSecond = defaultEtherscanCallsPerSecond
+	}
+	return time.Second / time.Duration(perSecond)
+}
+
+var (
+	explorerLimitersMu sync.Mutex
+	// explorerLimiters holds one bucket per explorer URL and API key
+	explorerLimiters = make(map[string]*tokenBucket)
+)
+
+// explorerLimiter returns the bucket shared by every chain calling the same explorer
+// with the same API key. Etherscan v2 rate-limits its key across all chains.
+func explorerLimiter(chain ChainID) *tokenBucket {
+	chainsMu.RLock()
+	key := "etherscan\x00" + etherscanConfig.APIKey
+	if explorer, ok := etherscanConfig.Explorers[string(chain)]; ok {
+		key = explorer.URL + "\x00" + explorer.APIKey
+	}
+	chainsMu.RUnlock()
+
+	explorerLimitersMu.Lock()
+	defer explorerLimitersMu.Unlock()
+	limiter, ok := explorerLimiters[key]
+	if !ok {
+		limiter = newTokenBucket(etherscanCallInterval, 1)
+		explorerLimiters[key] = limiter
+	}
+	return limiter
+}
+
+// tokenBucket is a rate limiter holding up to burst tokens, refilled one per interval
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket creates a full bucket
+func newTokenBucket(interval time.Duration, burst int) *tokenBucket {
+	return &tokenBucket{
+		interval: interval,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// reserve takes a token, returning how long the caller must wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.interval))
+}
+
+// Wait blocks until a token is available or ctx is done. A nil bucket never blocks.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
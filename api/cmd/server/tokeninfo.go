@@ -0,0 +1,91 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Token Display Info
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// maxTokenNameLength caps TokenName; longer names are cut with an ellipsis
+const maxTokenNameLength = 30
+
+// nameSelector is the name() function selector
+const nameSelector = "0x06fdde03"
+
+// tokenDisplayInfo is the user-facing name and logo of a token
+type tokenDisplayInfo struct {
+	Name    string
+	LogoURL string
+}
+
+// tokenDisplayCache holds token names and logos for a day, keyed "chain:address".
+// Symbols are resolved separately by getTokenSymbol.
+var tokenDisplayCache = NewCache(24 * time.Hour)
+
+// capTokenName shortens a token name to maxTokenNameLength characters
+func capTokenName(name string) string {
+	name = strings.TrimSpace(name)
+	runes := []rune(name)
+	if len(runes) <= maxTokenNameLength {
+		return name
+	}
+	return strings.TrimSpace(string(runes[:maxTokenNameLength-1])) + "…"
+}
+
+// FetchTokenName returns the token's display name from the Etherscan token info API,
+// falling back to the on-chain name() on chains the API doesn't cover
+func (c *ChainClient) FetchTokenName(ctx context.Context, tokenAddress string) (string, error) {
+	info, err := c.fetchTokenDisplayInfo(ctx, tokenAddress)
+	return info.Name, err
+}
+
+// fetchTokenDisplayInfo resolves and caches the display name and logo of a token
+func (c *ChainClient) fetchTokenDisplayInfo(ctx context.Context, tokenAddress string) (tokenDisplayInfo, error) {
+	tokenAddress = strings.ToLower(tokenAddress)
+	cacheKey := "token:" + string(c.ChainID) + ":" + tokenAddress
+	if cached, ok := tokenDisplayCache.Get(cacheKey); ok {
+		return cached.(tokenDisplayInfo), nil
+	}
+
+	var info tokenDisplayInfo
+	var tokenInfos []struct {
+		TokenName string `json:"tokenName"`
+		Image     string `json:"image"`
+	}
+	err := c.etherscanQuery(ctx, "module=token&action=tokeninfo&contractaddress="+tokenAddress, &tokenInfos)
+	if err == nil && len(tokenInfos) > 0 && tokenInfos[0].TokenName != "" {
+		info = tokenDisplayInfo{Name: tokenInfos[0].TokenName, LogoURL: tokenInfos[0].Image}
+	} else {
+		result, err := c.ethCall(ctx, tokenAddress, nameSelector)
+		if err != nil {
+			return tokenDisplayInfo{}, err
+		}
+		info.Name = decodeString(result)
+	}
+
+	info.Name = capTokenName(info.Name)
+	tokenDisplayCache.Set(cacheKey, info)
+	return info, nil
+}
+
+// annotateTokenInfo sets TokenName and TokenLogoURL on approvals, looking up each token once
+func (c *ChainClient) annotateTokenInfo(ctx context.Context, approvals []Approval) {
+	infos := make(map[string]tokenDisplayInfo)
+	for i := range approvals {
+		token := strings.ToLower(approvals[i].TokenAddress)
+		info, ok := infos[token]
+		if !ok {
+			info, _ = c.fetchTokenDisplayInfo(ctx, token)
+			infos[token] = info
+		}
+		approvals[i].TokenName = info.Name
+		approvals[i].TokenLogoURL = info.LogoURL
+	}
+}
@@ -0,0 +1,363 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Token Trust Score
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lowTrustScore is the TokenTrustScore below which a token counts as low trust
+const lowTrustScore = 25
+
+// knownTokenTrustScore is assigned to tokens in the curated knownTokens list
+const knownTokenTrustScore = 90
+
+// neutralTrustScore is used when no trust signal could be fetched
+const neutralTrustScore = 50
+
+// coinGeckoPlatforms maps chains to CoinGecko asset platform IDs
+var coinGeckoPlatforms = map[ChainID]string{
+	Ethereum:  "ethereum",
+	Arbitrum:  "arbitrum-one",
+	Optimism:  "optimistic-ethereum",
+	Base:      "base",
+	ZkSync:    "zksync",
+	Linea:     "linea",
+	Scroll:    "scroll",
+	ZkEVM:     "polygon-zkevm",
+	BSC:       "binance-smart-chain",
+	Polygon:   "polygon-pos",
+	Avalanche: "avalanche",
+	Fantom:    "fantom",
+	Cronos:    "cronos",
+	Gnosis:    "xdai",
+	Celo:      "celo",
+	Moonbeam:  "moonbeam",
+	Mantle:    "mantle",
+	Mode:      "mode",
+	Blast:     "blast",
+	Manta:     "manta-pacific",
+}
+
+// TokenTrustSignals are the inputs to a token trust score (-1 = unknown)
+type TokenTrustSignals struct {
+	AgeDays int
+	Holders int
+	Listed  int // 1 = listed, 0 = not listed, -1 = unknown
+}
+
+// computeTokenTrustScore scores a token 0-100 from its age, holder count and listing.
+// Age and holders contribute up to 35 points each, a CoinGecko listing 20 points.
+func computeTokenTrustScore(signals TokenTrustSignals) int {
+	if signals.AgeDays < 0 && signals.Holders < 0 && signals.Listed < 0 {
+		return neutralTrustScore
+	}
+
+	score := 0
+	switch {
+	case signals.AgeDays >= 730:
+		score += 35
+	case signals.AgeDays >= 365:
+		score += 28
+	case signals.AgeDays >= 180:
+		score += 20
+	case signals.AgeDays >= 30:
+		score += 12
+	case signals.AgeDays >= 7:
+		score += 6
+	case signals.AgeDays >= 0:
+		score += 2
+	}
+
+	switch {
+	case signals.Holders >= 100_000:
+		score += 35
+	case signals.Holders >= 10_000:
+		score += 28
+	case signals.Holders >= 1_000:
+		score += 20
+	case signals.Holders >= 100:
+		score += 10
+	case signals.Holders >= 0:
+		score += 3
+	}
+
+	if signals.Listed == 1 {
+		score += 20
+	}
+	return score
+}
+
+// TokenTrustScorer computes and caches token trust scores
+type TokenTrustScorer struct {
+	clock Clock
+	cache *Cache
+
+	listMu     sync.Mutex
+	listed     map[string]bool // "platform:address"
+	listLoaded time.Time
+}
+
+// NewTokenTrustScorer creates a scorer; scores are cached for a day
+func NewTokenTrustScorer(clock Clock) *TokenTrustScorer {
+	return &TokenTrustScorer{
+		clock: clock,
+		cache: NewCacheWithClock(24*time.Hour, clock),
+	}
+}
+
+// ScoreApprovals sets TokenTrustScore on each approval, looking up each token once
+func (t *TokenTrustScorer) ScoreApprovals(ctx context.Context, client *ChainClient, approvals []Approval) {
+	scores := make(map[string]int)
+	for i := range approvals {
+		token := strings.ToLower(approvals[i].TokenAddress)
+		score, ok := scores[token]
+		if !ok {
+			score = t.Score(ctx, client, token)
+			scores[token] = score
+		}
+		approvals[i].TokenTrustScore = score
+		approvals[i].trustScored = true
+	}
+}
+
+// Score returns the trust score of a token on the client's chain
+func (t *TokenTrustScorer) Score(ctx context.Context, client *ChainClient, tokenAddress string) int {
+	tokenAddress = strings.ToLower(tokenAddress)
+	if _, ok := spenderDB.Token(tokenAddress); ok {
+		return knownTokenTrustScore
+	}
+
+	cacheKey := string(client.ChainID) + ":" + tokenAddress
+	if cached, ok := t.cache.Get(cacheKey); ok {
+		return cached.(int)
+	}
+
+	signals := TokenTrustSignals{AgeDays: -1, Holders: -1, Listed: -1}
+	if creation, err := client.getContractCreation(ctx, tokenAddress); err == nil {
+		signals.AgeDays = int(t.clock.Now().Sub(time.Unix(creation.Timestamp, 0)).Hours() / 24)
+	}
+	if holders, err := client.getTokenHolderCount(ctx, tokenAddress); err == nil {
+		signals.Holders = holders
+	}
+	if listed, err := t.isListed(ctx, client.ChainID, tokenAddress); err == nil {
+		signals.Listed = 0
+		if listed {
+			signals.Listed = 1
+		}
+	}
+
+	score := computeTokenTrustScore(signals)
+	t.cache.Set(cacheKey, score)
+	return score
+}
+
+// isListed reports whether CoinGecko tracks the token on chain
+func (t *TokenTrustScorer) isListed(ctx context.Context, chain ChainID, tokenAddress string) (bool, error) {
+	platform, ok := coinGeckoPlatforms[chain]
+	if !ok {
+		return false, fmt.Errorf("no CoinGecko platform for %s", chain)
+	}
+
+	t.listMu.Lock()
+	defer t.listMu.Unlock()
+
+	if t.listed == nil || t.clock.Now().Sub(t.listLoaded) > 24*time.Hour {
+		listed, err := fetchCoinGeckoListings(ctx)
+		if err != nil {
+			if t.listed == nil {
+				return false, err
+			}
+			defaultLogger.Warn("Failed to refresh CoinGecko coin list, using stale copy", Fields{"error": errorText(err)})
+		} else {
+			t.listed = listed
+			t.listLoaded = t.clock.Now()
+		}
+	}
+	return t.listed[platform+":"+tokenAddress], nil
+}
+
+// fetchCoinGeckoListings downloads the CoinGecko coin list with platform addresses
+func fetchCoinGeckoListings(ctx context.Context) (map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", coinGeckoBaseURL+"/coins/list?include_platform=true", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coingecko returned status %d", resp.StatusCode)
+	}
+
+	var coins []struct {
+		Platforms map[string]string `json:"platforms"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&coins); err != nil {
+		return nil, err
+	}
+
+	listed := make(map[string]bool, len(coins))
+	for _, coin := range coins {
+		for platform, address := range coin.Platforms {
+			if address != "" {
+				listed[platform+":"+strings.ToLower(address)] = true
+			}
+		}
+	}
+	return listed, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              EXPLORER LOOKUPS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// etherscanQuery calls the chain's Etherscan-compatible API and decodes result
+func (c *ChainClient) etherscanQuery(ctx context.Context, query string, result interface{}) error {
+	url, ok := explorerURL(c.ChainID, query)
+	if !ok {
+		return fmt.Errorf("chain %s not supported by Etherscan", c.ChainID)
+	}
+
+	if err := explorerLimiter(c.ChainID).Wait(ctx); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Etherscan API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var rawResp struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	respBody, err := c.readRPCBody(resp, "etherscan", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read Etherscan response: %w", err)
+	}
+	if err := json.Unmarshal(respBody, &rawResp); err != nil {
+		return fmt.Errorf("failed to decode Etherscan response: %w", err)
+	}
+	if rawResp.Status != "1" {
+		return &etherscanError{Message: rawResp.Message, Result: strings.Trim(string(rawResp.Result), `"`)}
+	}
+	return json.Unmarshal(rawResp.Result, result)
+}
+
+// etherscanError is a NOTOK (status "0") Etherscan response
+type etherscanError struct {
+	Message string
+	Result  string
+}
+
+func (e *etherscanError) Error() string {
+	return fmt.Sprintf("Etherscan error: %s %s", e.Message, e.Result)
+}
+
+// rateLimited reports whether Etherscan rejected the call for exceeding the rate limit
+func (e *etherscanError) rateLimited() bool {
+	return strings.Contains(strings.ToLower(e.Message+" "+e.Result), "rate limit")
+}
+
+// etherscanNoRecords reports whether a NOTOK response only means the query matched nothing
+func etherscanNoRecords(message string, result json.RawMessage) bool {
+	return message == "No records found" || strings.Contains(string(result), "No records found")
+}
+
+// contractCreation is when and by whom a contract was deployed
+type contractCreation struct {
+	Deployer  string // lowercase
+	Block     uint64
+	Timestamp int64
+}
+
+// getContractCreation returns the deployer, block and timestamp of a contract's creation
+func (c *ChainClient) getContractCreation(ctx context.Context, contractAddress string) (contractCreation, error) {
+	var creations []struct {
+		ContractCreator string `json:"contractCreator"`
+		TxHash          string `json:"txHash"`
+		BlockNumber     string `json:"blockNumber"`
+		Timestamp       string `json:"timestamp"`
+	}
+	if err := c.etherscanQuery(ctx, "module=contract&action=getcontractcreation&contractaddresses="+contractAddress, &creations); err != nil {
+		return contractCreation{}, err
+	}
+	if len(creations) == 0 {
+		return contractCreation{}, fmt.Errorf("no creation record for %s", contractAddress)
+	}
+	record := creations[0]
+	creation := contractCreation{Deployer: strings.ToLower(record.ContractCreator)}
+
+	if record.BlockNumber != "" {
+		block, err := strconv.ParseUint(record.BlockNumber, 10, 64)
+		if err != nil {
+			return contractCreation{}, fmt.Errorf("invalid creation block %q", record.BlockNumber)
+		}
+		creation.Block = block
+	} else {
+		// Older explorer versions only return the creator and transaction - read the
+		// block from the creation receipt
+		var receipt struct {
+			From        string `json:"from"`
+			BlockNumber string `json:"blockNumber"`
+		}
+		if err := c.rpcCall(ctx, "eth_getTransactionReceipt", []interface{}{record.TxHash}, &receipt); err != nil {
+			return contractCreation{}, err
+		}
+		if receipt.BlockNumber == "" {
+			return contractCreation{}, fmt.Errorf("no receipt for creation transaction %s", record.TxHash)
+		}
+		creation.Block = parseHexUint64(receipt.BlockNumber)
+		if creation.Deployer == "" {
+			creation.Deployer = strings.ToLower(receipt.From)
+		}
+	}
+
+	if ts, err := strconv.ParseInt(record.Timestamp, 10, 64); err == nil && ts > 0 {
+		creation.Timestamp = ts
+		return creation, nil
+	}
+
+	// Older explorer versions omit the timestamp - read it from the block header
+	var header struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := c.rpcCall(ctx, "eth_getBlockByNumber", []interface{}{fmt.Sprintf("0x%x", creation.Block), false}, &header); err != nil {
+		return contractCreation{}, err
+	}
+	creation.Timestamp = int64(parseHexUint64(header.Timestamp))
+	return creation, nil
+}
+
+// getTokenHolderCount returns the number of holders of a token
+func (c *ChainClient) getTokenHolderCount(ctx context.Context, tokenAddress string) (int, error) {
+	var count string
+	if err := c.etherscanQuery(ctx, "module=token&action=tokenholdercount&contractaddress="+tokenAddress, &count); err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(count)
+}
@@ -0,0 +1,100 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Tornado Cash History
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// tornadoCashWarning is the recommendation added for wallets that used Tornado Cash
+const tornadoCashWarning = "⚠️ Wallet has interacted with Tornado Cash"
+
+// tornadoCashCacheTTL is how long a wallet's Tornado Cash check is reused
+const tornadoCashCacheTTL = 30 * time.Minute
+
+// tornadoCashAddresses are the Ethereum Tornado Cash router, proxies and pools
+// designated by OFAC (lowercase)
+var tornadoCashAddresses = map[string]bool{
+	"0xd90e2f925da726b50c4ed8d0fb90ad053324f31b": true, // Router
+	"0x722122df12d4e14e13ac3b6895a86e84145b6967": true, // Proxy
+	"0x905b63fff465b9ffbf41dea908ceb12478ec7601": true, // Old proxy
+	"0x12d66f87a04a9e220743712ce6d9bb1b5616b8fc": true, // 0.1 ETH
+	"0x47ce0c6ed5b0ce3d3a51fdb1c52dc66a7c3c2936": true, // 1 ETH
+	"0x910cbd523d972eb0a6f4cae4618ad62622b39dbf": true, // 10 ETH
+	"0xa160cdab225685da1d56aa342ad8841c3b53f291": true, // 100 ETH
+	"0xd4b88df4d29f5cedd6857912842cff3b20c8cfa3": true, // 100 DAI
+	"0xfd8610d20aa15b7b2e3be39b396a1bc3516c7144": true, // 1,000 DAI
+	"0xf60dd140cff0706bae9cd734ac3ae76ad9ebc32a": true, // 10,000 DAI
+}
+
+// tornadoCheckSkippedKey marks a scan context whose Tornado Cash check is skipped
+type tornadoCheckSkippedKey struct{}
+
+// withoutTornadoCheck returns ctx with the Tornado Cash check disabled (?skipTCCheck=true)
+func withoutTornadoCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tornadoCheckSkippedKey{}, true)
+}
+
+// tornadoCheckSkipped reports whether ctx disables the Tornado Cash check
+func tornadoCheckSkipped(ctx context.Context) bool {
+	skipped, _ := ctx.Value(tornadoCheckSkippedKey{}).(bool)
+	return skipped
+}
+
+// TornadoCashChecker looks for Tornado Cash transactions in a wallet's history
+type TornadoCashChecker struct {
+	cache *Cache
+}
+
+// NewTornadoCashChecker creates a checker caching each wallet's result for 30 minutes
+func NewTornadoCashChecker(clock Clock) *TornadoCashChecker {
+	return &TornadoCashChecker{cache: NewCacheWithClock(tornadoCashCacheTTL, clock)}
+}
+
+// HasInteracted reports whether any of the wallet's transactions on the client's
+// chain were sent to a Tornado Cash contract
+func (t *TornadoCashChecker) HasInteracted(ctx context.Context, client *ChainClient, walletAddress string) (bool, error) {
+	cacheKey := string(client.ChainID) + ":" + strings.ToLower(walletAddress)
+	if cached, ok := t.cache.Get(cacheKey); ok {
+		return cached.(bool), nil
+	}
+
+	var txs []struct {
+		To string `json:"to"`
+	}
+	if err := client.etherscanQuery(ctx, "module=account&action=txlist&address="+walletAddress+"&startblock=0&endblock=99999999&sort=desc", &txs); err != nil {
+		if !strings.Contains(err.Error(), "No transactions found") {
+			return false, err
+		}
+	}
+
+	interacted := false
+	for _, tx := range txs {
+		if tornadoCashAddresses[strings.ToLower(tx.To)] {
+			interacted = true
+			break
+		}
+	}
+	t.cache.Set(cacheKey, interacted)
+	return interacted, nil
+}
+
+// checkTornadoCash sets HasTornadoCashHistory unless the scan context skips the check
+func (s *Scanner) checkTornadoCash(ctx context.Context, client *ChainClient, result *WalletScanResult) {
+	if s.tornado == nil || tornadoCheckSkipped(ctx) {
+		return
+	}
+	interacted, err := s.tornado.HasInteracted(ctx, client, result.WalletAddress)
+	if err != nil {
+		requestLogger(ctx, s.logger()).Warn("Tornado Cash check failed", Fields{"wallet": result.WalletAddress, "error": errorText(err)})
+		return
+	}
+	result.HasTornadoCashHistory = interacted
+}
@@ -0,0 +1,136 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Approval Usage Analysis
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// transferEventTopic is keccak256("Transfer(address,address,uint256)")
+const transferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// frequentTransferFromCount is the TransferFromCount at which a spender counts as frequent
+const frequentTransferFromCount = 10
+
+// maxTransferTxLookups caps eth_getTransactionByHash calls per chain scan
+const maxTransferTxLookups = 50
+
+// recentTransferWindow is how recent a spender's last transfer must be to count as
+// recently used
+const recentTransferWindow = 7 * 24 * time.Hour
+
+// neverUsedReason is added to approvals whose spender never moved the wallet's tokens
+const neverUsedReason = "💤 This approval has never been used — safe to revoke"
+
+// analyzeTransferFromUsage sets TransferFromCount, LastTransferFromBlock and
+// LastTransferFromTime on each approval. Transfer logs don't carry msg.sender, so an
+// outgoing transfer is attributed to a spender when the transaction that emitted it was
+// sent to the spender. Counts stay -1 (unknown) when the logs can't be fetched or not
+// every transfer could be inspected. It returns the transaction recipients it looked
+// up by lowercase hash, for VolumeAnalyzer to reuse within the same budget.
+func (c *ChainClient) analyzeTransferFromUsage(ctx context.Context, walletAddress string, approvals []Approval) map[string]string {
+	byToken := make(map[string][]int)
+	for i := range approvals {
+		token := strings.ToLower(approvals[i].TokenAddress)
+		byToken[token] = append(byToken[token], i)
+	}
+
+	txTargets := make(map[string]string) // tx hash -> lowercase "to"
+	blockTimes := make(map[uint64]int64)
+	for token, indices := range byToken {
+		logs, _, err := c.getContractLogs(ctx, token, []string{transferEventTopic, padTopicAddress(walletAddress)})
+		if err != nil {
+			continue
+		}
+
+		// Newest first, so a lookup budget cut keeps the most recent activity
+		sort.SliceStable(logs, func(i, j int) bool {
+			return parseHexUint64(logs[i].BlockNumber) > parseHexUint64(logs[j].BlockNumber)
+		})
+
+		counts := make(map[string]int)
+		lastBlocks := make(map[string]uint64)
+		lastTimes := make(map[string]int64) // Etherscan logs carry their block time
+		complete := true
+		for _, logEntry := range logs {
+			hash := strings.ToLower(logEntry.TxHash)
+			target, ok := txTargets[hash]
+			if !ok {
+				if len(txTargets) >= maxTransferTxLookups {
+					complete = false
+					break
+				}
+				target = c.transactionTarget(ctx, hash)
+				txTargets[hash] = target
+			}
+			if target == "" {
+				complete = false // lookup failed, the transfer can't be attributed
+				continue
+			}
+			counts[target]++
+			if block := parseHexUint64(logEntry.BlockNumber); block > lastBlocks[target] {
+				lastBlocks[target] = block
+				lastTimes[target] = int64(parseHexUint64(logEntry.TimeStamp))
+			}
+		}
+
+		for _, i := range indices {
+			spender := strings.ToLower(approvals[i].SpenderAddress)
+			count := counts[spender]
+			if count == 0 && !complete {
+				continue // older transfers were not inspected
+			}
+			approvals[i].TransferFromCount = count
+			approvals[i].LastTransferFromBlock = lastBlocks[spender]
+			if count == 0 {
+				approvals[i].RiskReasons = append(approvals[i].RiskReasons, neverUsedReason)
+				continue
+			}
+			approvals[i].LastTransferFromTime = lastTimes[spender]
+			if approvals[i].LastTransferFromTime == 0 {
+				approvals[i].LastTransferFromTime = c.blockTime(ctx, lastBlocks[spender], blockTimes, maxApprovalTimestampLookups)
+			}
+		}
+	}
+	return txTargets
+}
+
+// transactionTarget returns the lowercase recipient of a transaction, or "" if unknown
+func (c *ChainClient) transactionTarget(ctx context.Context, txHash string) string {
+	if txHash == "" {
+		return ""
+	}
+	var tx struct {
+		To string `json:"to"`
+	}
+	if err := c.rpcCall(ctx, "eth_getTransactionByHash", []interface{}{txHash}, &tx); err != nil {
+		return ""
+	}
+	return strings.ToLower(tx.To)
+}
+
+// isFrequentlyUsed reports whether the spender regularly moves the wallet's tokens
+func isFrequentlyUsed(approval Approval) bool {
+	return approval.TransferFromCount >= frequentTransferFromCount
+}
+
+// transferRecencyKnown reports whether it is known whether the spender moved the
+// wallet's tokens recently
+func transferRecencyKnown(approval Approval) bool {
+	return approval.volumeAnalyzed || approval.TransferFromCount == 0 || approval.TransferFromCount > 0 && approval.LastTransferFromTime != 0
+}
+
+// isRecentlyUsed reports whether the spender's last transfer is within
+// recentTransferWindow, by VolumeAnalyzer or the last transferFrom
+func isRecentlyUsed(approval Approval, now time.Time) bool {
+	return approval.recentlyUsed || approval.TransferFromCount > 0 && approval.LastTransferFromTime != 0 &&
+		now.Sub(time.Unix(approval.LastTransferFromTime, 0)) < recentTransferWindow
+}
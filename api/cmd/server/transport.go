@@ -0,0 +1,92 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - HTTP Transports
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Connection pool defaults of the shared RPC transport
+const (
+	defaultRPCMaxIdleConns    = 20
+	defaultRPCIdleConnTimeout = 90 * time.Second
+)
+
+var (
+	rpcTransportOnce sync.Once
+	rpcTransport     *http.Transport
+
+	serviceTransportOnce sync.Once
+	serviceTransport     *http.Transport
+)
+
+// sharedRPCTransport returns the transport every ChainClient shares, so RPC and
+// explorer connections are reused across chains and scans
+func sharedRPCTransport() *http.Transport {
+	rpcTransportOnce.Do(func() {
+		rpcTransport = newRPCTransport()
+	})
+	return rpcTransport
+}
+
+// sharedServiceTransport returns the transport of the internal decompiler and analyzer
+// services, kept apart so slow analyses can't exhaust the RPC pool
+func sharedServiceTransport() *http.Transport {
+	serviceTransportOnce.Do(func() {
+		serviceTransport = newPooledTransport(defaultRPCMaxIdleConns, defaultRPCIdleConnTimeout)
+	})
+	return serviceTransport
+}
+
+// newRPCTransport creates the RPC transport; RPC_MAX_IDLE_CONNS sets the idle
+// connections kept per host and RPC_IDLE_CONN_TIMEOUT how long they are kept
+func newRPCTransport() *http.Transport {
+	return newPooledTransport(
+		getEnvInt("RPC_MAX_IDLE_CONNS", defaultRPCMaxIdleConns),
+		getEnvDuration("RPC_IDLE_CONN_TIMEOUT", defaultRPCIdleConnTimeout),
+	)
+}
+
+// newPooledTransport creates a transport keeping up to maxIdlePerHost idle connections per host
+func newPooledTransport(maxIdlePerHost int, idleTimeout time.Duration) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2: true,
+		// No overall idle limit: the per-host limit applies to each chain's provider
+		MaxIdleConnsPerHost: maxIdlePerHost,
+		IdleConnTimeout:     idleTimeout,
+		TLSHandshakeTimeout: 10 * time.Second,
+		DisableCompression:  false,
+	}
+}
+
+// getEnvDuration returns a duration environment variable ("90s" or plain seconds) or default value
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		defaultLogger.Warn("Invalid duration setting, using default", Fields{"key": key, "value": value, "default": fallback.String()})
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,139 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Uniswap V4 Hooks
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// modifyLiquidityTopic is keccak256("ModifyLiquidity(bytes32,address,int24,int24,int256,bytes32)")
+const modifyLiquidityTopic = "0xf208f4912782fd25c7f114ca3723a2d5dd6f3bcc3ac8db5af63baa85f711d5ec"
+
+// PoolManager view selectors
+const (
+	getHooksSelector  = "0x3fb4c953" // getHooks(bytes32)
+	positionsSelector = "0x8737e63b" // positions(address,bytes32,int24,int24)
+)
+
+// maxV4PositionLookups caps positions() calls per chain scan
+const maxV4PositionLookups = 50
+
+// uniswapV4PoolManagers is the singleton PoolManager of each chain (lowercase)
+var uniswapV4PoolManagers = map[ChainID]string{
+	Ethereum: "0x000000000004444c5dc75cb358380d2e3de08a90",
+	Arbitrum: "0x360e68faccca8ca495c1b759fd9eee466db9fb32",
+	Optimism: "0x9a13f98cb987694c9f086b1f5eb990eea8264ec3",
+	Base:     "0x498581ff718922c3f8e6a244956af099b2652b2b",
+	Polygon:  "0x67366782805870060151383f4bbff9dab53e5cd6",
+	BSC:      "0x28e2ea090877bf75740558f6bfb36a5ffee9e9df",
+}
+
+// trustedHooks are audited hook contracts (lowercase address -> name). Any other hook
+// in a pool the wallet provides liquidity to is flagged on the PoolManager approval.
+var trustedHooks = map[string]string{}
+
+// v4Position is a liquidity range the wallet opened in a V4 pool
+type v4Position struct {
+	poolID    string // 32-byte hex word
+	tickLower string // ABI-encoded int24 words, passed through to positions()
+	tickUpper string
+}
+
+// annotateUniswapV4Hooks sets UniswapV4Hooks on approvals to the chain's PoolManager.
+// Approving the PoolManager lets every hook of the wallet's pools act on the tokens, so
+// pools with open positions are found from the wallet's ModifyLiquidity events, checked
+// with positions() and their hooks read with getHooks(). Lookup failures are skipped.
+func (c *ChainClient) annotateUniswapV4Hooks(ctx context.Context, walletAddress string, approvals []Approval) {
+	poolManager, ok := uniswapV4PoolManagers[c.ChainID]
+	if !ok {
+		return
+	}
+	var indices []int
+	for i := range approvals {
+		if strings.EqualFold(approvals[i].SpenderAddress, poolManager) {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == 0 {
+		return
+	}
+
+	hooks := c.uniswapV4Hooks(ctx, poolManager, walletAddress)
+	if len(hooks) == 0 {
+		return
+	}
+
+	var unaudited []string
+	for _, hook := range hooks {
+		if _, ok := trustedHooks[hook]; !ok {
+			unaudited = append(unaudited, hook[:6]+"..."+hook[len(hook)-4:])
+		}
+	}
+	for _, i := range indices {
+		approvals[i].UniswapV4Hooks = hooks
+		if len(unaudited) > 0 {
+			approvals[i].RiskReasons = append(approvals[i].RiskReasons,
+				"⚠️ This pool uses unaudited hooks: "+strings.Join(unaudited, ", "))
+		}
+	}
+}
+
+// uniswapV4Hooks returns the sorted hook addresses of pools where walletAddress
+// still holds liquidity
+func (c *ChainClient) uniswapV4Hooks(ctx context.Context, poolManager, walletAddress string) []string {
+	logs, _, err := c.getContractLogs(ctx, poolManager, []string{modifyLiquidityTopic, "", padTopicAddress(walletAddress)})
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[v4Position]bool)
+	pools := make(map[string]bool)
+	for _, logEntry := range logs {
+		words := abiWords(logEntry.Data)
+		if len(logEntry.Topics) < 2 || len(words) < 2 {
+			continue
+		}
+		position := v4Position{
+			poolID:    strings.TrimPrefix(strings.ToLower(logEntry.Topics[1]), "0x"),
+			tickLower: words[0],
+			tickUpper: words[1],
+		}
+		if seen[position] || pools[position.poolID] {
+			continue
+		}
+		if len(seen) >= maxV4PositionLookups {
+			break
+		}
+		seen[position] = true
+
+		data := positionsSelector + strings.TrimPrefix(padTopicAddress(walletAddress), "0x") +
+			position.poolID + position.tickLower + position.tickUpper
+		result, err := c.ethCall(ctx, poolManager, data)
+		if err != nil {
+			continue
+		}
+		if liquidity := abiWords(result); len(liquidity) > 0 && strings.Trim(liquidity[0], "0") != "" {
+			pools[position.poolID] = true
+		}
+	}
+
+	unique := make(map[string]bool)
+	for poolID := range pools {
+		if hook := c.callAddress(ctx, poolManager, getHooksSelector+poolID); hook != "" {
+			unique[hook] = true
+		}
+	}
+	hooks := make([]string, 0, len(unique))
+	for hook := range unique {
+		hooks = append(hooks, hook)
+	}
+	sort.Strings(hooks)
+	return hooks
+}
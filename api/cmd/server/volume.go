@@ -0,0 +1,113 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Approval Transfer Volume
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// unusedApprovalAge is the age from which approvals without transfers are reported
+const unusedApprovalAge = 30 * 24 * time.Hour
+
+// unusedApprovalReason is added to approvals older than unusedApprovalAge whose
+// spender never moved the token, in place of neverUsedReason
+const unusedApprovalReason = "Unused approval"
+
+// VolumeAnalyzer counts the wallet's token transfers made through each approval,
+// from the wallet's Alchemy asset transfers. A transfer is attributed to a spender
+// when the transaction that moved the token was sent to the spender, as in
+// analyzeTransferFromUsage, whose transaction lookups and budget it shares.
+type VolumeAnalyzer struct {
+	clock Clock
+}
+
+// NewVolumeAnalyzer creates an analyzer judging transfer recency by clock
+func NewVolumeAnalyzer(clock Clock) *VolumeAnalyzer {
+	return &VolumeAnalyzer{clock: clock}
+}
+
+// newVolumeAnalyzerFromEnv returns an analyzer when ANALYZE_TRANSFER_VOLUME=true (nil =
+// disabled, the analysis reads the wallet's transfer history and their transactions)
+func newVolumeAnalyzerFromEnv(clock Clock) *VolumeAnalyzer {
+	if getEnv("ANALYZE_TRANSFER_VOLUME", "") != "true" {
+		return nil
+	}
+	return NewVolumeAnalyzer(clock)
+}
+
+// AnalyzeApprovals sets TransferCount on the approvals of a wallet on chains with an
+// Alchemy endpoint. txTargets holds the transaction recipients analyzeTransferFromUsage
+// already looked up (nil = none). Approvals stay unanalyzed when a zero count can't be
+// trusted because not every transfer could be read or attributed.
+func (v *VolumeAnalyzer) AnalyzeApprovals(ctx context.Context, client *ChainClient, walletAddress string, approvals []Approval, txTargets map[string]string) {
+	if len(approvals) == 0 || len(client.alchemyEndpoints()) == 0 {
+		return
+	}
+	transfers, complete, err := client.getOutgoingAssetTransfers(ctx, walletAddress)
+	if err != nil {
+		requestLogger(ctx, client.logger()).Debug("Asset transfer lookup failed", Fields{"wallet": walletAddress, "error": errorText(err)})
+		return
+	}
+
+	tokens := make(map[string]bool, len(approvals))
+	for _, approval := range approvals {
+		tokens[strings.ToLower(approval.TokenAddress)] = true
+	}
+
+	counts := make(map[string]int)          // "token:spender"
+	lastTransfers := make(map[string]int64) // "token:spender" -> unix seconds
+	if txTargets == nil {
+		txTargets = make(map[string]string) // tx hash -> lowercase "to"
+	}
+	for _, transfer := range transfers {
+		token := strings.ToLower(transfer.RawContract.Address)
+		if !tokens[token] {
+			continue
+		}
+		hash := strings.ToLower(transfer.Hash)
+		target, ok := txTargets[hash]
+		if !ok {
+			if len(txTargets) >= maxTransferTxLookups {
+				complete = false
+				break
+			}
+			target = client.transactionTarget(ctx, hash)
+			txTargets[hash] = target
+		}
+		if target == "" {
+			complete = false // lookup failed, the transfer can't be attributed
+			continue
+		}
+		key := token + ":" + target
+		counts[key]++
+		lastTransfers[key] = max(lastTransfers[key], transfer.timestamp())
+	}
+
+	now := v.clock.Now()
+	for i := range approvals {
+		approval := &approvals[i]
+		key := strings.ToLower(approval.TokenAddress) + ":" + strings.ToLower(approval.SpenderAddress)
+		count := counts[key]
+		if count == 0 && !complete {
+			continue // older transfers were not inspected
+		}
+		approval.TransferCount = count
+		approval.volumeAnalyzed = true
+		if count == 0 && approval.LastUpdated != 0 && now.Sub(time.Unix(approval.LastUpdated, 0)) > unusedApprovalAge {
+			approval.RiskReasons = append(slices.DeleteFunc(approval.RiskReasons, func(reason string) bool {
+				return reason == neverUsedReason
+			}), unusedApprovalReason)
+		}
+		if last := lastTransfers[key]; last != 0 && now.Sub(time.Unix(last, 0)) < recentTransferWindow {
+			approval.recentlyUsed = true
+		}
+	}
+}
@@ -0,0 +1,258 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Wallet Labels
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxLabelLength = 64
+
+// labelTypeDAOTreasury marks a wallet whose approvals are governance decisions
+const labelTypeDAOTreasury = "dao_treasury"
+
+// LabelStore is an address book of labelled wallets
+type LabelStore struct {
+	mu     sync.RWMutex
+	labels map[string]string // lowercase address -> label
+	types  map[string]string // lowercase address -> label type, e.g. "dao_treasury"
+}
+
+// NewLabelStore creates an empty label store
+func NewLabelStore() *LabelStore {
+	return &LabelStore{labels: make(map[string]string), types: make(map[string]string)}
+}
+
+// Set adds or updates the label of an address
+func (l *LabelStore) Set(address, label string) error {
+	label = strings.TrimSpace(label)
+	if !isValidEthereumAddress(address) {
+		return fmt.Errorf("invalid address %q", address)
+	}
+	if label == "" || len(label) > maxLabelLength {
+		return fmt.Errorf("label must be 1-%d characters", maxLabelLength)
+	}
+
+	l.mu.Lock()
+	l.labels[strings.ToLower(address)] = label
+	l.mu.Unlock()
+	return nil
+}
+
+// SetType sets the type of a labelled address; "" clears it
+func (l *LabelStore) SetType(address, labelType string) error {
+	if labelType != "" && labelType != labelTypeDAOTreasury {
+		return fmt.Errorf("label type must be %q", labelTypeDAOTreasury)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	address = strings.ToLower(address)
+	if _, ok := l.labels[address]; !ok {
+		return fmt.Errorf("%s has no label", address)
+	}
+	if labelType == "" {
+		delete(l.types, address)
+	} else {
+		l.types[address] = labelType
+	}
+	return nil
+}
+
+// Type returns the label type of an address, "" if it has none
+func (l *LabelStore) Type(address string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.types[strings.ToLower(address)]
+}
+
+// Get returns the label of an address
+func (l *LabelStore) Get(address string) (string, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	label, ok := l.labels[strings.ToLower(address)]
+	return label, ok
+}
+
+// All returns a copy of every label
+func (l *LabelStore) All() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	labels := make(map[string]string, len(l.labels))
+	for address, label := range l.labels {
+		labels[address] = label
+	}
+	return labels
+}
+
+// Parse loads labels from "0xabc...:Founder Hot Wallet,0xdef...:Treasury:dao_treasury",
+// where a trailing ":dao_treasury" sets the label type
+func (l *LabelStore) Parse(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		address, label, ok := strings.Cut(entry, ":")
+		if !ok {
+			return fmt.Errorf("label entry %q must be address:label", entry)
+		}
+		labelType := ""
+		if name, suffix, found := cutLast(label, ":"); found && strings.TrimSpace(suffix) == labelTypeDAOTreasury {
+			label, labelType = name, labelTypeDAOTreasury
+		}
+		if err := l.Set(strings.TrimSpace(address), label); err != nil {
+			return err
+		}
+		if err := l.SetType(strings.TrimSpace(address), labelType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+var fullAddressPattern = regexp.MustCompile(`0x[0-9a-fA-F]{40}`)
+
+// Apply replaces labelled addresses in text with "Label (0x1234...abcd)"
+func (l *LabelStore) Apply(text string) string {
+	return fullAddressPattern.ReplaceAllStringFunc(text, func(address string) string {
+		if label, ok := l.Get(address); ok {
+			return fmt.Sprintf("%s (%s...%s)", label, address[:6], address[len(address)-4:])
+		}
+		return address
+	})
+}
+
+// initWalletLabels loads WALLET_LABELS, skipping the env var entirely if it is malformed
+func initWalletLabels() *LabelStore {
+	store := NewLabelStore()
+	if err := store.Parse(os.Getenv("WALLET_LABELS")); err != nil {
+		defaultLogger.Warn("Ignoring invalid WALLET_LABELS", Fields{"error": errorText(err)})
+		return NewLabelStore()
+	}
+	return store
+}
+
+// Global wallet address book
+var walletLabels = initWalletLabels()
+
+// walletENS returns the verified primary ENS name of a wallet. ENS lives on Ethereum
+// mainnet, so it is only looked up for scans that include Ethereum.
+func (s *Scanner) walletENS(ctx context.Context, walletAddress string, chains []ChainID) string {
+	if !slices.Contains(chains, Ethereum) {
+		return ""
+	}
+
+	chainsMu.RLock()
+	client := s.clients[Ethereum]
+	chainsMu.RUnlock()
+	if client == nil || client.ens == nil || !isValidEthereumAddress(walletAddress) {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	name, err := client.ens.Reverse(ctx, walletAddress)
+	if err != nil {
+		client.logger().Debug("ENS reverse lookup failed", Fields{"wallet": walletAddress, "error": errorText(err)})
+	}
+	return name
+}
+
+// walletLabel returns the manual label of a wallet, falling back to its ENS name
+func walletLabel(walletAddress, ensName string) string {
+	if label, ok := walletLabels.Get(walletAddress); ok {
+		return label
+	}
+	return ensName
+}
+
+// Wallet labels endpoint - GET lists labels, POST (admin) adds or updates one
+func (s *Server) handleLabels(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		labels := walletLabels.All()
+		addresses := make([]string, 0, len(labels))
+		for address := range labels {
+			addresses = append(addresses, address)
+		}
+		sort.Strings(addresses)
+
+		type labelInfo struct {
+			Address string `json:"address"`
+			Label   string `json:"label"`
+			Type    string `json:"type,omitempty"`
+		}
+		list := make([]labelInfo, 0, len(addresses))
+		for _, address := range addresses {
+			list = append(list, labelInfo{Address: address, Label: labels[address], Type: walletLabels.Type(address)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"labels": list,
+		})
+
+	case http.MethodPost:
+		adminMiddleware(s.handleSetLabel)(w, r)
+
+	default:
+		http.Error(w, "GET or POST method required", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSetLabel adds or updates a wallet label (in memory; persist via WALLET_LABELS)
+func (s *Server) handleSetLabel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Address string `json:"address"`
+		Label   string `json:"label"`
+		Type    string `json:"type"` // "" or "dao_treasury"
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Type != "" && req.Type != labelTypeDAOTreasury {
+		http.Error(w, fmt.Sprintf("type must be %q", labelTypeDAOTreasury), http.StatusBadRequest)
+		return
+	}
+	if err := walletLabels.Set(req.Address, req.Label); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_ = walletLabels.SetType(req.Address, req.Type)
+	requestLogger(r.Context(), s.logger()).Info("Labelled wallet", Fields{"wallet": req.Address, "label": strings.TrimSpace(req.Label)})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"address": strings.ToLower(req.Address),
+		"label":   strings.TrimSpace(req.Label),
+		"type":    req.Type,
+		"updated": true,
+	})
+}
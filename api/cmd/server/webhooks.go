@@ -0,0 +1,251 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - Scan Webhooks
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// webhookRetryDelays are the waits before each retry of a failed delivery
+// (overridable in tests)
+var webhookRetryDelays = []time.Duration{5 * time.Second, 15 * time.Second, 45 * time.Second}
+
+const (
+	webhookWorkers   = 4
+	webhookQueueSize = 100
+)
+
+// Webhook event names, sent as X-Sentinel-Event
+const (
+	webhookEventScan = "scan.completed"
+	webhookEventTest = "webhook.test"
+)
+
+// WebhookDispatcher POSTs scan results to WEBHOOK_URL. Bodies are signed with
+// HMAC-SHA256 over the raw body, sent as X-Sentinel-Signature: sha256=<hex>.
+// Deliveries are queued for a pool of webhookWorkers workers, like ScanJobQueue.
+type WebhookDispatcher struct {
+	url    string
+	secret []byte
+	client *http.Client
+	log    Logger
+
+	queue chan webhookDelivery
+	start sync.Once
+	wg    sync.WaitGroup
+	// mu guards closed, so Dispatch never sends on the queue Shutdown closed
+	mu     sync.RWMutex
+	closed bool
+	stop   chan struct{} // closed by Shutdown to abandon pending retries
+}
+
+// webhookDelivery is a queued scan.completed body
+type webhookDelivery struct {
+	wallet string
+	body   []byte
+}
+
+// NewWebhookDispatcher creates a dispatcher for url, signing with secret
+func NewWebhookDispatcher(url, secret string, logger Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 10 * time.Second},
+		log:    logger,
+		queue:  make(chan webhookDelivery, webhookQueueSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// NewWebhookDispatcherFromEnv reads WEBHOOK_URL and WEBHOOK_SECRET; nil = webhooks disabled
+func NewWebhookDispatcherFromEnv(logger Logger) *WebhookDispatcher {
+	url := os.Getenv("WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	if os.Getenv("WEBHOOK_SECRET") == "" {
+		loggerOr(logger).Warn("WEBHOOK_SECRET is not set, webhook deliveries are unsigned", nil)
+	}
+	return NewWebhookDispatcher(url, os.Getenv("WEBHOOK_SECRET"), logger)
+}
+
+// logger returns the dispatcher's logger
+func (d *WebhookDispatcher) logger() Logger {
+	return loggerOr(d.log)
+}
+
+// sign returns the X-Sentinel-Signature value of body
+func (d *WebhookDispatcher) sign(body []byte) string {
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send delivers body once, failing on transport errors and non-2xx responses
+func (d *WebhookDispatcher) Send(ctx context.Context, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentinel-Event", event)
+	if len(d.secret) > 0 {
+		req.Header.Set("X-Sentinel-Signature", d.sign(body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Dispatch queues a scan result for delivery, retrying failed deliveries after 5s,
+// 15s and 45s. The result is encoded before Dispatch returns, so the caller may keep
+// using it. Results are dropped when the queue is full or the dispatcher is shut
+// down. A nil dispatcher does nothing.
+func (d *WebhookDispatcher) Dispatch(result *WalletScanResult) {
+	if d == nil {
+		return
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		d.logger().Error("Failed to encode webhook body", Fields{"wallet": result.WalletAddress, "error": errorText(err)})
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		d.logger().Warn("Webhook dispatcher shut down, dropping delivery", Fields{"wallet": result.WalletAddress})
+		return
+	}
+	d.start.Do(func() {
+		d.wg.Add(webhookWorkers)
+		for i := 0; i < webhookWorkers; i++ {
+			go d.work()
+		}
+	})
+
+	select {
+	case d.queue <- webhookDelivery{wallet: result.WalletAddress, body: body}:
+	default:
+		d.logger().Warn("Webhook queue full, dropping delivery", Fields{"wallet": result.WalletAddress})
+	}
+}
+
+// work delivers queued results until Shutdown closes the queue
+func (d *WebhookDispatcher) work() {
+	defer d.wg.Done()
+	for delivery := range d.queue {
+		d.deliver(delivery)
+	}
+}
+
+// deliver sends one result, retrying after webhookRetryDelays unless shutting down
+func (d *WebhookDispatcher) deliver(delivery webhookDelivery) {
+	for attempt := 0; ; attempt++ {
+		err := d.Send(context.Background(), webhookEventScan, delivery.body)
+		if err == nil {
+			return
+		}
+		if attempt >= len(webhookRetryDelays) {
+			d.logger().Error("Webhook delivery failed", Fields{"wallet": delivery.wallet, "attempts": attempt + 1, "error": errorText(err)})
+			return
+		}
+		d.logger().Warn("Webhook delivery failed, retrying", Fields{"wallet": delivery.wallet, "attempt": attempt + 1, "retry_in_ms": webhookRetryDelays[attempt].Milliseconds(), "error": errorText(err)})
+		select {
+		case <-time.After(webhookRetryDelays[attempt]):
+		case <-d.stop:
+			d.logger().Error("Webhook delivery abandoned at shutdown", Fields{"wallet": delivery.wallet, "attempts": attempt + 1, "error": errorText(err)})
+			return
+		}
+	}
+}
+
+// Shutdown stops accepting results and waits for the workers to send each queued one
+// (pending retries are abandoned), or until ctx is done. A nil dispatcher does nothing.
+func (d *WebhookDispatcher) Shutdown(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.stop)
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Webhook test endpoint - sends a synthetic scan result once and reports the outcome
+func (s *Server) handleWebhookTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.webhooks == nil {
+		http.Error(w, "WEBHOOK_URL is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	now := time.Now().Unix()
+	body, _ := json.Marshal(&WalletScanResult{
+		WalletAddress:   zeroAddress,
+		WalletLabel:     "Sentinel webhook test",
+		ScanTimestamp:   now,
+		CachedAt:        now,
+		ChainsScanned:   []ChainID{Ethereum},
+		Approvals:       []Approval{},
+		ContractRisks:   []ContractRisk{},
+		Recommendations: []string{},
+		ChainScanStats:  map[ChainID]ChainResult{},
+	})
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+	err := s.webhooks.Send(ctx, webhookEventTest, body)
+	auditLog(r, "webhook test sent: %v", err == nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"delivered": false, "error": errorText(err)})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"delivered": true})
+}
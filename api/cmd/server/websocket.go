@@ -0,0 +1,243 @@
+/*
+ ═══════════════════════════════════════════════════════════════════════════════
+  SENTINEL SHIELD - WebSocket Scan Watch
+  Author: SENTINEL Team
+ ═══════════════════════════════════════════════════════════════════════════════
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// defaultWSScanInterval is how often a watched wallet is rescanned (WS_SCAN_INTERVAL)
+	defaultWSScanInterval = 60 * time.Second
+	// wsHeartbeatInterval is how often watch connections are pinged
+	wsHeartbeatInterval = 30 * time.Second
+	// wsPongWait is how long a watch connection may stay silent, pongs included
+	wsPongWait = 2 * wsHeartbeatInterval
+	// wsWriteWait bounds each message write
+	wsWriteWait = 10 * time.Second
+	// wsMaxMessageSize caps client messages, which are only pause/resume actions
+	wsMaxMessageSize = 512
+	// defaultWSMaxWatches caps the open watch connections per API key, or per client IP
+	// while authentication is off (WS_MAX_WATCHES)
+	defaultWSMaxWatches = 5
+)
+
+// watchLimiter counts open watch connections per client
+type watchLimiter struct {
+	mu     sync.Mutex
+	max    int
+	active map[string]int
+}
+
+// newWatchLimiter creates a limiter admitting max connections per client
+func newWatchLimiter(max int) *watchLimiter {
+	if max <= 0 {
+		max = defaultWSMaxWatches
+	}
+	return &watchLimiter{max: max, active: make(map[string]int)}
+}
+
+// acquire reserves a connection for client, false when it already has max open
+func (l *watchLimiter) acquire(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[client] >= l.max {
+		return false
+	}
+	l.active[client]++
+	return true
+}
+
+// release frees a connection acquired for client
+func (l *watchLimiter) release(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[client]--; l.active[client] <= 0 {
+		delete(l.active, client)
+	}
+}
+
+// watchClient identifies who a watch connection counts against: its API key, or its
+// IP while authentication is off
+func watchClient(r *http.Request) string {
+	if key, ok := r.Context().Value(apiKeyContextKey{}).(APIKey); ok {
+		return "key:" + key.Hash
+	}
+	return "ip:" + clientIP(r)
+}
+
+// wsUpgrader accepts any origin: like the REST API (CORS *), access is controlled by
+// API key rather than cookies
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// WatchMessage is a message sent on /ws/scan
+type WatchMessage struct {
+	// Type is "approvals" (approvals not in the previous scan; the first scan sends
+	// all of them), "paused", "resumed" or "error"
+	Type          string     `json:"type"`
+	Approvals     []Approval `json:"approvals,omitempty"`
+	ScanTimestamp int64      `json:"scanTimestamp,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	RequestID     string     `json:"requestId,omitempty"`
+}
+
+// watchAction is a message clients send on /ws/scan
+type watchAction struct {
+	Action string `json:"action"` // "pause" or "resume"
+}
+
+// WebSocket watch endpoint: rescans the wallet every WS_SCAN_INTERVAL and pushes the
+// approvals that appeared since the previous scan, until the client disconnects
+func (s *Server) handleScanWebSocket(w http.ResponseWriter, r *http.Request) {
+	walletAddress, chains, ok := parseScanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	client := watchClient(r)
+	if !s.watches.acquire(client) {
+		http.Error(w, "too many open watch connections", http.StatusTooManyRequests)
+		return
+	}
+	defer s.watches.release(client)
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has already replied with an HTTP error
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	if r.URL.Query().Get("skipTCCheck") == "true" {
+		ctx = withoutTornadoCheck(ctx)
+	}
+
+	actions := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.watchWallet(ctx, conn, walletAddress, chains, actions)
+	}()
+
+	s.readWatchActions(conn, actions, done)
+	cancel()
+	<-done
+}
+
+// readWatchActions forwards client actions to the watch loop until the connection
+// fails, closes or stays silent past wsPongWait
+func (s *Server) readWatchActions(conn *websocket.Conn, actions chan<- string, done <-chan struct{}) {
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var action watchAction
+		if err := json.Unmarshal(data, &action); err != nil {
+			action.Action = ""
+		}
+		select {
+		case actions <- action.Action:
+		case <-done:
+			return
+		}
+	}
+}
+
+// watchWallet scans on every tick while not paused and pings on every heartbeat. A
+// failed write closes the connection, which ends the read loop.
+func (s *Server) watchWallet(ctx context.Context, conn *websocket.Conn, walletAddress string, chains []ChainID, actions <-chan string) {
+	send := func(message WatchMessage) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if err := conn.WriteJSON(message); err != nil {
+			conn.Close()
+			return false
+		}
+		return true
+	}
+
+	scans := time.NewTicker(s.wsScanInterval)
+	defer scans.Stop()
+	heartbeat := time.NewTicker(wsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var previous []Approval
+	scan := func() bool {
+		scanCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		// Refresh so the interval, not the scan cache TTL, sets how fresh updates are;
+		// only blocks added since the previous scan are queried
+		result, err := s.scanner.ScanWallet(withIncrementalRefresh(scanCtx), walletAddress, chains, true)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			requestID := requestIDOrNew(ctx)
+			s.logger().Error("Watch scan failed", Fields{"request_id": requestID, "wallet": walletAddress, "error": errorText(err)})
+			return send(WatchMessage{Type: "error", Error: "scan_failed", RequestID: requestID})
+		}
+		added := diffApprovals(previous, result.Approvals, chains).NewApprovals
+		previous = result.Approvals
+		if len(added) == 0 {
+			return true
+		}
+		return send(WatchMessage{Type: "approvals", Approvals: added, ScanTimestamp: result.ScanTimestamp})
+	}
+
+	if !scan() {
+		return
+	}
+	paused := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-scans.C:
+			if !paused && !scan() {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				conn.Close()
+				return
+			}
+		case action := <-actions:
+			var ok bool
+			switch action {
+			case "pause":
+				paused = true
+				ok = send(WatchMessage{Type: "paused"})
+			case "resume":
+				paused = false
+				ok = send(WatchMessage{Type: "resumed"})
+			default:
+				ok = send(WatchMessage{Type: "error", Error: `unknown action, expected {"action":"pause"} or {"action":"resume"}`})
+			}
+			if !ok {
+				return
+			}
+		}
+	}
+}
@@ -128,6 +128,10 @@ const SUPPORTED_CHAINS: Chain[] = [
   { id: 'linea', name: 'Linea', icon: '📐', color: '#61DFFF' },
   { id: 'scroll', name: 'Scroll', icon: '📜', color: '#FFCB45' },
   { id: 'zkevm', name: 'Polygon zkEVM', icon: '🔐', color: '#7B3FE4' },
+  { id: 'mantle', name: 'Mantle', icon: '🧥', color: '#000000' },
+  { id: 'mode', name: 'Mode', icon: '🟩', color: '#DFFE00' },
+  { id: 'blast', name: 'Blast', icon: '💥', color: '#FCFC03' },
+  { id: 'manta', name: 'Manta Pacific', icon: '🐟', color: '#0091FF' },
   // Alt L1s
   { id: 'bsc', name: 'BNB Chain', icon: '⬡', color: '#F3BA2F' },
   { id: 'polygon', name: 'Polygon PoS', icon: '⬢', color: '#8247E5' },
@@ -151,6 +155,10 @@ const EXPLORER_URLS: Record<string, string> = {
   linea: 'https://lineascan.build',
   scroll: 'https://scrollscan.com',
   zkevm: 'https://zkevm.polygonscan.com',
+  mantle: 'https://mantlescan.xyz',
+  mode: 'https://modescan.io',
+  blast: 'https://blastscan.io',
+  manta: 'https://pacific-explorer.manta.network',
   bsc: 'https://bscscan.com',
   polygon: 'https://polygonscan.com',
   avalanche: 'https://snowtrace.io',
@@ -49,6 +49,28 @@ func FuzzAddressValidation(f *testing.F) {
 	})
 }
 
+// FuzzSolanaAddressValidation tests chain-aware validation: Solana wallets are
+// base58 public keys, every other chain takes 0x addresses
+func FuzzSolanaAddressValidation(f *testing.F) {
+	f.Add("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM")
+	f.Add("11111111111111111111111111111111")
+	f.Add("0x742d35Cc6634C0532925a3b844Bc9e7595f5b2e1")
+	f.Add("0OIl0OIl0OIl0OIl0OIl0OIl0OIl0OIl")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, addr string) {
+		// Property: no address is valid on both Solana and an EVM chain
+		if isValidAddressForChain(addr, "solana") && isValidAddressForChain(addr, "ethereum") {
+			t.Errorf("Address %s accepted as both Solana and EVM", addr)
+		}
+
+		// Property: the server agrees with the test validator
+		if isValidAddressForChain(addr, "solana") != isValidSolanaAddress(addr) {
+			t.Errorf("Solana validation of %s disagrees with the server", addr)
+		}
+	})
+}
+
 // FuzzChainParsing tests chain parameter parsing
 func FuzzChainParsing(f *testing.F) {
 	f.Add("ethereum")
@@ -367,6 +389,22 @@ func isValidAddress(addr string) bool {
 	return true
 }
 
+// isValidAddressForChain validates addr in the address format of chain
+func isValidAddressForChain(addr, chain string) bool {
+	if chain == "solana" {
+		if len(addr) < 32 || len(addr) > 44 {
+			return false
+		}
+		for _, c := range addr {
+			if !strings.ContainsRune("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", c) {
+				return false
+			}
+		}
+		return true
+	}
+	return isValidAddress(addr)
+}
+
 func isSupportedChain(chain string) bool {
 	supported := map[string]bool{
 		"ethereum":  true,
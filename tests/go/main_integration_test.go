@@ -1,11 +1,32 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	sentinelv1 "github.com/sentinel-team/sentinel/api/proto/sentinel/v1"
 )
 
 type mockScanner struct {
@@ -14,13 +35,16 @@ type mockScanner struct {
 	called     bool
 	lastWallet string
 	lastChains []ChainID
+
+	lastForceRefresh bool
 }
 
 func newMockScanner(result *WalletScanResult, err error) *mockScanner {
 	return &mockScanner{result: result, err: err}
 }
 
-func (m *mockScanner) ScanWallet(_ context.Context, walletAddress string, chains []ChainID) (*WalletScanResult, error) {
+func (m *mockScanner) ScanWallet(_ context.Context, walletAddress string, chains []ChainID, forceRefresh bool) (*WalletScanResult, error) {
+	m.lastForceRefresh = forceRefresh
 	m.called = true
 	m.lastWallet = walletAddress
 	clone := append([]ChainID(nil), chains...)
@@ -45,7 +69,7 @@ func (m *mockScanner) ScanWallet(_ context.Context, walletAddress string, chains
 
 func TestHandleScanFiltersChains(t *testing.T) {
 	mock := newMockScanner(&WalletScanResult{}, nil)
-	server := NewServerWithScanner(mock)
+	server := NewServerWithScanner(mock, defaultLogger)
 	ts := httptest.NewServer(http.HandlerFunc(server.handleScan))
 	defer ts.Close()
 
@@ -83,7 +107,7 @@ func TestHandleScanFiltersChains(t *testing.T) {
 
 func TestHandleScanRejectsInvalidChains(t *testing.T) {
 	mock := newMockScanner(&WalletScanResult{}, nil)
-	server := NewServerWithScanner(mock)
+	server := NewServerWithScanner(mock, defaultLogger)
 	ts := httptest.NewServer(http.HandlerFunc(server.handleScan))
 	defer ts.Close()
 
@@ -101,3 +125,1484 @@ func TestHandleScanRejectsInvalidChains(t *testing.T) {
 		t.Fatal("scanner should not run when chains are invalid")
 	}
 }
+
+func TestHandleScan_InvalidWalletAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		wallet string
+	}{
+		{"plain text", "notanaddress"},
+		{"missing prefix", "1234567890123456789012345678901234567890"},
+		{"too short", "0x12345678901234567890123456789012345678"},
+		{"too long", "0x123456789012345678901234567890123456789012"},
+		{"non-hex", "0xZZ34567890123456789012345678901234567890"},
+		{"uppercase prefix", "0X1234567890123456789012345678901234567890"},
+		{"base58 with zero", "0OIl1111111111111111111111111111111"},
+		{"base58 too short", "4Nd1mBQtrMJVYVfKf2PJ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockScanner(&WalletScanResult{}, nil)
+			server := NewServerWithScanner(mock, defaultLogger)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet="+url.QueryEscape(tt.wallet), nil)
+			w := httptest.NewRecorder()
+			server.handleScan(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", w.Code)
+			}
+			if mock.called {
+				t.Fatal("scanner should not run for an invalid wallet address")
+			}
+
+			var payload struct {
+				Error            string   `json:"error"`
+				Address          string   `json:"address"`
+				SupportedFormats []string `json:"supported_formats"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&payload); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if payload.Error != "invalid_wallet_address" || payload.Address != tt.wallet {
+				t.Fatalf("unexpected payload: %+v", payload)
+			}
+			if len(payload.SupportedFormats) != 2 {
+				t.Fatalf("expected 2 supported formats, got %v", payload.SupportedFormats)
+			}
+		})
+	}
+}
+
+func TestHandleScan_AcceptsSolanaAddress(t *testing.T) {
+	mock := newMockScanner(&WalletScanResult{}, nil)
+	server := NewServerWithScanner(mock, defaultLogger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet=4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T&chains=ethereum", nil)
+	w := httptest.NewRecorder()
+	server.handleScan(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !mock.called {
+		t.Fatal("expected scanner to be invoked for a Solana address")
+	}
+}
+
+func TestHandleScanPassesRefreshFlag(t *testing.T) {
+	mock := newMockScanner(&WalletScanResult{}, nil)
+	server := NewServerWithScanner(mock, defaultLogger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet=0x1234567890123456789012345678901234567890&chains=ethereum&refresh=true", nil)
+	w := httptest.NewRecorder()
+	server.handleScan(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !mock.lastForceRefresh {
+		t.Fatal("expected refresh=true to force a fresh scan")
+	}
+}
+
+func TestHandleScan_SanitizesScannerErrors(t *testing.T) {
+	mock := newMockScanner(nil, fmt.Errorf(`Post "https://eth-mainnet.g.alchemy.com/v2/demo": context deadline exceeded (Client.Timeout exceeded)`))
+	server := NewServerWithScanner(mock, defaultLogger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet=0x1234567890123456789012345678901234567890&chains=ethereum", nil)
+	w := httptest.NewRecorder()
+	server.handleScan(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "alchemy.com") || strings.Contains(body, "demo") {
+		t.Fatalf("expected internal error details to be hidden, got %s", body)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload["error"] != "scan_failed" || payload["message"] == "" || payload["requestId"] == "" {
+		t.Fatalf("unexpected error payload: %v", payload)
+	}
+	if w.Header().Get("X-Request-ID") != payload["requestId"] {
+		t.Fatalf("expected X-Request-ID to match requestId, got %q", w.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestLabelsEndpoint(t *testing.T) {
+	original := walletLabels
+	walletLabels = NewLabelStore()
+	t.Cleanup(func() { walletLabels = original })
+	t.Setenv("ADMIN_KEY", "secret")
+
+	server := NewServerWithScanner(newMockScanner(nil, nil), defaultLogger)
+	body := `{"address": "0x742d35Cc6634C0532925a3b844Bc454e4438f44e", "label": "Founder Hot Wallet"}`
+
+	// POST requires the admin key
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/labels", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleLabels(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without admin key, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/labels", strings.NewReader(body))
+	req.Header.Set("X-Admin-Key", "secret")
+	w = httptest.NewRecorder()
+	server.handleLabels(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/labels", nil)
+	w = httptest.NewRecorder()
+	server.handleLabels(w, req)
+
+	var payload struct {
+		Labels []struct {
+			Address string `json:"address"`
+			Label   string `json:"label"`
+		} `json:"labels"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(payload.Labels) != 1 || payload.Labels[0].Label != "Founder Hot Wallet" ||
+		payload.Labels[0].Address != "0x742d35cc6634c0532925a3b844bc454e4438f44e" {
+		t.Fatalf("unexpected labels: %+v", payload.Labels)
+	}
+}
+
+func TestAdminChainsRegistersCustomChain(t *testing.T) {
+	t.Setenv("ADMIN_KEY", "test-admin-key")
+
+	savedChains := append([]ChainID(nil), AllChains...)
+	t.Cleanup(func() {
+		chainsMu.Lock()
+		defer chainsMu.Unlock()
+		AllChains = savedChains
+		delete(config.RPC, "mychainl2")
+		delete(etherscanConfig.ChainIDs, "mychainl2")
+		delete(etherscanConfig.Explorers, "mychainl2")
+		delete(customChains, "mychainl2")
+	})
+
+	mock := newMockScanner(&WalletScanResult{}, nil)
+	server := NewServerWithScanner(mock, defaultLogger)
+	admin := httptest.NewServer(adminMiddleware(server.handleAdminChains))
+	defer admin.Close()
+
+	body := `{"id": "mychainl2", "rpcUrl": "https://rpc.mychainl2.io", "chainNumericId": 12345, "etherscanUrl": "https://api.mychainl2.io/api"}`
+
+	unauthorized, err := http.Post(admin.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+	unauthorized.Body.Close()
+	if unauthorized.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without admin key, got %d", unauthorized.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, admin.URL, strings.NewReader(body))
+	req.Header.Set("X-Admin-Key", "test-admin-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", resp.StatusCode)
+	}
+
+	if _, ok := server.chainClients["mychainl2"]; !ok {
+		t.Fatal("expected a chain client for the custom chain")
+	}
+
+	scan := httptest.NewServer(http.HandlerFunc(server.handleScan))
+	defer scan.Close()
+
+	scanResp, err := http.Get(scan.URL + "?wallet=0x1234567890123456789012345678901234567890&chains=mychainl2")
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+	scanResp.Body.Close()
+	if scanResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 scanning custom chain, got %d", scanResp.StatusCode)
+	}
+	if len(mock.lastChains) != 1 || mock.lastChains[0] != "mychainl2" {
+		t.Fatalf("unexpected chains passed to scanner: %#v", mock.lastChains)
+	}
+}
+
+func TestHandleBatchAnalyze_DataRace(t *testing.T) {
+	server := NewServerWithScanner(newMockScanner(nil, nil), defaultLogger)
+
+	// Unsupported chains fail fast without network calls, and the error text
+	// pairs each address with its chain so mismatched captures are detectable
+	type contract struct {
+		Address string  `json:"address"`
+		Chain   ChainID `json:"chain"`
+	}
+	contracts := make([]contract, 10)
+	for i := range contracts {
+		contracts[i] = contract{
+			Address: fmt.Sprintf("0x%040d", i),
+			Chain:   ChainID(fmt.Sprintf("racechain%d", i)),
+		}
+	}
+	body, _ := json.Marshal(map[string]interface{}{"contracts": contracts})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/analyze/batch", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleBatchAnalyze(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var payload struct {
+		Errors []string `json:"errors"`
+		Failed int      `json:"failed"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if payload.Failed != len(contracts) {
+		t.Fatalf("expected %d failures, got %d", len(contracts), payload.Failed)
+	}
+
+	seen := make(map[string]bool)
+	for _, msg := range payload.Errors {
+		seen[msg] = true
+	}
+	for _, c := range contracts {
+		expected := fmt.Sprintf("%s: unsupported chain: %s", c.Address, c.Chain)
+		if !seen[expected] {
+			t.Errorf("missing result for %s on %s", c.Address, c.Chain)
+		}
+	}
+}
+
+func TestAdminRiskOverrides(t *testing.T) {
+	t.Setenv("ADMIN_KEY", "test-admin-key")
+
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	saved := riskOverrides
+	riskOverrides = NewRiskOverrideStore(clock)
+	t.Cleanup(func() { riskOverrides = saved })
+
+	server := NewServerWithScanner(newMockScanner(nil, nil), defaultLogger)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/admin/risk/override", adminMiddleware(server.handleRiskOverride))
+	mux.HandleFunc("/api/v1/admin/risk/overrides/", adminMiddleware(server.handleRiskOverrides))
+	mux.HandleFunc("/api/v1/admin/risk/overrides", adminMiddleware(server.handleRiskOverrides))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Admin-Key", "test-admin-key")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// Uniswap's router is normally safe
+	router := "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"
+	if w := do(http.MethodPost, "/api/v1/admin/risk/override", `{"address": "0x68B3465833fb72A70ecDF485E0e4C7bD8665Fc45", "riskLevel": "severe"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for invalid risk level, got %d", w.Code)
+	}
+	body := fmt.Sprintf(`{"address": "0x68B3465833fb72A70ecDF485E0e4C7bD8665Fc45", "riskLevel": "critical", "reason": "New Pink Drainer variant", "expiresAt": %d}`, clock.Now().Unix()+3600)
+	if w := do(http.MethodPost, "/api/v1/admin/risk/override", body); w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if _, level := getSpenderInfo(router); level != "critical" {
+		t.Fatalf("expected override to make the router critical, got %s", level)
+	}
+	result := &WalletScanResult{Approvals: []Approval{{TokenAddress: "0x6b175474e89094c44da98b954eedeac495271d0f", SpenderAddress: router, SpenderName: "✅ Uniswap V3: Router 2", RiskLevel: "safe"}}}
+	NewScanner().calculateRiskScores(result)
+	if result.Approvals[0].RiskLevel != "critical" || !strings.Contains(strings.Join(result.Approvals[0].RiskReasons, "\n"), "New Pink Drainer variant") {
+		t.Fatalf("expected overridden approval to be critical with the override reason, got %s %v", result.Approvals[0].RiskLevel, result.Approvals[0].RiskReasons)
+	}
+
+	w := do(http.MethodGet, "/api/v1/admin/risk/overrides", "")
+	var listed struct {
+		Overrides []RiskOverride `json:"overrides"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&listed); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(listed.Overrides) != 1 || listed.Overrides[0].Address != router || listed.Overrides[0].CreatedAt != clock.Now().Unix() {
+		t.Fatalf("unexpected overrides: %+v", listed.Overrides)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/admin/risk/overrides/"+router, ""); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 removing override, got %d", w.Code)
+	}
+	if _, level := getSpenderInfo(router); level != "safe" {
+		t.Fatalf("expected router to be safe after removal, got %s", level)
+	}
+	if w := do(http.MethodDelete, "/api/v1/admin/risk/overrides/"+router, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for a removed override, got %d", w.Code)
+	}
+
+	// Overrides lapse at expiresAt
+	do(http.MethodPost, "/api/v1/admin/risk/override", body)
+	clock.Advance(2 * time.Hour)
+	if _, level := getSpenderInfo(router); level != "safe" {
+		t.Fatalf("expected expired override to be ignored, got %s", level)
+	}
+	if overrides := riskOverrides.List(); len(overrides) != 0 {
+		t.Fatalf("expected no active overrides after expiry, got %+v", overrides)
+	}
+}
+
+// localOnlyTransport fails every request that isn't to the in-process mock node
+type localOnlyTransport struct {
+	base http.RoundTripper
+}
+
+func (l localOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if host := req.URL.Hostname(); host != "127.0.0.1" && host != "localhost" {
+		return nil, fmt.Errorf("external request blocked in test: %s", req.URL.Host)
+	}
+	return l.base.RoundTrip(req)
+}
+
+func TestFullScanFlow(t *testing.T) {
+	savedTransport := http.DefaultTransport
+	http.DefaultTransport = localOnlyTransport{base: savedTransport}
+	t.Cleanup(func() { http.DefaultTransport = savedTransport })
+
+	wallet := "0x1234567890123456789012345678901234567890"
+	uniswapRouter := "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"  // safe
+	unknownSpender := "0x5555555555555555555555555555555555555555" // warning
+	pinkDrainer := "0x000000000000084e91743124a982076c59f10084"    // critical
+
+	tokens := map[string]string{
+		"ethereum": "0x7777777777777777777777777777777777777701",
+		"polygon":  "0x7777777777777777777777777777777777777702",
+	}
+	approvalLog := func(token, spender string, block int) string {
+		return fmt.Sprintf(`{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0x%x","transactionHash":"0x%064x","logIndex":"0x0"}`,
+			token, approvalEventTopic, padTopicAddress(wallet), padTopicAddress(spender), 1000, block, block)
+	}
+	abiString := func(s string) string {
+		return fmt.Sprintf("0x%064x%064x%-64s", 32, len(s), hex.EncodeToString([]byte(s)))
+	}
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chain := strings.Trim(r.URL.Path, "/")
+		token := tokens[chain]
+
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		reply := func(result string) {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":%s}`, result)
+		}
+		switch req.Method {
+		case "eth_blockNumber":
+			reply(`"0x3e8"`)
+		case "eth_getLogs":
+			var filter struct {
+				Address string `json:"address"`
+			}
+			_ = json.Unmarshal(req.Params[0], &filter)
+			if filter.Address != "" {
+				reply(`[]`) // no outgoing transfers
+				return
+			}
+			reply("[" + strings.Join([]string{
+				approvalLog(token, uniswapRouter, 10),
+				approvalLog(token, unknownSpender, 20),
+				approvalLog(token, pinkDrainer, 30),
+			}, ",") + "]")
+		case "eth_call":
+			var call struct {
+				To string `json:"to"`
+			}
+			_ = json.Unmarshal(req.Params[0], &call)
+			if !strings.EqualFold(call.To, token) {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+				return
+			}
+			reply(`"` + abiString("MOCK") + `"`)
+		case "eth_getBalance":
+			reply(`"0x0"`)
+		case "eth_getCode":
+			reply(`"0x"`)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported method %s"}}`, req.Method)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{"ethereum": node.URL + "/ethereum", "polygon": node.URL + "/polygon"}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	scanner := NewScannerWithClock(NewMockClock(time.Unix(1700000000, 0)))
+	scanner.clients = map[ChainID]*ChainClient{
+		Ethereum: NewChainClient(Ethereum, node.URL+"/ethereum", defaultLogger),
+		Polygon:  NewChainClient(Polygon, node.URL+"/polygon", defaultLogger),
+	}
+
+	result, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{Ethereum, Polygon}, false)
+	if err != nil {
+		t.Fatalf("unexpected scan error: %v", err)
+	}
+
+	if len(result.ChainsScanned) != 2 || result.ChainsScanned[0] != Ethereum || result.ChainsScanned[1] != Polygon {
+		t.Fatalf("expected ChainsScanned [ethereum polygon], got %v", result.ChainsScanned)
+	}
+	for chain, stats := range result.ChainScanStats {
+		if stats.Error != "" || stats.ApprovalsFound != 3 {
+			t.Fatalf("expected 3 approvals without error on %s, got %+v", chain, stats)
+		}
+	}
+	if result.TotalApprovals != 6 || len(result.Approvals) != 6 {
+		t.Fatalf("expected 6 approvals, got %d (%d listed)", result.TotalApprovals, len(result.Approvals))
+	}
+	if result.CriticalRisks != 2 {
+		t.Errorf("expected 2 critical approvals, got %d", result.CriticalRisks)
+	}
+	if result.Warnings != 2 {
+		t.Errorf("expected 2 warnings, got %d", result.Warnings)
+	}
+	// Per chain: trusted (2) + unknown (15 + 10) + drainer (50), capped at 100
+	if result.OverallRiskScore < 90 || result.OverallRiskScore > 100 {
+		t.Errorf("expected overall risk score between 90 and 100, got %d", result.OverallRiskScore)
+	}
+
+	names := make(map[string]int)
+	for _, approval := range result.Approvals {
+		names[approval.SpenderName]++
+		if approval.TokenSymbol != "MOCK" {
+			t.Errorf("expected token symbol from the mock node, got %q", approval.TokenSymbol)
+		}
+	}
+	for _, name := range []string{"✅ Uniswap V3: Router 2", "🚨 DRAINER: Pink Drainer", "0x5555...5555"} {
+		if names[name] != 2 {
+			t.Errorf("expected spender %q on both chains, got %d", name, names[name])
+		}
+	}
+
+	urgent := false
+	for _, rec := range result.Recommendations {
+		if strings.Contains(rec, "URGENT") {
+			urgent = true
+		}
+	}
+	if !urgent {
+		t.Errorf("expected an URGENT recommendation, got %v", result.Recommendations)
+	}
+}
+
+func TestHandleScan_ETagRevalidation(t *testing.T) {
+	mock := newMockScanner(&WalletScanResult{ScanTimestamp: 1700000000, Approvals: []Approval{
+		{Chain: Ethereum, TokenAddress: "0x7777777777777777777777777777777777777777"},
+		{Chain: Polygon, TokenAddress: "0x8888888888888888888888888888888888888888"},
+	}}, nil)
+	server := NewServerWithScanner(mock, defaultLogger)
+	target := "/api/v1/scan?wallet=0x1234567890123456789012345678901234567890&chains=polygon,ethereum"
+
+	w := httptest.NewRecorder()
+	server.handleScan(w, httptest.NewRequest(http.MethodGet, target, nil))
+	etag := w.Header().Get("ETag")
+	sum := sha256.Sum256(w.Body.Bytes())
+	if w.Code != http.StatusOK || etag != `"`+hex.EncodeToString(sum[:])+`"` {
+		t.Fatalf("expected 200 tagged with the body's SHA-256, got %d %q", w.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.handleScan(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Fatalf("expected empty 304 with the same ETag, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Another page is another body
+	req = httptest.NewRequest(http.MethodGet, target+"&limit=1", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.handleScan(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected a fresh 200 for a different query, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// Parameters that don't change the body don't change the response either
+	req = httptest.NewRequest(http.MethodGet, target+"&utm_source=test", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.handleScan(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 with an unrelated parameter, got %d", w.Code)
+	}
+
+	// A scan with chain errors isn't served to the complete rescan of the same second
+	complete := *mock.result
+	failed := complete
+	failed.ChainScanStats = map[ChainID]ChainResult{Polygon: {Error: "rpc unavailable"}}
+	mock.result = &failed
+	failedTarget := target + "&refresh=true&limit=2"
+	w = httptest.NewRecorder()
+	server.handleScan(w, httptest.NewRequest(http.MethodGet, failedTarget, nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "rpc unavailable") {
+		t.Fatalf("expected the failed scan, got %d %s", w.Code, w.Body.String())
+	}
+	mock.result = &complete
+	w = httptest.NewRecorder()
+	server.handleScan(w, httptest.NewRequest(http.MethodGet, target+"&limit=2", nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "rpc unavailable") {
+		t.Fatalf("expected the complete rescan, got %d %s", w.Code, w.Body.String())
+	}
+
+	// v2 envelopes differ per response and aren't tagged
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/scan?wallet=0x1234567890123456789012345678901234567890", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.handleScanV2(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Fatalf("expected an untagged v2 200, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// A scanner cache hit keeps the scan's timestamp but must still report cacheHit
+	wallet := "0x1234567890123456789012345678901234567890"
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var filter struct {
+			Address string   `json:"address"`
+			Topics  []string `json:"topics"`
+		}
+		if req.Method == "eth_getLogs" {
+			_ = json.Unmarshal(req.Params[0], &filter)
+		}
+		switch {
+		case req.Method == "eth_blockNumber":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x3e8"}`)
+		case req.Method == "eth_getLogs" && filter.Address == "" && len(filter.Topics) > 0 && filter.Topics[0] == approvalEventTopic:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"0x7777777777777777777777777777777777777777","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0xa","logIndex":"0x0"}]}`,
+				approvalEventTopic, padTopicAddress(wallet), padTopicAddress("0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"), 1000)
+		case req.Method == "eth_getLogs":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[]}`)
+		case req.Method == "eth_getBalance":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x0"}`)
+		case req.Method == "eth_getCode":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x"}`)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(Ethereum): node.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	scanner := NewScannerWithClock(NewMockClock(time.Unix(1700000000, 0)))
+	scanner.clients = map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, node.URL, defaultLogger)}
+	server = NewServerWithScanner(scanner, defaultLogger)
+	target = "/api/v1/scan?wallet=" + wallet + "&chains=ethereum"
+
+	var bodies [2]WalletScanResult
+	for i := range bodies {
+		w = httptest.NewRecorder()
+		server.handleScan(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("scan %d: expected 200, got %d %s", i, w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&bodies[i]); err != nil {
+			t.Fatalf("scan %d: decode response: %v", i, err)
+		}
+	}
+	if bodies[0].CacheHit || !bodies[1].CacheHit {
+		t.Fatalf("expected a fresh scan then a cache hit, got cacheHit %t then %t", bodies[0].CacheHit, bodies[1].CacheHit)
+	}
+}
+
+func TestHandleScanV2_EnvelopeAndV1Deprecation(t *testing.T) {
+	t.Setenv("API_V1_SUNSET_DATE", "2027-06-30")
+
+	mock := newMockScanner(&WalletScanResult{
+		ChainScanStats: map[ChainID]ChainResult{Polygon: {Error: "rpc unavailable"}},
+	}, nil)
+	server := NewServerWithScanner(mock, defaultLogger)
+	wallet := "0x1234567890123456789012345678901234567890"
+
+	w := httptest.NewRecorder()
+	server.handleScanV2(w, httptest.NewRequest(http.MethodGet, "/api/v2/scan?wallet="+wallet+"&chains=ethereum,polygon", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var envelope struct {
+		Version   string           `json:"version"`
+		Timestamp int64            `json:"timestamp"`
+		RequestID string           `json:"requestId"`
+		Data      WalletScanResult `json:"data"`
+		Warnings  []string         `json:"warnings"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&envelope); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if envelope.Version != "1.0.0" || envelope.Timestamp == 0 || len(envelope.RequestID) != 36 {
+		t.Fatalf("expected version, timestamp and request id, got %+v", envelope)
+	}
+	if w.Header().Get("X-Request-ID") != envelope.RequestID {
+		t.Fatalf("expected X-Request-ID %q, got %q", envelope.RequestID, w.Header().Get("X-Request-ID"))
+	}
+	if envelope.Data.WalletAddress != wallet || len(envelope.Data.ChainsScanned) != 2 {
+		t.Fatalf("expected scan result as data, got %+v", envelope.Data)
+	}
+	if len(envelope.Warnings) != 1 || !strings.Contains(envelope.Warnings[0], "polygon: scan failed: rpc unavailable") {
+		t.Fatalf("expected chain error warning, got %v", envelope.Warnings)
+	}
+
+	// Errors keep their status with the v1 message in the envelope
+	w = httptest.NewRecorder()
+	envelopeMiddleware(server.handleScan)(w, httptest.NewRequest(http.MethodGet, "/api/v2/scan", nil))
+	var failed ResponseEnvelope
+	if err := json.NewDecoder(w.Body).Decode(&failed); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	if w.Code != http.StatusBadRequest || failed.Error != "wallet parameter required" || string(failed.Data) != "null" || failed.Warnings == nil {
+		t.Fatalf("expected enveloped 400, got %d %+v", w.Code, failed)
+	}
+
+	// v1 stays bare JSON but announces its retirement
+	w = httptest.NewRecorder()
+	deprecatedV1(server.handleScan)(w, httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet="+wallet, nil))
+	var bare WalletScanResult
+	if err := json.NewDecoder(w.Body).Decode(&bare); err != nil || bare.WalletAddress != wallet {
+		t.Fatalf("expected bare v1 result, got %v (%v)", bare, err)
+	}
+	if w.Header().Get("Deprecated") != "true" {
+		t.Fatalf("expected Deprecated header, got %q", w.Header().Get("Deprecated"))
+	}
+	if got := w.Header().Get("Sunset"); got != "Wed, 30 Jun 2027 00:00:00 GMT" {
+		t.Fatalf("expected Sunset from API_V1_SUNSET_DATE, got %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `</api/v2/scan>; rel="successor-version"` {
+		t.Fatalf("expected successor link, got %q", got)
+	}
+}
+
+func TestHandleScanWebSocket_CapsWatchesPerClient(t *testing.T) {
+	server := NewServerWithScanner(&growingScanner{}, defaultLogger)
+	server.watches = newWatchLimiter(1)
+	ts := httptest.NewServer(http.HandlerFunc(server.handleScanWebSocket))
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/scan?wallet=0x9999999999999999999999999999999999999999&chains=ethereum"
+	first, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected a second watch from the same client to get 429, got %v", err)
+	}
+
+	// Closing the first watch frees its slot
+	first.Close()
+	deadline := time.Now().Add(time.Second)
+	for {
+		second, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err == nil {
+			second.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a watch after the first closed, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// growingScanner finds one more approval on every scan
+type growingScanner struct {
+	scans atomic.Int32
+}
+
+func (g *growingScanner) ScanWallet(_ context.Context, walletAddress string, chains []ChainID, _ bool) (*WalletScanResult, error) {
+	n := int(g.scans.Add(1))
+	result := &WalletScanResult{WalletAddress: walletAddress, ChainsScanned: chains, ScanTimestamp: int64(n)}
+	for i := 1; i <= n; i++ {
+		result.Approvals = append(result.Approvals, Approval{Chain: Ethereum, TokenAddress: "0x7777777777777777777777777777777777777777", SpenderAddress: fmt.Sprintf("0x%040x", i)})
+	}
+	return result, nil
+}
+
+func TestHandleScanWebSocket_PushesNewApprovalsUntilClosed(t *testing.T) {
+	scanner := &growingScanner{}
+	server := NewServerWithScanner(scanner, defaultLogger)
+	server.wsScanInterval = 20 * time.Millisecond
+	// Behind the tracing middleware, whose writer must support the upgrade
+	ts := httptest.NewServer(tracingMiddleware(http.HandlerFunc(server.handleScanWebSocket)))
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/scan?wallet=0x9999999999999999999999999999999999999999&chains=ethereum"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Each message holds only the approval the scan added
+	for want := 1; want <= 2; want++ {
+		var message WatchMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if message.Type != "approvals" || len(message.Approvals) != 1 || message.Approvals[0].SpenderAddress != fmt.Sprintf("0x%040x", want) {
+			t.Fatalf("expected approval %d alone, got %+v", want, message)
+		}
+	}
+
+	readUntil := func(messageType string) {
+		t.Helper()
+		for {
+			var message WatchMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if message.Type == messageType {
+				return
+			}
+		}
+	}
+
+	if err := conn.WriteJSON(map[string]string{"action": "pause"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	readUntil("paused")
+	paused := scanner.scans.Load()
+	time.Sleep(100 * time.Millisecond)
+	if got := scanner.scans.Load(); got != paused {
+		t.Fatalf("expected no scans while paused, got %d more", got-paused)
+	}
+
+	if err := conn.WriteJSON(map[string]string{"action": "resume"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	readUntil("resumed")
+	readUntil("approvals")
+
+	// Closing the connection stops the background scans
+	conn.Close()
+	time.Sleep(100 * time.Millisecond)
+	closed := scanner.scans.Load()
+	time.Sleep(100 * time.Millisecond)
+	if got := scanner.scans.Load(); got != closed {
+		t.Fatalf("expected scans to stop after disconnect, got %d more", got-closed)
+	}
+}
+
+func TestGRPCScanWallet_StreamsApprovalsAndSummary(t *testing.T) {
+	sum := sha256.Sum256([]byte("grpc-key"))
+	saved := apiKeys
+	apiKeys = loadAPIKeys(hex.EncodeToString(sum[:]))
+	t.Cleanup(func() { apiKeys = saved })
+
+	scanner := &growingScanner{}
+	scanner.scans.Store(1) // the scan finds two approvals
+	srv := newGRPCServer(NewServerWithScanner(scanner, defaultLogger), nil, nil)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = srv.Serve(listener) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := sentinelv1.NewSentinelServiceClient(conn)
+	request := &sentinelv1.ScanWalletRequest{Wallet: "0x9999999999999999999999999999999999999999", Chains: []string{"ethereum"}}
+
+	// Authenticated like the HTTP API
+	stream, err := client.ScanWallet(context.Background(), request)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without a key, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer grpc-key")
+	stream, err = client.ScanWallet(ctx, request)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	var approvals []*sentinelv1.Approval
+	var summary *sentinelv1.ScanSummary
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if approval := event.GetApproval(); approval != nil {
+			approvals = append(approvals, approval)
+		}
+		if event.GetSummary() != nil {
+			summary = event.GetSummary()
+		}
+	}
+	if len(approvals) != 2 || approvals[1].GetSpenderAddress() != fmt.Sprintf("0x%040x", 2) || approvals[0].GetChain() != "ethereum" {
+		t.Fatalf("expected the scan's two approvals, got %v", approvals)
+	}
+	if summary == nil || summary.GetWallet() != request.Wallet || !slices.Equal(summary.GetChainsScanned(), []string{"ethereum"}) {
+		t.Fatalf("expected a summary last, got %v", summary)
+	}
+
+	_, err = client.AnalyzeContract(ctx, &sentinelv1.AnalyzeContractRequest{Contract: "not-an-address"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a bad contract, got %v", err)
+	}
+}
+
+func TestGRPCServer_AppliesIPFilterAndRateLimit(t *testing.T) {
+	dial := func(t *testing.T, ipFilter *IPFilter, limiter *RateLimiter) sentinelv1.SentinelServiceClient {
+		srv := newGRPCServer(NewServerWithScanner(&growingScanner{}, defaultLogger), ipFilter, limiter)
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		go func() { _ = srv.Serve(listener) }()
+		t.Cleanup(srv.Stop)
+
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return sentinelv1.NewSentinelServiceClient(conn)
+	}
+	request := &sentinelv1.AnalyzeContractRequest{Contract: "not-an-address"}
+
+	blocked := dial(t, NewIPFilter("", "127.0.0.1", ""), nil)
+	if _, err := blocked.AnalyzeContract(context.Background(), request); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for a blocklisted IP, got %v", err)
+	}
+	stream, err := blocked.ScanWallet(context.Background(), &sentinelv1.ScanWalletRequest{Wallet: "0x9999999999999999999999999999999999999999"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for a blocklisted stream, got %v", err)
+	}
+
+	limited := dial(t, nil, NewRateLimiter(1, 1, NewMockClock(time.Now())))
+	if _, err := limited.AnalyzeContract(context.Background(), request); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected the first call through, got %v", err)
+	}
+	if _, err := limited.AnalyzeContract(context.Background(), request); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted over the limit, got %v", err)
+	}
+}
+
+func TestHandleScanStream_SendsChainEventsAndSummary(t *testing.T) {
+	wallet := "0x9999999999999999999999999999999999999999"
+	token := "0x7777777777777777777777777777777777777777"
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		var filter struct {
+			Address string   `json:"address"`
+			Topics  []string `json:"topics"`
+		}
+		if req.Method == "eth_getLogs" {
+			_ = json.Unmarshal(req.Params[0], &filter)
+		}
+		switch {
+		case req.Method == "eth_blockNumber":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x3e8"}`)
+		case req.Method == "eth_getLogs" && filter.Address == "" && filter.Topics[0] == approvalEventTopic:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0xa","logIndex":"0x0"}]}`,
+				token, approvalEventTopic, padTopicAddress(wallet), padTopicAddress("0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"), 1000)
+		case req.Method == "eth_getLogs":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[]}`)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(Ethereum): node.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	// Polygon has no client, so its chain fails
+	scanner := NewScannerWithClock(NewMockClock(time.Unix(1700000000, 0)))
+	scanner.clients = map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, node.URL, defaultLogger)}
+	server := NewServerWithScanner(scanner, defaultLogger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/scan/stream?wallet="+wallet+"&chains=ethereum,polygon", nil)
+	w := httptest.NewRecorder()
+	server.handleScanStream(w, req)
+
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", got)
+	}
+
+	var approvals []Approval
+	var errorEvents []map[string]interface{}
+	var summary map[string]interface{}
+	for _, block := range strings.Split(strings.TrimSpace(w.Body.String()), "\n\n") {
+		event, data := "", ""
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "event: ") {
+				event = strings.TrimPrefix(line, "event: ")
+			} else if strings.HasPrefix(line, "data: ") {
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		switch event {
+		case "":
+			var approval Approval
+			if err := json.Unmarshal([]byte(data), &approval); err != nil {
+				t.Fatalf("decode approval event: %v", err)
+			}
+			approvals = append(approvals, approval)
+		case "error":
+			var payload map[string]interface{}
+			_ = json.Unmarshal([]byte(data), &payload)
+			errorEvents = append(errorEvents, payload)
+		case "done":
+			if summary != nil {
+				t.Fatalf("expected a single done event")
+			}
+			_ = json.Unmarshal([]byte(data), &summary)
+		}
+	}
+
+	if len(approvals) != 1 || !strings.EqualFold(approvals[0].TokenAddress, token) || approvals[0].RiskLevel == "" {
+		t.Fatalf("expected one scored approval event, got %+v", approvals)
+	}
+	if len(errorEvents) != 1 || errorEvents[0]["chain"] != "polygon" {
+		t.Fatalf("expected an error event for polygon, got %v", errorEvents)
+	}
+	if summary == nil || summary["totalApprovals"] != float64(1) {
+		t.Fatalf("expected done summary with 1 approval, got %v", summary)
+	}
+	if _, ok := summary["approvals"]; ok {
+		t.Fatalf("expected summary without approvals, got %v", summary)
+	}
+	if !strings.HasSuffix(w.Body.String(), "\n\n") || !strings.Contains(w.Body.String(), "event: done\n") {
+		t.Fatalf("expected done to be the last event, got %s", w.Body.String())
+	}
+}
+
+func TestHandleScan_PaginatesApprovalsWithCursor(t *testing.T) {
+	approvals := []Approval{
+		{Chain: Ethereum, TokenAddress: "0xcccccccccccccccccccccccccccccccccccccccc", SpenderAddress: "0x01", RiskLevel: "safe"},
+		{Chain: Ethereum, TokenAddress: "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", SpenderAddress: "0x02", RiskLevel: "critical"},
+		{Chain: Polygon, TokenAddress: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", SpenderAddress: "0x03", RiskLevel: "warning"},
+		{Chain: Ethereum, TokenAddress: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", SpenderAddress: "0x04", RiskLevel: "critical"},
+		{Chain: Ethereum, TokenAddress: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", SpenderAddress: "0x05", RiskLevel: "warning", ViaPermit2: true},
+		{Chain: Ethereum, TokenAddress: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", SpenderAddress: "0x05", RiskLevel: "warning"},
+	}
+	server := NewServerWithScanner(newMockScanner(&WalletScanResult{
+		Approvals:        approvals,
+		TotalApprovals:   len(approvals),
+		CriticalRisks:    2,
+		OverallRiskScore: 77,
+	}, nil), defaultLogger)
+
+	scan := func(query string) (*httptest.ResponseRecorder, WalletScanResult) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet=0x1234567890123456789012345678901234567890&chains=ethereum,polygon"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleScan(w, req)
+		var result WalletScanResult
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+		}
+		return w, result
+	}
+
+	var spenders []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("expected pagination to finish in 3 pages")
+		}
+		w, page := scan("&limit=2&cursor=" + url.QueryEscape(cursor))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if page.Total != 6 || page.OverallRiskScore != 77 || page.CriticalRisks != 2 {
+			t.Fatalf("expected summary fields on every page, got total %d score %d critical %d", page.Total, page.OverallRiskScore, page.CriticalRisks)
+		}
+		if len(page.Approvals) > 2 {
+			t.Fatalf("expected at most 2 approvals per page, got %d", len(page.Approvals))
+		}
+		for _, approval := range page.Approvals {
+			spender := approval.SpenderAddress
+			if approval.ViaPermit2 {
+				spender += "/permit2"
+			}
+			spenders = append(spenders, spender)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	// Most severe first, then by token address (chain, spender and Permit2 break ties)
+	if got, want := strings.Join(spenders, ","), "0x04,0x02,0x05,0x05/permit2,0x03,0x01"; got != want {
+		t.Fatalf("expected order %s, got %s", want, got)
+	}
+
+	if w, page := scan(""); w.Code != http.StatusOK || len(page.Approvals) != 6 || page.NextCursor != "" {
+		t.Fatalf("expected the default page to hold all 6 approvals, got %d (cursor %q)", len(page.Approvals), page.NextCursor)
+	}
+	for _, query := range []string{"&limit=0", "&limit=abc", "&cursor=not-a-cursor"} {
+		if w, _ := scan(query); w.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400 for %s, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestHandleScan_FiltersApprovals(t *testing.T) {
+	spender := "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"
+	approvals := []Approval{
+		{Chain: Ethereum, TokenAddress: "0x01", SpenderAddress: spender, RiskLevel: "critical", TokenType: "ERC20", IsUnlimited: true},
+		{Chain: Ethereum, TokenAddress: "0x02", SpenderAddress: "0x5555555555555555555555555555555555555555", RiskLevel: "warning", TokenType: "ERC721", IsUnlimited: true},
+		{Chain: Arbitrum, TokenAddress: "0x03", SpenderAddress: spender, RiskLevel: "safe", TokenType: "ERC20"},
+		{Chain: Arbitrum, TokenAddress: "0x04", SpenderAddress: spender, RiskLevel: "warning", TokenType: "ERC20", IsUnlimited: true},
+	}
+	server := NewServerWithScanner(newMockScanner(&WalletScanResult{Approvals: approvals, TotalApprovals: len(approvals)}, nil), defaultLogger)
+
+	tests := []struct {
+		query  string
+		status int
+		tokens string
+	}{
+		{query: "&chains=ethereum,arbitrum", status: http.StatusOK, tokens: "0x01,0x02,0x04,0x03"},
+		{query: "&chains=ethereum,arbitrum&risk=critical,warning", status: http.StatusOK, tokens: "0x01,0x02,0x04"},
+		{query: "&chains=arbitrum", status: http.StatusOK, tokens: "0x04,0x03"},
+		{query: "&chains=ethereum,arbitrum&isUnlimited=false", status: http.StatusOK, tokens: "0x03"},
+		{query: "&chains=ethereum,arbitrum&tokenType=erc721", status: http.StatusOK, tokens: "0x02"},
+		{query: "&chains=ethereum,arbitrum&spender=0x68B3465833FB72A70ECDF485E0E4C7BD8665FC45&isUnlimited=true", status: http.StatusOK, tokens: "0x01,0x04"},
+		{query: "&risk=severe", status: http.StatusBadRequest},
+		{query: "&tokenType=ERC4626", status: http.StatusBadRequest},
+		{query: "&isUnlimited=maybe", status: http.StatusBadRequest},
+		{query: "&spender=uniswap", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet=0x1234567890123456789012345678901234567890"+tt.query, nil)
+		w := httptest.NewRecorder()
+		server.handleScan(w, req)
+		if w.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.query, tt.status, w.Code)
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+
+		var result WalletScanResult
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		var tokens []string
+		for _, approval := range result.Approvals {
+			tokens = append(tokens, approval.TokenAddress)
+		}
+		if got := strings.Join(tokens, ","); got != tt.tokens {
+			t.Fatalf("%s: expected %s, got %s", tt.query, tt.tokens, got)
+		}
+		if result.FilteredApprovals != len(tokens) || result.TotalApprovals != len(approvals) {
+			t.Fatalf("%s: expected filtered %d of %d, got %d of %d", tt.query, len(tokens), len(approvals), result.FilteredApprovals, result.TotalApprovals)
+		}
+	}
+}
+
+func TestHandleScan_ExportsCSV(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	approvals := []Approval{
+		{Chain: Ethereum, TokenAddress: "0x01", TokenSymbol: "=HYPERLINK(\"x\")", SpenderAddress: "0x05", SpenderName: "Evil, Inc.", AllowanceHuman: "Unlimited", IsUnlimited: true, RiskLevel: "critical", RiskReasons: []string{"Unlimited approval", "Unknown spender"}, LastUpdated: 1700000000},
+		{Chain: Arbitrum, TokenAddress: "0x02", TokenSymbol: "USDC", SpenderAddress: "0x06", SpenderName: "✅ Uniswap V3: Router 2", AllowanceHuman: "100", RiskLevel: "safe"},
+	}
+	server := NewServerWithScanner(newMockScanner(&WalletScanResult{WalletAddress: wallet, ScanTimestamp: 1700000001, Approvals: approvals}, nil), defaultLogger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/scan?wallet="+wallet+"&limit=1", nil)
+	req.Header.Set("Accept", "text/csv, application/json;q=0.5")
+	w := httptest.NewRecorder()
+	server.handleScan(w, req)
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected a CSV response, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="approvals-`+wallet+`-1700000001.csv"` {
+		t.Fatalf("unexpected Content-Disposition %s", got)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	// Every approval is exported regardless of limit, in page order
+	if len(rows) != 3 || strings.Join(rows[0], ",") != "chain,tokenAddress,tokenSymbol,spenderAddress,spenderName,allowanceHuman,isUnlimited,riskLevel,riskReasons,lastUpdated" {
+		t.Fatalf("expected a header and 2 rows, got %v", rows)
+	}
+	want := []string{"ethereum", "0x01", "'=HYPERLINK(\"x\")", "0x05", "Evil, Inc.", "Unlimited", "true", "critical", "Unlimited approval|Unknown spender", "2023-11-14T22:13:20Z"}
+	if strings.Join(rows[1], "\x00") != strings.Join(want, "\x00") {
+		t.Fatalf("expected row %q, got %q", want, rows[1])
+	}
+	if rows[2][9] != "" {
+		t.Fatalf("expected an undated approval to export an empty lastUpdated, got %q", rows[2][9])
+	}
+}
+
+func TestHandleHistory_ReturnsScansOldestFirst(t *testing.T) {
+	wallet := "0xAbCdEf0123456789aBcDeF0123456789AbCdEf01"
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	scanner := NewScannerWithClock(clock, Options{HistoryDepth: 2})
+	scanner.clients = map[ChainID]*ChainClient{}
+	server := NewServerWithScanner(scanner, defaultLogger)
+
+	// No chains, so every scan completes and is cached
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Hour) // past the cache TTL
+		if _, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{}, false); err != nil {
+			t.Fatalf("scan %d: %v", i, err)
+		}
+	}
+	// Cache hits are not new scans
+	if result, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{}, false); err != nil || !result.CacheHit {
+		t.Fatalf("expected a cache hit, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/history/"+strings.ToLower(wallet), nil)
+	w := httptest.NewRecorder()
+	server.handleHistory(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var payload struct {
+		Wallet  string         `json:"wallet"`
+		History []HistoryEntry `json:"history"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.Wallet != strings.ToLower(wallet) {
+		t.Fatalf("expected normalized wallet, got %q", payload.Wallet)
+	}
+	// Depth 2 keeps the last two of three scans
+	if len(payload.History) != 2 || payload.History[0].Timestamp != 1700007200 || payload.History[1].Timestamp != 1700010800 {
+		t.Fatalf("expected the last two scans oldest first, got %+v", payload.History)
+	}
+
+	for path, status := range map[string]int{
+		"/api/v1/history/not-a-wallet": http.StatusBadRequest,
+		"/api/v1/history/":             http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		server.handleHistory(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != status {
+			t.Fatalf("%s: expected status %d, got %d", path, status, w.Code)
+		}
+	}
+}
+
+func TestHandleDiff_ComparesWithClosestBaseline(t *testing.T) {
+	wallet := "0xabCDeF0123456789AbcdEf0123456789aBCDEF01"
+	clock := NewMockClock(time.Unix(1700100000, 0))
+	scanner := NewScannerWithClock(clock, Options{})
+	scanner.clients = map[ChainID]*ChainClient{}
+	server := NewServerWithScanner(scanner, defaultLogger)
+
+	baseline := Approval{Chain: Ethereum, TokenAddress: "0x01", SpenderAddress: "0x05", RiskLevel: "critical"}
+	scanner.history.Add(wallet, HistoryEntry{Timestamp: 1700000000, OverallRiskScore: 40, approvals: []Approval{baseline}})
+	scanner.history.Add(wallet, HistoryEntry{Timestamp: 1690000000, OverallRiskScore: 90})
+
+	diff := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleDiff(w, httptest.NewRequest(http.MethodPost, "/api/v1/diff", strings.NewReader(body)))
+		return w
+	}
+
+	// No client for ethereum, so the current scan finds nothing: the approval was revoked
+	w := diff(`{"wallet":"` + wallet + `","chains":["ethereum"],"baselineTimestamp":1700003600}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var payload ScanDiff
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.BaselineTimestamp != 1700000000 || payload.CurrentTimestamp != 1700100000 {
+		t.Fatalf("expected the closest baseline and the current scan, got %+v", payload)
+	}
+	if len(payload.RevokedApprovals) != 1 || payload.RevokedApprovals[0].SpenderAddress != "0x05" || len(payload.NewApprovals) != 0 {
+		t.Fatalf("expected the baseline approval to be revoked, got %+v", payload)
+	}
+	if payload.RiskScoreChange != -40 {
+		t.Fatalf("expected risk score change -40, got %d", payload.RiskScoreChange)
+	}
+
+	for body, status := range map[string]int{
+		`{"wallet":"` + wallet + `","baselineTimestamp":1600000000}`: http.StatusNotFound,
+		`{"wallet":"not-a-wallet","baselineTimestamp":1700000000}`:   http.StatusBadRequest,
+		`{"wallet":"` + wallet + `"}`:                                http.StatusBadRequest,
+		// Mixed case must be a valid checksum, as on /api/v1/scan
+		`{"wallet":"0xAbCdEf0123456789aBcDeF0123456789AbCdEf01","baselineTimestamp":1700000000}`: http.StatusBadRequest,
+	} {
+		if w := diff(body); w.Code != status {
+			t.Fatalf("%s: expected status %d, got %d", body, status, w.Code)
+		}
+	}
+}
+
+// batchScanner fails one wallet and records the peak number of concurrent scans
+type batchScanner struct {
+	failWallet string
+	active     atomic.Int32
+	peak       atomic.Int32
+}
+
+func (b *batchScanner) ScanWallet(_ context.Context, walletAddress string, chains []ChainID, _ bool) (*WalletScanResult, error) {
+	n := b.active.Add(1)
+	defer b.active.Add(-1)
+	for {
+		peak := b.peak.Load()
+		if n <= peak || b.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if walletAddress == b.failWallet {
+		return nil, fmt.Errorf("rpc failed: https://rpc.example/v2/secret-key")
+	}
+	return &WalletScanResult{WalletAddress: walletAddress, ChainsScanned: chains}, nil
+}
+
+func TestHandleScanBatch(t *testing.T) {
+	wallets := make([]string, 8)
+	for i := range wallets {
+		wallets[i] = fmt.Sprintf("0x%040x", i+1)
+	}
+	scanner := &batchScanner{failWallet: wallets[3]}
+	server := NewServerWithScanner(scanner, defaultLogger)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handleScanBatch(rec, httptest.NewRequest(http.MethodPost, "/api/v1/scan/batch", strings.NewReader(body)))
+		return rec
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"wallets": wallets, "chains": []string{"Ethereum"}})
+	rec := post(string(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var payload BatchScanResponse
+	if err := json.NewDecoder(rec.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.Total != 8 || payload.Success != 7 || payload.Failed != 1 {
+		t.Fatalf("expected 8 total, 7 success, 1 failed, got %d/%d/%d", payload.Total, payload.Success, payload.Failed)
+	}
+	if payload.Results[0].WalletAddress != wallets[0] || payload.Results[3].WalletAddress != wallets[4] {
+		t.Fatalf("expected results in request order, got %s, %s", payload.Results[0].WalletAddress, payload.Results[3].WalletAddress)
+	}
+	if got := payload.Results[0].ChainsScanned; len(got) != 1 || got[0] != Ethereum {
+		t.Fatalf("expected the requested chain to be scanned, got %v", got)
+	}
+	if len(payload.Errors) != 1 || payload.Errors[0].Wallet != wallets[3] || payload.Errors[0].Error != "scan_failed" || strings.Contains(payload.Errors[0].Message, "rpc.example") {
+		t.Fatalf("expected a generic error for the failing wallet, got %+v", payload.Errors)
+	}
+	if payload.Errors[0].RequestID == "" {
+		t.Fatalf("expected the failing wallet's error to carry a request ID, got %+v", payload.Errors[0])
+	}
+	if peak := scanner.peak.Load(); peak > 5 {
+		t.Fatalf("expected at most 5 concurrent scans, got %d", peak)
+	}
+
+	tooMany := make([]string, 21)
+	for i := range tooMany {
+		tooMany[i] = wallets[0]
+	}
+	body, _ = json.Marshal(map[string]interface{}{"wallets": tooMany})
+	if rec := post(string(body)); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for 21 wallets, got %d", rec.Code)
+	}
+	if rec := post(`{"wallets":["0xnot-an-address"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid wallet, got %d", rec.Code)
+	}
+	if rec := post(`{"wallets":["` + wallets[0] + `"],"chains":["nochain"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unsupported chain, got %d", rec.Code)
+	}
+}
+
+func TestHandleScanAsync_PollsJobUntilComplete(t *testing.T) {
+	good := "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+	failing := "0x000000000000000000000000000000000000dead"
+	scanner := &batchScanner{failWallet: failing}
+	server := NewServerWithScanner(scanner, defaultLogger)
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	server.jobs = NewScanJobQueue(scanner, 2, clock, defaultLogger)
+
+	enqueue := func(wallet string) string {
+		rec := httptest.NewRecorder()
+		server.handleScanAsync(rec, httptest.NewRequest(http.MethodPost, "/api/v1/scan/async?wallet="+wallet+"&chains=ethereum", nil))
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var accepted map[string]string
+		_ = json.NewDecoder(rec.Body).Decode(&accepted)
+		if accepted["status"] != "pending" || accepted["pollUrl"] != "/api/v1/jobs/"+accepted["jobId"] {
+			t.Fatalf("expected a pending job with its poll URL, got %v", accepted)
+		}
+		return accepted["pollUrl"]
+	}
+	poll := func(url string) (int, ScanJobStatus) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			rec := httptest.NewRecorder()
+			server.handleJob(rec, httptest.NewRequest(http.MethodGet, url, nil))
+			var status ScanJobStatus
+			_ = json.NewDecoder(rec.Body).Decode(&status)
+			if rec.Code != http.StatusOK || status.Status == "complete" || status.Status == "failed" || time.Now().After(deadline) {
+				return rec.Code, status
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	goodURL := enqueue(good)
+	if code, status := poll(goodURL); code != http.StatusOK || status.Status != "complete" || status.Result == nil || status.Result.WalletAddress != good {
+		t.Fatalf("expected a complete job with the scan result, got %d %+v", code, status)
+	}
+	if _, status := poll(enqueue(failing)); status.Status != "failed" || status.Result != nil || strings.Contains(status.Error, "secret-key") {
+		t.Fatalf("expected a failed job with a redacted error, got %+v", status)
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleScanAsync(rec, httptest.NewRequest(http.MethodPost, "/api/v1/scan/async?wallet=0x123", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an invalid wallet, got %d", rec.Code)
+	}
+
+	clock.Advance(10 * time.Minute)
+	if code, _ := poll(goodURL); code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for an expired job, got %d", code)
+	}
+}
+
+func TestHandleReady_ReportsUnhealthyDependencies(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/polygon") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+	}))
+	defer node.Close()
+	services := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/decompiler/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer services.Close()
+
+	clients := map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, node.URL+"/ethereum", defaultLogger)}
+	server := &Server{
+		chainClients: clients,
+		contractAnalyzer: NewContractAnalyzerWithServices(clients,
+			&DecompilerClient{baseURL: services.URL + "/decompiler", client: http.DefaultClient},
+			&AnalyzerClient{baseURL: services.URL + "/analyzer", client: http.DefaultClient},
+			defaultLogger),
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleReady(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	var body struct {
+		Unhealthy []string `json:"unhealthy"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&body)
+	if rec.Code != http.StatusServiceUnavailable || !slices.Equal(body.Unhealthy, []string{"analyzer"}) {
+		t.Fatalf("expected 503 listing the analyzer, got %d %v", rec.Code, body.Unhealthy)
+	}
+
+	server.contractAnalyzer.analyzer = &AnalyzerClient{baseURL: services.URL + "/decompiler", client: http.DefaultClient}
+	server.chainClients[Polygon] = NewChainClient(Polygon, node.URL+"/polygon", defaultLogger)
+	rec = httptest.NewRecorder()
+	server.handleReady(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	_ = json.NewDecoder(rec.Body).Decode(&body)
+	if rec.Code != http.StatusServiceUnavailable || !slices.Equal(body.Unhealthy, []string{"polygon-rpc"}) {
+		t.Fatalf("expected 503 listing the failing RPC endpoint, got %d %v", rec.Code, body.Unhealthy)
+	}
+
+	delete(server.chainClients, Polygon)
+	rec = httptest.NewRecorder()
+	server.handleReady(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 once every dependency answers, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	(&Server{}).handleLive(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected liveness to answer 200, got %d", rec.Code)
+	}
+}
+
+func TestHandleReady_CachesResult(t *testing.T) {
+	var calls atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+	}))
+	defer node.Close()
+
+	clock := NewMockClock(time.Now())
+	server := &Server{
+		chainClients: map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, node.URL, defaultLogger)},
+		responses:    NewCacheWithClock(time.Minute, clock),
+	}
+	ready := func() int {
+		rec := httptest.NewRecorder()
+		server.handleReady(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		return rec.Code
+	}
+
+	if ready() != http.StatusOK || ready() != http.StatusOK || calls.Load() != 1 {
+		t.Fatalf("expected back-to-back probes to share one RPC check, got %d calls", calls.Load())
+	}
+	clock.Advance(readinessCacheTTL + time.Second)
+	if ready() != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("expected the check to rerun after %v, got %d calls", readinessCacheTTL, calls.Load())
+	}
+}
@@ -8,14 +8,31 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // ═══════════════════════════════════════════════════════════════════════════════
@@ -39,13 +56,20 @@ func TestCache_SetAndGet(t *testing.T) {
 }
 
 func TestCache_Expiration(t *testing.T) {
-	// Create cache with very short TTL
-	cache := NewCache(1 * time.Millisecond)
+	ttl := 50 * time.Millisecond
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	cache := NewCacheWithClock(ttl, clock)
 
 	cache.Set("expire_key", "expire_value")
 
-	// Wait for expiration
-	time.Sleep(5 * time.Millisecond)
+	// Still valid right before expiry
+	clock.Advance(ttl - time.Millisecond)
+	if _, ok := cache.Get("expire_key"); !ok {
+		t.Error("Expected key to still be cached before TTL")
+	}
+
+	// Move past expiration
+	clock.Advance(2 * time.Millisecond)
 
 	// Should not find expired key
 	_, ok := cache.Get("expire_key")
@@ -54,6 +78,24 @@ func TestCache_Expiration(t *testing.T) {
 	}
 }
 
+func TestCache_SweepsExpiredEntries(t *testing.T) {
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	cache := NewCacheWithClock(time.Second, clock)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	// Expired entries are dropped by the next write after the sweep interval
+	clock.Advance(cacheSweepInterval)
+	cache.SetWithTTL("fresh", true, time.Hour)
+	if len(cache.data) != 1 {
+		t.Errorf("Expected only the fresh entry to remain, got %d entries", len(cache.data))
+	}
+	if _, ok := cache.Get("fresh"); !ok {
+		t.Error("Expected the fresh entry to be cached")
+	}
+}
+
 func TestCache_MissingKey(t *testing.T) {
 	cache := NewCache(5 * time.Minute)
 
@@ -96,7 +138,7 @@ func TestScanner_ScanWallet(t *testing.T) {
 	ctx := context.Background()
 
 	// Test with valid address
-	result, err := scanner.ScanWallet(ctx, "0x1234567890123456789012345678901234567890", []ChainID{Ethereum})
+	result, err := scanner.ScanWallet(ctx, "0x1234567890123456789012345678901234567890", []ChainID{Ethereum}, false)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -120,7 +162,7 @@ func TestScanner_MultiChainScan(t *testing.T) {
 	ctx := context.Background()
 
 	chains := []ChainID{Ethereum, Polygon, Arbitrum}
-	result, err := scanner.ScanWallet(ctx, "0xabcdef1234567890abcdef1234567890abcdef12", chains)
+	result, err := scanner.ScanWallet(ctx, "0xabcdef1234567890abcdef1234567890abcdef12", chains, false)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -185,7 +227,7 @@ func TestScanner_RiskScoreCapped(t *testing.T) {
 // ═══════════════════════════════════════════════════════════════════════════════
 
 func TestHandler_Health(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 
 	req := httptest.NewRequest("GET", "/health", nil)
 	w := httptest.NewRecorder()
@@ -205,7 +247,7 @@ func TestHandler_Health(t *testing.T) {
 }
 
 func TestHandler_Chains(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 
 	req := httptest.NewRequest("GET", "/api/v1/chains", nil)
 	w := httptest.NewRecorder()
@@ -225,7 +267,7 @@ func TestHandler_Chains(t *testing.T) {
 }
 
 func TestHandler_Scan_MissingWallet(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 
 	req := httptest.NewRequest("GET", "/api/v1/scan", nil)
 	w := httptest.NewRecorder()
@@ -238,7 +280,7 @@ func TestHandler_Scan_MissingWallet(t *testing.T) {
 }
 
 func TestHandler_Scan_ValidWallet(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 
 	req := httptest.NewRequest("GET", "/api/v1/scan?wallet=0x1234567890123456789012345678901234567890", nil)
 	w := httptest.NewRecorder()
@@ -277,7 +319,7 @@ func TestHandler_CORS(t *testing.T) {
 // ═══════════════════════════════════════════════════════════════════════════════
 
 func TestChainClient_New(t *testing.T) {
-	client := NewChainClient(Ethereum, "https://eth.example.com")
+	client := NewChainClient(Ethereum, "https://eth.example.com", defaultLogger)
 
 	if client.ChainID != Ethereum {
 		t.Error("Chain ID mismatch")
@@ -288,6 +330,63 @@ func TestChainClient_New(t *testing.T) {
 	}
 }
 
+func TestChainClient_FailsOverToFallbackRPCs(t *testing.T) {
+	var calls atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer down.Close()
+	erroring := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":429,"message":"Too Many Requests"}}`)
+	}))
+	defer erroring.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x2a"}`)
+	}))
+	defer healthy.Close()
+
+	client := NewChainClientWithFallbacks(Ethereum, []string{down.URL, erroring.URL, healthy.URL}, defaultLogger)
+	block, err := client.latestBlockNumber(context.Background())
+	if err != nil || block != 42 {
+		t.Fatalf("Expected block 42 from the last RPC URL, got %d, %v", block, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected every RPC URL to be tried once, got %d calls", calls.Load())
+	}
+
+	// A revert is the chain's answer, not the endpoint's failure
+	calls.Store(0)
+	reverting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}`)
+	}))
+	defer reverting.Close()
+	client = NewChainClientWithFallbacks(Ethereum, []string{reverting.URL, healthy.URL}, defaultLogger)
+	var rpcErr *jsonRPCError
+	if _, err := client.ethCall(context.Background(), "0x1111111111111111111111111111111111111111", "0x01"); !errors.As(err, &rpcErr) || calls.Load() != 1 {
+		t.Errorf("Expected the revert without trying the fallback, got %v after %d calls", err, calls.Load())
+	}
+
+	t.Setenv("FALLBACK_RPCS_ETHEREUM", "https://eth.example.com, https://eth-mainnet.g.alchemy.com/v2/second")
+	rpcs := withFallbackRPCs(map[string]string{"ethereum": "https://primary.example.com", "polygon": "https://polygon.example.com"})
+	if !slices.Equal(rpcs["ethereum"], []string{"https://primary.example.com", "https://eth.example.com", "https://eth-mainnet.g.alchemy.com/v2/second"}) ||
+		len(rpcs["polygon"]) != 1 {
+		t.Errorf("Expected FALLBACK_RPCS_ETHEREUM appended to the Ethereum RPC only, got %v", rpcs)
+	}
+
+	// Approval log scans only go to Alchemy endpoints, the configured one first
+	originalEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{"ethereum": "https://configured.example.com"}
+	t.Cleanup(func() { alchemyConfig.Endpoints = originalEndpoints })
+	client = NewChainClientWithFallbacks(Ethereum, rpcs["ethereum"], defaultLogger)
+	if endpoints := client.alchemyEndpoints(); !slices.Equal(endpoints, []string{"https://configured.example.com", "https://eth-mainnet.g.alchemy.com/v2/second"}) {
+		t.Errorf("Expected the configured and alchemy.com endpoints, got %v", endpoints)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════════
 //                              APPROVAL TESTS
 // ═══════════════════════════════════════════════════════════════════════════════
@@ -350,11 +449,11 @@ func BenchmarkScanner_RiskCalculation(b *testing.B) {
 }
 
 // ═══════════════════════════════════════════════════════════════════════════════
-//                         16 CHAIN SUPPORT TESTS
+//                         21 CHAIN SUPPORT TESTS
 // ═══════════════════════════════════════════════════════════════════════════════
 
 func TestAllChains_Count(t *testing.T) {
-	expected := 16
+	expected := 21
 	if len(AllChains) != expected {
 		t.Errorf("Expected %d chains, got %d", expected, len(AllChains))
 	}
@@ -362,19 +461,38 @@ func TestAllChains_Count(t *testing.T) {
 
 func TestAllChains_HaveRPCConfig(t *testing.T) {
 	for _, chain := range AllChains {
-		rpc, ok := config.RPC[string(chain)]
+		rpcs, ok := config.RPC[string(chain)]
 		if !ok {
 			t.Errorf("Chain %s has no RPC configured", chain)
 		}
-		if rpc == "" {
+		if len(rpcs) == 0 || rpcs[0] == "" {
 			t.Errorf("Chain %s has empty RPC URL", chain)
 		}
 	}
 }
 
+func TestNewL2Chains_HaveExplorers(t *testing.T) {
+	want := map[ChainID]int{Mantle: 5000, Mode: 34443, Blast: 81457, Manta: 169}
+	for chain, id := range want {
+		if etherscanConfig.ChainIDs[string(chain)] != id {
+			t.Errorf("Expected %s to have chain ID %d, got %d", chain, id, etherscanConfig.ChainIDs[string(chain)])
+		}
+	}
+
+	// Blast and Mode use their own explorers, overridable by environment
+	if url, _ := explorerURL(Blast, "module=logs"); !strings.HasPrefix(url, "https://api.blastscan.io/api?module=logs") {
+		t.Errorf("Expected Blast to use BlastScan, got %s", url)
+	}
+	t.Setenv("MODESCAN_API_URL", "https://modescan.example/api")
+	t.Setenv("MODESCAN_API_KEY", "key")
+	if explorer := modeScanConfig(); explorer.URL != "https://modescan.example/api" || explorer.APIKey != "key" {
+		t.Errorf("Expected MODESCAN_API_URL and MODESCAN_API_KEY to configure Modescan, got %+v", explorer)
+	}
+}
+
 func TestChainCategories(t *testing.T) {
 	// Ethereum L2s
-	l2s := []ChainID{Ethereum, Arbitrum, Optimism, Base, ZkSync, Linea, Scroll, ZkEVM}
+	l2s := []ChainID{Ethereum, Arbitrum, Optimism, Base, ZkSync, Linea, Scroll, ZkEVM, Mantle, Mode, Blast, Manta}
 	for _, chain := range l2s {
 		found := false
 		for _, c := range AllChains {
@@ -453,15 +571,155 @@ func TestFormatAllowance_Billions(t *testing.T) {
 	}
 }
 
+func TestFormatAllowance_USDT(t *testing.T) {
+	usdt := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	wallet := "0x1234567890123456789012345678901234567890"
+
+	// Mock RPC returning a single 1 USDT (1_000_000 raw units) approval
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "eth_getLogs" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"%s","topics":["0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925","0x000000000000000000000000%s","0x0000000000000000000000001111111111111111111111111111111111111111"],"data":"0x%064x","blockNumber":"0x1"}]}`,
+			usdt, wallet[2:], 1_000_000)
+	}))
+	defer server.Close()
+
+	client := NewChainClient(Ethereum, server.URL, defaultLogger)
+	result, err := client.getApprovalsAlchemy(context.Background(), wallet, server.URL, blockRange{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Approvals) != 1 {
+		t.Fatalf("Expected 1 approval, got %d", len(result.Approvals))
+	}
+
+	approval := result.Approvals[0]
+	if approval.AllowanceHuman != "1.0000" {
+		t.Errorf("Expected AllowanceHuman 1.0000, got %s", approval.AllowanceHuman)
+	}
+	if approval.IsUnlimited {
+		t.Error("Expected 1 USDT approval not to be unlimited")
+	}
+}
+
+func TestAlchemyApprovals_IncludeEventLocation(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	txHash := "0x" + strings.Repeat("ab", 32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "eth_getLogs" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"0x6b175474e89094c44da98b954eedeac495271d0f","topics":["0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925","0x000000000000000000000000%s","0x0000000000000000000000001111111111111111111111111111111111111111"],"data":"0x%064x","blockNumber":"0x12d687","blockHash":"0x%s","transactionHash":"%s","logIndex":"0x1b"}]}`,
+			wallet[2:], 1_000, strings.Repeat("cd", 32), txHash)
+	}))
+	defer server.Close()
+
+	client := NewChainClient(Ethereum, server.URL, defaultLogger)
+	result, err := client.getApprovalsAlchemy(context.Background(), wallet, server.URL, blockRange{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Approvals) != 1 {
+		t.Fatalf("Expected 1 approval, got %d", len(result.Approvals))
+	}
+
+	approval := result.Approvals[0]
+	if approval.TxHash != txHash {
+		t.Errorf("Expected TxHash %s, got %q", txHash, approval.TxHash)
+	}
+	if approval.LogIndex != 27 {
+		t.Errorf("Expected LogIndex 27, got %d", approval.LogIndex)
+	}
+	if approval.BlockNumber != 1234567 {
+		t.Errorf("Expected BlockNumber 1234567, got %d", approval.BlockNumber)
+	}
+}
+
+func TestIsUnlimitedForToken_UsesDecimals(t *testing.T) {
+	usdt := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+
+	// 10^18 whole USDT is unlimited, but the same raw amount of an 18-decimal token is 1 token
+	amount := new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)
+	if !isUnlimitedForToken(amount, usdt) {
+		t.Error("Expected 10^18 USDT to be unlimited")
+	}
+	if isUnlimitedForToken(amount, "0x6b175474e89094c44da98b954eedeac495271d0f") {
+		t.Error("Expected 10^6 DAI not to be unlimited")
+	}
+}
+
+func TestSelfApprovalDetection(t *testing.T) {
+	token := "0x9999999999999999999999999999999999999999"
+	wallet := "0x1234567890123456789012345678901234567890"
+
+	// Mock RPC returning an approval where the token is its own spender
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "eth_getLogs" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"%s","topics":["0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925","0x000000000000000000000000%s","0x000000000000000000000000%s"],"data":"0x%064x","blockNumber":"0x1"}]}`,
+			token, wallet[2:], strings.ToUpper(token[2:]), 1_000)
+	}))
+	defer server.Close()
+
+	client := NewChainClient(Ethereum, server.URL, defaultLogger)
+	chainResult, err := client.getApprovalsAlchemy(context.Background(), wallet, server.URL, blockRange{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chainResult.Approvals) != 1 {
+		t.Fatalf("Expected 1 approval, got %d", len(chainResult.Approvals))
+	}
+
+	approval := chainResult.Approvals[0]
+	if !approval.IsSelfApproval {
+		t.Error("Expected token == spender to be flagged as self-approval")
+	}
+	if !strings.Contains(strings.Join(approval.RiskReasons, "\n"), selfApprovalReason) {
+		t.Errorf("Expected self-approval reason, got %v", approval.RiskReasons)
+	}
+
+	result := &WalletScanResult{Approvals: chainResult.Approvals}
+	NewScanner().calculateRiskScores(result)
+
+	if result.Approvals[0].RiskLevel != "critical" {
+		t.Errorf("Expected self-approval to be critical, got %s", result.Approvals[0].RiskLevel)
+	}
+	// unknown spender (15) + unknown spender name (10) + self-approval (40)
+	if result.OverallRiskScore != 65 {
+		t.Errorf("Expected risk score 65, got %d", result.OverallRiskScore)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════════
 //                      CONTRACT ANALYZER TESTS
 // ═══════════════════════════════════════════════════════════════════════════════
 
 func TestNewContractAnalyzer(t *testing.T) {
 	clients := make(map[ChainID]*ChainClient)
-	clients[Ethereum] = NewChainClient(Ethereum, "https://eth.drpc.org")
+	clients[Ethereum] = NewChainClient(Ethereum, "https://eth.drpc.org", defaultLogger)
 
-	analyzer := NewContractAnalyzer(clients)
+	analyzer := NewContractAnalyzer(clients, defaultLogger)
 
 	if analyzer == nil {
 		t.Error("Expected non-nil ContractAnalyzer")
@@ -474,12 +732,418 @@ func TestNewContractAnalyzer(t *testing.T) {
 	}
 }
 
+// MockDecompilerService returns a configured response and records the bytecode it receives
+type MockDecompilerService struct {
+	mu       sync.Mutex
+	Response *DecompilerResponse
+	Err      error
+	Calls    [][]byte
+}
+
+func (m *MockDecompilerService) Analyze(ctx context.Context, bytecode []byte) (*DecompilerResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Calls = append(m.Calls, bytecode)
+	return m.Response, m.Err
+}
+
+// MockAnalyzerCall is one recorded MockAnalyzerService.Analyze call
+type MockAnalyzerCall struct {
+	Address  string
+	Chain    string
+	Bytecode []byte
+}
+
+// MockAnalyzerService returns a configured response and records its calls
+type MockAnalyzerService struct {
+	mu       sync.Mutex
+	Response *AnalyzerResponse
+	Err      error
+	Calls    []MockAnalyzerCall
+}
+
+func (m *MockAnalyzerService) Analyze(ctx context.Context, address string, chain string, bytecode []byte) (*AnalyzerResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Calls = append(m.Calls, MockAnalyzerCall{Address: address, Chain: chain, Bytecode: bytecode})
+	return m.Response, m.Err
+}
+
+func TestAnalyzeContract_WithMockServices(t *testing.T) {
+	contract := "0x1111111111111111111111111111111111111111"
+	serviceDown := fmt.Errorf("service unavailable")
+
+	tests := []struct {
+		name          string
+		code          string
+		decompilerErr error
+		analyzerErr   error
+		wantErr       bool
+		wantDecompile bool
+		wantReport    bool
+		wantRisk      int
+		wantCalls     int
+	}{
+		{name: "both succeed", code: "0x6080604052", wantDecompile: true, wantReport: true, wantRisk: 42, wantCalls: 1},
+		{name: "decompiler fails, analyzer succeeds", code: "0x6080604052", decompilerErr: serviceDown, wantReport: true, wantRisk: 42, wantCalls: 1},
+		{name: "both fail", code: "0x6080604052", decompilerErr: serviceDown, analyzerErr: serviceDown, wantCalls: 1},
+		{name: "empty bytecode returns early", code: "0x", wantErr: true, wantCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, tt.code)
+			}))
+			defer rpc.Close()
+
+			decompiler := &MockDecompilerService{Response: &DecompilerResponse{Success: true, Selectors: []string{"0x095ea7b3"}}, Err: tt.decompilerErr}
+			analyzer := &MockAnalyzerService{Response: &AnalyzerResponse{RiskScore: 42, RiskLevel: "medium"}, Err: tt.analyzerErr}
+			if tt.decompilerErr != nil {
+				decompiler.Response = nil
+			}
+			if tt.analyzerErr != nil {
+				analyzer.Response = nil
+			}
+
+			ca := NewContractAnalyzerWithServices(
+				map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, rpc.URL, defaultLogger)}, decompiler, analyzer, defaultLogger)
+			result, err := ca.AnalyzeContract(context.Background(), contract, Ethereum)
+
+			if len(decompiler.Calls) != tt.wantCalls || len(analyzer.Calls) != tt.wantCalls {
+				t.Errorf("Expected %d call(s) per service, got decompiler %d, analyzer %d",
+					tt.wantCalls, len(decompiler.Calls), len(analyzer.Calls))
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if (result.Decompilation != nil) != tt.wantDecompile {
+				t.Errorf("Expected decompilation present = %v", tt.wantDecompile)
+			}
+			if (result.SecurityReport != nil) != tt.wantReport {
+				t.Errorf("Expected security report present = %v", tt.wantReport)
+			}
+			if result.OverallRisk != tt.wantRisk {
+				t.Errorf("Expected overall risk %d, got %d", tt.wantRisk, result.OverallRisk)
+			}
+			if result.BytecodeSize != 5 {
+				t.Errorf("Expected bytecode size 5, got %d", result.BytecodeSize)
+			}
+			if call := analyzer.Calls[0]; call.Address != contract || call.Chain != string(Ethereum) || len(call.Bytecode) != 5 {
+				t.Errorf("Expected analyzer to receive the contract's bytecode, got %+v", call)
+			}
+		})
+	}
+}
+
+func TestAnalyzeContract_DetectsProxyWithoutDecompiler(t *testing.T) {
+	contract := "0x1111111111111111111111111111111111111111"
+	implementation := "0x2222222222222222222222222222222222222222"
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		result := "0x6080604052"
+		if req.Method == "eth_getStorageAt" {
+			result = "0x" + strings.Repeat("0", 64)
+			if req.Params[1] == "0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc" {
+				result = "0x" + strings.Repeat("0", 24) + implementation[2:]
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, result)
+	}))
+	defer rpc.Close()
+
+	decompiler := &MockDecompilerService{Err: fmt.Errorf("service unavailable")}
+	analyzer := &MockAnalyzerService{Response: &AnalyzerResponse{RiskScore: 42}}
+	ca := NewContractAnalyzerWithServices(
+		map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, rpc.URL, defaultLogger)}, decompiler, analyzer, defaultLogger)
+	result, err := ca.AnalyzeContract(context.Background(), contract, Ethereum)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	risk := result.ContractRisk
+	if risk == nil || !risk.IsProxy || risk.ProxyType != "EIP-1967" || risk.ImplementationAddress != implementation {
+		t.Fatalf("Expected an EIP-1967 proxy of %s, got %+v", implementation, risk)
+	}
+	if risk.RiskScore != 42 {
+		t.Errorf("Expected the analyzer's risk score, got %d", risk.RiskScore)
+	}
+}
+
+// addressRiskAnalyzer scores each contract by address
+type addressRiskAnalyzer map[string]int
+
+func (a addressRiskAnalyzer) Analyze(ctx context.Context, address string, chain string, bytecode []byte) (*AnalyzerResponse, error) {
+	return &AnalyzerResponse{RiskScore: a[strings.ToLower(address)]}, nil
+}
+
+func TestAnalyzeContract_DiamondFacetsRaiseOverallRisk(t *testing.T) {
+	diamond := "0x1111111111111111111111111111111111111111"
+	safeFacet := "0x2222222222222222222222222222222222222222"
+	riskyFacet := "0x3333333333333333333333333333333333333333"
+	word := func(hex string) string { return strings.Repeat("0", 64-len(hex)) + hex }
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		result := "0x6080604052"
+		switch req.Method {
+		case "eth_getStorageAt":
+			result = "0x" + word("0")
+		case "eth_call":
+			var call struct{ To, Data string }
+			_ = json.Unmarshal(req.Params[0], &call)
+			switch {
+			case call.To != diamond:
+				result = "0x"
+			case strings.HasPrefix(call.Data, "0x01ffc9a748e2b093"):
+				result = "0x" + word("1")
+			case call.Data == "0x52ef6b2c":
+				result = "0x" + word("20") + word("3") + word(diamond[2:]) + word(safeFacet[2:]) + word(riskyFacet[2:])
+			default:
+				result = "0x" + word("0")
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, result)
+	}))
+	defer rpc.Close()
+
+	analyzer := addressRiskAnalyzer{diamond: 10, safeFacet: 5, riskyFacet: 85}
+	ca := NewContractAnalyzerWithServices(
+		map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, rpc.URL, defaultLogger)},
+		&MockDecompilerService{Response: &DecompilerResponse{Success: true}}, analyzer, defaultLogger)
+	result, err := ca.AnalyzeContract(context.Background(), diamond, Ethereum)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := []string{diamond, safeFacet, riskyFacet}; !slices.Equal(result.DiamondFacets, want) {
+		t.Errorf("Expected facets %v, got %v", want, result.DiamondFacets)
+	}
+	if result.OverallRisk != 85 {
+		t.Errorf("Expected the riskiest facet's score 85, got %d", result.OverallRisk)
+	}
+
+	facet, err := ca.AnalyzeContract(context.Background(), riskyFacet, Ethereum)
+	if err != nil || len(facet.DiamondFacets) != 0 {
+		t.Errorf("Expected a facet to have no facets, got %v (%v)", facet.DiamondFacets, err)
+	}
+}
+
+func TestAnalyzeContract_CloneInheritsImplementationRisk(t *testing.T) {
+	clone := "0x1111111111111111111111111111111111111111"
+	implementation := "0xbebebebebebebebebebebebebebebebebebebebe"
+	cloneCode := "0x363d3d373d3d3d363d73" + implementation[2:] + "5af43d82803e903d91602b57fd5bf3"
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		result := "0x6080604052"
+		switch {
+		case req.Method == "eth_getCode" && req.Params[0] == clone:
+			result = cloneCode
+		case req.Method == "eth_getStorageAt":
+			result = "0x" + strings.Repeat("0", 64)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, result)
+	}))
+	defer rpc.Close()
+
+	analyzer := addressRiskAnalyzer{clone: 5, implementation: 80}
+	ca := NewContractAnalyzerWithServices(
+		map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, rpc.URL, defaultLogger)},
+		&MockDecompilerService{Response: &DecompilerResponse{Success: true}}, analyzer, defaultLogger)
+	result, err := ca.AnalyzeContract(context.Background(), clone, Ethereum)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	risk := result.ContractRisk
+	if risk == nil || !risk.IsProxy || risk.ProxyType != "EIP-1167" || risk.ImplementationAddress != implementation {
+		t.Fatalf("Expected an EIP-1167 clone of %s, got %+v", implementation, risk)
+	}
+	if result.OverallRisk != 80 || risk.RiskScore != 80 {
+		t.Errorf("Expected the implementation's risk 80, got %d (contract risk %d)", result.OverallRisk, risk.RiskScore)
+	}
+
+	if _, ok := minimalProxyImplementation([]byte{0x36, 0x3d, 0x3d, 0x37}); ok {
+		t.Errorf("Expected truncated bytecode not to be a clone")
+	}
+}
+
+func TestAnalyzeContract_YoungContractFromReceipt(t *testing.T) {
+	contract := "0x1111111111111111111111111111111111111111"
+	deployer := "0x4444444444444444444444444444444444444444"
+	createdAt := time.Now().Add(-50 * time.Hour).Unix()
+
+	// Serves both the explorer (GET) and JSON-RPC (POST); the creation record has the
+	// older format without block number and timestamp
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			if r.URL.Query().Get("action") != "getcontractcreation" {
+				fmt.Fprint(w, `{"status":"0","message":"NOTOK","result":"unsupported"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"status":"1","message":"OK","result":[{"contractAddress":"%s","txHash":"0xabc"}]}`, contract)
+			return
+		}
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "eth_getTransactionReceipt":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"from":"0x%s","blockNumber":"0x10"}}`, strings.ToUpper(deployer[2:]))
+		case "eth_getBlockByNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"timestamp":"0x%x"}}`, createdAt)
+		case "eth_getStorageAt":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%s"}`, strings.Repeat("0", 64))
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x6080604052"}`)
+		}
+	}))
+	defer server.Close()
+
+	chainsMu.Lock()
+	etherscanConfig.Explorers[string(Ethereum)] = ExplorerConfig{URL: server.URL}
+	chainsMu.Unlock()
+	t.Cleanup(func() {
+		chainsMu.Lock()
+		delete(etherscanConfig.Explorers, string(Ethereum))
+		chainsMu.Unlock()
+	})
+
+	ca := NewContractAnalyzerWithServices(
+		map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, server.URL, defaultLogger)},
+		&MockDecompilerService{Response: &DecompilerResponse{Success: true}},
+		&MockAnalyzerService{Response: &AnalyzerResponse{RiskScore: 42}}, defaultLogger)
+	result, err := ca.AnalyzeContract(context.Background(), contract, Ethereum)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.DeployerAddress != deployer || result.CreatedAt != createdAt || result.AgeInDays != 2 {
+		t.Errorf("Expected deployer %s created at %d (2 days old), got %s at %d (%d days)",
+			deployer, createdAt, result.DeployerAddress, result.CreatedAt, result.AgeInDays)
+	}
+	if result.OverallRisk != 57 {
+		t.Errorf("Expected the analyzer's 42 plus 15 for a young contract, got %d", result.OverallRisk)
+	}
+}
+
+func TestAnalyzeContract_SourceVerification(t *testing.T) {
+	contract := "0x1111111111111111111111111111111111111111"
+
+	tests := []struct {
+		name         string
+		response     string
+		wantRisk     int
+		wantVerified bool
+		wantInfo     *VerificationInfo
+	}{
+		{
+			name:         "verified",
+			response:     `{"status":"1","message":"OK","result":[{"SourceCode":"contract Token {}","ContractName":"Token","CompilerVersion":"v0.8.20+commit.a1b79de6","OptimizationUsed":"1"}]}`,
+			wantRisk:     42,
+			wantVerified: true,
+			wantInfo:     &VerificationInfo{ContractName: "Token", CompilerVersion: "v0.8.20+commit.a1b79de6", OptimizationUsed: true},
+		},
+		{
+			name:     "unverified",
+			response: `{"status":"1","message":"OK","result":[{"SourceCode":"","ABI":"Contract source code not verified","ContractName":"","CompilerVersion":"","OptimizationUsed":""}]}`,
+			wantRisk: 57,
+		},
+		{
+			name:     "explorer error",
+			response: `{"status":"0","message":"NOTOK","result":"Invalid API Key"}`,
+			wantRisk: 42,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodGet {
+					if r.URL.Query().Get("action") != "getsourcecode" {
+						fmt.Fprint(w, `{"status":"0","message":"NOTOK","result":"unsupported"}`)
+						return
+					}
+					fmt.Fprint(w, tt.response)
+					return
+				}
+				var req struct {
+					Method string `json:"method"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				if req.Method == "eth_getStorageAt" {
+					fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%s"}`, strings.Repeat("0", 64))
+					return
+				}
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x6080604052"}`)
+			}))
+			defer server.Close()
+
+			chainsMu.Lock()
+			etherscanConfig.Explorers[string(Ethereum)] = ExplorerConfig{URL: server.URL}
+			chainsMu.Unlock()
+			t.Cleanup(func() {
+				chainsMu.Lock()
+				delete(etherscanConfig.Explorers, string(Ethereum))
+				chainsMu.Unlock()
+			})
+
+			ca := NewContractAnalyzerWithServices(
+				map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, server.URL, defaultLogger)},
+				&MockDecompilerService{Response: &DecompilerResponse{Success: true}},
+				&MockAnalyzerService{Response: &AnalyzerResponse{RiskScore: 42}}, defaultLogger)
+			result, err := ca.AnalyzeContract(context.Background(), contract, Ethereum)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.OverallRisk != tt.wantRisk {
+				t.Errorf("Expected overall risk %d, got %d", tt.wantRisk, result.OverallRisk)
+			}
+			if (result.Verification == nil) != (tt.wantInfo == nil) || (tt.wantInfo != nil && *result.Verification != *tt.wantInfo) {
+				t.Errorf("Expected verification %+v, got %+v", tt.wantInfo, result.Verification)
+			}
+			if result.ContractRisk != nil && result.ContractRisk.IsVerified != tt.wantVerified {
+				t.Errorf("Expected isVerified %v, got %v", tt.wantVerified, result.ContractRisk.IsVerified)
+			}
+		})
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════════
 //                         ANALYZE HANDLER TESTS
 // ═══════════════════════════════════════════════════════════════════════════════
 
 func TestHandleAnalyze_MissingContract(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 	req := httptest.NewRequest("GET", "/api/v1/analyze", nil)
 	w := httptest.NewRecorder()
 
@@ -491,7 +1155,7 @@ func TestHandleAnalyze_MissingContract(t *testing.T) {
 }
 
 func TestHandleAnalyze_InvalidAddress(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 	req := httptest.NewRequest("GET", "/api/v1/analyze?contract=notanaddress", nil)
 	w := httptest.NewRecorder()
 
@@ -503,7 +1167,7 @@ func TestHandleAnalyze_InvalidAddress(t *testing.T) {
 }
 
 func TestHandleAnalyze_InvalidChain(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 	req := httptest.NewRequest("GET", "/api/v1/analyze?contract=0x1234567890123456789012345678901234567890&chain=fakechain", nil)
 	w := httptest.NewRecorder()
 
@@ -515,7 +1179,7 @@ func TestHandleAnalyze_InvalidChain(t *testing.T) {
 }
 
 func TestHandleBatchAnalyze_NotPost(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 	req := httptest.NewRequest("GET", "/api/v1/analyze/batch", nil)
 	w := httptest.NewRecorder()
 
@@ -527,7 +1191,7 @@ func TestHandleBatchAnalyze_NotPost(t *testing.T) {
 }
 
 func TestHandleBatchAnalyze_EmptyBody(t *testing.T) {
-	server := NewServer()
+	server := NewServer(defaultLogger)
 	req := httptest.NewRequest("POST", "/api/v1/analyze/batch", strings.NewReader("{}"))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
@@ -625,3 +1289,4544 @@ func TestGenerateRecommendations_HighRiskScore(t *testing.T) {
 		t.Error("Expected elevated risk warning")
 	}
 }
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                      APPROVAL CAP TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestCapApprovalLogs_KeepsMostRecent(t *testing.T) {
+	logs := []approvalLog{
+		{BlockNumber: "0x30"},
+		{BlockNumber: "0x10"},
+		{BlockNumber: "0x40"},
+		{BlockNumber: "0x20"},
+	}
+
+	capped, truncated := capApprovalLogs(logs, 2)
+
+	if !truncated {
+		t.Fatal("Expected logs to be truncated")
+	}
+	if len(capped) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(capped))
+	}
+	if capped[0].BlockNumber != "0x30" || capped[1].BlockNumber != "0x40" {
+		t.Errorf("Expected most recent blocks in ascending order, got %s, %s", capped[0].BlockNumber, capped[1].BlockNumber)
+	}
+}
+
+func TestCapApprovalLogs_UnderLimit(t *testing.T) {
+	logs := []approvalLog{{BlockNumber: "0x1"}, {BlockNumber: "0x2"}}
+
+	capped, truncated := capApprovalLogs(logs, 500)
+
+	if truncated {
+		t.Error("Expected no truncation under the limit")
+	}
+	if len(capped) != 2 {
+		t.Errorf("Expected 2 logs, got %d", len(capped))
+	}
+}
+
+func TestGenerateRecommendations_TruncatedChains(t *testing.T) {
+	scanner := NewScanner()
+	result := &WalletScanResult{
+		Approvals:       []Approval{},
+		TruncatedChains: []ChainID{Ethereum},
+		truncatedTotals: map[ChainID]int{Ethereum: 12456},
+	}
+
+	scanner.generateRecommendations(result)
+
+	expected := fmt.Sprintf("⚠️ Results truncated for Ethereum: showing %s most recent of 12,456 approvals",
+		formatCount(config.MaxApprovalsPerChain))
+	found := false
+	for _, rec := range result.Recommendations {
+		if rec == expected {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected truncation recommendation %q, got %v", expected, result.Recommendations)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                      CHAIN SCAN STATS TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestScanWallet_ReportsMissingClient(t *testing.T) {
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	scanner := &Scanner{
+		clients: map[ChainID]*ChainClient{},
+		cache:   NewCacheWithClock(time.Minute, clock),
+		clock:   clock,
+	}
+
+	result, err := scanner.ScanWallet(context.Background(), "0x1234567890123456789012345678901234567890", []ChainID{Ethereum}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ScanTimestamp != 1700000000 {
+		t.Errorf("Expected scan timestamp from clock, got %d", result.ScanTimestamp)
+	}
+
+	stats, ok := result.ChainScanStats[Ethereum]
+	if !ok {
+		t.Fatal("Expected stats for ethereum")
+	}
+	if stats.Error == "" {
+		t.Error("Expected an error for a chain without a client")
+	}
+	if stats.ApprovalsFound != 0 {
+		t.Errorf("Expected 0 approvals, got %d", stats.ApprovalsFound)
+	}
+}
+
+func TestRedactURLs_StripsKeys(t *testing.T) {
+	msg := `Post "https://eth-mainnet.g.alchemy.com/v2/secretkey": context deadline exceeded; ` +
+		`Get "https://api.etherscan.io/v2/api?chainid=1&apikey=secret2": EOF`
+
+	redacted := redactURLs(msg)
+
+	if strings.Contains(redacted, "secretkey") || strings.Contains(redacted, "secret2") {
+		t.Errorf("Expected API keys to be redacted, got %s", redacted)
+	}
+	if !strings.Contains(redacted, "https://eth-mainnet.g.alchemy.com") {
+		t.Errorf("Expected host to be preserved, got %s", redacted)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                         RISK RULES ENGINE TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestDefaultRules_MatchBuiltInScoring(t *testing.T) {
+	engine, err := NewRulesEngine("")
+	if err != nil {
+		t.Fatalf("Default rules failed to load: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		approval   Approval
+		wantPoints int
+		wantLevel  string
+	}{
+		{"trusted limited", Approval{RiskLevel: "safe", SpenderName: "✅ Aave V3: Pool"}, 2, ""},
+		{"trusted unlimited", Approval{RiskLevel: "safe", SpenderName: "✅ Aave V3: Pool", IsUnlimited: true}, 10, "warning"},
+		{"relay limited", Approval{RiskLevel: "warning", SpenderName: "⚠️ Relay: RouterV3"}, 15, ""},
+		{"unknown unlimited", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true}, 40, "critical"},
+		{"drainer unlimited", Approval{RiskLevel: "critical", SpenderName: "🚨 DRAINER: Pink Drainer", IsUnlimited: true}, 70, ""},
+		{"unknown unlimited low-trust token", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true, TokenTrustScore: 5, trustScored: true}, 60, "critical"},
+		{"unknown unlimited trusted token", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true, TokenTrustScore: 90, trustScored: true}, 40, "critical"},
+		{"trusted self-approval", Approval{RiskLevel: "safe", SpenderName: "✅ Token", IsSelfApproval: true}, 42, "critical"},
+		{"frequent unknown spender", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", TransferFromCount: 25}, 35, ""},
+		{"frequent trusted spender", Approval{RiskLevel: "safe", SpenderName: "✅ Aave V3: Pool", TransferFromCount: 25}, 2, ""},
+		{"classified unknown spender", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", SpenderCategory: "DEX Router"}, 20, ""},
+		{"unknown unlimited 18 months old", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true, LastUpdated: 1, ageDays: 540}, 50, "critical"},
+		{"unknown unlimited 5 years old", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true, LastUpdated: 1, ageDays: 1900}, 70, "critical"},
+		{"unknown unlimited without timestamp", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true, ageDays: 1900}, 40, "critical"},
+		{"trusted unlimited 5 years old", Approval{RiskLevel: "safe", SpenderName: "✅ Aave V3: Pool", IsUnlimited: true, LastUpdated: 1, ageDays: 1900}, 10, "warning"},
+		{"unknown unlimited busy spender", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true, SpenderTxCount: 250000, txCounted: true}, 30, "warning"},
+		{"unknown limited fresh spender", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", txCounted: true}, 45, "critical"},
+		{"unknown limited uncounted spender", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234"}, 25, ""},
+		{"recently used unknown spender", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", TransferFromCount: 3, LastTransferFromTime: 1, recentlyUsed: true}, 35, "critical"},
+		{"recently used trusted spender", Approval{RiskLevel: "safe", SpenderName: "✅ Aave V3: Pool", TransferFromCount: 3, LastTransferFromTime: 1, recentlyUsed: true}, 2, ""},
+		{"unknown spender recently used by volume", Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", TransferFromCount: -1, TransferCount: 3, volumeAnalyzed: true, recentlyUsed: true}, 35, "critical"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := engine.Evaluate(tt.approval)
+			if outcome.RiskPoints != tt.wantPoints {
+				t.Errorf("Expected %d points, got %d", tt.wantPoints, outcome.RiskPoints)
+			}
+			if outcome.RiskLevel != tt.wantLevel {
+				t.Errorf("Expected level %q, got %q", tt.wantLevel, outcome.RiskLevel)
+			}
+		})
+	}
+}
+
+func TestCalculateRiskScores_AgesUnlimitedUnknownApprovals(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	scanner := &Scanner{clock: NewMockClock(now)}
+	unknownUnlimited := func(lastUpdated time.Time) Approval {
+		approval := Approval{RiskLevel: "warning", SpenderName: "0xabcd...1234", IsUnlimited: true}
+		if !lastUpdated.IsZero() {
+			approval.LastUpdated = lastUpdated.Unix()
+		}
+		return approval
+	}
+
+	tests := []struct {
+		name        string
+		lastUpdated time.Time
+		wantScore   int
+	}{
+		{"yesterday", now.AddDate(0, 0, -1), 40},
+		{"two and a half years ago", now.AddDate(-2, -6, 0), 60},
+		{"unknown", time.Time{}, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &WalletScanResult{Approvals: []Approval{unknownUnlimited(tt.lastUpdated)}}
+			scanner.calculateRiskScores(result)
+			if result.OverallRiskScore != tt.wantScore {
+				t.Errorf("Expected risk score %d, got %d (%v)", tt.wantScore, result.OverallRiskScore, result.Approvals[0].RiskReasons)
+			}
+		})
+	}
+}
+
+func TestRulesEngine_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"empty", `{"rules": []}`, "no rules"},
+		{"missing id", `{"rules": [{"condition": {"riskLevel": "safe"}, "action": {"addRiskPoints": 1}}]}`, "missing id"},
+		{"duplicate id", `{"rules": [
+			{"id": "a", "condition": {"riskLevel": "safe"}, "action": {"addRiskPoints": 1}},
+			{"id": "a", "condition": {"riskLevel": "safe"}, "action": {"addRiskPoints": 1}}]}`, "duplicate id"},
+		{"bad level", `{"rules": [{"id": "a", "condition": {"riskLevel": "high"}, "action": {"addRiskPoints": 1}}]}`, "condition.riskLevel"},
+		{"typo field", `{"rules": [{"id": "a", "condition": {"isUnlimted": true}, "action": {"addRiskPoints": 1}}]}`, "unknown field"},
+		{"no effect", `{"rules": [{"id": "a", "condition": {"riskLevel": "safe"}, "action": {}}]}`, "no effect"},
+		{"negative age", `{"rules": [{"id": "a", "condition": {"minAgeDays": -1}, "action": {"addRiskPoints": 1}}]}`, "minAgeDays"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRiskRules([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRulesEngine_ReloadKeepsRulesOnError(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/rules.json"
+	valid := `{"rules": [{"id": "flat", "condition": {"riskLevel": "safe"}, "action": {"addRiskPoints": 7}}]}`
+	if err := os.WriteFile(path, []byte(valid), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := NewRulesEngine(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"rules": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Load(path); err == nil {
+		t.Fatal("Expected reload of invalid file to fail")
+	}
+
+	if got := engine.Evaluate(Approval{RiskLevel: "safe"}).RiskPoints; got != 7 {
+		t.Errorf("Expected previous rules to stay active (7 points), got %d", got)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              ENS RESOLUTION TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestNamehash_SpecVectors(t *testing.T) {
+	// Test vectors from EIP-137
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"eth", "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{"foo.eth", "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+		{"FOO.eth", "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := namehash(tt.name)
+			if got := fmt.Sprintf("%x", node[:]); got != tt.want {
+				t.Errorf("Expected namehash(%q) = %s, got %s", tt.name, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTrustedENSParent_Subdomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted bool
+	}{
+		{"v3.uniswap.eth", true},
+		{"v2pool.curve.eth", true},
+		{"uniswap.eth", false},
+		{"fakeuniswap.eth", false},
+		{"uniswap.eth.evil.eth", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := trustedENSParent(tt.name); ok != tt.trusted {
+			t.Errorf("Expected trustedENSParent(%q) = %v, got %v", tt.name, tt.trusted, ok)
+		}
+	}
+}
+
+func TestENSResolver_ReverseVerifiesAndCachesNames(t *testing.T) {
+	router := "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"
+	claimant := "0x1111111111111111111111111111111111111111" // claims a name it doesn't own
+	resolver := "0x2222222222222222222222222222222222222222"
+	names := map[string]string{router: "uniswap.eth", claimant: "vitalik.eth"}
+
+	nodeOf := func(name string) string { node := namehash(name); return hex.EncodeToString(node[:]) }
+	abiString := func(s string) string {
+		data := hex.EncodeToString([]byte(s))
+		return fmt.Sprintf("0x%064x%064x", 32, len(s)) + data + strings.Repeat("0", 64-len(data))
+	}
+	word := func(address string) string { return "0x" + strings.Repeat("0", 24) + address[2:] }
+
+	var calls atomic.Int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var call struct {
+			Data string `json:"data"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+
+		result := word(zeroAddress)
+		for address, name := range names {
+			switch call.Data {
+			case selectorENSResolver + nodeOf(address[2:]+".addr.reverse"), selectorENSResolver + nodeOf(name):
+				result = word(resolver)
+			case selectorENSName + nodeOf(address[2:]+".addr.reverse"):
+				result = abiString(name)
+			case selectorENSAddr + nodeOf(name):
+				if address == router {
+					result = word(router) // vitalik.eth doesn't resolve to the claimant
+				}
+			}
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, result)
+	}))
+	defer rpc.Close()
+
+	client := NewChainClient(Ethereum, rpc.URL, defaultLogger)
+	if name, err := client.ens.Reverse(context.Background(), router); err != nil || name != "uniswap.eth" {
+		t.Fatalf("Expected uniswap.eth, got %q (%v)", name, err)
+	}
+	if name, _ := client.ens.Reverse(context.Background(), claimant); name != "" {
+		t.Errorf("Expected an unverified reverse record to be ignored, got %q", name)
+	}
+
+	approvals := []Approval{
+		{SpenderAddress: router, SpenderName: "✅ Uniswap V3: Router 2"},
+		{SpenderAddress: claimant, SpenderName: "0x1111...1111"},
+	}
+	before := calls.Load()
+	client.annotateSpenderENS(context.Background(), approvals)
+	if calls.Load() != before {
+		t.Errorf("Expected cached names to be reused, got %d more RPC calls", calls.Load()-before)
+	}
+	if approvals[0].SpenderName != "uniswap.eth (✅ Uniswap V3: Router 2)" || approvals[0].SpenderENS != "uniswap.eth" {
+		t.Errorf("Expected the ENS name to prefix the spender name, got %q", approvals[0].SpenderName)
+	}
+	if approvals[1].SpenderName != "0x1111...1111" {
+		t.Errorf("Expected the claimant to keep its name, got %q", approvals[1].SpenderName)
+	}
+	if isUnknownSpender(approvals[0]) || !isUnknownSpender(Approval{SpenderENS: "foo.eth", SpenderName: "foo.eth (0x1111...1111)"}) {
+		t.Errorf("Expected an ENS prefix not to change whether the spender is known")
+	}
+
+	// L2 clients don't resolve ENS
+	l2 := []Approval{{SpenderAddress: router, SpenderName: "✅ Uniswap V3: Router 2"}}
+	NewChainClient(Arbitrum, rpc.URL, defaultLogger).annotateSpenderENS(context.Background(), l2)
+	if l2[0].SpenderName != "✅ Uniswap V3: Router 2" {
+		t.Errorf("Expected no ENS lookup on Arbitrum, got %q", l2[0].SpenderName)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                          PROTOCOL EXPOSURE TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestAllowanceUSDValue_Stablecoins(t *testing.T) {
+	usdc := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+
+	if got := allowanceUSDValue(big.NewInt(2_500_000), usdc, false); got != 2.5 {
+		t.Errorf("Expected 2.5 USD, got %v", got)
+	}
+	if got := allowanceUSDValue(big.NewInt(2_500_000), usdc, true); got != -1 {
+		t.Errorf("Expected unlimited approval to be unknown (-1), got %v", got)
+	}
+	if got := allowanceUSDValue(big.NewInt(1e18), "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", false); got != -1 {
+		t.Errorf("Expected non-stablecoin to be unknown (-1), got %v", got)
+	}
+}
+
+func TestBuildProtocolExposures_GroupsBySpender(t *testing.T) {
+	approvals := []Approval{
+		{Chain: Ethereum, TokenAddress: "0xA0b8", SpenderName: "Aave V3", AllowanceUSD: 200_000, RiskLevel: "safe"},
+		{Chain: Ethereum, TokenAddress: "0xdac1", SpenderName: "Aave V3", AllowanceUSD: 150_000, RiskLevel: "warning"},
+		{Chain: Ethereum, TokenAddress: "0x6b17", SpenderName: "Aave V3", AllowanceUSD: 150_000, RiskLevel: "safe"},
+		{Chain: Ethereum, TokenAddress: "0xc02a", SpenderName: "Aave V3", AllowanceUSD: -1, RiskLevel: "safe"},
+		{Chain: Ethereum, TokenAddress: "0xA0b8", SpenderName: "Uniswap V3", AllowanceUSD: 900_000, RiskLevel: "critical"},
+		{Chain: Polygon, TokenAddress: "0xA0b8", SpenderName: "Curve", AllowanceUSD: 50, RiskLevel: "safe"},
+	}
+
+	exposures := buildProtocolExposures(approvals)
+	if len(exposures) != 3 {
+		t.Fatalf("Expected 3 protocols, got %d", len(exposures))
+	}
+
+	if exposures[0].Protocol != "Uniswap V3" || exposures[1].Protocol != "Aave V3" || exposures[2].Protocol != "Curve" {
+		t.Errorf("Expected exposures sorted by USD descending, got %s, %s, %s",
+			exposures[0].Protocol, exposures[1].Protocol, exposures[2].Protocol)
+	}
+
+	aave := exposures[1]
+	if aave.TotalExposureUSD != "500000.00" {
+		t.Errorf("Expected 500000.00, got %s", aave.TotalExposureUSD)
+	}
+	if aave.TokenCount != 4 || len(aave.Approvals) != 4 {
+		t.Errorf("Expected 4 tokens and approvals, got %d and %d", aave.TokenCount, len(aave.Approvals))
+	}
+	if aave.WorstRiskLevel != "warning" {
+		t.Errorf("Expected worst risk level warning, got %s", aave.WorstRiskLevel)
+	}
+}
+
+func TestGroupApprovalsBySpender(t *testing.T) {
+	approvals := []Approval{
+		{Chain: Ethereum, TokenAddress: "0xA0b8", SpenderName: "Uniswap V3", SpenderAddress: "0x68B3465833fb72A70ecDF485E0e4C7bD8665Fc45", AllowanceUSD: 1_000, RiskLevel: "safe"},
+		{Chain: Ethereum, TokenAddress: "0xdac1", SpenderName: "Aave V3", SpenderAddress: "0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2", AllowanceUSD: 500, RiskLevel: "safe"},
+		{Chain: Polygon, TokenAddress: "0x2791", SpenderName: "Uniswap V3", SpenderAddress: "0xE592427A0AEce92De3Edee1F18E0157C05861564", AllowanceUSD: 2_000, RiskLevel: "warning"},
+		{Chain: Arbitrum, TokenAddress: "0xaf88", SpenderName: "Uniswap V3", SpenderAddress: "0xe592427a0aece92de3edee1f18e0157c05861564", AllowanceUSD: -1, RiskLevel: "safe"},
+		{Chain: Ethereum, TokenAddress: "0xdac1", SpenderName: "Uniswap V3", SpenderAddress: "0xE592427A0AEce92De3Edee1F18E0157C05861564", AllowanceUSD: -1, RiskLevel: "safe"},
+	}
+
+	groups := groupApprovalsBySpender(approvals)
+	if len(groups) != 2 || groups[0].SpenderName != "Uniswap V3" || groups[1].SpenderName != "Aave V3" {
+		t.Fatalf("Expected Uniswap V3 then Aave V3, got %+v", groups)
+	}
+
+	uniswap := groups[0]
+	if uniswap.TotalExposureUSD != 3_000 {
+		t.Errorf("Expected 3000 USD across chains, got %v", uniswap.TotalExposureUSD)
+	}
+	if len(uniswap.Chains) != 3 || uniswap.Chains[0] != Ethereum || uniswap.Chains[1] != Polygon || uniswap.Chains[2] != Arbitrum {
+		t.Errorf("Expected chains in scan order, got %v", uniswap.Chains)
+	}
+	if !slices.Equal(uniswap.SpenderAddresses[Polygon], []string{"0xe592427a0aece92de3edee1f18e0157c05861564"}) ||
+		!slices.Equal(uniswap.SpenderAddresses[Ethereum], []string{"0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45", "0xe592427a0aece92de3edee1f18e0157c05861564"}) {
+		t.Errorf("Expected every lowercase spender address per chain, got %v", uniswap.SpenderAddresses)
+	}
+	if uniswap.HighestRiskLevel != "warning" || len(uniswap.Approvals) != 4 {
+		t.Errorf("Expected warning across 4 approvals, got %s across %d", uniswap.HighestRiskLevel, len(uniswap.Approvals))
+	}
+}
+
+func TestHandleScan_RejectsUnknownGroupBy(t *testing.T) {
+	server := NewServer(defaultLogger)
+	w := httptest.NewRecorder()
+	server.handleScan(w, httptest.NewRequest("GET", "/api/v1/scan?wallet=0x1234567890123456789012345678901234567890&groupBy=token", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for groupBy=token, got %d", w.Code)
+	}
+}
+
+func TestGenerateRecommendations_HighExposure(t *testing.T) {
+	scanner := &Scanner{}
+	result := &WalletScanResult{
+		ProtocolExposures: buildProtocolExposures([]Approval{
+			{TokenAddress: "0x1", SpenderName: "Aave V3", AllowanceUSD: 125_000},
+			{TokenAddress: "0x2", SpenderName: "Aave V3", AllowanceUSD: 125_000},
+			{TokenAddress: "0x3", SpenderName: "Aave V3", AllowanceUSD: 125_000},
+			{TokenAddress: "0x4", SpenderName: "Aave V3", AllowanceUSD: 125_000},
+			{TokenAddress: "0x5", SpenderName: "Small DEX", AllowanceUSD: 100},
+		}),
+	}
+
+	scanner.generateRecommendations(result)
+
+	found := false
+	for _, rec := range result.Recommendations {
+		if rec == "💰 You have $500K exposure to Aave V3 across 4 tokens" {
+			found = true
+		}
+		if strings.Contains(rec, "Small DEX") {
+			t.Errorf("Did not expect recommendation for low exposure: %s", rec)
+		}
+	}
+	if !found {
+		t.Errorf("Expected Aave V3 exposure recommendation, got %v", result.Recommendations)
+	}
+}
+
+func TestGenerateRecommendations_HardwareWallet(t *testing.T) {
+	hwRec := "🔐 Consider using a hardware wallet (Ledger/Trezor) for wallets holding significant assets"
+	entryPoint := "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+
+	tests := []struct {
+		name       string
+		result     *WalletScanResult
+		wantHW     bool
+		wantAANote bool
+	}{
+		{"small eoa", &WalletScanResult{NativeBalance: map[ChainID]float64{Ethereum: 1}, TotalValueAtRisk: 500, WalletType: "eoa"}, false, false},
+		{"large eth balance", &WalletScanResult{NativeBalance: map[ChainID]float64{Ethereum: 6}, WalletType: "eoa"}, true, false},
+		{"large value at risk", &WalletScanResult{TotalValueAtRisk: 25_000}, true, false},
+		{"gnosis safe", &WalletScanResult{NativeBalance: map[ChainID]float64{Ethereum: 500}, WalletType: "safe"}, false, false},
+		{"smart account", &WalletScanResult{NativeBalance: map[ChainID]float64{Ethereum: 10}, WalletType: "smart_account"}, true, true},
+		{"entrypoint approval", &WalletScanResult{TotalValueAtRisk: 50_000, Approvals: []Approval{{SpenderAddress: entryPoint}}}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			(&Scanner{}).generateRecommendations(tt.result)
+
+			gotHW, gotAANote := false, false
+			for _, rec := range tt.result.Recommendations {
+				if rec == hwRec {
+					gotHW = true
+				}
+				if strings.Contains(rec, "ERC-4337") {
+					gotAANote = true
+				}
+			}
+			if gotHW != tt.wantHW {
+				t.Errorf("Expected hardware wallet recommendation=%v, got %v", tt.wantHW, tt.result.Recommendations)
+			}
+			if gotAANote != tt.wantAANote {
+				t.Errorf("Expected smart account note=%v, got %v", tt.wantAANote, tt.result.Recommendations)
+			}
+		})
+	}
+}
+
+func TestIsGnosisSafeBytecode(t *testing.T) {
+	safeProxy, _ := hex.DecodeString("608060405273ffffffffffffffffffffffffffffffffffffffff600054167fa619486e0000000000000000000000000000000000000000000000000000000060003514156050578060005260206000f35b3660008037600080366000845af43d6000803e60008114156070573d6000fd5b3d6000f3")
+	if !isGnosisSafeBytecode(safeProxy) {
+		t.Error("Expected Safe proxy bytecode to be detected")
+	}
+
+	minimalProxy, _ := hex.DecodeString("363d3d373d3d3d363d73bebebebebebebebebebebebebebebebebebebebe5af43d82803e903d91602b57fd5bf3")
+	if isGnosisSafeBytecode(minimalProxy) {
+		t.Error("Did not expect an EIP-1167 proxy to be detected as a Safe")
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              FEE MARKET TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// newFeeMarketRPC mocks eth_feeHistory (base fee 20 gwei, next 22 gwei, tips 1/2/5 gwei) and eth_estimateGas
+func newFeeMarketRPC(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case "eth_feeHistory":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"oldestBlock":"0x1","baseFeePerGas":["0x4a817c800","0x51f4d5c00"],"gasUsedRatio":[0.5],"reward":[["0x3b9aca00","0x77359400","0x12a05f200"]]}}`)
+		case "eth_estimateGas":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0xb3b0"}`) // 46000
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFeeMarketClient_GetCurrentFees(t *testing.T) {
+	rpc := newFeeMarketRPC(t)
+	fees, err := NewFeeMarketClient(map[ChainID]*ChainClient{
+		Ethereum: NewChainClient(Ethereum, rpc.URL, defaultLogger),
+	}).GetCurrentFees(context.Background(), Ethereum)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fees.BaseFeeGwei != 20 || fees.EstimatedNextBaseFee != 22 {
+		t.Errorf("Expected base fee 20 and next 22 gwei, got %v and %v", fees.BaseFeeGwei, fees.EstimatedNextBaseFee)
+	}
+	if fees.SlowPriorityFeeGwei != 1 || fees.MaxPriorityFeeGwei != 2 || fees.FastPriorityFeeGwei != 5 {
+		t.Errorf("Expected priority fees 1/2/5 gwei, got %v/%v/%v",
+			fees.SlowPriorityFeeGwei, fees.MaxPriorityFeeGwei, fees.FastPriorityFeeGwei)
+	}
+}
+
+func TestHandler_RevokeEstimate(t *testing.T) {
+	rpc := newFeeMarketRPC(t)
+	prices := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ethereum":{"usd":2000}}`)
+	}))
+	defer prices.Close()
+
+	originalURL := coinGeckoBaseURL
+	coinGeckoBaseURL = prices.URL
+	t.Cleanup(func() { coinGeckoBaseURL = originalURL })
+	nativePriceCache = NewCache(10 * time.Minute)
+
+	server := NewServerWithScanner(newMockScanner(nil, nil), defaultLogger)
+	server.chainClients[Ethereum] = NewChainClient(Ethereum, rpc.URL, defaultLogger)
+
+	req := httptest.NewRequest("GET", "/api/v1/revoke/estimate?chain=ethereum"+
+		"&wallet=0x1234567890123456789012345678901234567890"+
+		"&token=0xdac17f958d2ee523a2206206994597c13d831ec7"+
+		"&spender=0x1111111111111111111111111111111111111111", nil)
+	w := httptest.NewRecorder()
+	server.handleRevokeEstimate(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var estimate RevocationEstimateResponse
+	if err := json.NewDecoder(w.Body).Decode(&estimate); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if estimate.GasLimit != 46000 || !estimate.GasEstimated {
+		t.Errorf("Expected estimated gas limit 46000, got %d (estimated=%v)", estimate.GasLimit, estimate.GasEstimated)
+	}
+
+	// 46000 gas * (20 + 2) gwei = 0.001012 ETH = $2.024 at $2000
+	if diff := estimate.CostETH - 0.001012; diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("Expected standard cost 0.001012 ETH, got %v", estimate.CostETH)
+	}
+	if diff := estimate.CostUSD - 2.024; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected standard cost $2.024, got %v", estimate.CostUSD)
+	}
+	if estimate.Speeds["fast"].CostETH <= estimate.Speeds["slow"].CostETH {
+		t.Errorf("Expected fast to cost more than slow: %+v", estimate.Speeds)
+	}
+}
+
+func TestHandleScan_IncludeRevocationCost(t *testing.T) {
+	rpc := newFeeMarketRPC(t) // 20 gwei base fee, 2 gwei median priority fee
+	prices := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ethereum":{"usd":2000}}`)
+	}))
+	defer prices.Close()
+
+	originalURL := coinGeckoBaseURL
+	coinGeckoBaseURL = prices.URL
+	t.Cleanup(func() { coinGeckoBaseURL = originalURL })
+	nativePriceCache = NewCache(10 * time.Minute)
+
+	wallet := "0x1234567890123456789012345678901234567890"
+	server := NewServerWithScanner(newMockScanner(&WalletScanResult{
+		WalletAddress: wallet,
+		Approvals: []Approval{
+			{Chain: Ethereum, TokenAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", SpenderAddress: "0x1111111111111111111111111111111111111111", RiskLevel: "warning"},
+			{Chain: Ethereum, TokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", SpenderAddress: "0x1111111111111111111111111111111111111111", RiskLevel: "warning"},
+			{Chain: Ethereum, TokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", SpenderAddress: "0x2222222222222222222222222222222222222222", RiskLevel: "warning", ViaPermit2: true},
+			{Chain: Polygon, TokenAddress: "0x3c499c542cef5e3811e1192ce70d8cc03d5c3359", SpenderAddress: "0x1111111111111111111111111111111111111111", RiskLevel: "warning"},
+		},
+	}, nil), defaultLogger)
+	server.chainClients[Ethereum] = NewChainClient(Ethereum, rpc.URL, defaultLogger)
+	delete(server.chainClients, Polygon)
+
+	w := httptest.NewRecorder()
+	server.handleScan(w, httptest.NewRequest("GET", "/api/v1/scan?wallet="+wallet+"&chains=ethereum,polygon&includeRevocationCost=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result WalletScanResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	estimate := result.RevocationCost
+	if estimate == nil || len(estimate.Chains) != 2 {
+		t.Fatalf("Expected a revocation estimate for 2 chains, got %+v", estimate)
+	}
+	if estimate.TotalRevocations != 4 || estimate.EstimatedGasUnits != 164_000 {
+		t.Errorf("Expected 4 revocations and 164000 gas, got %d and %d", estimate.TotalRevocations, estimate.EstimatedGasUnits)
+	}
+
+	// 119000 gas * (20 + 2) gwei = 0.002618 ETH = $5.236 at $2000; Polygon has no client
+	if diff := estimate.EstimatedCostETH - 0.002618; diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("Expected 0.002618 ETH, got %v", estimate.EstimatedCostETH)
+	}
+	if diff := estimate.EstimatedCostUSD - 5.236; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected $5.236, got %v", estimate.EstimatedCostUSD)
+	}
+	if ethereum := estimate.Chains[0]; ethereum.Chain != Ethereum || ethereum.GasUnits != 119_000 || ethereum.BaseFeeGwei != 20 || ethereum.PriorityFeeGwei != 2 {
+		t.Errorf("Expected Ethereum at 119000 gas, 20 gwei base and 2 gwei priority fee, got %+v", ethereum)
+	}
+	if failed := estimate.Chains[1]; failed.Error == "" || failed.CostUSD != -1 {
+		t.Errorf("Expected Polygon to report its failed lookup, got %+v", failed)
+	}
+}
+
+func TestHandler_RevokeBuildsUnsignedTransactions(t *testing.T) {
+	rpc := newFeeMarketRPC(t)
+	server := NewServerWithScanner(newMockScanner(nil, nil), defaultLogger)
+	server.chainClients[Ethereum] = NewChainClient(Ethereum, rpc.URL, defaultLogger)
+
+	spender := "0x1111111111111111111111111111111111111111"
+	body := `{"wallet":"0x1234567890123456789012345678901234567890","approvals":[
+		{"chain":"ethereum","tokenAddress":"0xdAC17F958D2ee523a2206206994597C13D831ec7","spenderAddress":"` + spender + `"},
+		{"chain":"ethereum","tokenAddress":"0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d","spenderAddress":"` + spender + `","isNft":true},
+		{"chain":"ethereum","tokenAddress":"0xdAC17F958D2ee523a2206206994597C13D831ec7","spenderAddress":"` + spender + `","viaPermit2":true},
+		{"chain":"nochain","tokenAddress":"0xdac17f958d2ee523a2206206994597c13d831ec7","spenderAddress":"` + spender + `"}]}`
+	w := httptest.NewRecorder()
+	server.handleRevoke(w, httptest.NewRequest(http.MethodPost, "/api/v1/revoke", strings.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response RevokeResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Transactions) != 3 || len(response.Errors) != 1 {
+		t.Fatalf("Expected 3 transactions and 1 error, got %+v", response)
+	}
+
+	erc20, nft, permit2 := response.Transactions[0], response.Transactions[1], response.Transactions[2]
+	wantData := "0x095ea7b3" + strings.Repeat("0", 24) + spender[2:] + strings.Repeat("0", 64)
+	if erc20.Data != wantData || erc20.To != "0xdac17f958d2ee523a2206206994597c13d831ec7" {
+		t.Errorf("Expected approve(spender, 0) to the token, got %s to %s", erc20.Data, erc20.To)
+	}
+	if !strings.HasPrefix(nft.Data, "0xa22cb465") || nft.Data[10:] != wantData[10:] {
+		t.Errorf("Expected setApprovalForAll(spender, false), got %s", nft.Data)
+	}
+	wantPermit2Data := "0x87517c45" + strings.Repeat("0", 24) + "dac17f958d2ee523a2206206994597c13d831ec7" +
+		strings.Repeat("0", 24) + spender[2:] + strings.Repeat("0", 128)
+	if permit2.Data != wantPermit2Data || permit2.To != permit2Address || permit2.Method != "approve(address,address,uint160,uint48)" {
+		t.Errorf("Expected Permit2 approve(token, spender, 0, 0) to Permit2, got %s %s to %s", permit2.Method, permit2.Data, permit2.To)
+	}
+	// 46000 gas; priority 2 gwei; max fee 2 * 22 + 2 = 46 gwei
+	if erc20.ChainID != "0x1" || erc20.Type != "0x2" || erc20.GasLimit != "0xb3b0" || !erc20.GasEstimated {
+		t.Errorf("Expected chain 0x1, type 0x2 and estimated gas 0xb3b0, got %+v", erc20)
+	}
+	if erc20.MaxPriorityFeePerGas != fmt.Sprintf("0x%x", 2_000_000_000) || erc20.MaxFeePerGas != fmt.Sprintf("0x%x", 46_000_000_000) {
+		t.Errorf("Expected fees 2 and 46 gwei, got %s and %s", erc20.MaxPriorityFeePerGas, erc20.MaxFeePerGas)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleRevoke(w, httptest.NewRequest(http.MethodPost, "/api/v1/revoke", strings.NewReader(`{"wallet":"0x123","approvals":[]}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid wallet, got %d", w.Code)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              TOKEN TRUST SCORE TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestComputeTokenTrustScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals TokenTrustSignals
+		want    int
+	}{
+		{"established listed token", TokenTrustSignals{AgeDays: 3 * 365, Holders: 100_000, Listed: 1}, 90},
+		{"fresh token", TokenTrustSignals{AgeDays: 2, Holders: 50, Listed: 0}, 5},
+		{"no signals", TokenTrustSignals{AgeDays: -1, Holders: -1, Listed: -1}, neutralTrustScore},
+		{"old but unlisted", TokenTrustSignals{AgeDays: 400, Holders: 2_000, Listed: 0}, 48},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeTokenTrustScore(tt.signals); got != tt.want {
+				t.Errorf("Expected score %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTokenTrustScorer_KnownTokensSkipLookups(t *testing.T) {
+	scorer := NewTokenTrustScorer(RealClock{})
+	// Unroutable RPC: any lookup would fail and yield the neutral score
+	client := NewChainClient(Ethereum, "http://127.0.0.1:0", defaultLogger)
+
+	approvals := []Approval{{TokenAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7"}}
+	scorer.ScoreApprovals(context.Background(), client, approvals)
+
+	if approvals[0].TokenTrustScore != knownTokenTrustScore {
+		t.Errorf("Expected known token score %d, got %d", knownTokenTrustScore, approvals[0].TokenTrustScore)
+	}
+	if isLowTrustToken(approvals[0]) {
+		t.Error("Expected known token not to be low trust")
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              SCAN CACHE TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestScanCacheKey_IgnoresChainOrderAndCase(t *testing.T) {
+	a := scanCacheKey("0xABCDEF1234567890abcdef1234567890abcdef12", []ChainID{Polygon, Ethereum}, false)
+	b := scanCacheKey("0xabcdef1234567890abcdef1234567890abcdef12", []ChainID{Ethereum, Polygon}, false)
+	if a != b {
+		t.Errorf("Expected identical keys, got %s and %s", a, b)
+	}
+	if a == scanCacheKey("0xabcdef1234567890abcdef1234567890abcdef12", []ChainID{Ethereum}, false) {
+		t.Error("Expected different chain sets to produce different keys")
+	}
+	if a == scanCacheKey("0xabcdef1234567890abcdef1234567890abcdef12", []ChainID{Ethereum, Polygon}, true) {
+		t.Error("Expected scans skipping the Tornado Cash check to be cached apart")
+	}
+
+	// Base58 Solana addresses are case-sensitive
+	solana := "7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU"
+	if scanCacheKey(solana, []ChainID{Solana}, false) == scanCacheKey(strings.ToLower(solana), []ChainID{Solana}, false) {
+		t.Error("Expected Solana wallets differing in case to have different scan keys")
+	}
+	if scanResponseKey(solana, []ChainID{Solana}, 0, false, nil) == scanResponseKey(strings.ToLower(solana), []ChainID{Solana}, 0, false, nil) {
+		t.Error("Expected Solana wallets differing in case to have different response keys")
+	}
+}
+
+func TestScanWallet_SkippedTornadoCheckNotServedToFullScans(t *testing.T) {
+	scanner := NewScannerWithClock(NewMockClock(time.Unix(1700000000, 0)), Options{})
+	scanner.clients = map[ChainID]*ChainClient{}
+	wallet := "0x1234567890123456789012345678901234567890"
+
+	if _, err := scanner.ScanWallet(withoutTornadoCheck(context.Background()), wallet, []ChainID{}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	full, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if full.CacheHit {
+		t.Error("Expected a full scan not to be served the scan that skipped the Tornado Cash check")
+	}
+}
+
+func TestScanWallet_UsesCacheOnSecondCall(t *testing.T) {
+	// A chain with no Alchemy or Etherscan config scans instantly with no network calls
+	chain := ChainID("cachetest")
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	scanner := &Scanner{
+		clients: map[ChainID]*ChainClient{chain: NewChainClient(chain, "http://127.0.0.1:0", defaultLogger)},
+		cache:   NewCacheWithClock(time.Minute, clock),
+		clock:   clock,
+	}
+	wallet := "0x1234567890123456789012345678901234567890"
+
+	first, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.CacheHit {
+		t.Error("Expected first scan to miss the cache")
+	}
+
+	clock.Advance(30 * time.Second)
+	second, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !second.CacheHit {
+		t.Fatal("Expected second scan to be served from cache")
+	}
+	if second.CachedAt != first.CachedAt {
+		t.Errorf("Expected cachedAt %d, got %d", first.CachedAt, second.CachedAt)
+	}
+
+	refreshed, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if refreshed.CacheHit || refreshed.CachedAt != clock.Now().Unix() {
+		t.Errorf("Expected forced refresh to rescan, got cacheHit=%v cachedAt=%d", refreshed.CacheHit, refreshed.CachedAt)
+	}
+
+	clock.Advance(2 * time.Minute)
+	expired, _ := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	if expired.CacheHit {
+		t.Error("Expected cache entry to expire after TTL")
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              WALLET LABEL TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestLabelStore_ParseAndApply(t *testing.T) {
+	store := NewLabelStore()
+	err := store.Parse("0x742d35Cc6634C0532925a3b844Bc454e4438f44e:Founder Hot Wallet, 0x000000000000000000000000000000000000dEaD:Treasury")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if label, ok := store.Get("0x742D35CC6634C0532925A3B844BC454E4438F44E"); !ok || label != "Founder Hot Wallet" {
+		t.Errorf("Expected case-insensitive lookup of Founder Hot Wallet, got %q", label)
+	}
+
+	got := store.Apply("Revoke approvals to 0x000000000000000000000000000000000000dead now")
+	if got != "Revoke approvals to Treasury (0x0000...dead) now" {
+		t.Errorf("Unexpected labelled text: %s", got)
+	}
+
+	for _, spec := range []string{"0x742d:Short", "no-separator", "0x742d35Cc6634C0532925a3b844Bc454e4438f44e:"} {
+		if err := NewLabelStore().Parse(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestAnnotateChainApprovals_LabelKeepsSpenderUnknown(t *testing.T) {
+	spender := "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
+	original := walletLabels
+	walletLabels = NewLabelStore()
+	t.Cleanup(func() { walletLabels = original })
+	if err := walletLabels.Set(spender, "Founder Hot Wallet"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`)
+	}))
+	defer rpc.Close()
+
+	approvals := []Approval{{Chain: ChainID("cachetest"), TokenAddress: "0x6b175474e89094c44da98b954eedeac495271d0f", SpenderAddress: spender, SpenderName: "0x742d...f44e", TransferFromCount: -1}}
+	NewScanner().annotateChainApprovals(context.Background(), NewChainClient(ChainID("cachetest"), rpc.URL, defaultLogger), "0x1234567890123456789012345678901234567890", approvals)
+
+	if approvals[0].SpenderLabel != "Founder Hot Wallet" || approvals[0].SpenderName != "0x742d...f44e" {
+		t.Errorf("Expected the label beside the spender name, got label %q, name %q", approvals[0].SpenderLabel, approvals[0].SpenderName)
+	}
+	if !isUnknownSpender(approvals[0]) {
+		t.Errorf("Expected a labelled spender to stay unknown to the risk rules")
+	}
+}
+
+func TestDAOTreasury_GovernanceRecommendations(t *testing.T) {
+	treasury := "0x000000000000000000000000000000000000dEaD"
+	original := walletLabels
+	walletLabels = NewLabelStore()
+	t.Cleanup(func() { walletLabels = original })
+	if err := walletLabels.Parse(treasury + ":Protocol: Treasury:dao_treasury,0x742d35Cc6634C0532925a3b844Bc454e4438f44e:Founder"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if label, _ := walletLabels.Get(treasury); label != "Protocol: Treasury" || !isDAOTreasury(treasury) {
+		t.Fatalf("Expected dao_treasury type split off the label, got %q (%q)", label, walletLabels.Type(treasury))
+	}
+	if isDAOTreasury("0x742d35Cc6634C0532925a3b844Bc454e4438f44e") {
+		t.Errorf("Expected untyped label not to be a DAO treasury")
+	}
+
+	result := &WalletScanResult{
+		WalletAddress: treasury,
+		WalletLabel:   "Protocol: Treasury",
+		IsDAOTreasury: true,
+		CriticalRisks: 1,
+		Approvals: []Approval{
+			{Chain: Ethereum, TokenSymbol: "USDC", TokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+				SpenderName: "🚨 DRAINER: Pink Drainer", SpenderAddress: "0x5555555555555555555555555555555555555555",
+				AllowanceHuman: "Unlimited", RiskLevel: "critical", RiskReasons: []string{"Known drainer"}},
+			{Chain: Ethereum, TokenSymbol: "DAI", SpenderName: "✅ Aave V3: Pool", RiskLevel: "safe"},
+		},
+	}
+	NewScanner().generateRecommendations(result)
+
+	joined := strings.Join(result.Recommendations, "\n")
+	if !strings.Contains(joined, "Submit a governance proposal to revoke 1 critical approvals") || strings.Contains(joined, "immediately") {
+		t.Errorf("Expected governance recommendation instead of immediate revoke, got %v", result.Recommendations)
+	}
+	for _, want := range []string{"Protocol: Treasury (0x000000000000000000000000000000000000dEaD)", "USDC", "Pink Drainer", "Known drainer", "approve(spender, 0)"} {
+		if !strings.Contains(result.GovernanceProposalTemplate, want) {
+			t.Errorf("Expected proposal template to mention %q, got:\n%s", want, result.GovernanceProposalTemplate)
+		}
+	}
+	if strings.Contains(result.GovernanceProposalTemplate, "DAI") {
+		t.Errorf("Expected only critical approvals in the proposal, got:\n%s", result.GovernanceProposalTemplate)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              CRONOS TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestCronosKnownSpenders(t *testing.T) {
+	spenders := map[string]string{
+		"0x145863Eb42Cf62847A6Ca784e6416C1682b1b2Ae": "VVS Finance",
+		"0x145677FC4d9b8F19B5D56d1820c48e0443049a30": "MM Finance",
+	}
+
+	for address, protocol := range spenders {
+		name, riskLevel := getSpenderInfo(address)
+		if !strings.Contains(name, protocol) {
+			t.Errorf("Expected %s spender name, got %s", protocol, name)
+		}
+		if riskLevel != "safe" {
+			t.Errorf("Expected %s riskLevel safe, got %s", protocol, riskLevel)
+		}
+	}
+}
+
+func TestExplorerURL_CronosUsesCronoScan(t *testing.T) {
+	url, ok := explorerURL(Cronos, "module=logs&action=getLogs")
+	if !ok {
+		t.Fatal("Expected Cronos to have an explorer")
+	}
+	if !strings.HasPrefix(url, "https://api.cronoscan.com/api?module=logs&action=getLogs&apikey=") {
+		t.Errorf("Expected CronoScan URL, got %s", url)
+	}
+
+	url, ok = explorerURL(Ethereum, "module=logs")
+	if !ok || !strings.HasPrefix(url, "https://api.etherscan.io/v2/api?chainid=1&module=logs") {
+		t.Errorf("Expected Etherscan v2 URL for Ethereum, got %s", url)
+	}
+
+	if _, ok := explorerURL(ChainID("nochain"), "module=logs"); ok {
+		t.Error("Expected unknown chain to have no explorer")
+	}
+}
+
+func TestGetApprovalsEtherscan_NOTOKIsAnError(t *testing.T) {
+	originalDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = originalDelay })
+
+	tests := []struct {
+		name         string
+		response     string
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{"no records", `{"status":"0","message":"No records found","result":[]}`, false, 1},
+		{"rate limited", `{"status":"0","message":"NOTOK","result":"Max rate limit reached"}`, true, defaultRetryAttempts},
+		{"invalid key", `{"status":"0","message":"NOTOK","result":"Invalid API Key"}`, true, 1},
+		{"malformed logs", `{"status":"1","message":"OK","result":{"logs":1}}`, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Query().Get("topic0_1_opr") != "" {
+					fmt.Fprint(w, `{"status":"0","message":"No records found","result":[]}`)
+					return
+				}
+				attempts.Add(1)
+				fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			chainsMu.Lock()
+			etherscanConfig.Explorers[string(Ethereum)] = ExplorerConfig{URL: server.URL}
+			chainsMu.Unlock()
+			t.Cleanup(func() {
+				chainsMu.Lock()
+				delete(etherscanConfig.Explorers, string(Ethereum))
+				chainsMu.Unlock()
+			})
+
+			client := NewChainClient(Ethereum, server.URL, defaultLogger)
+			result, err := client.getApprovalsEtherscan(context.Background(), "0x1234567890123456789012345678901234567890", blockRange{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && (len(result.Approvals) != 0 || result.Incomplete) {
+				t.Errorf("Expected a complete empty result, got %+v", result)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, got)
+			}
+		})
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              BRIDGE REGISTRY TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestBridgeRegistryUpdater_Refresh(t *testing.T) {
+	opConfig := `name = "OP Mainnet"
+chain_id = 10
+
+[addresses]
+  L1StandardBridgeProxy = "0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1"
+  OptimismPortalProxy = "0xbEb5Fc579115071764c7423A4f12eDde41f106Ed"
+  SystemConfigProxy = "0x229047fed2591dbec1eF1118d64F7aF3dB9EB290"
+`
+	arbitrumNetworks := `const mainnetTokenBridge: TokenBridge = {
+  l1GatewayRouter: '0x72Ce9c846789fdB6fC1f34aC4AD25Dd9ef7031ef',
+  l2GatewayRouter: '0x5288c571Fd7aD117beA99bF60FE0846C4E84F933',
+  l1ERC20Gateway: '0xa3A7B6F88361F48403514059F1F16C8E78d60EeC',
+}
+const novaTokenBridge: TokenBridge = {
+  l1GatewayRouter: '0xC840838Bc438d73C16c2f8b22D2Ce3669963cD48',
+}`
+
+	failArbitrum := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/op.toml":
+			fmt.Fprint(w, opConfig)
+		case "/networks.ts":
+			if failArbitrum {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, arbitrumNetworks)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	store := NewDynamicSpenderStore()
+	updater := NewBridgeRegistryUpdater(store)
+	updater.sources = []bridgeSource{
+		{Name: "op", URL: server.URL + "/op.toml", Parse: superchainConfigParser("Optimism")},
+		{Name: "arbitrum", URL: server.URL + "/networks.ts", Parse: parseArbitrumTokenBridge},
+	}
+	updater.Refresh(context.Background())
+
+	expected := map[string]string{
+		"0x99c9fc46f92e8a1c0dec1b1747d010903e884be1": "✅ Optimism: L1 Standard Bridge",
+		"0xbeb5fc579115071764c7423a4f12edde41f106ed": "✅ Optimism: Optimism Portal",
+		"0x72ce9c846789fdb6fc1f34ac4ad25dd9ef7031ef": "✅ Arbitrum: L1 Gateway Router",
+		"0xa3a7b6f88361f48403514059f1f16c8e78d60eec": "✅ Arbitrum: L1 ERC20 Gateway",
+	}
+	for addr, want := range expected {
+		if name, ok := store.Get(addr); !ok || name != want {
+			t.Errorf("Expected %s to be %q, got %q", addr, want, name)
+		}
+	}
+	if store.Count() != len(expected) {
+		t.Errorf("Expected %d bridge addresses (no L2 or Nova contracts), got %d", len(expected), store.Count())
+	}
+
+	// A failing source keeps the addresses from its last successful fetch
+	failArbitrum = true
+	updater.Refresh(context.Background())
+	if _, ok := store.Get("0x72ce9c846789fdb6fc1f34ac4ad25dd9ef7031ef"); !ok {
+		t.Error("Expected Arbitrum addresses to survive a failed refresh")
+	}
+}
+
+func TestGetSpenderInfo_DynamicSpenders(t *testing.T) {
+	bridge := "0x1111111111111111111111111111111111111111"
+	if _, risk := getSpenderInfo(bridge); risk != "warning" {
+		t.Fatalf("Expected unregistered address to be a warning, got %s", risk)
+	}
+
+	original := dynamicSpenders
+	dynamicSpenders = NewDynamicSpenderStore()
+	t.Cleanup(func() { dynamicSpenders = original })
+
+	dynamicSpenders.Replace("test", map[string]string{bridge: "✅ Test: L1 Bridge"})
+	name, risk := getSpenderInfo(strings.ToUpper(bridge[:2]) + bridge[2:])
+	if name != "✅ Test: L1 Bridge" || risk != "safe" {
+		t.Errorf("Expected dynamic bridge to be trusted, got %q (%s)", name, risk)
+	}
+}
+
+func TestGetSpenderInfo_SanctionedAddresses(t *testing.T) {
+	if sanctionsList.Len() == 0 {
+		t.Fatal("Expected the bundled sanctions list to load")
+	}
+
+	// Tornado Cash router, on the SDN list; an admin override can't clear it
+	tornado := "0xd90e2f925DA726b50C4Ed8D0Fb90Ad053324F31b"
+	original := sanctionsList
+	sanctionsList = mustSanctionsChecker([]byte(`["` + strings.ToLower(tornado) + `"]`))
+	t.Cleanup(func() { sanctionsList = original })
+
+	riskOverrides.Set(RiskOverride{Address: strings.ToLower(tornado), RiskLevel: "safe"})
+	t.Cleanup(func() { riskOverrides.Delete(tornado) })
+	name, risk := getSpenderInfo(tornado)
+	if name != "🚨 OFAC SANCTIONED" || risk != "critical" {
+		t.Errorf("Expected sanctioned spender to be critical, got %q (%s)", name, risk)
+	}
+
+	sanctioned := sanctionedSpenders([]Approval{
+		{SpenderAddress: tornado},
+		{SpenderAddress: "0x1111111111111111111111111111111111111111"},
+		{SpenderAddress: strings.ToLower(tornado)},
+	})
+	if len(sanctioned) != 1 || sanctioned[0] != strings.ToLower(tornado) {
+		t.Errorf("Expected one sanctioned spender, got %v", sanctioned)
+	}
+
+	if _, err := NewSanctionsChecker([]byte(`["not-an-address"]`)); err == nil {
+		t.Error("Expected an invalid address to be rejected")
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              ALLOWANCE HISTORY TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestHandler_AllowanceHistory(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	spender := "0x1111111111111111111111111111111111111111"
+	usdt := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+
+	event := func(block, logIndex int, amount string) string {
+		return fmt.Sprintf(`{"address":"%s","topics":["%s","0x000000000000000000000000%s","0x000000000000000000000000%s"],"data":"0x%064s","blockNumber":"0x%x","transactionHash":"0x%064x","logIndex":"0x%x"}`,
+			usdt, approvalEventTopic, wallet[2:], spender[2:], amount, block, block, logIndex)
+	}
+
+	var filter map[string]interface{}
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case "eth_getLogs":
+			_ = json.Unmarshal(req.Params[0], &filter)
+			// approved 1M -> revoked -> re-approved unlimited, returned out of order
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[%s,%s,%s]}`,
+				event(300, 0, strings.Repeat("f", 64)), event(100, 2, "e8d4a51000"), event(200, 1, "0"))
+		case "eth_getBlockByNumber":
+			var block string
+			_ = json.Unmarshal(req.Params[0], &block)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"timestamp":"0x%x"}}`, 1_600_000_000+parseHexUint64(block))
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+		}
+	}))
+	defer rpc.Close()
+
+	originalEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{"ethereum": rpc.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = originalEndpoints })
+
+	server := NewServerWithScanner(newMockScanner(nil, nil), defaultLogger)
+	server.chainClients[Ethereum] = NewChainClient(Ethereum, rpc.URL, defaultLogger)
+
+	req := httptest.NewRequest("GET", "/api/v1/allowance/history?chain=ethereum&wallet="+wallet+"&token="+usdt+"&spender="+spender, nil)
+	w := httptest.NewRecorder()
+	server.handleAllowanceHistory(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var history AllowanceHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if filter["address"] != usdt {
+		t.Errorf("Expected logs filtered by token address, got %v", filter["address"])
+	}
+	if len(history.Events) != 3 {
+		t.Fatalf("Expected all 3 events including the revocation, got %d", len(history.Events))
+	}
+
+	wantRaw := []string{"1000000000000", "0", maxUint256.String()}
+	for i, ev := range history.Events {
+		if ev.BlockNumber != uint64(100*(i+1)) {
+			t.Errorf("Expected event %d at block %d, got %d", i, 100*(i+1), ev.BlockNumber)
+		}
+		if ev.AllowanceRaw != wantRaw[i] {
+			t.Errorf("Expected event %d allowance %s, got %s", i, wantRaw[i], ev.AllowanceRaw)
+		}
+		if ev.Timestamp != int64(1_600_000_000+ev.BlockNumber) {
+			t.Errorf("Expected event %d timestamp from its block, got %d", i, ev.Timestamp)
+		}
+		if ev.TxHash == "" {
+			t.Errorf("Expected event %d to have a tx hash", i)
+		}
+	}
+	if history.Events[0].AllowanceHuman != "1.00M" || !history.Events[2].IsUnlimited {
+		t.Errorf("Expected 1M then unlimited, got %+v", history.Events)
+	}
+}
+
+func TestHandler_AllowanceHistory_InvalidAddress(t *testing.T) {
+	server := NewServerWithScanner(newMockScanner(nil, nil), defaultLogger)
+
+	req := httptest.NewRequest("GET", "/api/v1/allowance/history?wallet=0x1234&token=0xdac17f958d2ee523a2206206994597c13d831ec7&spender=0x1111111111111111111111111111111111111111", nil)
+	w := httptest.NewRecorder()
+	server.handleAllowanceHistory(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              APPROVAL USAGE TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+func TestAnalyzeTransferFromUsage(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	token := "0x6b175474e89094c44da98b954eedeac495271d0f"
+	router := "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+	idle := "0x2222222222222222222222222222222222222222"
+
+	// Three outgoing transfers: two in transactions sent to the router, one sent by the wallet itself
+	txTargets := map[string]string{"0x01": router, "0x02": router, "0x03": token}
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case "eth_getLogs":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[`+
+				`{"address":"`+token+`","blockNumber":"0x10","transactionHash":"0x01","data":"0x1"},`+
+				`{"address":"`+token+`","blockNumber":"0x30","transactionHash":"0x02","data":"0x1"},`+
+				`{"address":"`+token+`","blockNumber":"0x20","transactionHash":"0x03","data":"0x1"}]}`)
+		case "eth_getBlockByNumber":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"timestamp":"0x6650a000"}}`)
+		case "eth_getTransactionByHash":
+			var hash string
+			_ = json.Unmarshal(req.Params[0], &hash)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"to":"%s"}}`, txTargets[hash])
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+		}
+	}))
+	defer rpc.Close()
+
+	originalEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{"ethereum": rpc.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = originalEndpoints })
+
+	approvals := []Approval{
+		{TokenAddress: token, SpenderAddress: router, TransferFromCount: -1},
+		{TokenAddress: token, SpenderAddress: idle, TransferFromCount: -1},
+	}
+	NewChainClient(Ethereum, rpc.URL, defaultLogger).analyzeTransferFromUsage(context.Background(), wallet, approvals)
+
+	if approvals[0].TransferFromCount != 2 || approvals[0].LastTransferFromBlock != 0x30 {
+		t.Errorf("Expected router to have 2 transfers, last at block 48, got %d at %d",
+			approvals[0].TransferFromCount, approvals[0].LastTransferFromBlock)
+	}
+	if approvals[0].LastTransferFromTime != 0x6650a000 {
+		t.Errorf("Expected the last transfer's block time, got %d", approvals[0].LastTransferFromTime)
+	}
+	if len(approvals[0].RiskReasons) != 0 {
+		t.Errorf("Expected no reasons for a used approval, got %v", approvals[0].RiskReasons)
+	}
+	lastTransfer := time.Unix(approvals[0].LastTransferFromTime, 0)
+	if !isRecentlyUsed(approvals[0], lastTransfer.Add(6*24*time.Hour)) || isRecentlyUsed(approvals[0], lastTransfer.Add(8*24*time.Hour)) {
+		t.Errorf("Expected the router to count as recently used for 7 days after its last transfer")
+	}
+
+	if approvals[1].TransferFromCount != 0 {
+		t.Errorf("Expected idle spender to have 0 transfers, got %d", approvals[1].TransferFromCount)
+	}
+	if len(approvals[1].RiskReasons) != 1 || approvals[1].RiskReasons[0] != neverUsedReason {
+		t.Errorf("Expected never-used reason, got %v", approvals[1].RiskReasons)
+	}
+	if !transferRecencyKnown(approvals[1]) || isRecentlyUsed(approvals[1], lastTransfer) {
+		t.Errorf("Expected an unused approval not to count as recently used")
+	}
+}
+
+func TestAnalyzeTransferFromUsage_UnknownWhenLogsUnavailable(t *testing.T) {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+	}))
+	defer rpc.Close()
+
+	originalEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{"cachetest": rpc.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = originalEndpoints })
+
+	approvals := []Approval{{TokenAddress: "0x6b175474e89094c44da98b954eedeac495271d0f", SpenderAddress: "0x2222222222222222222222222222222222222222", TransferFromCount: -1}}
+	NewChainClient(ChainID("cachetest"), rpc.URL, defaultLogger).analyzeTransferFromUsage(context.Background(), "0x1234567890123456789012345678901234567890", approvals)
+
+	if approvals[0].TransferFromCount != -1 || len(approvals[0].RiskReasons) != 0 {
+		t.Errorf("Expected usage to stay unknown, got count %d, reasons %v", approvals[0].TransferFromCount, approvals[0].RiskReasons)
+	}
+}
+
+func TestSpenderStats_ProbesContractTransactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		txs       int
+		wantCount int64
+		wantPages []string
+	}{
+		{"fresh", 0, 0, []string{"1"}},
+		{"used", 42, 1, []string{"1", "10000"}},
+		{"busy", 25000, busySpenderTxCount, []string{"1", "10000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var pages []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodGet {
+					query := r.URL.Query()
+					mu.Lock()
+					pages = append(pages, query.Get("page"))
+					mu.Unlock()
+					page, _ := strconv.Atoi(query.Get("page"))
+					if query.Get("offset") != "1" || page > tt.txs {
+						fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+						return
+					}
+					fmt.Fprint(w, `{"status":"1","message":"OK","result":[{"hash":"0x01"}]}`)
+					return
+				}
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x6080604052"}`)
+			}))
+			defer server.Close()
+
+			chainsMu.Lock()
+			etherscanConfig.Explorers[string(Ethereum)] = ExplorerConfig{URL: server.URL}
+			chainsMu.Unlock()
+			t.Cleanup(func() {
+				chainsMu.Lock()
+				delete(etherscanConfig.Explorers, string(Ethereum))
+				chainsMu.Unlock()
+			})
+
+			count, err := NewSpenderStatsEnricher(NewCache(time.Minute)).TxCount(context.Background(), NewChainClient(Ethereum, server.URL, defaultLogger), "0x2222222222222222222222222222222222222222")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if count != tt.wantCount || !slices.Equal(pages, tt.wantPages) {
+				t.Errorf("Expected count %d from pages %v, got %d from %v", tt.wantCount, tt.wantPages, count, pages)
+			}
+		})
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════════
+//                              TRACING TESTS
+// ═══════════════════════════════════════════════════════════════════════════════
+
+// useSpanRecorder routes SENTINEL spans into an in-memory recorder for the test
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	originalTracer, originalPropagator := tracer, otel.GetTextMapPropagator()
+	tracer = provider.Tracer("test")
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		tracer = originalTracer
+		otel.SetTextMapPropagator(originalPropagator)
+	})
+	return recorder
+}
+
+func TestTracingMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	recorder := useSpanRecorder(t)
+
+	var outgoing string
+	decompiler := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outgoing = r.Header.Get("traceparent")
+		fmt.Fprint(w, `{"success":true}`)
+	}))
+	defer decompiler.Close()
+
+	handler := tracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := &DecompilerClient{baseURL: decompiler.URL, client: http.DefaultClient}
+		_, _ = client.Analyze(r.Context(), []byte{0x60, 0x80})
+	}))
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/api/v1/analyze", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if got := w.Header().Get("X-Trace-ID"); got != traceID {
+		t.Errorf("Expected X-Trace-ID %s, got %q", traceID, got)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected request and decompiler spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		if span.SpanContext().TraceID().String() != traceID {
+			t.Errorf("Expected span %q to continue trace %s, got %s", span.Name(), traceID, span.SpanContext().TraceID())
+		}
+	}
+	if !strings.Contains(outgoing, traceID) {
+		t.Errorf("Expected traceparent %q to be forwarded to the decompiler", outgoing)
+	}
+}
+
+func TestScanWallet_SpanAttributes(t *testing.T) {
+	recorder := useSpanRecorder(t)
+
+	chain := ChainID("cachetest")
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	scanner := &Scanner{
+		clients: map[ChainID]*ChainClient{chain: NewChainClient(chain, "http://127.0.0.1:0", defaultLogger)},
+		cache:   NewCacheWithClock(time.Minute, clock),
+		clock:   clock,
+	}
+	wallet := "0x1234567890123456789012345678901234567890"
+	_, _ = scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	_, _ = scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+
+	var cacheHits []bool
+	var cacheEvents []string
+	for _, span := range recorder.Ended() {
+		if span.Name() != "Scanner.ScanWallet" {
+			continue
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == "cache_hit" {
+				cacheHits = append(cacheHits, attr.Value.AsBool())
+			}
+		}
+		for _, event := range span.Events() {
+			cacheEvents = append(cacheEvents, event.Name)
+		}
+	}
+	if len(cacheHits) != 2 || cacheHits[0] || !cacheHits[1] {
+		t.Errorf("Expected cache_hit false then true, got %v", cacheHits)
+	}
+	if !slices.Equal(cacheEvents, []string{"cache.miss", "cache.hit"}) {
+		t.Errorf("Expected cache.miss then cache.hit events, got %v", cacheEvents)
+	}
+}
+
+func TestScanWallet_IncrementalScan(t *testing.T) {
+	chain := ChainID("cachetest")
+	wallet := "0x1234567890123456789012345678901234567890"
+	tokenA := "0x6b175474e89094c44da98b954eedeac495271d0f"
+	tokenB := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	spender := "0x2222222222222222222222222222222222222222"
+
+	approvalLogJSON := func(token string, amount int64, block int) string {
+		return fmt.Sprintf(`{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0x%x"}`,
+			token, approvalEventTopic, padTopicAddress(wallet), padTopicAddress(spender), amount, block)
+	}
+
+	head := 100
+	var fromBlocks []string
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                   `json:"method"`
+			Params []map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == "eth_blockNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%x"}`, head)
+		case req.Method == "eth_getLogs" && req.Params[0]["address"] == nil && req.Params[0]["topics"].([]interface{})[0] == approvalEventTopic:
+			from := req.Params[0]["fromBlock"].(string)
+			fromBlocks = append(fromBlocks, from)
+			if from == "0x0" {
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[%s,%s]}`, approvalLogJSON(tokenA, 1000, 10), approvalLogJSON(tokenB, 2000, 20))
+				return
+			}
+			// New blocks revoke tokenA and raise tokenB
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[%s,%s]}`, approvalLogJSON(tokenA, 0, 120), approvalLogJSON(tokenB, 5000, 130))
+		case req.Method == "eth_getLogs":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[]}`)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+		}
+	}))
+	defer rpc.Close()
+
+	originalEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(chain): rpc.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = originalEndpoints })
+
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	scanner := &Scanner{
+		clients: map[ChainID]*ChainClient{chain: NewChainClient(chain, rpc.URL, defaultLogger)},
+		cache:   NewCacheWithClock(time.Minute, clock),
+		clock:   clock,
+		state:   NewScanStateStore("", 0),
+	}
+
+	first, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(first.Approvals) != 2 {
+		t.Fatalf("Expected 2 approvals after full scan, got %d", len(first.Approvals))
+	}
+
+	head = 150
+	clock.Advance(2 * time.Minute) // expire the result cache
+	second, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fromBlocks) != 2 || fromBlocks[1] != "0x65" {
+		t.Fatalf("Expected second scan to start at block 0x65, got %v", fromBlocks)
+	}
+	if len(second.Approvals) != 1 {
+		t.Fatalf("Expected revoked approval to be dropped, got %d approvals", len(second.Approvals))
+	}
+	if !strings.EqualFold(second.Approvals[0].TokenAddress, tokenB) || second.Approvals[0].AllowanceRaw != "5000" {
+		t.Errorf("Expected tokenB allowance updated to 5000, got %s %s", second.Approvals[0].TokenAddress, second.Approvals[0].AllowanceRaw)
+	}
+
+	// Nothing new since block 150: the stored state is served without querying logs
+	clock.Advance(2 * time.Minute)
+	if _, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fromBlocks) != 2 {
+		t.Errorf("Expected no log query without new blocks, got %v", fromBlocks)
+	}
+
+	if _, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fromBlocks) != 3 || fromBlocks[2] != "0x0" {
+		t.Errorf("Expected forceRefresh to rescan from block 0, got %v", fromBlocks)
+	}
+
+	// An incremental refresh bypasses the result cache but keeps the block cursor
+	head = 160
+	if _, err := scanner.ScanWallet(withIncrementalRefresh(context.Background()), wallet, []ChainID{chain}, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fromBlocks) != 4 || fromBlocks[3] != "0x97" {
+		t.Errorf("Expected an incremental refresh to start at block 0x97, got %v", fromBlocks)
+	}
+}
+
+func TestScanWallet_IncrementalScanConfirmsStoredAllowances(t *testing.T) {
+	chain := ChainID("confirmtest")
+	wallet := "0x1234567890123456789012345678901234567890"
+	token := "0x6b175474e89094c44da98b954eedeac495271d0f"
+	spender := "0x2222222222222222222222222222222222222222"
+
+	head := 100
+	allowance := "0x3e8"
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                   `json:"method"`
+			Params []map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == "eth_blockNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%x"}`, head)
+		case req.Method == "eth_getLogs" && req.Params[0]["address"] == nil && req.Params[0]["topics"].([]interface{})[0] == approvalEventTopic && req.Params[0]["fromBlock"] == "0x0":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0xa"}]}`,
+				token, approvalEventTopic, padTopicAddress(wallet), padTopicAddress(spender), 1000)
+		case req.Method == "eth_getLogs":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[]}`)
+		case req.Method == "alchemy_getTokenAllowance":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, allowance)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+		}
+	}))
+	defer rpc.Close()
+
+	originalEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(chain): rpc.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = originalEndpoints })
+
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	scanner := &Scanner{
+		clients: map[ChainID]*ChainClient{chain: NewChainClient(chain, rpc.URL, defaultLogger)},
+		cache:   NewCacheWithClock(time.Minute, clock),
+		clock:   clock,
+		state:   NewScanStateStore("", 0),
+	}
+
+	first, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(first.Approvals) != 1 {
+		t.Fatalf("Expected 1 approval after full scan, got %d", len(first.Approvals))
+	}
+
+	// transferFrom spends the allowance without an Approval event
+	allowance = "0x0"
+	head = 150
+	clock.Advance(2 * time.Minute) // expire the result cache
+	second, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{chain}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(second.Approvals) != 0 {
+		t.Errorf("Expected the spent allowance to be dropped by the incremental scan, got %+v", second.Approvals)
+	}
+}
+
+func TestScanStateStore_PersistsAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/scan-state.json"
+	wallet := "0x1234567890123456789012345678901234567890"
+
+	store := NewScanStateStore(path, 0)
+	store.Set(wallet, Ethereum, 19000000, []Approval{{
+		TokenAddress:   "0x6b175474e89094c44da98b954eedeac495271d0f",
+		SpenderAddress: "0x2222222222222222222222222222222222222222",
+		AllowanceRaw:   "1000",
+		RiskReasons:    []string{"Unlimited approval"},
+	}})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only scan-state.json after save, got %d files", len(entries))
+	}
+
+	restored := NewScanStateStore(path, 0)
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	approvals, lastBlock, ok := restored.Get(strings.ToUpper(wallet[:2])+wallet[2:], Ethereum)
+	if !ok || lastBlock != 19000000 {
+		t.Fatalf("Expected state at block 19000000, got %d (found %v)", lastBlock, ok)
+	}
+	if len(approvals) != 1 || approvals[0].AllowanceRaw != "1000" {
+		t.Errorf("Expected stored approval to round-trip, got %+v", approvals)
+	}
+
+	if err := NewScanStateStore(dir+"/missing.json", 0).Load(); err != nil {
+		t.Errorf("Expected missing state file to be ignored, got %v", err)
+	}
+}
+
+func TestScanStateStore_RetriesFailedSave(t *testing.T) {
+	dir := t.TempDir() + "/state"
+	store := NewScanStateStore(dir+"/scan-state.json", 0)
+	store.Set("0x1234567890123456789012345678901234567890", Ethereum, 100, nil)
+
+	if err := store.Save(); err == nil {
+		t.Fatal("Expected saving into a missing directory to fail")
+	}
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Nothing changed since, but the failed write is retried
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(dir + "/scan-state.json"); err != nil {
+		t.Errorf("Expected the state file after the retried save, got %v", err)
+	}
+}
+
+func TestScanStateStore_EvictsLeastRecentlyUpdated(t *testing.T) {
+	store := NewScanStateStore("", 2)
+	walletA := "0x1111111111111111111111111111111111111111"
+	walletB := "0x2222222222222222222222222222222222222222"
+	walletC := "0x3333333333333333333333333333333333333333"
+
+	store.Set(walletA, Ethereum, 100, nil)
+	store.Set(walletB, Ethereum, 100, nil)
+	store.Set(walletA, Polygon, 200, nil) // A is now the most recently updated
+	store.Set(walletC, Ethereum, 100, nil)
+
+	if _, _, ok := store.Get(walletB, Ethereum); ok {
+		t.Error("Expected the least recently updated wallet to be evicted")
+	}
+	for _, wallet := range []string{walletA, walletC} {
+		if _, _, ok := store.Get(wallet, Ethereum); !ok {
+			t.Errorf("Expected %s to be kept", wallet)
+		}
+	}
+}
+
+func TestFetchApprovals_ReResolvesStoredSpenders(t *testing.T) {
+	spender := "0x9999999999999999999999999999999999999999"
+	wallet := "0x1234567890123456789012345678901234567890"
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "eth_blockNumber" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x64"}`)
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"unsupported"}}`)
+	}))
+	defer rpc.Close()
+
+	scanner := &Scanner{clock: NewMockClock(time.Unix(1700000000, 0)), state: NewScanStateStore("", 0)}
+	scanner.state.Set(wallet, Ethereum, 100, []Approval{{
+		Chain: Ethereum, TokenAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", SpenderAddress: spender,
+		SpenderName: "✅ Old Protocol", RiskLevel: "safe",
+	}})
+
+	originalOverrides := riskOverrides
+	riskOverrides = NewRiskOverrideStore(NewMockClock(time.Unix(1700000000, 0)))
+	t.Cleanup(func() { riskOverrides = originalOverrides })
+	riskOverrides.Set(RiskOverride{Address: spender, RiskLevel: "critical", Reason: "drainer"})
+
+	result, err := scanner.fetchApprovals(context.Background(), NewChainClient(Ethereum, rpc.URL, defaultLogger), wallet, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Approvals) != 1 || result.Approvals[0].RiskLevel != "critical" || result.Approvals[0].SpenderName == "✅ Old Protocol" {
+		t.Errorf("Expected the stored approval re-resolved as critical, got %+v", result.Approvals)
+	}
+}
+
+func TestSuperchainBridge_CrossCheckedAgainstChain(t *testing.T) {
+	tokenBridge := "0x4200000000000000000000000000000000000028"
+	name, level := getSpenderInfo(tokenBridge)
+	if level != "safe" {
+		t.Fatalf("Expected Superchain Token Bridge to be a known spender, got %s (%s)", name, level)
+	}
+
+	result := &WalletScanResult{Approvals: []Approval{
+		{Chain: Base, TokenAddress: "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", SpenderAddress: tokenBridge, SpenderName: name, RiskLevel: level, AllowanceRaw: "1000"},
+		{Chain: BSC, TokenAddress: "0x55d398326f99059ff775485246999027b3197955", SpenderAddress: tokenBridge, SpenderName: name, RiskLevel: level, AllowanceRaw: "1000"},
+	}}
+	NewScanner().calculateRiskScores(result)
+
+	onBase, onBSC := result.Approvals[0], result.Approvals[1]
+	if onBase.RiskLevel != "safe" {
+		t.Errorf("Expected bridge approval on Base to stay safe, got %s", onBase.RiskLevel)
+	}
+	if !strings.Contains(strings.Join(onBase.RiskReasons, "\n"), "shared by Optimism, Base, Mode, Zora") {
+		t.Errorf("Expected shared Superchain bridge reason, got %v", onBase.RiskReasons)
+	}
+	if onBSC.RiskLevel != "warning" || !isUnknownSpender(onBSC) {
+		t.Errorf("Expected predeploy address on BSC to be scored as unknown, got %s (%s)", onBSC.SpenderName, onBSC.RiskLevel)
+	}
+
+	if info, ok := lookupBridge("0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1"); !ok || !info.IsSuperchainBridge || !info.deployedOn(Ethereum) {
+		t.Errorf("Expected Optimism Gateway to be the Superchain L1 Standard Bridge on Ethereum, got %+v", info)
+	}
+}
+
+func TestFetchTokenName_ExplorerAndOnChainFallback(t *testing.T) {
+	chain := ChainID("tokeninfotest")
+	listed := "0x1111111111111111111111111111111111111111"
+	unlisted := "0x2222222222222222222222222222222222222222"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet { // explorer API
+			if r.URL.Query().Get("contractaddress") == listed {
+				fmt.Fprint(w, `{"status":"1","message":"OK","result":[{"tokenName":"BNB: USD Value Locked in Protocol","symbol":"BNB","image":"https://example.com/bnb.png"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":"0","message":"NOTOK","result":"Invalid contract address"}`)
+			return
+		}
+		// eth_call name() returning the ABI-encoded string "Plain Token"
+		name := hex.EncodeToString([]byte("Plain Token"))
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x%064x%-64s"}`, 32, len("Plain Token"), name)
+	}))
+	defer server.Close()
+
+	chainsMu.Lock()
+	etherscanConfig.Explorers[string(chain)] = ExplorerConfig{URL: server.URL}
+	chainsMu.Unlock()
+	t.Cleanup(func() {
+		chainsMu.Lock()
+		delete(etherscanConfig.Explorers, string(chain))
+		chainsMu.Unlock()
+	})
+
+	client := NewChainClient(chain, server.URL, defaultLogger)
+	approvals := []Approval{{TokenAddress: listed}, {TokenAddress: unlisted}}
+	client.annotateTokenInfo(context.Background(), approvals)
+
+	if approvals[0].TokenName != "BNB: USD Value Locked in Prot…" || len([]rune(approvals[0].TokenName)) != 30 {
+		t.Errorf("Expected explorer name capped at 30 characters, got %q", approvals[0].TokenName)
+	}
+	if approvals[0].TokenLogoURL != "https://example.com/bnb.png" {
+		t.Errorf("Expected logo from the explorer, got %q", approvals[0].TokenLogoURL)
+	}
+
+	name, err := client.FetchTokenName(context.Background(), unlisted)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "Plain Token" || approvals[1].TokenName != "Plain Token" {
+		t.Errorf("Expected on-chain name() fallback, got %q / %q", name, approvals[1].TokenName)
+	}
+	if approvals[1].TokenLogoURL != "" {
+		t.Errorf("Expected no logo without explorer info, got %q", approvals[1].TokenLogoURL)
+	}
+}
+
+func TestContractClassifier_ClassifiesUnknownSpender(t *testing.T) {
+	router := "0x3333333333333333333333333333333333333333"
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var req struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var call struct {
+			Data string `json:"data"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch call.Data {
+		case "0xc45a0155": // factory() -> Uniswap V2 factory
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x0000000000000000000000005c69bee701ef814a2b6a3edd4b1652cb9cc5aa6f"}`)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewChainClient(Ethereum, server.URL, defaultLogger)
+	classifier := NewContractClassifier(NewCache(time.Minute))
+	approvals := []Approval{
+		{SpenderAddress: router, SpenderName: "0x3333...3333", RiskLevel: "warning"},
+		{SpenderAddress: "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45", SpenderName: "✅ Uniswap V3: Router 2", RiskLevel: "safe"},
+	}
+	classifier.ClassifyApprovals(context.Background(), client, approvals)
+
+	if approvals[0].SpenderCategory != "DEX Router" {
+		t.Errorf("Expected DEX Router category, got %q", approvals[0].SpenderCategory)
+	}
+	if len(approvals[0].RiskReasons) != 1 || !strings.Contains(approvals[0].RiskReasons[0], "DEX Router") {
+		t.Errorf("Expected classification reason, got %v", approvals[0].RiskReasons)
+	}
+	if approvals[1].SpenderCategory != "" {
+		t.Errorf("Expected known spenders to be left alone, got %q", approvals[1].SpenderCategory)
+	}
+
+	engine, err := NewRulesEngine("")
+	if err != nil {
+		t.Fatalf("Default rules failed to load: %v", err)
+	}
+	unclassified := approvals[0]
+	unclassified.SpenderCategory = ""
+	if got, base := engine.Evaluate(approvals[0]).RiskPoints, engine.Evaluate(unclassified).RiskPoints; got != base-5 {
+		t.Errorf("Expected classification to lower the score by 5, got %d vs %d", got, base)
+	}
+
+	probes := atomic.LoadInt32(&calls)
+	if classification := classifier.Classify(context.Background(), client, router); classification.Category != "DEX Router" {
+		t.Errorf("Expected cached DEX Router category, got %q", classification.Category)
+	}
+	if atomic.LoadInt32(&calls) != probes {
+		t.Errorf("Expected cached classification, got %d more RPC calls", atomic.LoadInt32(&calls)-probes)
+	}
+}
+
+func TestUniswapV4Hooks_FlagsUnauditedHooks(t *testing.T) {
+	wallet := "0x9999999999999999999999999999999999999999"
+	poolManager := "0x000000000004444c5dc75cb358380d2e3de08a90"
+	openPool := strings.Repeat("aa", 32)
+	closedPool := strings.Repeat("bb", 32)
+	hook := "0x12340000000000000000000000000000000000c0"
+	tick := func(v int64) string { return fmt.Sprintf("%064x", uint64(v)) }
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		if req.Method == "eth_getLogs" {
+			var filter struct {
+				Topics []*string `json:"topics"`
+			}
+			_ = json.Unmarshal(req.Params[0], &filter)
+			if len(filter.Topics) != 3 || filter.Topics[1] != nil || *filter.Topics[2] != padTopicAddress(wallet) {
+				t.Errorf("Expected ModifyLiquidity filter on sender only, got %s", req.Params[0])
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[
+				{"topics":["%s","0x%s","%s"],"data":"0x%s%s","blockNumber":"0x10"},
+				{"topics":["%s","0x%s","%s"],"data":"0x%s%s","blockNumber":"0x11"}]}`,
+				modifyLiquidityTopic, openPool, padTopicAddress(wallet), tick(-600), tick(600),
+				modifyLiquidityTopic, closedPool, padTopicAddress(wallet), tick(-60), tick(60))
+			return
+		}
+
+		var call struct {
+			Data string `json:"data"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+		switch {
+		case strings.HasPrefix(call.Data, "0x8737e63b"): // positions()
+			liquidity := 0
+			if strings.HasSuffix(call.Data, openPool+tick(-600)+tick(600)) {
+				liquidity = 1000
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x%064x%064x"}`, liquidity, 0, 0)
+		case call.Data == "0x3fb4c953"+openPool: // getHooks()
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, padTopicAddress(hook))
+		default:
+			t.Errorf("Unexpected eth_call %s", call.Data)
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`)
+		}
+	}))
+	defer rpc.Close()
+
+	originalEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{"ethereum": rpc.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = originalEndpoints })
+
+	client := NewChainClient(Ethereum, rpc.URL, defaultLogger)
+	approvals := []Approval{
+		{SpenderAddress: poolManager},
+		{SpenderAddress: "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"},
+	}
+	client.annotateUniswapV4Hooks(context.Background(), wallet, approvals)
+
+	if len(approvals[0].UniswapV4Hooks) != 1 || approvals[0].UniswapV4Hooks[0] != hook {
+		t.Fatalf("Expected hooks of the open pool only, got %v", approvals[0].UniswapV4Hooks)
+	}
+	if len(approvals[0].RiskReasons) != 1 || approvals[0].RiskReasons[0] != "⚠️ This pool uses unaudited hooks: 0x1234...00c0" {
+		t.Errorf("Expected unaudited hook reason, got %v", approvals[0].RiskReasons)
+	}
+	if approvals[1].UniswapV4Hooks != nil || approvals[1].RiskReasons != nil {
+		t.Errorf("Expected other spenders to be left alone, got %+v", approvals[1])
+	}
+
+	trustedHooks[hook] = "Test Hook"
+	t.Cleanup(func() { delete(trustedHooks, hook) })
+	trusted := []Approval{{SpenderAddress: poolManager}}
+	client.annotateUniswapV4Hooks(context.Background(), wallet, trusted)
+	if len(trusted[0].UniswapV4Hooks) != 1 || len(trusted[0].RiskReasons) != 0 {
+		t.Errorf("Expected trusted hook without a warning, got %+v", trusted[0])
+	}
+}
+
+func TestCheckPendleExpiry_FlagsMaturedPT(t *testing.T) {
+	router := "0x0000000001e4ef00d069e71d6ba041b0a16f7ea0"
+	maturedPT := "0x1111111111111111111111111111111111111111"
+	activePT := "0x2222222222222222222222222222222222222222"
+	plainToken := "0x3333333333333333333333333333333333333333"
+	now := int64(1_750_000_000)
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var call struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case call.To == plainToken:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`)
+		case call.Data == "0x781c18db": // YT()
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x"}`, 0xbeef)
+		case call.Data == "0xe184c9be" && call.To == maturedPT: // expiry()
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x"}`, now-86400)
+		case call.Data == "0xe184c9be":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x"}`, now+86400)
+		}
+	}))
+	defer rpc.Close()
+
+	client := NewChainClient(Ethereum, rpc.URL, defaultLogger)
+	approvals := []Approval{
+		{TokenAddress: maturedPT, SpenderAddress: router},
+		{TokenAddress: activePT, SpenderAddress: router},
+		{TokenAddress: plainToken, SpenderAddress: router},
+		{TokenAddress: maturedPT, SpenderAddress: "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"},
+	}
+	client.checkPendleExpiry(context.Background(), approvals, now)
+
+	if len(approvals[0].RiskReasons) != 1 || !strings.Contains(approvals[0].RiskReasons[0], "Pendle PT has expired") {
+		t.Errorf("Expected expired PT warning, got %v", approvals[0].RiskReasons)
+	}
+	for i, approval := range approvals[1:] {
+		if len(approval.RiskReasons) != 0 {
+			t.Errorf("Expected no warning for approval %d, got %v", i+1, approval.RiskReasons)
+		}
+	}
+	if name, level := getSpenderInfo(router); name != "✅ Pendle: Router" || level != "safe" {
+		t.Errorf("Expected Pendle Router to be a known safe spender, got %q (%s)", name, level)
+	}
+}
+
+func TestGetApprovals_ScansNFTApprovalForAll(t *testing.T) {
+	wallet := "0x9999999999999999999999999999999999999999"
+	collection := "0x1111111111111111111111111111111111111111"
+	revokedCollection := "0x2222222222222222222222222222222222222222"
+	operator := "0x5555555555555555555555555555555555555555"
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "eth_getLogs" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+			return
+		}
+
+		var filter struct {
+			Topics []string `json:"topics"`
+		}
+		_ = json.Unmarshal(req.Params[0], &filter)
+		logEntry := func(address, topic0, data string, block int, extraTopics ...string) string {
+			topics := append([]string{topic0, padTopicAddress(wallet), padTopicAddress(operator)}, extraTopics...)
+			encoded, _ := json.Marshal(topics)
+			return fmt.Sprintf(`{"address":"%s","topics":%s,"data":"%s","blockNumber":"0x%x","transactionHash":"0x%064x","logIndex":"0x0"}`,
+				address, encoded, data, block, block)
+		}
+		if filter.Topics[0] == approvalForAllTopic {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[%s,%s,%s]}`,
+				logEntry(collection, approvalForAllTopic, fmt.Sprintf("0x%064x", 1), 10),
+				logEntry(revokedCollection, approvalForAllTopic, fmt.Sprintf("0x%064x", 1), 11),
+				logEntry(revokedCollection, approvalForAllTopic, fmt.Sprintf("0x%064x", 0), 12))
+			return
+		}
+		// A single-token ERC721 Approval shares the ERC20 topic but carries a token id
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[%s]}`,
+			logEntry(collection, approvalEventTopic, "0x", 9, fmt.Sprintf("0x%064x", 42)))
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(Ethereum): node.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	client := NewChainClient(Ethereum, node.URL, defaultLogger)
+	result, err := client.getApprovals(context.Background(), wallet, blockRange{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Approvals) != 1 {
+		t.Fatalf("Expected 1 active NFT approval, got %+v", result.Approvals)
+	}
+	if len(result.Revoked) != 1 || result.Revoked[0] != approvalKey(revokedCollection, operator) {
+		t.Errorf("Expected revoked collection in %v", result.Revoked)
+	}
+
+	approval := result.Approvals[0]
+	if !approval.IsNFT || approval.TokenType != "ERC721" || !approval.IsUnlimited {
+		t.Errorf("Expected unlimited ERC721 NFT approval, got %+v", approval)
+	}
+	if approval.AllowanceHuman != "ALL TOKENS" {
+		t.Errorf("Expected ALL TOKENS allowance, got %q", approval.AllowanceHuman)
+	}
+
+	scanResult := &WalletScanResult{Approvals: result.Approvals}
+	NewScanner().calculateRiskScores(scanResult)
+	if got := scanResult.Approvals[0].RiskLevel; got != "critical" {
+		t.Errorf("Expected ApprovalForAll to unknown operator to be critical, got %s", got)
+	}
+}
+
+func TestGetApprovals_DetectsERC1155Collections(t *testing.T) {
+	wallet := "0x9999999999999999999999999999999999999999"
+	collection := "0x1155115511551155115511551155115511551155"
+	operator := "0x5555555555555555555555555555555555555555"
+
+	var symbolCalls int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		var call struct {
+			To     string   `json:"to"`
+			Data   string   `json:"data"`
+			Topics []string `json:"topics"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+		switch {
+		case req.Method == "eth_getLogs" && call.Topics[0] == approvalForAllTopic:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0xa","transactionHash":"0x%064x","logIndex":"0x0"}]}`,
+				collection, approvalForAllTopic, padTopicAddress(wallet), padTopicAddress(operator), 1, 1)
+		case req.Method == "eth_getLogs":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[]}`)
+		case call.Data == "0x01ffc9a7d9b67a26"+strings.Repeat("0", 56):
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x"}`, 1)
+		case call.Data == "0x06fdde03": // name()
+			name := hex.EncodeToString([]byte("Game Items"))
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x%064x%s"}`, 32, 10, name+strings.Repeat("0", 64-len(name)))
+		case call.Data == "0x95d89b41": // symbol()
+			atomic.AddInt32(&symbolCalls, 1)
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(Ethereum): node.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	result, err := NewChainClient(Ethereum, node.URL, defaultLogger).getApprovals(context.Background(), wallet, blockRange{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Approvals) != 1 {
+		t.Fatalf("Expected 1 ERC1155 approval, got %+v", result.Approvals)
+	}
+
+	approval := result.Approvals[0]
+	if approval.TokenType != "ERC1155" || !approval.IsNFT {
+		t.Errorf("Expected ERC1155 NFT approval, got %+v", approval)
+	}
+	if approval.TokenSymbol != "Game Items" {
+		t.Errorf("Expected name() fallback for symbol, got %q", approval.TokenSymbol)
+	}
+	if approval.AllowanceRaw != "ApprovalForAll" || approval.AllowanceHuman != "ApprovalForAll" {
+		t.Errorf("Expected ApprovalForAll allowance fields, got %q / %q", approval.AllowanceRaw, approval.AllowanceHuman)
+	}
+	if calls := atomic.LoadInt32(&symbolCalls); calls != 0 {
+		t.Errorf("Expected symbol() not to be called on ERC1155 contracts, got %d calls", calls)
+	}
+
+	// Unknown operators are critical even when the allowance doesn't read as unlimited
+	scanResult := &WalletScanResult{Approvals: []Approval{
+		{SpenderAddress: operator, SpenderName: "0x5555...5555", RiskLevel: "warning", IsNFT: true, TokenType: "ERC1155"},
+		{SpenderAddress: operator, SpenderName: "0x5555...5555", RiskLevel: "warning", TokenType: "ERC20", AllowanceRaw: "1000"},
+	}}
+	NewScanner().calculateRiskScores(scanResult)
+	if got := scanResult.Approvals[0].RiskLevel; got != "critical" {
+		t.Errorf("Expected NFT approval to unknown spender to be critical, got %s", got)
+	}
+	if got := scanResult.Approvals[1].RiskLevel; got != "warning" {
+		t.Errorf("Expected limited ERC20 approval to stay a warning, got %s", got)
+	}
+}
+
+func TestGetApprovals_IncludesPermit2Allowances(t *testing.T) {
+	wallet := "0x9999999999999999999999999999999999999999"
+	tokenA := "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	tokenB := "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	tokenC := "0xcccccccccccccccccccccccccccccccccccccccc"
+	spender := "0x5555555555555555555555555555555555555555"
+	future, past := time.Now().Add(30*24*time.Hour).Unix(), time.Now().Add(-time.Hour).Unix()
+
+	permit2Log := func(topic, token string, amount, expiration int64, block int) string {
+		return fmt.Sprintf(`{"address":"0x000000000022d473030f116ddee9f6b43ac78ba3","topics":["%s","%s","%s","%s"],"data":"0x%064x%064x","blockNumber":"0x%x","logIndex":"0x0"}`,
+			topic, padTopicAddress(wallet), padTopicAddress(token), padTopicAddress(spender), amount, expiration, block)
+	}
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "eth_getLogs" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+			return
+		}
+
+		var filter struct {
+			Address string   `json:"address"`
+			Topics  []string `json:"topics"`
+		}
+		_ = json.Unmarshal(req.Params[0], &filter)
+		switch {
+		case filter.Address == "" && filter.Topics[0] == approvalEventTopic:
+			// A direct ERC20 approval of the same pair as a Permit2 allowance
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0x5","logIndex":"0x0"}]}`,
+				tokenA, approvalEventTopic, padTopicAddress(wallet), padTopicAddress(spender), 500)
+		case filter.Topics[0] == permit2ApprovalTopic:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[%s,%s]}`,
+				permit2Log(permit2ApprovalTopic, tokenA, 1000, future, 10),
+				permit2Log(permit2ApprovalTopic, tokenB, 1000, past, 11))
+		case filter.Topics[0] == permit2PermitTopic:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[%s]}`, permit2Log(permit2PermitTopic, tokenC, 1000, future, 12))
+		case filter.Topics[0] == permit2LockdownTopic:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"0x000000000022d473030f116ddee9f6b43ac78ba3","topics":["%s","%s"],"data":"%s%s","blockNumber":"0xd","logIndex":"0x0"}]}`,
+				permit2LockdownTopic, padTopicAddress(wallet), padTopicAddress(tokenC), strings.TrimPrefix(padTopicAddress(spender), "0x"))
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[]}`)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(Ethereum): node.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	result, err := NewChainClient(Ethereum, node.URL, defaultLogger).GetApprovals(context.Background(), wallet)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Approvals) != 2 {
+		t.Fatalf("Expected the direct approval and one Permit2 allowance, got %+v", result.Approvals)
+	}
+
+	var permit2 *Approval
+	for i := range result.Approvals {
+		if result.Approvals[i].ViaPermit2 {
+			permit2 = &result.Approvals[i]
+		}
+	}
+	if permit2 == nil || !strings.EqualFold(permit2.TokenAddress, tokenA) || permit2.AllowanceRaw != "1000" {
+		t.Fatalf("Expected Permit2 allowance of 1000 on tokenA, got %+v", result.Approvals)
+	}
+	if permit2.ExpiresAt != future || permit2.IsUnlimited {
+		t.Errorf("Expected limited allowance expiring at %d, got %d (unlimited %v)", future, permit2.ExpiresAt, permit2.IsUnlimited)
+	}
+	for _, key := range []string{"permit2:" + approvalKey(tokenB, spender), "permit2:" + approvalKey(tokenC, spender)} {
+		if !slices.Contains(result.Revoked, key) {
+			t.Errorf("Expected %s in revoked keys %v", key, result.Revoked)
+		}
+	}
+
+	// Stored allowances are dropped once they expire, like zero allowances
+	merged := mergeApprovals(result.Approvals, nil, nil)
+	if got := dropExpiredApprovals(merged, future); len(got) != 1 || got[0].ViaPermit2 {
+		t.Errorf("Expected only the direct approval after expiry, got %+v", got)
+	}
+}
+
+func TestKnownMEVBots_NotScoredAsUnknown(t *testing.T) {
+	bot := "0x6B75d8AF000000e20B7a7DDf000Ba900b4009A80"
+	name, level := getSpenderInfo(bot)
+	if name != "⚠️ MEV Bot: Legitimate but monitor" || level != "warning" {
+		t.Fatalf("Expected MEV bot spender at warning, got %q (%s)", name, level)
+	}
+
+	approval := Approval{SpenderAddress: bot, SpenderName: name, RiskLevel: level}
+	if isUnknownSpender(approval) {
+		t.Errorf("Expected MEV bot not to count as an unknown spender")
+	}
+
+	engine, err := NewRulesEngine("")
+	if err != nil {
+		t.Fatalf("Default rules failed to load: %v", err)
+	}
+	unknown := Approval{SpenderAddress: "0x5555555555555555555555555555555555555555", SpenderName: "0x5555...5555", RiskLevel: "warning"}
+	if got, want := engine.Evaluate(approval).RiskPoints, engine.Evaluate(unknown).RiskPoints-10; got != want {
+		t.Errorf("Expected MEV bot to skip the unknown spender points (%d), got %d", want, got)
+	}
+
+	annotateMEVBot(&approval)
+	if len(approval.RiskReasons) != 1 || !strings.Contains(approval.RiskReasons[0], "Flashbots: MEV Bot") {
+		t.Errorf("Expected operator label in reasons, got %v", approval.RiskReasons)
+	}
+}
+
+func TestGenerateRecommendations_ChainMaturityNormalization(t *testing.T) {
+	approvalsOn := func(chain ChainID, n int) []Approval {
+		approvals := make([]Approval, n)
+		for i := range approvals {
+			approvals[i] = Approval{Chain: chain, SpenderName: "✅ Aave V3: Pool", RiskLevel: "safe"}
+		}
+		return approvals
+	}
+	recommends := func(result *WalletScanResult, text string) bool {
+		for _, rec := range result.Recommendations {
+			if strings.Contains(rec, text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// 25 approvals on Base (weight 0.3) stay under both adjusted thresholds
+	base := &WalletScanResult{Approvals: approvalsOn(Base, 25), TotalApprovals: 25}
+	NewScanner().generateRecommendations(base)
+	if recommends(base, "many active approvals") || recommends(base, "consolidating") {
+		t.Errorf("Expected no approval-count recommendations for 25 Base approvals, got %v", base.Recommendations)
+	}
+
+	ethereum := &WalletScanResult{Approvals: approvalsOn(Ethereum, 21), TotalApprovals: 21}
+	NewScanner().generateRecommendations(ethereum)
+	if !recommends(ethereum, "many active approvals") || !recommends(ethereum, "21 approvals on ethereum - consider consolidating") {
+		t.Errorf("Expected approval-count recommendations for 21 Ethereum approvals, got %v", ethereum.Recommendations)
+	}
+
+	t.Setenv("CHAIN_MATURITY_BASE", "1")
+	t.Setenv("CHAIN_MATURITY_SCROLL", "-2")
+	weights := initChainMaturityWeights()
+	if weights[Base] != 1 || weights[Scroll] != 0.25 || weights[Ethereum] != 1 {
+		t.Errorf("Expected CHAIN_MATURITY_BASE override and invalid values ignored, got base=%g scroll=%g", weights[Base], weights[Scroll])
+	}
+}
+
+func TestScanWallet_ParallelWithConcurrencyLimit(t *testing.T) {
+	wallet := "0x9999999999999999999999999999999999999999"
+	chains := []ChainID{Ethereum, Polygon, Arbitrum, Optimism, Base, BSC}
+
+	var inFlight, maxInFlight int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch req.Method {
+		case "eth_blockNumber":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x3e8"}`)
+		case "eth_getLogs":
+			var filter struct {
+				Address string   `json:"address"`
+				Topics  []string `json:"topics"`
+			}
+			_ = json.Unmarshal(req.Params[0], &filter)
+			if filter.Address != "" || filter.Topics[0] == approvalForAllTopic {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":[]}`)
+				return
+			}
+			current := atomic.AddInt32(&inFlight, 1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+
+			token := fmt.Sprintf("0x77777777777777777777777777777777777777%02x", len(r.URL.Path))
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","blockNumber":"0xa","transactionHash":"0x%064x","logIndex":"0x0"}]}`,
+				token, approvalEventTopic, padTopicAddress(wallet), padTopicAddress("0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"), 1000, 1)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	newScanner := func(opts ...Options) *Scanner {
+		scanner := NewScannerWithClock(NewMockClock(time.Unix(1700000000, 0)), opts...)
+		scanner.clients = map[ChainID]*ChainClient{}
+		for _, chain := range chains {
+			url := node.URL + "/" + string(chain)
+			alchemyConfig.Endpoints[string(chain)] = url
+			scanner.clients[chain] = NewChainClient(chain, url, defaultLogger)
+		}
+		return scanner
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Options
+		wantMax int32
+	}{
+		{"default of 4", nil, 4},
+		{"configured 2", []Options{{MaxConcurrency: 2}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&maxInFlight, 0)
+			result, err := newScanner(tt.opts...).ScanWallet(context.Background(), wallet, chains, true)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := atomic.LoadInt32(&maxInFlight); got != tt.wantMax {
+				t.Errorf("Expected %d chains scanned at once, got %d", tt.wantMax, got)
+			}
+			if len(result.Approvals) != len(chains) {
+				t.Fatalf("Expected one approval per chain, got %d", len(result.Approvals))
+			}
+			for i, chain := range chains {
+				if result.Approvals[i].Chain != chain {
+					t.Errorf("Expected approvals in chain order, got %s at %d", result.Approvals[i].Chain, i)
+				}
+				if stats := result.ChainScanStats[chain]; stats.Error != "" || stats.ApprovalsFound != 1 {
+					t.Errorf("Expected one approval on %s, got %+v", chain, stats)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucket_SpacesCalls(t *testing.T) {
+	bucket := newTokenBucket(20*time.Millisecond, 1)
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := bucket.Wait(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected 3 calls to take at least 40ms, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := newTokenBucket(time.Hour, 1).Wait(ctx); err != nil {
+		t.Errorf("Expected the first token without waiting, got %v", err)
+	}
+	drained := newTokenBucket(time.Hour, 1)
+	_ = drained.Wait(context.Background())
+	if err := drained.Wait(ctx); err == nil {
+		t.Errorf("Expected a cancelled context to stop the wait")
+	}
+}
+
+func TestExplorerLimiter_SharedPerAPIKey(t *testing.T) {
+	if explorerLimiter(Ethereum) != explorerLimiter(Polygon) {
+		t.Errorf("Expected Etherscan v2 chains to share the limiter of their API key")
+	}
+	if explorerLimiter(Cronos) == explorerLimiter(Ethereum) {
+		t.Errorf("Expected CronoScan to have its own limiter")
+	}
+	if etherscanCallInterval < 200*time.Millisecond {
+		t.Errorf("Expected at most 5 Etherscan calls per second by default, got one per %v", etherscanCallInterval)
+	}
+	if callInterval(10) != 100*time.Millisecond || callInterval(0) != 200*time.Millisecond {
+		t.Errorf("Expected 10/s = 100ms and an invalid limit to fall back to 5/s")
+	}
+}
+
+func TestScanHistory_RingBuffer(t *testing.T) {
+	history := NewScanHistory(3, 0)
+	for i := 1; i <= 5; i++ {
+		history.Add("0xABCDEF0123456789ABCDEF0123456789ABCDEF01", HistoryEntry{Timestamp: int64(i), OverallRiskScore: i * 10})
+	}
+
+	entries := history.Get("0xabcdef0123456789abcdef0123456789abcdef01")
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Timestamp != int64(i+3) {
+			t.Errorf("Expected entry %d to be scan %d, got %d", i, i+3, entry.Timestamp)
+		}
+	}
+
+	if entries := history.Get("0x0000000000000000000000000000000000000001"); entries == nil || len(entries) != 0 {
+		t.Errorf("Expected empty history for an unscanned wallet, got %v", entries)
+	}
+	if entries := NewScanHistory(0, 0).Get("0xabcdef0123456789abcdef0123456789abcdef01"); len(entries) != 0 {
+		t.Errorf("Expected empty history, got %v", entries)
+	}
+}
+
+func TestScanHistory_EvictsLeastRecentlyScannedWallet(t *testing.T) {
+	history := NewScanHistory(3, 2)
+	walletA := "0x000000000000000000000000000000000000000a"
+	walletB := "0x000000000000000000000000000000000000000b"
+	walletC := "0x000000000000000000000000000000000000000c"
+
+	history.Add(walletA, HistoryEntry{Timestamp: 1})
+	history.Add(walletB, HistoryEntry{Timestamp: 2})
+	history.Add(walletA, HistoryEntry{Timestamp: 3})
+	history.Add(walletC, HistoryEntry{Timestamp: 4})
+
+	if entries := history.Get(walletB); len(entries) != 0 {
+		t.Errorf("Expected the wallet scanned longest ago to be dropped, got %v", entries)
+	}
+	if len(history.Get(walletA)) != 2 || len(history.Get(walletC)) != 1 {
+		t.Errorf("Expected the recently scanned wallets to keep their history")
+	}
+}
+
+func TestPriceEnricher_GetUSDValue(t *testing.T) {
+	const priced = "0xabcdef0123456789abcdef0123456789abcdef01"
+	var requests atomic.Int32
+	prices := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/simple/token_price/ethereum" {
+			t.Errorf("Expected token_price request for ethereum, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("contract_addresses") == priced {
+			_, _ = w.Write([]byte(`{"` + priced + `":{"usd":1.5}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer prices.Close()
+
+	originalURL := coinGeckoBaseURL
+	coinGeckoBaseURL = prices.URL
+	t.Cleanup(func() { coinGeckoBaseURL = originalURL })
+
+	enricher := NewPriceEnricher(NewCache(time.Minute))
+	amount, _ := new(big.Int).SetString("2000000000000000000", 10)
+
+	// 2 tokens (18 decimals) at $1.50
+	for i := 0; i < 2; i++ {
+		value, err := enricher.GetUSDValue("0xABCDEF0123456789abcdef0123456789abcdef01", Ethereum, amount)
+		if err != nil {
+			t.Fatalf("Expected a price, got error: %v", err)
+		}
+		if diff := value - 3.0; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("Expected $3.00, got %v", value)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected the price to be cached after 1 request, got %d", n)
+	}
+
+	if _, err := enricher.GetUSDValue("0x1111111111111111111111111111111111111111", Ethereum, amount); err == nil {
+		t.Error("Expected an error for an unpriced token")
+	}
+
+	// Unpriced limited approvals keep AllowanceUSD -1; unlimited ones are not looked up
+	approvals := []Approval{
+		{TokenAddress: priced, Chain: Ethereum, AllowanceRaw: "2000000000000000000", AllowanceUSD: -1},
+		{TokenAddress: "0x2222222222222222222222222222222222222222", Chain: Ethereum, AllowanceRaw: "5", AllowanceUSD: -1},
+		{TokenAddress: "0x3333333333333333333333333333333333333333", Chain: Ethereum, AllowanceRaw: "5", AllowanceUSD: -1, IsUnlimited: true},
+	}
+	enricher.priceApprovals(approvals)()
+	if diff := approvals[0].AllowanceUSD - 3.0; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected priced approval worth $3.00, got %v", approvals[0].AllowanceUSD)
+	}
+	if approvals[1].AllowanceUSD != -1 || approvals[2].AllowanceUSD != -1 {
+		t.Errorf("Expected unpriced approvals to stay -1, got %v and %v", approvals[1].AllowanceUSD, approvals[2].AllowanceUSD)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("Expected 3 CoinGecko requests, got %d", n)
+	}
+}
+
+func TestNewCache_UsesRedisWhenConfigured(t *testing.T) {
+	t.Setenv("REDIS_URL", "")
+	if _, ok := NewCache(time.Minute).(*Cache); !ok {
+		t.Error("Expected an in-memory cache without REDIS_URL")
+	}
+
+	// Nothing listens on port 1: every call must fall through as a miss
+	t.Setenv("REDIS_URL", "redis://127.0.0.1:1/0")
+	cache, ok := NewCache(time.Minute).(*RedisCache)
+	if !ok {
+		t.Fatal("Expected a RedisCache with REDIS_URL set")
+	}
+
+	start := time.Now()
+	cache.Set("key", 42)
+	if _, found := cache.Get("key"); found {
+		t.Error("Expected a miss while Redis is unavailable")
+	}
+	if _, found := cache.Get("key"); found {
+		t.Error("Expected a miss while Redis is unavailable")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected unavailable Redis to fail fast, took %v", elapsed)
+	}
+}
+
+func TestRedisCache_EntriesRoundTrip(t *testing.T) {
+	result := &WalletScanResult{
+		WalletAddress:    "0x1234567890123456789012345678901234567890",
+		OverallRiskScore: 55,
+		Approvals:        []Approval{{TokenSymbol: "USDC", Chain: Ethereum, AllowanceUSD: 12.5}},
+	}
+	raw, ok := encodeCacheEntry(result)
+	if !ok {
+		t.Fatal("Expected WalletScanResult to be cacheable")
+	}
+	decoded, ok := decodeCacheEntry(raw)
+	if !ok {
+		t.Fatal("Expected the entry to decode")
+	}
+	hit, ok := decoded.(*WalletScanResult)
+	if !ok {
+		t.Fatalf("Expected *WalletScanResult, got %T", decoded)
+	}
+	if hit.OverallRiskScore != 55 || len(hit.Approvals) != 1 || hit.Approvals[0].AllowanceUSD != 12.5 {
+		t.Errorf("Expected the scan result to round-trip, got %+v", hit)
+	}
+
+	raw, _ = encodeCacheEntry(1.5)
+	if price, ok := decodeCacheEntry(raw); !ok || price.(float64) != 1.5 {
+		t.Errorf("Expected float64 1.5, got %v", price)
+	}
+
+	if _, ok := encodeCacheEntry(struct{ X int }{1}); ok {
+		t.Error("Expected unregistered types not to be cached")
+	}
+}
+
+func TestMetricsHandler_RequiresTokenAndExposesMetrics(t *testing.T) {
+	t.Setenv("METRICS_AUTH_TOKEN", "scrape-secret")
+
+	cache := NewCacheWithClock(time.Minute, RealClock{})
+	cache.Set("present", 1)
+	cache.Get("present")
+	cache.Get("absent")
+	activeScans.Inc()
+	defer activeScans.Dec()
+
+	w := httptest.NewRecorder()
+	metricsHandler()(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without token, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-secret")
+	w = httptest.NewRecorder()
+	metricsHandler()(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 with token, got %d", w.Code)
+	}
+	for _, metric := range []string{"sentinel_cache_hits_total", "sentinel_cache_misses_total", "sentinel_active_scans"} {
+		if !strings.Contains(w.Body.String(), metric) {
+			t.Errorf("Expected %s in metrics output", metric)
+		}
+	}
+}
+
+func TestLogger_WritesJSONLinesAtConfiguredLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLoggerTo(&buf, "warn").With(Fields{"chain": Ethereum})
+
+	logger.Info("Scanning approvals", Fields{"wallet": "0xabc"})
+	logger.Warn("Permit2 lookup failed", Fields{"wallet": "0xabc", "error": "timeout"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the warn line at level warn, got %d lines: %s", len(lines), buf.String())
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &line); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", lines[0], err)
+	}
+	for key, want := range map[string]string{"level": "warn", "msg": "Permit2 lookup failed", "chain": "ethereum", "wallet": "0xabc", "error": "timeout"} {
+		if line[key] != want {
+			t.Errorf("Expected %s=%q, got %v", key, want, line[key])
+		}
+	}
+	if _, ok := line["ts"]; !ok {
+		t.Error("Expected a ts field")
+	}
+
+	if body := truncateLogBody(bytes.Repeat([]byte("x"), 3000)); len(body) > maxLoggedBodyBytes+len("...(truncated)") {
+		t.Errorf("Expected debug bodies truncated to %d bytes, got %d", maxLoggedBodyBytes, len(body))
+	}
+}
+
+func TestCircuitBreaker_OpensProbesAndCloses(t *testing.T) {
+	clock := NewMockClock(time.Unix(1700000000, 0))
+	breaker := NewCircuitBreaker(3, 30*time.Second, clock)
+	failure := fmt.Errorf("502 bad gateway")
+
+	for i := 0; i < 3; i++ {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("Expected call %d to be allowed, got %v", i+1, err)
+		}
+		breaker.Record(failure)
+	}
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("Expected open after 3 failures, got %s", breaker.State())
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen while open, got %v", err)
+	}
+
+	// After the reset timeout a single probe goes through; its failure reopens the breaker
+	clock.Advance(30 * time.Second)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected a probe after the reset timeout, got %v", err)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected only one probe while half-open, got %v", err)
+	}
+	breaker.Record(failure)
+	clock.Advance(29 * time.Second)
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected a failed probe to restart the timer, got %v", err)
+	}
+
+	clock.Advance(time.Second)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected a second probe, got %v", err)
+	}
+	breaker.Record(nil)
+	if breaker.State() != CircuitClosed {
+		t.Errorf("Expected a successful probe to close the breaker, got %s", breaker.State())
+	}
+}
+
+func TestScanWallet_SkipsChainsWithOpenCircuit(t *testing.T) {
+	var requests atomic.Int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "eth_getLogs") {
+			requests.Add(1)
+		}
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer rpc.Close()
+
+	client := NewChainClient(Ethereum, rpc.URL, defaultLogger)
+	client.breaker = NewCircuitBreaker(1, time.Minute, RealClock{})
+	client.breaker.Record(fmt.Errorf("provider down"))
+
+	scanner := &Scanner{
+		clients: map[ChainID]*ChainClient{Ethereum: client},
+		cache:   NewCache(time.Minute),
+		clock:   RealClock{},
+	}
+	result, err := scanner.ScanWallet(context.Background(), "0x1234567890123456789012345678901234567890", []ChainID{Ethereum}, false)
+	if err != nil {
+		t.Fatalf("Expected an open circuit to be skipped, got error: %v", err)
+	}
+
+	stats := result.ChainScanStats[Ethereum]
+	if !stats.Skipped || stats.Error != "" {
+		t.Errorf("Expected ethereum skipped without error, got %+v", stats)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no eth_getLogs calls while the circuit is open, got %d", n)
+	}
+	if _, ok := scanner.cache.Get(scanCacheKey("0x1234567890123456789012345678901234567890", []ChainID{Ethereum}, false)); ok {
+		t.Error("Expected a scan with skipped chains not to be cached")
+	}
+}
+
+func TestWithRetry_BacksOffAndStopsOnPermanentErrors(t *testing.T) {
+	originalDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = originalDelay })
+
+	calls := 0
+	err := withRetry(context.Background(), 3, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("transient")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = withRetry(context.Background(), 3, func() error {
+		calls++
+		return noRetry(fmt.Errorf("bad request"))
+	})
+	if err == nil || err.Error() != "bad request" || calls != 1 {
+		t.Errorf("Expected a permanent error after 1 call, got %v after %d calls", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = withRetry(ctx, 3, func() error {
+		calls++
+		cancel()
+		return fmt.Errorf("transient")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("Expected ctx.Err() once the context ends, got %v after %d calls", err, calls)
+	}
+
+	for attempt, base := range []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond} {
+		if delay := retryDelay(attempt); delay < base*8/10 || delay > base*12/10 {
+			t.Errorf("Expected retry %d delay within 20%% of %v, got %v", attempt, base, delay)
+		}
+	}
+}
+
+func TestGetApprovalsAlchemy_RetriesRateLimits(t *testing.T) {
+	originalDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = originalDelay })
+
+	var calls atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[]}`))
+	}))
+	defer node.Close()
+
+	client := NewChainClient(Ethereum, node.URL, defaultLogger)
+	if _, err := client.getLogsAlchemy(context.Background(), node.URL, map[string]interface{}{}); err == nil {
+		t.Fatal("Expected the 429 to surface from a single call")
+	}
+	if _, err := client.getApprovalsAlchemy(context.Background(), "0x1234567890123456789012345678901234567890", node.URL, blockRange{}); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+
+	calls.Store(0)
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Count the ERC-20 Approval query only, not the ApprovalForAll one alongside it
+		if body, _ := io.ReadAll(r.Body); strings.Contains(string(body), approvalEventTopic) {
+			calls.Add(1)
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer forbidden.Close()
+	if _, err := client.getApprovalsAlchemy(context.Background(), "0x1234567890123456789012345678901234567890", forbidden.URL, blockRange{}); err == nil {
+		t.Error("Expected a 403 to fail")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected a 403 not to be retried, got %d calls", n)
+	}
+}
+
+func TestGetApprovalsAlchemy_SplitsRangesTooLarge(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	eventBlocks := []uint64{100, 4000}
+	var queries atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params []struct {
+				FromBlock string   `json:"fromBlock"`
+				ToBlock   string   `json:"toBlock"`
+				Topics    []string `json:"topics"`
+			} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "eth_blockNumber" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0xfff"}`)
+			return
+		}
+		queries.Add(1)
+		filter := req.Params[0]
+		from, to := parseHexUint64(filter.FromBlock), parseHexUint64(filter.ToBlock)
+		if filter.ToBlock == "latest" || to-from >= 1000 {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"query exceeds max block range 1000"}}`)
+			return
+		}
+		logs := []map[string]interface{}{}
+		for i, block := range eventBlocks {
+			if block >= from && block <= to && filter.Topics[0] == approvalEventTopic {
+				logs = append(logs, map[string]interface{}{
+					"address":     fmt.Sprintf("0x%040x", i+1),
+					"topics":      []string{approvalEventTopic, padTopicAddress(wallet), padTopicAddress("0x" + strings.Repeat("ab", 20))},
+					"data":        "0x" + abiUint(big.NewInt(1000)),
+					"blockNumber": fmt.Sprintf("0x%x", block),
+				})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": logs})
+	}))
+	defer node.Close()
+
+	client := NewChainClient(Ethereum, node.URL, defaultLogger)
+	result, err := client.getApprovalsAlchemy(context.Background(), wallet, node.URL, blockRange{})
+	if err != nil {
+		t.Fatalf("Expected the split queries to succeed, got %v", err)
+	}
+	if len(result.Approvals) != 2 {
+		t.Errorf("Expected the approvals of both halves of the range, got %d", len(result.Approvals))
+	}
+
+	// Invalid params unrelated to the range are not split
+	queries.Store(0)
+	invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid topic"}}`)
+	}))
+	defer invalid.Close()
+	if _, err := client.getLogsSplitting(context.Background(), invalid.URL, map[string]interface{}{}, blockRange{}); err == nil || queries.Load() != 1 {
+		t.Errorf("Expected one failed query, got %d queries, %v", queries.Load(), err)
+	}
+}
+
+func TestGetApprovalsAlchemyEndpoint_PrefersTokenAllowances(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	token := "0x" + strings.Repeat("11", 20)
+	spender := "0x" + strings.Repeat("ab", 20)
+	var allowanceCalls, logQueries atomic.Int32
+	var supported atomic.Bool
+	supported.Store(true)
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params []struct {
+				Account string   `json:"account"`
+				Topics  []string `json:"topics"`
+			} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "alchemy_getTokenAllowances":
+			allowanceCalls.Add(1)
+			if !supported.Load() {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`)
+				return
+			}
+			if req.Params[0].Account != wallet {
+				t.Errorf("Expected the wallet as account, got %q", req.Params[0].Account)
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"tokenAllowances":[
+				{"tokenAddress":"%s","spenderAddress":"%s","allowance":"0x3e8"},
+				{"tokenAddress":"%s","spenderAddress":"0x%s","allowance":"0"}]}}`, token, spender, token, strings.Repeat("cd", 20))
+		case "eth_getLogs":
+			logQueries.Add(1)
+			logs := []map[string]interface{}{}
+			if req.Params[0].Topics[0] == approvalEventTopic {
+				logs = append(logs, map[string]interface{}{
+					"address":     token,
+					"topics":      []string{approvalEventTopic, padTopicAddress(wallet), padTopicAddress(spender)},
+					"data":        "0x" + abiUint(big.NewInt(500)),
+					"blockNumber": "0x64",
+				})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": logs})
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"unsupported"}}`)
+		}
+	}))
+	defer node.Close()
+	t.Cleanup(func() { tokenAllowancesUnsupported.Delete(node.URL) })
+
+	client := NewChainClient(Ethereum, node.URL, defaultLogger)
+	result, err := client.getApprovalsAlchemyEndpoint(context.Background(), wallet, node.URL, blockRange{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Approvals) != 1 || result.Approvals[0].AllowanceRaw != "1000" || result.Approvals[0].SpenderAddress != spender {
+		t.Errorf("Expected the non-zero allowance from alchemy_getTokenAllowances, got %+v", result.Approvals)
+	}
+
+	// Chains without the method fall back to eth_getLogs, and stop asking
+	supported.Store(false)
+	for i := 0; i < 2; i++ {
+		result, err = client.getApprovalsAlchemyEndpoint(context.Background(), wallet, node.URL, blockRange{})
+		if err != nil || len(result.Approvals) != 1 || result.Approvals[0].AllowanceRaw != "500" {
+			t.Fatalf("Expected the approval from eth_getLogs, got %+v (%v)", result, err)
+		}
+	}
+	if allowanceCalls.Load() != 2 {
+		t.Errorf("Expected method-not-found to be remembered, got %d alchemy_getTokenAllowances calls", allowanceCalls.Load())
+	}
+
+	// Incremental scans replay the range's events
+	logQueries.Store(0)
+	tokenAllowancesUnsupported.Delete(node.URL)
+	supported.Store(true)
+	if _, err := client.getApprovalsAlchemyEndpoint(context.Background(), wallet, node.URL, blockRange{From: 50}); err != nil || allowanceCalls.Load() != 2 || logQueries.Load() == 0 {
+		t.Errorf("Expected an incremental scan to use eth_getLogs only, got %d allowance calls (%v)", allowanceCalls.Load(), err)
+	}
+}
+
+func TestGetApprovalsAlchemyEndpoint_ConfirmsCurrentAllowances(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	token := "0x" + strings.Repeat("11", 20)
+	spent := "0x" + strings.Repeat("ab", 20)
+	partial := "0x" + strings.Repeat("cd", 20)
+	var allowanceCalls atomic.Int32
+	var supported atomic.Bool
+	supported.Store(true)
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "alchemy_getTokenAllowances":
+			// Allowances are replayed from events on chains without the bulk method
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`)
+		case "alchemy_getTokenAllowance":
+			allowanceCalls.Add(1)
+			if !supported.Load() {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`)
+				return
+			}
+			var pair struct{ Contract, Owner, Spender string }
+			_ = json.Unmarshal(req.Params[0], &pair)
+			if pair.Contract != token || pair.Owner != wallet {
+				t.Errorf("Expected the token and wallet, got %+v", pair)
+			}
+			if pair.Spender == spent {
+				// Recorded alchemy_getTokenAllowance response
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0"}`)
+				return
+			}
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"250"}`)
+		case "eth_getLogs":
+			var filter struct {
+				Topics []interface{} `json:"topics"`
+			}
+			_ = json.Unmarshal(req.Params[0], &filter)
+			logs := []map[string]interface{}{}
+			if filter.Topics[0] == approvalEventTopic {
+				for i, spender := range []string{spent, partial} {
+					logs = append(logs, map[string]interface{}{
+						"address":         token,
+						"topics":          []string{approvalEventTopic, padTopicAddress(wallet), padTopicAddress(spender)},
+						"data":            "0x" + abiUint(big.NewInt(500)),
+						"blockNumber":     "0x64",
+						"transactionHash": fmt.Sprintf("0x%064x", i+1),
+					})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": logs})
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"unsupported"}}`)
+		}
+	}))
+	defer node.Close()
+	t.Cleanup(func() {
+		tokenAllowancesUnsupported.Delete(node.URL)
+		tokenAllowanceUnsupported.Delete(node.URL)
+	})
+
+	client := NewChainClient(Ethereum, node.URL, defaultLogger)
+	result, err := client.getApprovalsAlchemyEndpoint(context.Background(), wallet, node.URL, blockRange{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Approvals) != 1 {
+		t.Fatalf("Expected the spent allowance to be dropped, got %+v", result.Approvals)
+	}
+	if approval := result.Approvals[0]; approval.SpenderAddress != partial || approval.AllowanceRaw != "250" || approval.BlockNumber != 100 || approval.TxHash != fmt.Sprintf("0x%064x", 2) {
+		t.Errorf("Expected the current allowance with the event's block and transaction, got %+v", approval)
+	}
+
+	// Incremental scans replay the range's events only
+	allowanceCalls.Store(0)
+	if _, err := client.getApprovalsAlchemyEndpoint(context.Background(), wallet, node.URL, blockRange{From: 50}); err != nil || allowanceCalls.Load() != 0 {
+		t.Errorf("Expected an incremental scan not to confirm allowances, got %d calls (%v)", allowanceCalls.Load(), err)
+	}
+
+	// Endpoints without the method keep the event allowances, and stop being asked
+	supported.Store(false)
+	for i := 0; i < 2; i++ {
+		result, err = client.getApprovalsAlchemyEndpoint(context.Background(), wallet, node.URL, blockRange{})
+		if err != nil || len(result.Approvals) != 2 || result.Approvals[0].AllowanceRaw != "500" {
+			t.Fatalf("Expected the event allowances, got %+v (%v)", result, err)
+		}
+	}
+	if calls := allowanceCalls.Load(); calls == 0 || calls > maxAllowanceChecks {
+		t.Errorf("Expected method-not-found to be remembered, got %d alchemy_getTokenAllowance calls", calls)
+	}
+}
+
+func TestAnnotateBlockTimestamps_UsesTransactionBlockTimes(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	var headerLookups atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "alchemy_getAssetTransfers":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"transfers":[
+				{"hash":"0xAAAA","metadata":{"blockTimestamp":"2024-01-02T03:04:05.000Z"}}]}}`)
+		case "eth_getBlockByNumber":
+			headerLookups.Add(1)
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"timestamp":"0x6553f100"}}`)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(Ethereum): node.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	approvals := []Approval{
+		{TxHash: "0xaaaa", BlockNumber: 100},
+		{TxHash: "0xbbbb", BlockNumber: 200},
+		{TxHash: "0xcccc", BlockNumber: 200},
+		{TxHash: "0xdddd", BlockNumber: 300, LastUpdated: 42}, // from Etherscan
+	}
+	NewChainClient(Ethereum, node.URL, defaultLogger).annotateBlockTimestamps(context.Background(), wallet, approvals)
+
+	want := []int64{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Unix(), 0x6553f100, 0x6553f100, 42}
+	for i, approval := range approvals {
+		if approval.LastUpdated != want[i] {
+			t.Errorf("Expected approval %d to be dated %d, got %d", i, want[i], approval.LastUpdated)
+		}
+	}
+	if headerLookups.Load() != 1 {
+		t.Errorf("Expected one block header lookup per block, got %d", headerLookups.Load())
+	}
+}
+
+func TestVolumeAnalyzer_CountsTransfersPerSpender(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	token := "0x" + strings.Repeat("11", 20)
+	router := "0x" + strings.Repeat("ab", 20)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	var lookups atomic.Int32
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "alchemy_getAssetTransfers":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"transfers":[
+				{"hash":"0x01","rawContract":{"address":"%[1]s"},"metadata":{"blockTimestamp":"2025-05-30T00:00:00.000Z"}},
+				{"hash":"0x02","rawContract":{"address":"%[1]s"},"metadata":{"blockTimestamp":"2025-01-01T00:00:00.000Z"}},
+				{"hash":"0x03","rawContract":{"address":"0x%[2]s"},"metadata":{"blockTimestamp":"2025-05-31T00:00:00.000Z"}}]}}`,
+				token, strings.Repeat("22", 20))
+		case "eth_getTransactionByHash":
+			lookups.Add(1)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"to":"%s"}}`, strings.ToUpper(router))
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`)
+		}
+	}))
+	defer node.Close()
+
+	savedEndpoints := alchemyConfig.Endpoints
+	alchemyConfig.Endpoints = map[string]string{string(Ethereum): node.URL}
+	t.Cleanup(func() { alchemyConfig.Endpoints = savedEndpoints })
+
+	approvals := []Approval{
+		{TokenAddress: token, SpenderAddress: router, LastUpdated: now.AddDate(-3, 0, 0).Unix()},
+		{TokenAddress: token, SpenderAddress: "0x" + strings.Repeat("cd", 20), LastUpdated: now.AddDate(0, -3, 0).Unix()},
+		{TokenAddress: token, SpenderAddress: "0x" + strings.Repeat("ef", 20), LastUpdated: now.AddDate(0, 0, -5).Unix()},
+	}
+	// The second approval was already found never used by analyzeTransferFromUsage,
+	// whose lookup of 0x02 is reused
+	approvals[1].TransferFromCount, approvals[1].RiskReasons = 0, []string{neverUsedReason}
+	txTargets := map[string]string{"0x02": router}
+	NewVolumeAnalyzer(NewMockClock(now)).AnalyzeApprovals(context.Background(), NewChainClient(Ethereum, node.URL, defaultLogger), wallet, approvals, txTargets)
+	if lookups.Load() != 1 {
+		t.Errorf("Expected only the unseen transaction to be looked up, got %d lookups", lookups.Load())
+	}
+	if !slices.Equal(approvals[1].RiskReasons, []string{"Unused approval"}) {
+		t.Errorf("Expected Unused approval to replace the never-used reason, got %v", approvals[1].RiskReasons)
+	}
+
+	tests := []struct {
+		count      int
+		recent     bool
+		wantUnused bool
+	}{
+		{2, true, false},
+		{0, false, true},
+		{0, false, false},
+	}
+	for i, tt := range tests {
+		approval := approvals[i]
+		if !approval.volumeAnalyzed || approval.TransferCount != tt.count || approval.recentlyUsed != tt.recent {
+			t.Errorf("Expected approval %d to have %d transfers (recent=%v), got %d (recent=%v, analyzed=%v)",
+				i, tt.count, tt.recent, approval.TransferCount, approval.recentlyUsed, approval.volumeAnalyzed)
+		}
+		if unused := slices.Contains(approval.RiskReasons, "Unused approval"); unused != tt.wantUnused {
+			t.Errorf("Expected approval %d unused=%v, got reasons %v", i, tt.wantUnused, approval.RiskReasons)
+		}
+	}
+}
+
+func TestGetApprovalsSubgraph_MapsApprovalEvents(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	token := "0x" + strings.Repeat("11", 20)
+	spender := "0x" + strings.Repeat("ab", 20)
+	revokedSpender := "0x" + strings.Repeat("cd", 20)
+
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["owner"] != wallet || !strings.Contains(req.Query, "approvals(") {
+			t.Errorf("Unexpected query %q with %v", req.Query, req.Variables)
+		}
+		fmt.Fprintf(w, `{"data":{"approvals":[
+			{"id":"3","token":{"id":"%[1]s","symbol":"FTK"},"spender":"%[3]s","value":"0","transaction":{"timestamp":"1700000300"}},
+			{"id":"1","token":{"id":"%[1]s","symbol":"FTK"},"spender":"%[2]s","value":"1000","transaction":{"timestamp":"1700000100"}},
+			{"id":"2","token":{"id":"%[1]s","symbol":"FTK"},"spender":"%[3]s","value":"5","transaction":{"timestamp":"1700000200"}}]}}`,
+			token, spender, revokedSpender)
+	}))
+	defer graph.Close()
+
+	t.Setenv("SUBGRAPH_URL_FANTOM", graph.URL)
+	if urls := initSubgraphURLs(); urls[Fantom] != graph.URL {
+		t.Fatalf("Expected SUBGRAPH_URL_FANTOM to configure Fantom, got %v", urls)
+	}
+
+	client := NewChainClient(Fantom, "http://127.0.0.1:1", defaultLogger)
+	client.subgraph = NewSubgraphClient(graph.URL)
+	result, err := client.getApprovalsSubgraph(context.Background(), wallet)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Source != "subgraph" || len(result.Approvals) != 1 {
+		t.Fatalf("Expected one active approval from the subgraph, got %+v", result)
+	}
+	approval := result.Approvals[0]
+	if approval.SpenderAddress != spender || approval.AllowanceRaw != "1000" || approval.TokenSymbol != "FTK" || approval.LastUpdated != 1700000100 {
+		t.Errorf("Expected the event's spender, value, symbol and timestamp, got %+v", approval)
+	}
+	if want := approvalKey(token, revokedSpender); len(result.Revoked) != 1 || result.Revoked[0] != want {
+		t.Errorf("Expected %s to be revoked by its later zero approval, got %v", want, result.Revoked)
+	}
+}
+
+func TestSolanaClient_GetApprovalsReturnsDelegations(t *testing.T) {
+	wallet := "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	mint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	delegate := "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T"
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "getTokenAccountsByOwner" || len(req.Params) == 0 || req.Params[0] != wallet {
+			t.Errorf("Unexpected call %s %v", req.Method, req.Params)
+		}
+		program, _ := req.Params[1].(map[string]interface{})
+		if program["programId"] != "TokenkegQfeZyiNwAJbNbGWPFXCQw5Fd4z6g5SrJWxH" {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"value":[]}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"value":[
+			{"pubkey":"a1","account":{"data":{"parsed":{"info":{"mint":"%[1]s","delegate":"%[2]s","delegatedAmount":{"amount":"18446744073709551615","uiAmountString":"18446744073709.551615"}}}}}},
+			{"pubkey":"a2","account":{"data":{"parsed":{"info":{"mint":"%[1]s","delegate":"%[2]s","delegatedAmount":{"amount":"0","uiAmountString":"0"}}}}}},
+			{"pubkey":"a3","account":{"data":{"parsed":{"info":{"mint":"%[1]s"}}}}}]}}`, mint, delegate)
+	}))
+	defer rpc.Close()
+
+	result, err := NewSolanaClient(rpc.URL, defaultLogger).GetApprovals(context.Background(), wallet)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Source != "solana" || len(result.Approvals) != 1 {
+		t.Fatalf("Expected one delegation with a non-zero amount, got %+v", result)
+	}
+	approval := result.Approvals[0]
+	if approval.Chain != Solana || approval.TokenAddress != mint || approval.SpenderAddress != delegate || !approval.IsUnlimited {
+		t.Errorf("Expected an unlimited Solana delegation of the mint, got %+v", approval)
+	}
+
+	if chains := walletChains(wallet, AllChains); len(chains) != 1 || chains[0] != Solana {
+		t.Errorf("Expected a Solana wallet to default to the Solana chain only, got %v", chains)
+	}
+}
+
+func TestDiffApprovals_MatchesByChainTokenAndSpender(t *testing.T) {
+	kept := Approval{Chain: Ethereum, TokenAddress: "0xAA", SpenderAddress: "0xBB", RiskLevel: "safe"}
+	revoked := Approval{Chain: Ethereum, TokenAddress: "0xAA", SpenderAddress: "0xCC", RiskLevel: "high"}
+	otherChain := Approval{Chain: Polygon, TokenAddress: "0xAA", SpenderAddress: "0xBB", RiskLevel: "safe"}
+
+	rescored := kept
+	rescored.TokenAddress = "0xaa" // same token, different case
+	rescored.RiskLevel = "critical"
+	added := Approval{Chain: Base, TokenAddress: "0xAA", SpenderAddress: "0xBB", RiskLevel: "medium"}
+
+	diff := diffApprovals([]Approval{kept, revoked, otherChain}, []Approval{rescored, added}, []ChainID{Ethereum, Base})
+	if len(diff.NewApprovals) != 1 || diff.NewApprovals[0].Chain != Base {
+		t.Errorf("Expected the Base approval to be new, got %+v", diff.NewApprovals)
+	}
+	if len(diff.RevokedApprovals) != 1 || diff.RevokedApprovals[0].SpenderAddress != "0xCC" {
+		t.Errorf("Expected only the Ethereum 0xCC approval to be revoked (Polygon is not diffed), got %+v", diff.RevokedApprovals)
+	}
+	if len(diff.RiskLevelChanges) != 1 || diff.RiskLevelChanges[0].From != "safe" || diff.RiskLevelChanges[0].To != "critical" {
+		t.Errorf("Expected one safe -> critical change, got %+v", diff.RiskLevelChanges)
+	}
+}
+
+func TestTornadoCashChecker_FlagsWalletTransactions(t *testing.T) {
+	tornadoWallet := "0x1111111111111111111111111111111111111111"
+	cleanWallet := "0x2222222222222222222222222222222222222222"
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Query().Get("action") != "txlist" {
+			t.Errorf("Unexpected explorer query %s", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("address") == tornadoWallet {
+			fmt.Fprint(w, `{"status":"1","message":"OK","result":[{"to":"0x7a250d5630b4cf539739df2c5dacb4c659f2488d"},{"to":"0x910Cbd523D972eb0a6f4cAe4618aD62622b39DbF"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+	}))
+	defer server.Close()
+
+	chainsMu.Lock()
+	etherscanConfig.Explorers[string(Ethereum)] = ExplorerConfig{URL: server.URL}
+	chainsMu.Unlock()
+	t.Cleanup(func() {
+		chainsMu.Lock()
+		delete(etherscanConfig.Explorers, string(Ethereum))
+		chainsMu.Unlock()
+	})
+
+	checker := NewTornadoCashChecker(NewMockClock(time.Unix(1700000000, 0)))
+	client := NewChainClient(Ethereum, server.URL, defaultLogger)
+	for wallet, want := range map[string]bool{tornadoWallet: true, cleanWallet: false} {
+		if got, err := checker.HasInteracted(context.Background(), client, wallet); err != nil || got != want {
+			t.Errorf("Expected %s interacted=%v, got %v (%v)", wallet, want, got, err)
+		}
+	}
+	if _, err := checker.HasInteracted(context.Background(), client, tornadoWallet); err != nil || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected the repeated check to be cached, got %d explorer calls", calls)
+	}
+
+	scanner := NewScanner()
+	scanner.tornado = checker
+	result := &WalletScanResult{WalletAddress: tornadoWallet}
+	scanner.checkTornadoCash(withoutTornadoCheck(context.Background()), client, result)
+	if result.HasTornadoCashHistory {
+		t.Errorf("Expected skipTCCheck to skip the check")
+	}
+	scanner.checkTornadoCash(context.Background(), client, result)
+	scanner.generateRecommendations(result)
+	if !result.HasTornadoCashHistory || !slices.Contains(result.Recommendations, "⚠️ Wallet has interacted with Tornado Cash") {
+		t.Errorf("Expected the Tornado Cash flag and recommendation, got %v", result.Recommendations)
+	}
+}
+
+func TestSpenderStatsEnricher_CountsTransactions(t *testing.T) {
+	eoa := "0x1111111111111111111111111111111111111111"
+	busy := "0x2222222222222222222222222222222222222222"
+	fresh := "0x3333333333333333333333333333333333333333"
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			// Explorer txlist for contracts, one row per page: busy has 3 transactions
+			if page, _ := strconv.Atoi(r.URL.Query().Get("page")); r.URL.Query().Get("address") == busy && page <= 3 {
+				fmt.Fprint(w, `{"status":"1","message":"OK","result":[{"hash":"0x01"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":"0","message":"No transactions found","result":[]}`)
+			return
+		}
+
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case req.Method == "eth_getCode" && string(req.Params[0]) == `"`+eoa+`"`:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x"}`)
+		case req.Method == "eth_getCode":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x6080"}`)
+		case req.Method == "eth_getTransactionCount":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x2a"}`)
+		}
+	}))
+	defer server.Close()
+
+	chainsMu.Lock()
+	etherscanConfig.Explorers[string(Ethereum)] = ExplorerConfig{URL: server.URL}
+	chainsMu.Unlock()
+	t.Cleanup(func() {
+		chainsMu.Lock()
+		delete(etherscanConfig.Explorers, string(Ethereum))
+		chainsMu.Unlock()
+	})
+
+	client := NewChainClient(Ethereum, server.URL, defaultLogger)
+	enricher := NewSpenderStatsEnricher(NewCache(time.Minute))
+	approvals := []Approval{
+		{SpenderAddress: eoa, SpenderName: "0x1111...1111", RiskLevel: "warning"},
+		{SpenderAddress: busy, SpenderName: "0x2222...2222", RiskLevel: "warning"},
+		{SpenderAddress: fresh, SpenderName: "0x3333...3333", RiskLevel: "warning"},
+		{SpenderAddress: "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45", SpenderName: "✅ Uniswap V3: Router 2", RiskLevel: "safe"},
+	}
+	enricher.EnrichApprovals(context.Background(), client, approvals)
+
+	// Contracts only report whether they have transactions
+	for i, want := range []int64{42, 1, 0} {
+		if !approvals[i].txCounted || approvals[i].SpenderTxCount != want {
+			t.Errorf("Expected %s to have %d transactions, got %d (counted=%v)", approvals[i].SpenderAddress, want, approvals[i].SpenderTxCount, approvals[i].txCounted)
+		}
+	}
+	if approvals[3].txCounted {
+		t.Errorf("Expected known spenders to be left alone")
+	}
+
+	before := atomic.LoadInt32(&calls)
+	if count, err := enricher.TxCount(context.Background(), client, busy); err != nil || count != 1 || atomic.LoadInt32(&calls) != before {
+		t.Errorf("Expected the repeated count to be cached, got %d (%v)", count, err)
+	}
+}
+
+func TestChainalysisClient_ScreensAndCachesAddresses(t *testing.T) {
+	sanctioned := "0x1111111111111111111111111111111111111111"
+	clean := "0x2222222222222222222222222222222222222222"
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("X-API-Key") != "test-key" {
+			t.Errorf("Expected the API key header, got %q", r.Header.Get("X-API-Key"))
+		}
+		if strings.HasSuffix(r.URL.Path, sanctioned) {
+			fmt.Fprint(w, `{"identifications":[{"category":"sanctions","name":"SANCTIONS: OFAC SDN"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"identifications":[]}`)
+	}))
+	defer server.Close()
+
+	client := NewChainalysisClient(server.URL+"/api/v1/address/", "test-key", NewMockClock(time.Unix(1700000000, 0)))
+	scanner := NewScanner()
+	scanner.sanctions = client
+
+	approvals := []Approval{
+		{Chain: Ethereum, SpenderAddress: sanctioned, RiskLevel: "safe"},
+		{Chain: Polygon, SpenderAddress: strings.ToUpper(sanctioned[:2]) + sanctioned[2:], RiskLevel: "warning"},
+		{Chain: Ethereum, SpenderAddress: clean, RiskLevel: "safe"},
+	}
+	flagged := scanner.screenSpenders(context.Background(), approvals)
+	if !flagged[sanctioned] || flagged[clean] || approvals[0].RiskLevel != "critical" || approvals[2].RiskLevel != "safe" {
+		t.Fatalf("Expected only %s to be flagged critical, got %v / %+v", sanctioned, flagged, approvals)
+	}
+	if got := sanctionedSpenders(approvals); len(got) != 1 || got[0] != sanctioned {
+		t.Errorf("Expected the provider-flagged spender in sanctionedAddresses, got %v", got)
+	}
+	risks := sanctionedContractRisks(approvals, flagged, client.Name())
+	if len(risks) != 1 || !risks[0].IsSanctioned || risks[0].SanctionSource != "chainalysis" {
+		t.Errorf("Expected one chainalysis-sourced contract risk, got %+v", risks)
+	}
+
+	// Each address is screened once per hour
+	if _, err := client.IsSanctioned(context.Background(), clean); err != nil || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected cached results, got %d calls (%v)", calls, err)
+	}
+}
+
+func TestIdentityResolver_ResolvesLensAndFarcaster(t *testing.T) {
+	known := "0x1111111111111111111111111111111111111111"
+	unknown := "0x2222222222222222222222222222222222222222"
+	var lensCalls, neynarCalls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/lens" {
+			atomic.AddInt32(&lensCalls, 1)
+			var req struct {
+				Variables map[string]string `json:"variables"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Variables["address"] == known {
+				fmt.Fprint(w, `{"data":{"defaultProfile":{"handle":"alice.lens"}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"defaultProfile":null}}`)
+			return
+		}
+
+		atomic.AddInt32(&neynarCalls, 1)
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("Expected the Neynar API key header, got %q", r.Header.Get("x-api-key"))
+		}
+		if address := r.URL.Query().Get("addresses"); address == known {
+			fmt.Fprintf(w, `{"%s":[{"fid":3,"username":"alice"}]}`, known)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code":"NotFound","message":"No users found"}`)
+	}))
+	defer server.Close()
+
+	resolver := NewIdentityResolver(server.URL+"/lens", server.URL, "test-key", NewMockClock(time.Unix(1700000000, 0)))
+	if lens, farcaster := resolver.Resolve(context.Background(), known, defaultLogger); lens != "alice.lens" || farcaster != "alice" {
+		t.Errorf("Expected alice.lens and alice, got %q and %q", lens, farcaster)
+	}
+	if lens, farcaster := resolver.Resolve(context.Background(), unknown, defaultLogger); lens != "" || farcaster != "" {
+		t.Errorf("Expected no names, got %q and %q", lens, farcaster)
+	}
+	resolver.Resolve(context.Background(), strings.ToUpper(known[:2])+known[2:], defaultLogger)
+	if atomic.LoadInt32(&lensCalls) != 2 || atomic.LoadInt32(&neynarCalls) != 2 {
+		t.Errorf("Expected repeated lookups to be cached, got %d Lens and %d Neynar calls", lensCalls, neynarCalls)
+	}
+
+	encoded, err := json.Marshal(WalletIdentity{})
+	if err != nil || string(encoded) != `{"ensName":"","lensHandle":"","farcasterName":"","labels":[]}` {
+		t.Errorf("Expected an empty identity to have no nulls, got %s (%v)", encoded, err)
+	}
+}
+
+func TestScanWallet_MergesWalletIdentity(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	lens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"defaultProfile":{"handle":"scanner.lens"}}}`)
+	}))
+	defer lens.Close()
+
+	original := walletLabels
+	walletLabels = NewLabelStore()
+	t.Cleanup(func() { walletLabels = original })
+	if err := walletLabels.Set(wallet, "Founder Hot Wallet"); err != nil {
+		t.Fatalf("Failed to set label: %v", err)
+	}
+
+	scanner := NewScanner()
+	scanner.identity = NewIdentityResolver(lens.URL, "", "", RealClock{})
+	result, err := scanner.ScanWallet(context.Background(), wallet, []ChainID{Solana}, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Identity.LensHandle != "scanner.lens" || !slices.Equal(result.Identity.Labels, []string{"Founder Hot Wallet"}) {
+		t.Errorf("Expected the Lens handle and address book label, got %+v", result.Identity)
+	}
+	if result.WalletLabel != "Founder Hot Wallet" {
+		t.Errorf("Expected the wallet label to be kept, got %q", result.WalletLabel)
+	}
+}
+
+func TestLiquidityAnalyzer_ReportsPoolLocksAndConcentration(t *testing.T) {
+	token := "0x1111111111111111111111111111111111111111"
+	pair := "0x2222222222222222222222222222222222222222"
+	uniCrypt := "0x663a5c229c09b049e36dcc11a9b0d4a8eb9db214"
+	word := func(n uint64) string { return fmt.Sprintf("%064x", n) }
+	addressWord := func(address string) string { return strings.Repeat("0", 24) + strings.TrimPrefix(address, "0x") }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/simple/price"):
+			fmt.Fprint(w, `{"ethereum":{"usd":2000}}`)
+			return
+		case r.Method == http.MethodGet: // explorer: top holders own 900 of 1000
+			fmt.Fprint(w, `{"status":"1","message":"OK","result":[{"TokenHolderQuantity":"600"},{"TokenHolderQuantity":"300"}]}`)
+			return
+		}
+
+		var req struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var call struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+
+		result := "0x"
+		switch {
+		case call.To == token && call.Data == "0x18160ddd": // totalSupply
+			result += word(1000)
+		case strings.HasPrefix(call.Data, "0xe6a43905"): // getPair
+			result += addressWord(pair)
+		case call.To == pair && call.Data == "0x0902f1ac": // getReserves: token, then 5 WETH
+			result += word(1e19) + word(5e18) + word(0)
+		case call.To == pair && call.Data == "0x0dfe1681": // token0
+			result += addressWord(token)
+		case call.To == pair && call.Data == "0x70a08231"+addressWord(uniCrypt):
+			result += word(1)
+		case call.To == pair && strings.HasPrefix(call.Data, "0x70a08231"):
+			result += word(0)
+		case call.To == uniCrypt && strings.HasPrefix(call.Data, "0x1f2a1d2f"): // getNumLocksForToken
+			result += word(1)
+		case call.To == uniCrypt && strings.HasPrefix(call.Data, "0xccebfa3f"): // tokenLocks
+			result += word(1690000000) + word(1) + word(1) + word(1800000000) + word(7) + addressWord(token)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, result)
+	}))
+	defer server.Close()
+
+	originalURL := coinGeckoBaseURL
+	coinGeckoBaseURL = server.URL
+	t.Cleanup(func() { coinGeckoBaseURL = originalURL })
+	nativePriceCache = NewCache(10 * time.Minute)
+	chainsMu.Lock()
+	etherscanConfig.Explorers[string(Ethereum)] = ExplorerConfig{URL: server.URL}
+	chainsMu.Unlock()
+	t.Cleanup(func() {
+		chainsMu.Lock()
+		delete(etherscanConfig.Explorers, string(Ethereum))
+		chainsMu.Unlock()
+	})
+
+	analyzer := NewLiquidityAnalyzer(map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, server.URL, defaultLogger)})
+	risk, err := analyzer.Analyze(context.Background(), token, Ethereum)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !risk.HasLockedLiquidity || risk.LockExpiry != 1800000000 {
+		t.Errorf("Expected liquidity locked in UniCrypt until 1800000000, got %+v", risk)
+	}
+	if risk.PoolReserveUSD != 20000 {
+		t.Errorf("Expected a $20,000 pool (2 x 5 WETH x $2000), got %v", risk.PoolReserveUSD)
+	}
+	if risk.TopHolderConcentration != 90 {
+		t.Errorf("Expected top holders to own 90%%, got %v", risk.TopHolderConcentration)
+	}
+
+	// Locked liquidity is fine, the concentrated supply isn't
+	if vulnerabilities := risk.vulnerabilities(); len(vulnerabilities) != 1 || !strings.Contains(vulnerabilities[0], "90.0%") {
+		t.Errorf("Expected only the holder concentration to be flagged, got %v", vulnerabilities)
+	}
+	risk.HasLockedLiquidity = false
+	if vulnerabilities := risk.vulnerabilities(); len(vulnerabilities) != 2 {
+		t.Errorf("Expected unlocked liquidity to be flagged too, got %v", vulnerabilities)
+	}
+}
+
+func TestBytecodePatternMatcher_FindsExploitSequences(t *testing.T) {
+	// CALLDATALOAD (with a PUSH1 argument that must not be read as an opcode) then DELEGATECALL
+	hijack := []byte{0x60, 0x04, 0x35, 0x5a, 0xf4, 0x00}
+	if opcodes := disassemble(hijack); !slices.Equal(opcodes, []string{"PUSH1", "CALLDATALOAD", "0x5a", "DELEGATECALL", "STOP"}) {
+		t.Fatalf("Unexpected disassembly %v", opcodes)
+	}
+
+	matcher := NewBytecodePatternMatcher(bytecodePatternRules)
+	matches := matcher.Match(disassemble(hijack))
+	if len(matches) != 1 || matches[0].Rule.Name != "proxy hijack via calldata delegatecall" || matches[0].Offset != 1 {
+		t.Fatalf("Expected the proxy hijack pattern at instruction 1, got %+v", matches)
+	}
+
+	loop := []string{"JUMPDEST", "CALL", "POP", "SLOAD", "ADD", "SSTORE", "JUMP"}
+	if matches := matcher.Match(loop); len(matches) != 1 || matches[0].Rule.Name != "reentrancy via flash loan" {
+		t.Errorf("Expected the flash loan reentrancy pattern, got %+v", matches)
+	}
+
+	// Out of order or spread beyond the window
+	spread := []string{"CALLDATALOAD"}
+	for range 20 {
+		spread = append(spread, "POP")
+	}
+	spread = append(spread, "DELEGATECALL")
+	for _, opcodes := range [][]string{{"SSTORE", "SLOAD", "CALL", "JUMP"}, spread} {
+		if matches := matcher.Match(opcodes); len(matches) != 0 {
+			t.Errorf("Expected no match in %v, got %+v", opcodes, matches)
+		}
+	}
+
+	custom := NewBytecodePatternMatcher([]PatternRule{{Name: "self destruct", Sequence: []string{"SELFDESTRUCT"}, Severity: "warning", Window: 1}})
+	if matches := custom.Match([]string{"CALLER", "SELFDESTRUCT"}); len(matches) != 1 || !strings.Contains(matches[0].vulnerability(), "self destruct") {
+		t.Errorf("Expected a custom rule to match, got %+v", matches)
+	}
+}
+
+func TestHasSelfDestruct_SkipsPushDataAndMetadata(t *testing.T) {
+	cases := []struct {
+		name     string
+		bytecode []byte
+		want     bool
+	}{
+		{"CALLER SELFDESTRUCT", []byte{0x33, 0xff}, true},
+		{"0xff as PUSH1 data", []byte{0x60, 0xff, 0x50, 0x00}, false},
+		{"0xff bytes of a PUSH32 max uint", append(append([]byte{0x7f}, bytes.Repeat([]byte{0xff}, 32)...), 0x00), false},
+		// INVALID, then a one-entry CBOR map of 0xff bytes and its 2-byte length
+		{"0xff in the metadata trailer", []byte{0x00, 0xfe, 0xa1, 0xff, 0xff, 0x00, 0x03}, false},
+		{"SELFDESTRUCT after PUSH2", []byte{0x61, 0xff, 0xff, 0x33, 0xff}, true},
+	}
+	for _, tc := range cases {
+		if got := hasSelfDestruct(tc.bytecode); got != tc.want {
+			t.Errorf("%s: expected hasSelfDestruct %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestChecksumAddress_EIP55Vectors(t *testing.T) {
+	// From the EIP-55 specification
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		got, err := ChecksumAddress(strings.ToLower(want))
+		if err != nil || got != want {
+			t.Errorf("Expected checksum %s, got %s (%v)", want, got, err)
+		}
+		if normalized, err := NormalizeAddress(want); err != nil || normalized != strings.ToLower(want) {
+			t.Errorf("Expected %s to normalize to lowercase, got %s (%v)", want, normalized, err)
+		}
+	}
+
+	if normalized, err := NormalizeAddress("0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"); err != nil || normalized != "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed" {
+		t.Errorf("Expected an all-uppercase address to be accepted, got %s (%v)", normalized, err)
+	}
+	for _, bad := range []string{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", "0xZaAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"} {
+		if _, err := NormalizeAddress(bad); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+
+	server := NewServer(defaultLogger)
+	w := httptest.NewRecorder()
+	server.handleScan(w, httptest.NewRequest("GET", "/api/v1/scan?wallet=0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", nil))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_address_checksum") {
+		t.Errorf("Expected 400 for a bad checksum, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestIDMiddleware_PropagatesHeaderAndLogs(t *testing.T) {
+	var forwarded string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Get("X-Request-ID")
+	}))
+	defer upstream.Close()
+
+	var buf bytes.Buffer
+	logger := newLoggerTo(&buf, "debug")
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestLogger(r.Context(), logger).Info("Handling request", nil)
+		req, _ := http.NewRequestWithContext(r.Context(), "POST", upstream.URL, nil)
+		setRequestIDHeader(r.Context(), req)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}))
+
+	req := httptest.NewRequest("GET", "/api/v1/scan", nil)
+	req.Header.Set("X-Request-ID", "client-42")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if got := w.Header().Get("X-Request-ID"); got != "client-42" {
+		t.Errorf("Expected the client's request ID to be echoed, got %q", got)
+	}
+	if forwarded != "client-42" {
+		t.Errorf("Expected the request ID to be forwarded upstream, got %q", forwarded)
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &line); err != nil || line["request_id"] != "client-42" {
+		t.Errorf("Expected request_id=client-42 in the log line, got %s", buf.String())
+	}
+
+	for _, header := range []string{"", "bad id\nwith newline", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest("GET", "/api/v1/scan", nil)
+		req.Header.Set("X-Request-ID", header)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		got := w.Header().Get("X-Request-ID")
+		if got == "" || got == header || forwarded != got {
+			t.Errorf("Expected %q to be replaced by a generated ID, got %q (forwarded %q)", header, got, forwarded)
+		}
+	}
+}
+func TestNewRPCTransport_PoolsConnectionsPerHost(t *testing.T) {
+	transport := newRPCTransport()
+	if transport.MaxIdleConnsPerHost != 20 || transport.IdleConnTimeout != 90*time.Second || transport.TLSHandshakeTimeout != 10*time.Second {
+		t.Errorf("Expected 20 idle conns per host, 90s idle and 10s TLS timeouts, got %d, %v, %v",
+			transport.MaxIdleConnsPerHost, transport.IdleConnTimeout, transport.TLSHandshakeTimeout)
+	}
+
+	t.Setenv("RPC_MAX_IDLE_CONNS", "7")
+	t.Setenv("RPC_IDLE_CONN_TIMEOUT", "45s")
+	transport = newRPCTransport()
+	if transport.MaxIdleConnsPerHost != 7 || transport.IdleConnTimeout != 45*time.Second {
+		t.Errorf("Expected env overrides 7 and 45s, got %d and %v", transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+
+	ethereum := NewChainClient(Ethereum, "http://localhost:1", defaultLogger)
+	polygon := NewChainClient(Polygon, "http://localhost:2", defaultLogger)
+	if ethereum.client.Transport != polygon.client.Transport {
+		t.Errorf("Expected chain clients to share one transport")
+	}
+	if NewDecompilerClient(defaultLogger).client.Transport == ethereum.client.Transport {
+		t.Errorf("Expected the decompiler to use a separate transport")
+	}
+	if NewDecompilerClient(defaultLogger).client.Transport != NewAnalyzerClient(defaultLogger).client.Transport {
+		t.Errorf("Expected the decompiler and analyzer to share a transport")
+	}
+}
+
+func TestIPFilter_AllowlistBlocklistAndForwardedHop(t *testing.T) {
+	request := func(filter *IPFilter, remote, forwarded string) *httptest.ResponseRecorder {
+		handler := filter.Middleware(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scan", nil)
+		req.RemoteAddr = remote
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	if rec := request(NewIPFilter("", "", ""), "203.0.113.9:1234", ""); rec.Code != http.StatusOK || rec.Header().Get("X-IP-Filter") != "bypassed" {
+		t.Errorf("Expected no lists to bypass the filter, got %d %q", rec.Code, rec.Header().Get("X-IP-Filter"))
+	}
+
+	filter := NewIPFilter("10.0.0.0/8, 192.168.1.0/24, not-a-cidr", "10.0.0.66", "172.16.0.0/12")
+	cases := []struct {
+		remote, forwarded string
+		code              int
+		header            string
+	}{
+		{"10.1.2.3:1234", "", http.StatusOK, "allowed"},
+		{"192.168.1.7:1234", "", http.StatusOK, "allowed"},
+		{"203.0.113.9:1234", "", http.StatusForbidden, "blocked"},
+		{"10.0.0.66:1234", "", http.StatusForbidden, "blocked"},
+		// The proxy's hop decides; spoofed earlier entries don't
+		{"172.16.0.1:1234", "10.0.0.5, 203.0.113.9", http.StatusForbidden, "blocked"},
+		{"172.16.0.1:1234", "203.0.113.9, 10.0.0.5", http.StatusOK, "allowed"},
+		{"172.16.0.1:1234", "10.0.0.5, 172.16.0.2", http.StatusOK, "allowed"},
+		// Only trusted proxies may set X-Forwarded-For
+		{"203.0.113.9:1234", "10.0.0.5", http.StatusForbidden, "blocked"},
+		{"10.1.2.3:1234", "10.0.0.66", http.StatusOK, "allowed"},
+	}
+	for _, c := range cases {
+		rec := request(filter, c.remote, c.forwarded)
+		if rec.Code != c.code || rec.Header().Get("X-IP-Filter") != c.header {
+			t.Errorf("Expected %s (X-Forwarded-For %q) to get %d %s, got %d %q", c.remote, c.forwarded, c.code, c.header, rec.Code, rec.Header().Get("X-IP-Filter"))
+		}
+	}
+
+	if rec := request(NewIPFilter("not-a-cidr", "", ""), "10.1.2.3:1234", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected an allowlist with no valid entries to fail closed, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_UsesClientBehindTrustedProxy(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, NewMockClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	var watchClients []string
+	handler := NewIPFilter("", "", "172.16.0.0/12").Middleware(limiter.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		watchClients = append(watchClients, watchClient(r))
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(forwarded string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scan", nil)
+		req.RemoteAddr = "172.16.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	// Clients behind the same proxy have their own buckets
+	if request("203.0.113.9") != http.StatusOK || request("198.51.100.7") != http.StatusOK {
+		t.Fatal("Expected each client behind the proxy to get its own limit")
+	}
+	if request("203.0.113.9") != http.StatusTooManyRequests {
+		t.Error("Expected the client's second request to be limited")
+	}
+	if !slices.Equal(watchClients, []string{"ip:203.0.113.9", "ip:198.51.100.7"}) {
+		t.Errorf("Expected watch connections to count against the forwarded client, got %v", watchClients)
+	}
+}
+
+func TestRateLimiter_LimitsPerIPAndExemptsHealth(t *testing.T) {
+	clock := NewMockClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := NewRateLimiter(2, 3, clock)
+	handler := limiter.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(path, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := request("/api/v1/scan", "10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected burst request %d to pass, got %d", i, rec.Code)
+		}
+	}
+	rec := request("/api/v1/scan", "10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the burst is spent, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "0.500" {
+		t.Errorf("Expected Retry-After 0.500, got %q", got)
+	}
+	if rec := request("/api/v1/scan", "10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another IP to have its own bucket, got %d", rec.Code)
+	}
+	for _, path := range []string{"/health", "/metrics"} {
+		if rec := request(path, "10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Errorf("Expected %s to be exempt, got %d", path, rec.Code)
+		}
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if rec := request("/api/v1/scan", "10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected a refilled token after 500ms, got %d", rec.Code)
+	}
+
+	clock.Advance(6 * time.Minute)
+	request("/api/v1/scan", "10.0.0.3:1234")
+	buckets := 0
+	limiter.buckets.Range(func(_, _ interface{}) bool { buckets++; return true })
+	if buckets != 1 {
+		t.Errorf("Expected idle buckets to be dropped, got %d buckets", buckets)
+	}
+}
+
+func TestAuthMiddleware_ChecksHashedAndScopedKeys(t *testing.T) {
+	hash := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+	wallet := "0x1234567890123456789012345678901234567890"
+	saved := apiKeys
+	apiKeys = loadAPIKeys(hash("open-key") + "," + hash("scoped-key") + ":wallet:" + wallet + ",not-a-hash")
+	t.Cleanup(func() { apiKeys = saved })
+
+	handler := AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := request("/api/v1/scan?wallet="+wallet, "")
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), `"error"`) {
+		t.Errorf("Expected 401 with a JSON error without a key, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := request("/api/v1/scan?wallet="+wallet, "wrong-key"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown key, got %d", rec.Code)
+	}
+	if rec := request("/health", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected /health to stay public, got %d", rec.Code)
+	}
+	if rec := request("/api/v1/scan?wallet=0x9999999999999999999999999999999999999999", "open-key"); rec.Code != http.StatusOK {
+		t.Errorf("Expected an unscoped key to scan any wallet, got %d", rec.Code)
+	}
+	if rec := request("/api/v1/history/"+wallet, "scoped-key"); rec.Code != http.StatusOK {
+		t.Errorf("Expected a scoped key to access its wallet, got %d", rec.Code)
+	}
+	if rec := request("/api/v1/scan?wallet=0x9999999999999999999999999999999999999999", "scoped-key"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a wallet outside the key's scope, got %d", rec.Code)
+	}
+
+	// Keys added through the admin API work without a restart
+	t.Setenv("ADMIN_KEY", "admin-secret")
+	server := NewServerWithScanner(&mockScanner{}, defaultLogger)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/keys", strings.NewReader(`{"hash":"`+hash("new-key")+`"}`))
+	req.Header.Set("X-Admin-Key", "admin-secret")
+	add := httptest.NewRecorder()
+	adminMiddleware(server.handleAdminKeys)(add, req)
+	if add.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 adding a key, got %d %s", add.Code, add.Body.String())
+	}
+	if rec := request("/api/v1/chains", "new-key"); rec.Code != http.StatusOK {
+		t.Errorf("Expected the hot-added key to be accepted, got %d", rec.Code)
+	}
+}
+
+// encodeAggregate3Results ABI-encodes an aggregate3 (bool,bytes)[] result
+func encodeAggregate3Results(results []multicallResult) string {
+	word := func(n int) string { return fmt.Sprintf("%064x", n) }
+	tuples := make([]string, len(results))
+	for i, r := range results {
+		data := strings.TrimPrefix(r.ReturnData, "0x")
+		success := 0
+		if r.Success {
+			success = 1
+		}
+		tuples[i] = word(success) + word(64) + word(len(data)/2) + data
+	}
+	out := word(32) + word(len(results))
+	offset := 32 * len(results)
+	for _, tuple := range tuples {
+		out += word(offset)
+		offset += len(tuple) / 2
+	}
+	return "0x" + out + strings.Join(tuples, "")
+}
+
+func TestBatchTokenMetadataFetcher_UsesOneMulticall(t *testing.T) {
+	word := func(n int) string { return fmt.Sprintf("%064x", n) }
+	abiString := func(s string) string {
+		return word(32) + word(len(s)) + fmt.Sprintf("%-64s", hex.EncodeToString([]byte(s)))[:64]
+	}
+
+	tokens := map[string]string{
+		"0x7777777777777777777777777777777777770001": "AAA",
+		"0x7777777777777777777777777777777777770002": "BBB",
+	}
+	reverting := "0x7777777777777777777777777777777777770003"
+
+	var multicalls, singleCalls atomic.Int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var call struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+		if !strings.EqualFold(call.To, multicall3Address) {
+			singleCalls.Add(1)
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"message":"execution reverted"}}`)
+			return
+		}
+		multicalls.Add(1)
+
+		// Answer each (target, calldata) pair; targets sit in the first word of each tuple
+		data := strings.TrimPrefix(call.Data, "0x82ad56cb")
+		count, _ := strconv.ParseInt(data[64:128], 16, 64)
+		results := make([]multicallResult, count)
+		for i := range results {
+			offset, _ := strconv.ParseInt(data[128+64*i:192+64*i], 16, 64)
+			tuple := 128 + int(offset)*2
+			target := "0x" + data[tuple+24:tuple+64]
+			selector := data[tuple+64*4 : tuple+64*4+8]
+			symbol, ok := tokens[target]
+			switch {
+			case !ok:
+				results[i] = multicallResult{Success: false, ReturnData: "0x"}
+			case selector == "95d89b41":
+				results[i] = multicallResult{Success: true, ReturnData: "0x" + abiString(symbol)}
+			default:
+				results[i] = multicallResult{Success: true, ReturnData: "0x" + word(6)}
+			}
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, encodeAggregate3Results(results))
+	}))
+	defer rpc.Close()
+
+	client := NewChainClient(ChainID("multicall-test"), rpc.URL, defaultLogger)
+	client.prefetchTokenMetadata(context.Background(), []string{
+		"0x7777777777777777777777777777777777770001",
+		"0x7777777777777777777777777777777777770002",
+		"0x7777777777777777777777777777777777770002",
+		reverting,
+		"0xdac17f958d2ee523a2206206994597c13d831ec7", // known token, never fetched
+	})
+
+	if n := multicalls.Load(); n != 1 {
+		t.Fatalf("Expected one Multicall3 request, got %d", n)
+	}
+	if symbol := getTokenSymbol("0x7777777777777777777777777777777777770002", client); symbol != "BBB" {
+		t.Errorf("Expected batched symbol BBB, got %s", symbol)
+	}
+	if meta, _ := cachedTokenMetadata(ChainID("multicall-test"), "0x7777777777777777777777777777777777770001"); meta.Decimals != 6 {
+		t.Errorf("Expected batched decimals 6, got %d", meta.Decimals)
+	}
+	if symbol := getTokenSymbol(reverting, client); symbol != "0x7777...0003" {
+		t.Errorf("Expected the shortened address for a token without symbol(), got %s", symbol)
+	}
+	if n := singleCalls.Load(); n != 0 {
+		t.Errorf("Expected no single symbol() calls after the batch, got %d", n)
+	}
+
+	// Another client of the same chain shares the cache
+	other := NewChainClient(ChainID("multicall-test"), rpc.URL, defaultLogger)
+	if symbol := getTokenSymbol("0x7777777777777777777777777777777777770001", other); symbol != "AAA" || multicalls.Load() != 1 {
+		t.Errorf("Expected the cached symbol AAA without new requests, got %s", symbol)
+	}
+}
+
+func TestChainClient_DetectTokenStandard(t *testing.T) {
+	erc20 := "0x8888888888888888888888888888888888880001"
+	erc721 := "0x8888888888888888888888888888888888880002"
+	erc1155 := "0x8888888888888888888888888888888888880003"
+	unknown := "0x8888888888888888888888888888888888880004"
+
+	var multicalls atomic.Int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		multicalls.Add(1)
+		var req struct {
+			Params []struct {
+				Data string `json:"data"`
+			} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		data := strings.TrimPrefix(req.Params[0].Data, "0x82ad56cb")
+		count, _ := strconv.ParseInt(data[64:128], 16, 64)
+		results := make([]multicallResult, count)
+		for i := range results {
+			offset, _ := strconv.ParseInt(data[128+64*i:192+64*i], 16, 64)
+			tuple := 128 + int(offset)*2
+			target := "0x" + data[tuple+24:tuple+64]
+			call := data[tuple+64*4 : tuple+64*4+16]
+			results[i] = multicallResult{Success: false, ReturnData: "0x"}
+			switch {
+			case target == unknown:
+			case call == "313ce56700000000": // decimals()
+				if target == erc20 {
+					results[i] = multicallResult{Success: true, ReturnData: fmt.Sprintf("0x%064x", 18)}
+				}
+			case call == "01ffc9a780ac58cd", call == "01ffc9a7d9b67a26": // supportsInterface(ERC721 / ERC1155)
+				supported := (target == erc721 && call[8:] == "80ac58cd") || (target == erc1155 && call[8:] == "d9b67a26")
+				results[i] = multicallResult{Success: true, ReturnData: fmt.Sprintf("0x%064x", map[bool]int{true: 1}[supported])}
+			}
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, encodeAggregate3Results(results))
+	}))
+	defer rpc.Close()
+
+	client := NewChainClient(ChainID("standard-test"), rpc.URL, defaultLogger)
+	for token, want := range map[string]string{erc20: "ERC20", erc721: "ERC721", erc1155: "ERC1155", unknown: "UNKNOWN"} {
+		if standard, err := client.DetectTokenStandard(context.Background(), token); err != nil || standard != want {
+			t.Errorf("Expected %s for %s, got %s (%v)", want, token, standard, err)
+		}
+	}
+	if n := multicalls.Load(); n != 4 {
+		t.Fatalf("Expected one Multicall3 request per token, got %d", n)
+	}
+
+	// Standards are cached, and a scan's prefetch records them too
+	if standard, _ := client.DetectTokenStandard(context.Background(), erc721); standard != "ERC721" || multicalls.Load() != 4 {
+		t.Errorf("Expected the cached standard without a new request, got %s", standard)
+	}
+	if !client.isNFTContract(erc1155) || client.isNFTContract(erc20) {
+		t.Error("Expected only the NFT contracts to be skipped by ERC20 parsing")
+	}
+}
+
+func TestChainClient_GetTokenDecimalsCachesOnChainValue(t *testing.T) {
+	arbitrumUSDC := "0xFF970A61A04b1cA14834A43f5dE4533eBDDB5CC8"
+	var calls atomic.Int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "0x313ce567") {
+			t.Errorf("Expected a decimals() call, got %s", body)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%064x"}`, 6)
+	}))
+	defer rpc.Close()
+
+	client := NewChainClient(Arbitrum, rpc.URL, defaultLogger)
+	for i := 0; i < 2; i++ {
+		decimals, err := client.GetTokenDecimals(context.Background(), arbitrumUSDC)
+		if err != nil || decimals != 6 {
+			t.Fatalf("Expected 6 decimals, got %d (%v)", decimals, err)
+		}
+	}
+	if got := client.formatAllowanceForToken(context.Background(), big.NewInt(2_500_000), arbitrumUSDC); got != "2.5000" {
+		t.Errorf("Expected 2.5 USDC, got %s", got)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected decimals() to be called once, got %d calls", n)
+	}
+
+	// Seeded tokens never hit the chain
+	if decimals, err := client.GetTokenDecimals(context.Background(), "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599"); err != nil || decimals != 8 || calls.Load() != 1 {
+		t.Errorf("Expected seeded WBTC decimals 8 without a call, got %d (%v, %d calls)", decimals, err, calls.Load())
+	}
+}
+
+func TestAnnotatePermitSupport_ReadsNoncesInOneBatch(t *testing.T) {
+	wallet := "0x1234567890123456789012345678901234567890"
+	permitToken := "0x6b175474e89094c44da98b954eedeac495271d0f"
+	plainToken := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+
+	var batches atomic.Int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batches.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "7ecebe00"+strings.Repeat("0", 24)+wallet[2:]) {
+			t.Errorf("Expected nonces(wallet) calls, got %s", body)
+		}
+		// Calls keep the approvals' token order: DAI first, USDT second
+		results := []multicallResult{
+			{Success: true, ReturnData: fmt.Sprintf("0x%064x", 3)},
+			{Success: false, ReturnData: "0x"},
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, encodeAggregate3Results(results))
+	}))
+	defer rpc.Close()
+
+	approvals := []Approval{
+		{TokenAddress: permitToken, SpenderAddress: "0x1111111111111111111111111111111111111111"},
+		{TokenAddress: plainToken, SpenderAddress: "0x1111111111111111111111111111111111111111"},
+		{TokenAddress: permitToken, SpenderAddress: "0x2222222222222222222222222222222222222222"},
+		{TokenAddress: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", IsNFT: true},
+	}
+	NewChainClient(Ethereum, rpc.URL, defaultLogger).annotatePermitSupport(context.Background(), wallet, approvals)
+
+	if n := batches.Load(); n != 1 {
+		t.Fatalf("Expected one Multicall3 request, got %d", n)
+	}
+	if !approvals[0].SupportsPermit || approvals[0].PermitNonce != 3 || !approvals[2].SupportsPermit {
+		t.Errorf("Expected DAI approvals to support permit with nonce 3, got %+v", approvals[0])
+	}
+	if approvals[1].SupportsPermit || approvals[3].SupportsPermit {
+		t.Errorf("Expected USDT and NFT approvals not to support permit")
+	}
+}
+
+func TestHoneypotRouters_AreValidAddresses(t *testing.T) {
+	for chain, router := range honeypotRouters {
+		for name, address := range map[string]string{"router": router.Router, "wrapped native": router.WrappedNative} {
+			if !isValidEthereumAddress(address) {
+				t.Errorf("Expected the %s %s of %s to be a 20-byte address", name, address, chain)
+			}
+		}
+	}
+}
+
+func TestHoneypotChecker_SimulatesBuyAndSell(t *testing.T) {
+	token := "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984"
+	tokens := fmt.Sprintf("0x%064x", 1000)
+
+	// newRouterRPC answers the buy simulation, then the sell simulation with sellStatus
+	// and the trader's native balance growing by sellWei
+	newRouterRPC := func(sellStatus string, sellWei int64) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Method == "eth_blockNumber" {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x100"}`)
+				return
+			}
+			if req.Method != "eth_simulateV1" || string(req.Params[1]) != `"0x100"` {
+				t.Errorf("Expected eth_simulateV1 on the pinned block, got %s %s", req.Method, req.Params)
+			}
+			ok := func(data string) string { return fmt.Sprintf(`{"status":"0x1","returnData":"%s"}`, data) }
+			var calls []string
+			if strings.Contains(string(req.Params[0]), "d06ca61f") {
+				quote := fmt.Sprintf("0x%064x%064x%064x%064x", 32, 2, int64(1e16), 1000)
+				calls = []string{ok(quote), ok("0x"), ok(tokens)}
+			} else {
+				if !strings.Contains(string(req.Params[0]), "791ac947"+fmt.Sprintf("%064x", 1000)) {
+					t.Errorf("Expected a sell of the 1000 tokens received, got %s", req.Params[0])
+				}
+				before := int64(9e18)
+				calls = []string{ok("0x"), ok(fmt.Sprintf("0x%064x", 1)),
+					ok(fmt.Sprintf("0x%064x", before)),
+					fmt.Sprintf(`{"status":"%s","returnData":"0x"}`, sellStatus),
+					ok(fmt.Sprintf("0x%064x", before+sellWei))}
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":[{"calls":[%s]}]}`, strings.Join(calls, ","))
+		}))
+	}
+
+	t.Run("sell reverts", func(t *testing.T) {
+		rpc := newRouterRPC("0x0", 0)
+		defer rpc.Close()
+		checker := NewHoneypotChecker(map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, rpc.URL, defaultLogger)})
+
+		result, err := checker.Check(context.Background(), token, Ethereum)
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if !result.IsHoneypot || result.HiddenFee != 100 || result.TokensReceived != "1000" {
+			t.Errorf("Expected a honeypot with a 100%% fee, got %+v", result)
+		}
+	})
+
+	t.Run("sell returns 95%", func(t *testing.T) {
+		rpc := newRouterRPC("0x1", 95e14)
+		defer rpc.Close()
+		checker := NewHoneypotChecker(map[ChainID]*ChainClient{Ethereum: NewChainClient(Ethereum, rpc.URL, defaultLogger)})
+
+		result, err := checker.Check(context.Background(), token, Ethereum)
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if result.IsHoneypot || result.HiddenFee < 4.99 || result.HiddenFee > 5.01 || result.SellAmountWei != "9500000000000000" {
+			t.Errorf("Expected a 5%% hidden fee, got %+v", result)
+		}
+		if risk := result.contractRisk(token, Ethereum); risk.IsHoneypot || risk.HiddenFee != result.HiddenFee || risk.RiskLevel != "safe" {
+			t.Errorf("Expected a safe ContractRisk carrying the fee, got %+v", risk)
+		}
+	})
+
+	t.Run("chain without router", func(t *testing.T) {
+		checker := NewHoneypotChecker(map[ChainID]*ChainClient{})
+		if _, err := checker.Check(context.Background(), token, ChainID("zksync")); err == nil {
+			t.Errorf("Expected an error for a chain without a DEX router")
+		}
+	})
+}
+
+func TestSpenderDatabase_ReloadMergesDataFiles(t *testing.T) {
+	dir := t.TempDir()
+	drainer := "0x00000000000000000000000000000000deadbeef"
+	spendersFile := dir + "/spenders.json"
+	riskFile := dir + "/spender-risk.json"
+	db := NewSpenderDatabase(spendersFile, riskFile, dir+"/tokens.json")
+	previous := spenderDB
+	spenderDB = db
+	defer func() { spenderDB = previous }()
+
+	os.WriteFile(spendersFile, []byte(`{"0x00000000000000000000000000000000DEADBEEF": "🚨 DRAINER: SAFE Drainer"}`), 0o644)
+	os.WriteFile(riskFile, []byte(`{"`+drainer+`": "critical"}`), 0o644)
+	if _, _, ok := db.Spender(drainer); ok {
+		t.Fatalf("Expected data files to be ignored until reloaded")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/reload", nil)
+	rec := httptest.NewRecorder()
+	(&Server{}).handleAdminReload(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from reload, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if name, level := getSpenderInfo(drainer); name != "🚨 DRAINER: SAFE Drainer" || level != "critical" {
+		t.Errorf("Expected the reloaded drainer to be critical, got %q %q", name, level)
+	}
+	if name, level := getSpenderInfo("0x7a250d5630b4cf539739df2c5dacb4c659f2488d"); name != "✅ Uniswap V2: Router" || level != "safe" {
+		t.Errorf("Expected built-in spenders to be kept, got %q %q", name, level)
+	}
+
+	// An invalid file fails the reload and keeps the loaded data
+	os.WriteFile(riskFile, []byte(`{"`+drainer+`": "dangerous"}`), 0o644)
+	if err := db.Reload(); err == nil {
+		t.Errorf("Expected an invalid risk level to fail the reload")
+	}
+	if _, level, _ := db.Spender(drainer); level != "critical" {
+		t.Errorf("Expected the previous risk level to be kept, got %q", level)
+	}
+}
+
+func TestWebhookDispatcher_SignsAndRetriesDeliveries(t *testing.T) {
+	saved := webhookRetryDelays
+	webhookRetryDelays = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	defer func() { webhookRetryDelays = saved }()
+
+	var attempts atomic.Int32
+	delivered := make(chan []byte, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("hook-secret"))
+		mac.Write(body)
+		if got := r.Header.Get("X-Sentinel-Signature"); got != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("Expected an HMAC-SHA256 signature of the body, got %q", got)
+		}
+		// The first two deliveries fail
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		delivered <- body
+	}))
+	defer hook.Close()
+
+	dispatcher := NewWebhookDispatcher(hook.URL, "hook-secret", defaultLogger)
+	dispatcher.Dispatch(&WalletScanResult{WalletAddress: "0x1234567890123456789012345678901234567890", OverallRiskScore: 42})
+
+	select {
+	case body := <-delivered:
+		var result WalletScanResult
+		if err := json.Unmarshal(body, &result); err != nil || result.OverallRiskScore != 42 {
+			t.Errorf("Expected the scan result as body, got %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the delivery to succeed on the third attempt, got %d attempts", attempts.Load())
+	}
+
+	// The admin test endpoint sends once and reports the outcome
+	rec := httptest.NewRecorder()
+	(&Server{webhooks: dispatcher}).handleWebhookTest(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/webhooks/test", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"delivered":true`) {
+		t.Errorf("Expected a delivered test webhook, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	var nilDispatcher *WebhookDispatcher
+	nilDispatcher.Dispatch(&WalletScanResult{}) // disabled webhooks are a no-op
+}
+
+func TestWebhookDispatcher_BoundsWorkersAndDrainsOnShutdown(t *testing.T) {
+	var inFlight, maxInFlight, delivered atomic.Int32
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		delivered.Add(1)
+	}))
+	defer hook.Close()
+
+	dispatcher := NewWebhookDispatcher(hook.URL, "", defaultLogger)
+	for i := 0; i < 3*webhookWorkers; i++ {
+		dispatcher.Dispatch(&WalletScanResult{WalletAddress: "0x1234567890123456789012345678901234567890"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := dispatcher.Shutdown(ctx); err != nil {
+		t.Fatalf("Expected the queue to drain, got %v", err)
+	}
+	if got := delivered.Load(); got != 3*webhookWorkers {
+		t.Errorf("Expected every queued delivery before Shutdown returned, got %d", got)
+	}
+	if got := maxInFlight.Load(); got > webhookWorkers {
+		t.Errorf("Expected at most %d concurrent deliveries, got %d", webhookWorkers, got)
+	}
+
+	// Results dispatched after shutdown are dropped
+	dispatcher.Dispatch(&WalletScanResult{})
+	if err := dispatcher.Shutdown(ctx); err != nil || delivered.Load() != 3*webhookWorkers {
+		t.Errorf("Expected no delivery after shutdown, got %d (%v)", delivered.Load(), err)
+	}
+}
+
+func TestOpenAPIHandler_ServesSpecWithGeneratedSchemas(t *testing.T) {
+	rec := httptest.NewRecorder()
+	openAPIHandler()(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Expected a JSON spec, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var spec struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.0.") {
+		t.Errorf("Expected an OpenAPI 3.0 document, got %q", spec.OpenAPI)
+	}
+	for _, path := range []string{"/health", "/api/v1/scan", "/api/v1/chains", "/api/v1/analyze"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("Expected %s to be documented", path)
+		}
+	}
+
+	// Schemas come from the structs, so new fields show up without editing the YAML
+	for name, field := range map[string]string{
+		"WalletScanResult":       "walletAddress",
+		"Approval":               "spenderAddress",
+		"ContractAnalysisResult": "overall_risk",
+		"ContractRisk":           "isHoneypot",
+		"ChainResult":            "approvalsFound",
+	} {
+		if _, ok := spec.Components.Schemas[name].Properties[field]; !ok {
+			t.Errorf("Expected schema %s with property %s", name, field)
+		}
+	}
+	if strings.Contains(rec.Body.String(), "$defs") {
+		t.Error("Expected JSON Schema $defs references to be rewritten to components")
+	}
+
+	rec = httptest.NewRecorder()
+	handleDocs(rec, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	if !strings.Contains(rec.Body.String(), `url: "/api/v1/openapi.json"`) {
+		t.Errorf("Expected Swagger UI to load the spec, got %s", rec.Body.String())
+	}
+}